@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"taskmanager/models"
+)
+
+// minAPIKeyLength keeps keys long enough that they cannot be guessed
+const minAPIKeyLength = 16
+
+// APIKey is a static key and the principal it authenticates. A key with a
+// Workspace only works in that workspace.
+type APIKey struct {
+	Key       string   `json:"key"`
+	Subject   string   `json:"subject"`
+	Roles     []string `json:"roles,omitempty"`
+	Workspace string   `json:"workspace,omitempty"`
+}
+
+func (k APIKey) validate() error {
+	if len(k.Key) < minAPIKeyLength {
+		return fmt.Errorf("key must be at least %d characters", minAPIKeyLength)
+	}
+	if k.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if k.Workspace != "" && !models.IsValidWorkspace(k.Workspace) {
+		return fmt.Errorf("invalid workspace %q", k.Workspace)
+	}
+	return nil
+}
+
+// LoadAPIKeys reads a JSON array of API keys from path
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return keys, nil
+}
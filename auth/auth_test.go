@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// testKeySet returns a JWKS document with an HS256 key "hs" and an RS256
+// key "rs", and the RSA private key matching the latter
+func testKeySet(t *testing.T) ([]byte, *rsa.PrivateKey) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hs", "alg": "HS256", "k": b64(hmacSecret)},
+		{"kty": "RSA", "kid": "rs", "use": "sig", "n": b64(priv.N.Bytes()), "e": b64(big.NewInt(int64(priv.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256"},
+	}}
+	data, _ := json.Marshal(doc)
+	return data, priv
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func isUnauthorized(err error) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == http.StatusUnauthorized
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	a, err := NewAuthenticator(Config{APIKeys: []APIKey{{Key: "k-0123456789abcdef", Subject: "ci-bot", Roles: []string{"member"}}}})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() unexpected error: %v", err)
+	}
+
+	p, err := a.AuthenticateAPIKey("k-0123456789abcdef")
+	if err != nil || p.Subject != "ci-bot" || p.Method != models.AuthMethodAPIKey || len(p.Roles) != 1 {
+		t.Errorf("AuthenticateAPIKey() = %+v, %v, want ci-bot by API key", p, err)
+	}
+	if _, err := a.AuthenticateAPIKey("k-0123456789abcdeX"); !isUnauthorized(err) {
+		t.Errorf("AuthenticateAPIKey() with wrong key error = %v, want 401", err)
+	}
+	if _, err := a.AuthenticateToken("a.b.c"); !isUnauthorized(err) {
+		t.Errorf("AuthenticateToken() without a key set error = %v, want 401", err)
+	}
+
+	invalid := [][]APIKey{
+		{{Key: "short", Subject: "x"}},
+		{{Key: "k-0123456789abcdef"}},
+		{{Key: "k-0123456789abcdef", Subject: "a"}, {Key: "k-0123456789abcdef", Subject: "b"}},
+		{{Key: "k-0123456789abcdef", Subject: "x", Workspace: "Team A"}},
+	}
+	for _, keys := range invalid {
+		if _, err := NewAuthenticator(Config{APIKeys: keys}); err == nil {
+			t.Errorf("NewAuthenticator(%+v) expected an error", keys)
+		}
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	os.WriteFile(path, []byte(`[{"key":"k-0123456789abcdef","subject":"ci-bot","roles":["admin"]}]`), 0o600)
+	keys, err := LoadAPIKeys(path)
+	if err != nil || len(keys) != 1 || keys[0].Subject != "ci-bot" || keys[0].Roles[0] != "admin" {
+		t.Errorf("LoadAPIKeys() = %+v, %v", keys, err)
+	}
+
+	os.WriteFile(path, []byte(`{"key":"oops"}`), 0o600)
+	if _, err := LoadAPIKeys(path); err == nil {
+		t.Error("LoadAPIKeys() with an object expected an error")
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	jwks, priv := testKeySet(t)
+	keys, err := ParseKeySet(jwks)
+	if err != nil {
+		t.Fatalf("ParseKeySet() unexpected error: %v", err)
+	}
+	a, _ := NewAuthenticator(Config{Keys: keys, Issuer: "https://idp.example.com", Audience: "tasks"})
+
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "alice",
+			"iss":   "https://idp.example.com",
+			"aud":   []string{"tasks", "other"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"roles": []string{"admin"},
+		}
+	}
+	with := func(key string, value any) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	rsaPublicKeyAsSecret := priv.PublicKey.N.Bytes()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"HS256 by kid", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, valid()), false},
+		{"HS256 without kid", sign(t, jwt.SigningMethodHS256, "", hmacSecret, valid()), false},
+		{"RS256 by kid", sign(t, jwt.SigningMethodRS256, "rs", priv, valid()), false},
+		{"Within clock skew", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("exp", now.Add(-30*time.Second).Unix())), false},
+		{"Expired", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("exp", now.Add(-time.Hour).Unix())), true},
+		{"No expiry", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("exp", nil)), true},
+		{"Not yet valid", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("nbf", now.Add(time.Hour).Unix())), true},
+		{"Wrong issuer", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("iss", "https://evil.example.com")), true},
+		{"Wrong audience", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("aud", "billing")), true},
+		{"No subject", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("sub", nil)), true},
+		{"Wrong secret", sign(t, jwt.SigningMethodHS256, "hs", []byte("fedcba9876543210fedcba9876543210"), valid()), true},
+		{"Unknown kid", sign(t, jwt.SigningMethodRS256, "other", priv, valid()), true},
+		{"Kid of a key for another algorithm", sign(t, jwt.SigningMethodHS256, "rs", rsaPublicKeyAsSecret, valid()), true},
+		{"RSA public key as HMAC secret", sign(t, jwt.SigningMethodHS256, "", rsaPublicKeyAsSecret, valid()), true},
+		{"Unsigned", sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, valid()), true},
+		{"Invalid workspace", sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, with("workspace", "../other")), true},
+		{"Malformed", "not-a-token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.AuthenticateToken(tt.token)
+			if tt.wantErr {
+				if !isUnauthorized(err) {
+					t.Errorf("AuthenticateToken() error = %v, want 401", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateToken() unexpected error: %v", err)
+			}
+			if p.Subject != "alice" || p.Method != models.AuthMethodToken || len(p.Roles) != 1 || p.Roles[0] != "admin" {
+				t.Errorf("AuthenticateToken() = %+v, want alice with the admin role", p)
+			}
+		})
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	invalid := []string{
+		`not json`,
+		`{"keys":[]}`,
+		`{"keys":[{"kty":"EC","crv":"P-256"}]}`,
+		`{"keys":[{"kty":"oct","k":"c2hvcnQ"}]}`,
+		`{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"oct","use":"enc","k":"` + b64(hmacSecret) + `"}]}`,
+	}
+	for _, doc := range invalid {
+		if _, err := ParseKeySet([]byte(doc)); err == nil {
+			t.Errorf("ParseKeySet(%s) expected an error", doc)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Minimum key sizes from RFC 7518
+const (
+	minHMACKeyBytes = 32
+	minRSAKeyBits   = 2048
+)
+
+// KeySet holds the keys bearer tokens may be signed with: symmetric keys
+// for HS256 and RSA public keys for RS256
+type KeySet struct {
+	keys []verificationKey
+}
+
+type verificationKey struct {
+	id  string
+	alg string
+	key jwt.VerificationKey
+}
+
+// jsonWebKey is the subset of RFC 7517 used to describe HS256 and RS256 keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// LoadKeySet reads a JWKS document from path
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set, err := ParseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return set, nil
+}
+
+// ParseKeySet reads a JWKS document. Keys for other algorithms or for
+// encryption are skipped, but the set must contain at least one usable key.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{}
+	for i, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var (
+			key verificationKey
+			err error
+		)
+		switch {
+		case jwk.Kty == "oct" && (jwk.Alg == "" || jwk.Alg == jwt.SigningMethodHS256.Alg()):
+			key, err = hmacKey(jwk)
+		case jwk.Kty == "RSA" && (jwk.Alg == "" || jwk.Alg == jwt.SigningMethodRS256.Alg()):
+			key, err = rsaKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		set.keys = append(set.keys, key)
+	}
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("no HS256 or RS256 signing keys")
+	}
+	return set, nil
+}
+
+func hmacKey(jwk jsonWebKey) (verificationKey, error) {
+	secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("invalid k: %w", err)
+	}
+	if len(secret) < minHMACKeyBytes {
+		return verificationKey{}, fmt.Errorf("HS256 key must be at least %d bytes", minHMACKeyBytes)
+	}
+	return verificationKey{id: jwk.Kid, alg: jwt.SigningMethodHS256.Alg(), key: secret}, nil
+}
+
+func rsaKey(jwk jsonWebKey) (verificationKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("invalid n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return verificationKey{}, fmt.Errorf("invalid e")
+	}
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if pub.N.BitLen() < minRSAKeyBits {
+		return verificationKey{}, fmt.Errorf("RS256 key must be at least %d bits", minRSAKeyBits)
+	}
+	return verificationKey{id: jwk.Kid, alg: jwt.SigningMethodRS256.Alg(), key: pub}, nil
+}
+
+// verificationKeys is a jwt.Keyfunc returning the keys that may have signed
+// token: those for its algorithm, narrowed to the one named by its kid
+// header if it has one. Matching on algorithm keeps an RSA public key from
+// ever being used as an HMAC secret.
+func (s *KeySet) verificationKeys(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	alg := token.Method.Alg()
+	var set jwt.VerificationKeySet
+	for _, k := range s.keys {
+		if k.alg == alg && (kid == "" || k.id == kid) {
+			set.Keys = append(set.Keys, k.key)
+		}
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("no %s key with kid %q", alg, kid)
+	}
+	return set, nil
+}
@@ -17,18 +17,149 @@ const (
 
 // HTTP status messages
 const (
-	MessageTaskCreated    = "Task created successfully"
-	MessageTaskUpdated    = "Task updated successfully"
-	MessageTaskDeleted    = "Task deleted successfully"
-	MessageTaskNotFound   = "Task not found"
-	MessageInvalidInput   = "Invalid input"
-	MessageInternalError  = "Internal server error"
+	MessageTaskCreated          = "Task created successfully"
+	MessageTaskUpdated          = "Task updated successfully"
+	MessageTaskDeleted          = "Task deleted successfully"
+	MessageTaskRestored         = "Task restored successfully"
+	MessageTaskNotDeleted       = "task is not in the trash"
+	MessageOpenSubtasks         = "cannot complete a task while it has open subtasks"
+	MessageTaskBlocked          = "cannot start a task until every task blocking it is completed"
+	MessageTaskNotFound         = "Task not found"
+	MessageInvalidInput         = "Invalid input"
+	MessageInternalError        = "Internal server error"
+	MessageTaskTransitioned     = "Task status changed successfully"
+	MessageInvalidTransition    = "cannot change status from %s to %s"
+	MessageVersionConflict      = "task was modified by someone else; reload it and retry"
+	MessageInvalidPatch         = "invalid patch"
+	MessagePatchTestFailed      = "patch test operation failed"
+	MessageUnsupportedPatchType = "unsupported patch content type; use application/merge-patch+json or application/json-patch+json"
+	MessageWebhookCreated       = "Webhook created successfully"
+	MessageWebhookUpdated       = "Webhook updated successfully"
+	MessageWebhookDeleted       = "Webhook deleted successfully"
+	MessageEventBusClosed       = "event stream is shutting down"
+	MessageUnknownMessageType   = "unknown message type"
+	MessageAuthRequired         = "authentication required"
+	MessageInvalidAPIKey        = "invalid API key"
+	MessageInvalidToken         = "invalid bearer token"
+	MessageTokenExpired         = "bearer token has expired"
+	MessageForbidden            = "permission %q is required"
+	MessageForbiddenUnlessOwn   = "permission %q is required, or %q for tasks assigned to you"
+	MessageWorkspaceMismatch    = "credentials are limited to another workspace"
+	MessageTaskIDTaken          = "task ID is already used in another workspace"
+	MessageProjectCreated       = "Project created successfully"
+	MessageProjectUpdated       = "Project updated successfully"
+	MessageProjectDeleted       = "Project deleted successfully"
+	MessageProjectNotEmpty      = "project still has tasks; move or delete them first"
+	MessageLabelRenamed         = "Label renamed successfully"
+	MessageLabelsMerged         = "Labels merged successfully"
+	MessageLabelInUse           = "label %q is already in use; merge the labels instead"
+	MessageCommentCreated       = "Comment added successfully"
+	MessageCommentUpdated       = "Comment updated successfully"
+	MessageCommentDeleted       = "Comment deleted successfully"
+	MessageForbiddenComment     = "permission %q is required, or %q for your own comments"
+	MessageAttachmentUploaded   = "Attachment uploaded successfully"
+	MessageAttachmentDeleted    = "Attachment deleted successfully"
+	MessageAttachmentTooLarge   = "attachments must be at most %d bytes"
+	MessageAttachmentType       = "attachments of type %q are not allowed"
+	MessageForbiddenAttachment  = "permission %q is required, or %q for your own attachments"
+	MessageViewCreated          = "View created successfully"
+	MessageViewUpdated          = "View updated successfully"
+	MessageViewDeleted          = "View deleted successfully"
+	MessageForbiddenView        = "permission %q is required to change views you do not own"
+)
+
+// Audit actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// AnonymousActor is recorded when a change is made without an identified caller
+const AnonymousActor = "anonymous"
+
+// SystemActor is recorded for changes made by background jobs
+const SystemActor = "system"
+
+// HeaderActor names the caller a change is attributed to
+const HeaderActor = "X-Actor"
+
+// DefaultWorkspace holds tasks created without naming a workspace, including
+// every task stored before workspaces existed
+const DefaultWorkspace = "default"
+
+// HeaderWorkspace selects the workspace a request operates on
+const HeaderWorkspace = "X-Workspace"
+
+// HeaderAPIKey carries a static API key
+const HeaderAPIKey = "X-API-Key"
+
+// HeaderLastEventID carries the ID of the last event a reconnecting stream
+// client received
+const HeaderLastEventID = "Last-Event-ID"
+
+// Headers sent with every webhook delivery
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// Patch content types
+const (
+	ContentTypeMergePatch = "application/merge-patch+json"
+	ContentTypeJSONPatch  = "application/json-patch+json"
 )
 
 // Validation messages
 const (
-	ValidationTitleRequired  = "title is required"
-	ValidationStatusRequired = "status is required"
-	ValidationInvalidStatus  = "invalid status value"
-	ValidationInvalidPriority = "invalid priority value"
+	ValidationTitleRequired        = "title is required"
+	ValidationStatusRequired       = "status is required"
+	ValidationInvalidStatus        = "invalid status value"
+	ValidationInvalidPriority      = "invalid priority value"
+	ValidationInvalidSortField     = "invalid sort field"
+	ValidationInvalidLimit         = "limit must be between 1 and 200"
+	ValidationInvalidCursor        = "invalid cursor"
+	ValidationInvalidTime          = "invalid time, expected RFC 3339"
+	ValidationInvalidAuditLimit    = "limit must be between 1 and 1000"
+	ValidationInvalidTimeRange     = "from must be before to"
+	ValidationParentNotFound       = "parent task not found"
+	ValidationParentCycle          = "a task cannot be nested under itself or its own subtasks"
+	ValidationInvalidBlockers      = "blockedBy must list distinct task IDs"
+	ValidationBlockerNotFound      = "blocking task not found"
+	ValidationDependencyCycle      = "a task cannot depend on itself or on tasks it blocks"
+	ValidationInvalidBool          = "must be true or false"
+	ValidationRRuleNeedsDueDate    = "a recurring task needs a due date"
+	ValidationInvalidCount         = "count must be between 1 and 100"
+	ValidationInvalidWebhookURL    = "url must be an absolute http or https URL"
+	ValidationInternalWebhookURL   = "url must not point at a loopback, link-local or private address"
+	ValidationInvalidEvents        = "events must list distinct task event types"
+	ValidationInvalidDeliveryLimit = "limit must be between 1 and 500"
+	ValidationInvalidEventID       = "unknown event ID"
+	ValidationSubscriptionRequired = "subscription is required"
+	ValidationSubscriptionInUse    = "subscription name is already in use"
+	ValidationTooManySubscriptions = "too many subscriptions on this connection"
+	ValidationTaskRequired         = "task is required"
+	ValidationTaskIDRequired       = "taskId is required"
+	ValidationInvalidWorkspace     = "workspace must be 1-64 lowercase letters, digits, '-' or '_'"
+	ValidationProjectNameRequired  = "name is required"
+	ValidationProjectNameTooLong   = "name must be at most 100 characters"
+	ValidationProjectNotFound      = "project not found"
+	ValidationInvalidLabels        = "labels must be at most 20 distinct labels of 1-50 lowercase letters, digits, '-', '_', '.' or ':'"
+	ValidationInvalidLabel         = "label must be 1-50 lowercase letters, digits, '-', '_', '.' or ':'"
+	ValidationLabelsRequired       = "at least one label is required"
+	ValidationCommentBodyRequired  = "body is required"
+	ValidationCommentBodyTooLong   = "body must be at most 10000 characters"
+	ValidationMultipartRequired    = "request must be multipart/form-data"
+	ValidationFileRequired         = "file is required"
+	ValidationFileEmpty            = "file is empty"
+	ValidationInvalidFilename      = "filename must be 1-255 characters"
+	ValidationSearchQueryRequired  = "q is required"
+	ValidationInvalidSearchLimit   = "limit must be between 1 and 100"
+	ValidationViewNameRequired     = "name is required"
+	ValidationViewNameTooLong      = "name must be at most 100 characters"
+	ValidationInvalidColumns       = "columns must list distinct task fields"
 )
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var attachmentService services.AttachmentService
+
+// SetupAttachments injects the service behind the attachment endpoints
+func SetupAttachments(attachmentSvc services.AttachmentService) {
+	attachmentService = attachmentSvc
+}
+
+// GetTaskAttachments lists the files attached to a task
+// @Summary List attachments
+// @Description List the files attached to a task, oldest first
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.Attachment
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments [get]
+func GetTaskAttachments(c *gin.Context) {
+	attachments, err := attachmentService.ListAttachments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": attachments, "count": len(attachments)})
+}
+
+// UploadTaskAttachment attaches a file to a task
+// @Summary Upload an attachment
+// @Description Attach the multipart "file" field to a task. The content type is detected from the file itself.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} models.Attachment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Router /tasks/{id}/attachments [post]
+func UploadTaskAttachment(c *gin.Context) {
+	// The parts are streamed straight to the blob store rather than being
+	// buffered by ParseMultipartForm
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		handleError(c, errors.NewValidationError("file", constants.ValidationMultipartRequired))
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			handleError(c, errors.NewValidationError("file", constants.ValidationFileRequired))
+			return
+		}
+		if err != nil {
+			handleError(c, errors.NewBadRequestError(err.Error()))
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment, err := attachmentService.UploadAttachment(c.Request.Context(), c.Param("id"), part.FileName(), part)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{
+			"data":    attachment,
+			"message": constants.MessageAttachmentUploaded,
+		})
+		return
+	}
+}
+
+// GetTaskAttachment retrieves an attachment's metadata
+// @Summary Get an attachment
+// @Description Get an attachment's name, type, size and hash
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} models.Attachment
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments/{attachmentId} [get]
+func GetTaskAttachment(c *gin.Context) {
+	attachment, err := attachmentService.GetAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": attachment})
+}
+
+// DownloadTaskAttachment sends an attachment's content
+// @Summary Download an attachment
+// @Description Download the attached file
+// @Tags attachments
+// @Produce octet-stream
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Header 200 {string} ETag "SHA-256 of the content"
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments/{attachmentId}/content [get]
+func DownloadTaskAttachment(c *gin.Context) {
+	attachment, content, err := attachmentService.OpenAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	etag := strconv.Quote(attachment.SHA256)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    disposition,
+		"ETag":                   etag,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteTaskAttachment removes an attachment
+// @Summary Delete an attachment
+// @Description Delete an attachment from a task
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments/{attachmentId} [delete]
+func DeleteTaskAttachment(c *gin.Context) {
+	if err := attachmentService.DeleteAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": constants.MessageAttachmentDeleted})
+}
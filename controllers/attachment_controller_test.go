@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAttachmentService is a mock implementation of AttachmentService for testing
+type MockAttachmentService struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentService) ListAttachments(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) GetAttachment(ctx context.Context, taskID, id string) (models.Attachment, error) {
+	args := m.Called(ctx, taskID, id)
+	return args.Get(0).(models.Attachment), args.Error(1)
+}
+
+// UploadAttachment reads the content so expectations can match on it
+func (m *MockAttachmentService) UploadAttachment(ctx context.Context, taskID, filename string, content io.Reader) (models.Attachment, error) {
+	data, _ := io.ReadAll(content)
+	args := m.Called(ctx, taskID, filename, string(data))
+	return args.Get(0).(models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) OpenAttachment(ctx context.Context, taskID, id string) (models.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, taskID, id)
+	content, _ := args.Get(1).(io.ReadCloser)
+	return args.Get(0).(models.Attachment), content, args.Error(2)
+}
+
+func (m *MockAttachmentService) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	args := m.Called(ctx, taskID, id)
+	return args.Error(0)
+}
+
+// multipartBody encodes a form with a single file field
+func multipartBody(field, filename, content string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	w.WriteField("note", "ignored")
+	part, _ := w.CreateFormFile(field, filename)
+	part.Write([]byte(content))
+	w.Close()
+	return body, w.FormDataContentType()
+}
+
+func TestUploadTaskAttachment(t *testing.T) {
+	tests := []struct {
+		name           string
+		field          string
+		contentType    string
+		setupMock      func(*MockAttachmentService)
+		expectedStatus int
+	}{
+		{
+			name:  "Valid upload",
+			field: "file",
+			setupMock: func(m *MockAttachmentService) {
+				m.On("UploadAttachment", mock.Anything, "t1", "build.log", "log line").
+					Return(models.Attachment{ID: "a1", TaskID: "t1", Filename: "build.log"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "No file field",
+			field:          "upload",
+			setupMock:      func(m *MockAttachmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not multipart",
+			field:          "file",
+			contentType:    "application/json",
+			setupMock:      func(m *MockAttachmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Too large",
+			field: "file",
+			setupMock: func(m *MockAttachmentService) {
+				m.On("UploadAttachment", mock.Anything, "t1", "build.log", "log line").
+					Return(models.Attachment{}, errors.NewAppError(http.StatusRequestEntityTooLarge, "attachments must be at most 4 bytes"))
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:  "Type not allowed",
+			field: "file",
+			setupMock: func(m *MockAttachmentService) {
+				m.On("UploadAttachment", mock.Anything, "t1", "build.log", "log line").
+					Return(models.Attachment{}, errors.NewAppError(http.StatusUnsupportedMediaType, `attachments of type "text/plain" are not allowed`))
+			},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttachmentService)
+			SetupAttachments(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/tasks/:id/attachments", UploadTaskAttachment)
+
+			body, contentType := multipartBody(tt.field, "build.log", "log line")
+			if tt.contentType != "" {
+				contentType = tt.contentType
+			}
+			req, _ := http.NewRequest("POST", "/tasks/t1/attachments", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDownloadTaskAttachment(t *testing.T) {
+	attachment := models.Attachment{ID: "a1", TaskID: "t1", Filename: "shot 1.png", ContentType: "image/png", Size: 5, SHA256: "abc"}
+	mockService := new(MockAttachmentService)
+	SetupAttachments(mockService)
+	mockService.On("OpenAttachment", mock.Anything, "t1", "a1").
+		Return(attachment, io.NopCloser(bytes.NewReader([]byte("hello"))), nil)
+	mockService.On("OpenAttachment", mock.Anything, "t1", "missing").
+		Return(models.Attachment{}, nil, errors.NewNotFoundError("Attachment"))
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id/attachments/:attachmentId/content", DownloadTaskAttachment)
+
+	req, _ := http.NewRequest("GET", "/tasks/t1/attachments/a1/content", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="shot 1.png"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest("GET", "/tasks/t1/attachments/a1/content", nil)
+	req.Header.Set("If-None-Match", `"abc"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	req, _ = http.NewRequest("GET", "/tasks/t1/attachments/missing/content", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListAndDeleteTaskAttachments(t *testing.T) {
+	mockService := new(MockAttachmentService)
+	SetupAttachments(mockService)
+	mockService.On("ListAttachments", mock.Anything, "t1").Return([]models.Attachment{{ID: "a1"}}, nil)
+	mockService.On("GetAttachment", mock.Anything, "t1", "a1").Return(models.Attachment{ID: "a1", Size: 5}, nil)
+	mockService.On("DeleteAttachment", mock.Anything, "t1", "a1").Return(nil)
+	mockService.On("DeleteAttachment", mock.Anything, "t1", "a2").
+		Return(errors.NewForbiddenError(constants.MessageForbiddenAttachment))
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id/attachments", GetTaskAttachments)
+	router.GET("/tasks/:id/attachments/:attachmentId", GetTaskAttachment)
+	router.DELETE("/tasks/:id/attachments/:attachmentId", DeleteTaskAttachment)
+
+	for _, tc := range []struct {
+		method, url string
+		status      int
+	}{
+		{"GET", "/tasks/t1/attachments", http.StatusOK},
+		{"GET", "/tasks/t1/attachments/a1", http.StatusOK},
+		{"DELETE", "/tasks/t1/attachments/a1", http.StatusOK},
+		{"DELETE", "/tasks/t1/attachments/a2", http.StatusForbidden},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, "%s %s", tc.method, tc.url)
+	}
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var auditService services.AuditService
+
+// SetupAudit injects the service behind the audit endpoints
+func SetupAudit(auditSvc services.AuditService) {
+	auditService = auditSvc
+}
+
+// ActorFromHeader attributes the request's changes to the caller named in the
+// X-Actor header
+func ActorFromHeader() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor := c.GetHeader(constants.HeaderActor); actor != "" {
+			c.Request = c.Request.WithContext(services.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
+
+// GetTaskHistory lists the recorded changes to a task
+// @Summary Get task history
+// @Description List the audit entries for a task, oldest first
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param from query string false "Changed on or after (RFC 3339)"
+// @Param to query string false "Changed before (RFC 3339)"
+// @Param limit query int false "Maximum entries (default 100, max 1000)"
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/history [get]
+func GetTaskHistory(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	entries, err := auditService.TaskHistory(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries, "count": len(entries)})
+}
+
+// GetAuditLog lists recorded changes across all tasks
+// @Summary Get audit log
+// @Description List audit entries for every task, oldest first
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param actor query string false "Filter by actor"
+// @Param from query string false "Changed on or after (RFC 3339)"
+// @Param to query string false "Changed before (RFC 3339)"
+// @Param limit query int false "Maximum entries (default 100, max 1000)"
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} map[string]string
+// @Router /audit [get]
+func GetAuditLog(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	query.Actor = c.Query("actor")
+
+	entries, err := auditService.ListAudit(c.Request.Context(), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries, "count": len(entries)})
+}
+
+// parseAuditQuery reads the time range and limit shared by the audit endpoints
+func parseAuditQuery(c *gin.Context) (models.AuditQuery, error) {
+	var query models.AuditQuery
+	for param, dest := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return models.AuditQuery{}, errors.NewValidationError(param, constants.ValidationInvalidTime)
+		}
+		*dest = &t
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return models.AuditQuery{}, errors.NewValidationError("limit", constants.ValidationInvalidAuditLimit)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
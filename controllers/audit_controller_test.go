@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditService is a mock implementation of AuditService for testing
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) TaskHistory(ctx context.Context, taskID string, q models.AuditQuery) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, taskID, q)
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func (m *MockAuditService) ListAudit(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func TestGetTaskHistory(t *testing.T) {
+	entries := []models.AuditEntry{{ID: "e1", TaskID: "1", Action: constants.AuditActionCreate}}
+	tests := []struct {
+		name           string
+		url            string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name: "Task with history",
+			url:  "/tasks/1/history",
+			setupMock: func(m *MockAuditService) {
+				m.On("TaskHistory", mock.Anything, "1", models.AuditQuery{}).Return(entries, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Unknown task",
+			url:  "/tasks/2/history",
+			setupMock: func(m *MockAuditService) {
+				m.On("TaskHistory", mock.Anything, "2", models.AuditQuery{}).Return([]models.AuditEntry(nil), errors.NewNotFoundError("Task"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid limit",
+			url:            "/tasks/1/history?limit=0",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			SetupAudit(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.GET("/tasks/:id/history", GetTaskHistory)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAuditLog(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		url            string
+		expectedQuery  *models.AuditQuery
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:           "No filters",
+			url:            "/audit",
+			expectedQuery:  &models.AuditQuery{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Time range and actor",
+			url:            "/audit?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&actor=bob&limit=10",
+			expectedQuery:  &models.AuditQuery{From: &from, To: &to, Actor: "bob", Limit: 10},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid time",
+			url:            "/audit?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "from",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			SetupAudit(mockService)
+			if tt.expectedQuery != nil {
+				mockService.On("ListAudit", mock.Anything, *tt.expectedQuery).Return([]models.AuditEntry{}, nil)
+			}
+
+			router := setupTestRouter()
+			router.GET("/audit", GetAuditLog)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedField != "" {
+				var response map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, tt.expectedField, response["field"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestActorFromHeader(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(ActorFromHeader())
+	router.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, services.ActorFromContext(c.Request.Context()))
+	})
+
+	req, _ := http.NewRequest("GET", "/whoami", nil)
+	req.Header.Set(constants.HeaderActor, "alice@example.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "alice@example.com", w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/whoami", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, constants.AnonymousActor, w.Body.String())
+}
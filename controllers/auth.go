@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"taskmanager/auth"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate rejects requests without a valid X-API-Key header or
+// Authorization bearer token and attributes the rest to the principal the
+// credentials belong to. The X-Actor header is ignored once it is in place.
+func Authenticate(authn *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticateRequest(authn, c.Request)
+		if err != nil {
+			challenge := `Bearer realm="tasks"`
+			if c.GetHeader("Authorization") != "" {
+				challenge += `, error="invalid_token"`
+			}
+			c.Header("WWW-Authenticate", challenge)
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(services.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func authenticateRequest(authn *auth.Authenticator, r *http.Request) (models.Principal, error) {
+	if key := r.Header.Get(constants.HeaderAPIKey); key != "" {
+		return authn.AuthenticateAPIKey(key)
+	}
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return models.Principal{}, errors.NewUnauthorizedError(constants.MessageInvalidToken)
+		}
+		return authn.AuthenticateToken(strings.TrimSpace(token))
+	}
+	return models.Principal{}, errors.NewUnauthorizedError(constants.MessageAuthRequired)
+}
+
+// ScopeWorkspace confines each request to one workspace: the one its
+// principal is limited to, else the one named in the X-Workspace header,
+// else the default workspace. Unscoped principals may only name another
+// workspace with workspaces:all under policy; without authentication anyone
+// may. It must run after authentication.
+func ScopeWorkspace(policy models.RolePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace, err := resolveWorkspace(c.Request, policy)
+		if err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(services.WithWorkspace(c.Request.Context(), workspace))
+		c.Next()
+	}
+}
+
+func resolveWorkspace(r *http.Request, policy models.RolePolicy) (string, error) {
+	requested := r.Header.Get(constants.HeaderWorkspace)
+	if requested != "" && !models.IsValidWorkspace(requested) {
+		return "", errors.NewValidationError(constants.HeaderWorkspace, constants.ValidationInvalidWorkspace)
+	}
+	p, ok := services.PrincipalFromContext(r.Context())
+	if ok && p.Workspace != "" {
+		if requested != "" && requested != p.Workspace {
+			return "", errors.NewForbiddenError(constants.MessageWorkspaceMismatch)
+		}
+		return p.Workspace, nil
+	}
+	if requested == "" || requested == constants.DefaultWorkspace {
+		return constants.DefaultWorkspace, nil
+	}
+	if ok && !policy.Grants(p.Roles, models.PermAllWorkspaces) {
+		return "", errors.NewForbiddenError(fmt.Sprintf(constants.MessageForbidden, models.PermAllWorkspaces))
+	}
+	return requested, nil
+}
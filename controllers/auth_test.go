@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/auth"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	keys, err := auth.ParseKeySet([]byte(`{"keys":[{"kty":"oct","k":"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"}]}`))
+	require.NoError(t, err)
+	authn, err := auth.NewAuthenticator(auth.Config{
+		APIKeys: []auth.APIKey{{Key: "k-0123456789abcdef", Subject: "ci-bot"}},
+		Keys:    keys,
+	})
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(secret)
+	require.NoError(t, err)
+
+	router := setupTestRouter()
+	router.Use(ActorFromHeader(), Authenticate(authn))
+	router.GET("/whoami", func(c *gin.Context) {
+		principal, _ := services.PrincipalFromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"actor": services.ActorFromContext(c.Request.Context()), "method": principal.Method})
+	})
+
+	tests := []struct {
+		name            string
+		headers         map[string]string
+		expectedStatus  int
+		expectedActor   string
+		expectedError   string
+		expectedWWWAuth string
+	}{
+		{
+			name:            "No credentials",
+			headers:         map[string]string{constants.HeaderActor: "mallory"},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   constants.MessageAuthRequired,
+			expectedWWWAuth: `Bearer realm="tasks"`,
+		},
+		{
+			name:           "API key",
+			headers:        map[string]string{constants.HeaderAPIKey: "k-0123456789abcdef", constants.HeaderActor: "mallory"},
+			expectedStatus: http.StatusOK,
+			expectedActor:  "ci-bot",
+		},
+		{
+			name:           "Wrong API key",
+			headers:        map[string]string{constants.HeaderAPIKey: "k-0000000000000000"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  constants.MessageInvalidAPIKey,
+		},
+		{
+			name:           "Bearer token",
+			headers:        map[string]string{"Authorization": "bearer " + token},
+			expectedStatus: http.StatusOK,
+			expectedActor:  "alice",
+		},
+		{
+			name:            "Basic credentials",
+			headers:         map[string]string{"Authorization": "Basic YWxpY2U6cGFzcw=="},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   constants.MessageInvalidToken,
+			expectedWWWAuth: `Bearer realm="tasks", error="invalid_token"`,
+		},
+		{
+			name:           "Tampered token",
+			headers:        map[string]string{"Authorization": "Bearer " + token + "x"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  constants.MessageInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/whoami", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if tt.expectedActor != "" {
+				assert.Equal(t, tt.expectedActor, response["actor"])
+			}
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"])
+			}
+			if tt.expectedWWWAuth != "" {
+				assert.Equal(t, tt.expectedWWWAuth, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestScopeWorkspace(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{
+		{Key: "k-0123456789abcdef", Subject: "ci-bot"},
+		{Key: "k-fedcba9876543210", Subject: "team-bot", Workspace: "team-a"},
+		{Key: "k-00112233445566778899", Subject: "ops", Roles: []string{models.RoleAdmin}},
+	}})
+	require.NoError(t, err)
+
+	router := setupTestRouter()
+	router.Use(Authenticate(authn), ScopeWorkspace(models.DefaultRolePolicy()))
+	router.GET("/workspace", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"workspace": services.WorkspaceFromContext(c.Request.Context())})
+	})
+
+	tests := []struct {
+		name              string
+		apiKey            string
+		workspace         string
+		expectedStatus    int
+		expectedWorkspace string
+		expectedError     string
+	}{
+		{"Default", "k-0123456789abcdef", "", http.StatusOK, constants.DefaultWorkspace, ""},
+		{"Unscoped key naming the default", "k-0123456789abcdef", constants.DefaultWorkspace, http.StatusOK, constants.DefaultWorkspace, ""},
+		{"Unscoped non-admin key escaping the default", "k-0123456789abcdef", "team-b", http.StatusForbidden, "", models.PermAllWorkspaces},
+		{"Unscoped admin key from header", "k-00112233445566778899", "team-b", http.StatusOK, "team-b", ""},
+		{"Invalid header", "k-0123456789abcdef", "Team B", http.StatusBadRequest, "", constants.ValidationInvalidWorkspace},
+		{"From principal", "k-fedcba9876543210", "", http.StatusOK, "team-a", ""},
+		{"Header matching principal", "k-fedcba9876543210", "team-a", http.StatusOK, "team-a", ""},
+		{"Header escaping principal", "k-fedcba9876543210", "team-b", http.StatusForbidden, "", constants.MessageWorkspaceMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/workspace", nil)
+			req.Header.Set(constants.HeaderAPIKey, tt.apiKey)
+			if tt.workspace != "" {
+				req.Header.Set(constants.HeaderWorkspace, tt.workspace)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if tt.expectedWorkspace != "" {
+				assert.Equal(t, tt.expectedWorkspace, response["workspace"])
+			}
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
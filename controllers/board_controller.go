@@ -0,0 +1,384 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Board socket message types. Clients send subscribe, unsubscribe, create
+// and update; the server answers each with ok or error, and sends event and
+// reset messages for its subscriptions.
+const (
+	boardSubscribe   = "subscribe"
+	boardUnsubscribe = "unsubscribe"
+	boardCreate      = "create"
+	boardUpdate      = "update"
+	boardOK          = "ok"
+	boardError       = "error"
+	boardEvent       = "event"
+	boardReset       = "reset"
+)
+
+// Board socket limits and timings
+const (
+	boardSendBuffer       = 64
+	boardMaxSubscriptions = 32
+	boardMaxMessageSize   = 64 << 10
+	boardWriteWait        = 10 * time.Second
+	boardPongWait         = 60 * time.Second
+	boardPingInterval     = boardPongWait * 9 / 10
+	boardShutdownReason   = "server is shutting down"
+)
+
+// boardUpgrader only accepts connections from pages served by this host
+var boardUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+// boardMessage is a message in either direction on a board socket. ID is
+// chosen by the client and echoed in the reply to its message.
+type boardMessage struct {
+	Type         string                  `json:"type"`
+	ID           string                  `json:"id,omitempty"`
+	Subscription string                  `json:"subscription,omitempty"`
+	Filter       *models.TaskEventFilter `json:"filter,omitempty"`
+	LastEventID  string                  `json:"lastEventId,omitempty"`
+	TaskID       string                  `json:"taskId,omitempty"`
+	Version      int64                   `json:"version,omitempty"`
+	Task         *models.Task            `json:"task,omitempty"`
+	EventID      string                  `json:"eventId,omitempty"`
+	Event        *models.TaskEvent       `json:"event,omitempty"`
+	Error        string                  `json:"error,omitempty"`
+	Field        string                  `json:"field,omitempty"`
+	Code         int                     `json:"code,omitempty"`
+}
+
+// BoardSocket upgrades the request to a WebSocket for a task board
+// @Summary Task board WebSocket
+// @Description Bidirectional task updates. Clients subscribe to sets of tasks by status and assignee and create or update tasks over the same connection.
+// @Tags tasks
+// @Success 101
+// @Failure 400 {object} map[string]string
+// @Router /ws [get]
+func BoardSocket(c *gin.Context) {
+	conn, err := boardUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	s := &boardSession{
+		conn:   conn,
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan boardMessage, boardSendBuffer),
+		subs:   make(map[string]*services.Subscription),
+	}
+	if boardSockets.add(s) {
+		defer boardSockets.remove(s)
+	}
+	s.wg.Add(1)
+	go s.writeLoop()
+
+	s.readLoop()
+	s.close(websocket.CloseNormalClosure, "")
+	for _, sub := range s.subs {
+		sub.Close()
+	}
+	s.wg.Wait()
+}
+
+// CloseBoardSockets ends every open board session, and any opened later,
+// telling clients the server is going away. It waits for the sessions to
+// finish until ctx ends. http.Server.Shutdown does neither, since WebSockets
+// are hijacked connections, so call this first.
+func CloseBoardSockets(ctx context.Context) error {
+	return boardSockets.closeAll(ctx)
+}
+
+var boardSockets = newBoardRegistry()
+
+// boardRegistry tracks the open board sessions so they can be ended on
+// shutdown
+type boardRegistry struct {
+	mu       sync.Mutex
+	sessions map[*boardSession]bool
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func newBoardRegistry() *boardRegistry {
+	return &boardRegistry{sessions: make(map[*boardSession]bool)}
+}
+
+// add tracks s and reports true, or ends s and reports false once the
+// registry is closed
+func (r *boardRegistry) add(s *boardSession) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		s.close(websocket.CloseGoingAway, boardShutdownReason)
+		return false
+	}
+	r.sessions[s] = true
+	r.wg.Add(1)
+	return true
+}
+
+// remove stops tracking s once its handler is done with it
+func (r *boardRegistry) remove(s *boardSession) {
+	r.mu.Lock()
+	delete(r.sessions, s)
+	r.mu.Unlock()
+	r.wg.Done()
+}
+
+func (r *boardRegistry) closeAll(ctx context.Context) error {
+	r.mu.Lock()
+	r.closed = true
+	for s := range r.sessions {
+		s.close(websocket.CloseGoingAway, boardShutdownReason)
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// boardSession is one board socket. The handler goroutine reads and runs
+// commands, one goroutine per subscription forwards its events, and a single
+// writer owns the connection's write side.
+//
+// Everything sent goes through one bounded queue. Replies block the reader
+// while it is full, so a client that sends faster than it reads is slowed
+// down. Events wait in their subscription's buffer on the bus; a client that
+// lets that fill up is disconnected and can resume with lastEventId.
+type boardSession struct {
+	conn   *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+	send   chan boardMessage
+	subs   map[string]*services.Subscription // only touched by the reader
+	wg     sync.WaitGroup
+
+	closeOnce sync.Once
+	closeMsg  []byte
+}
+
+// close ends the session, telling the client why. Only the first call has
+// any effect.
+func (s *boardSession) close(code int, reason string) {
+	s.closeOnce.Do(func() {
+		s.closeMsg = websocket.FormatCloseMessage(code, reason)
+		s.cancel()
+	})
+}
+
+// queue waits for room to send m and reports false if the session ended first
+func (s *boardSession) queue(m boardMessage) bool {
+	select {
+	case s.send <- m:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+func (s *boardSession) readLoop() {
+	s.conn.SetReadLimit(boardMaxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(boardPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(boardPongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(boardPongWait))
+
+		var msg boardMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if !s.queue(boardErrorReply("", errors.NewBadRequestError(constants.MessageInvalidInput))) {
+				return
+			}
+			continue
+		}
+		if !s.handle(msg) {
+			return
+		}
+	}
+}
+
+// writeLoop sends queued messages and heartbeat pings until the session ends
+func (s *boardSession) writeLoop() {
+	defer s.wg.Done()
+	defer s.conn.Close()
+	ping := time.NewTicker(boardPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case m := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(boardWriteWait))
+			if err := s.conn.WriteJSON(m); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(boardWriteWait)); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-s.ctx.Done():
+			// Makes sure closeMsg is set if the context ended some other way
+			s.close(websocket.CloseGoingAway, "")
+			s.conn.WriteControl(websocket.CloseMessage, s.closeMsg, time.Now().Add(boardWriteWait))
+			return
+		}
+	}
+}
+
+// handle runs one client message and reports false once the session has ended
+func (s *boardSession) handle(msg boardMessage) bool {
+	switch msg.Type {
+	case boardSubscribe:
+		return s.subscribe(msg)
+	case boardUnsubscribe:
+		sub, ok := s.subs[msg.Subscription]
+		if !ok {
+			return s.queue(boardErrorReply(msg.ID, errors.NewNotFoundError("Subscription")))
+		}
+		sub.Close()
+		delete(s.subs, msg.Subscription)
+		return s.queue(boardMessage{Type: boardOK, ID: msg.ID, Subscription: msg.Subscription})
+	case boardCreate:
+		if msg.Task == nil {
+			return s.queue(boardErrorReply(msg.ID, errors.NewValidationError("task", constants.ValidationTaskRequired)))
+		}
+		created, err := taskService.CreateTask(s.ctx, *msg.Task)
+		return s.queue(boardTaskReply(msg.ID, created, err))
+	case boardUpdate:
+		if msg.TaskID == "" {
+			return s.queue(boardErrorReply(msg.ID, errors.NewValidationError("taskId", constants.ValidationTaskIDRequired)))
+		}
+		if msg.Task == nil {
+			return s.queue(boardErrorReply(msg.ID, errors.NewValidationError("task", constants.ValidationTaskRequired)))
+		}
+		updated, err := taskService.UpdateTask(s.ctx, msg.TaskID, *msg.Task, msg.Version)
+		return s.queue(boardTaskReply(msg.ID, updated, err))
+	default:
+		return s.queue(boardErrorReply(msg.ID, errors.NewBadRequestError(constants.MessageUnknownMessageType)))
+	}
+}
+
+func (s *boardSession) subscribe(msg boardMessage) bool {
+	var err error
+	switch {
+	case msg.Subscription == "":
+		err = errors.NewValidationError("subscription", constants.ValidationSubscriptionRequired)
+	case s.subs[msg.Subscription] != nil:
+		err = errors.NewValidationError("subscription", constants.ValidationSubscriptionInUse)
+	case len(s.subs) >= boardMaxSubscriptions:
+		err = errors.NewValidationError("subscription", constants.ValidationTooManySubscriptions)
+	}
+	if err != nil {
+		return s.queue(boardErrorReply(msg.ID, err))
+	}
+
+	var filter models.TaskEventFilter
+	if msg.Filter != nil {
+		filter = *msg.Filter
+	}
+	sub, err := eventBus.Subscribe(s.ctx, filter, msg.LastEventID)
+	if err != nil {
+		return s.queue(boardErrorReply(msg.ID, err))
+	}
+	s.subs[msg.Subscription] = sub
+
+	// Acknowledge before the pump can send any of the subscription's events
+	if !s.queue(boardMessage{Type: boardOK, ID: msg.ID, Subscription: msg.Subscription}) {
+		return false
+	}
+	s.wg.Add(1)
+	go s.pump(msg.Subscription, sub)
+	return true
+}
+
+// pump forwards a subscription's events, starting with any it is replaying
+func (s *boardSession) pump(name string, sub *services.Subscription) {
+	defer s.wg.Done()
+	if sub.Gap && !s.queue(boardMessage{Type: boardReset, Subscription: name}) {
+		return
+	}
+	for _, e := range sub.Replay {
+		if !s.queue(boardEventMessage(name, e)) {
+			return
+		}
+	}
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Dropped() {
+					s.close(websocket.CloseTryAgainLater, "client is not keeping up with events")
+				}
+				return
+			}
+			if !s.queue(boardEventMessage(name, e)) {
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func boardEventMessage(subscription string, e services.BusEvent) boardMessage {
+	event := e.TaskEvent
+	return boardMessage{Type: boardEvent, Subscription: subscription, EventID: e.ID, Event: &event}
+}
+
+func boardTaskReply(id string, task models.Task, err error) boardMessage {
+	if err != nil {
+		return boardErrorReply(id, err)
+	}
+	return boardMessage{Type: boardOK, ID: id, Task: &task}
+}
+
+// boardErrorReply reports err to the client with the status handleError
+// would give it over HTTP
+func boardErrorReply(id string, err error) boardMessage {
+	reply := boardMessage{Type: boardError, ID: id}
+	switch e := err.(type) {
+	case *errors.ValidationError:
+		reply.Code, reply.Error, reply.Field = http.StatusBadRequest, e.Error(), e.Field
+	case *errors.AppError:
+		reply.Code, reply.Error = e.Code, e.Message
+	default:
+		reply.Code, reply.Error = http.StatusInternalServerError, constants.MessageInternalError
+	}
+	return reply
+}
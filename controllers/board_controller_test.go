@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func dialBoard(t *testing.T, bus *services.EventBus, svc services.TaskService) *websocket.Conn {
+	t.Helper()
+	Setup(svc)
+	SetupStream(bus)
+	router := setupTestRouter()
+	router.GET("/ws", BoardSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// roundTrip sends msg and returns the next message from the server
+func roundTrip(t *testing.T, conn *websocket.Conn, msg boardMessage) boardMessage {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(msg))
+	return readBoard(t, conn)
+}
+
+func readBoard(t *testing.T, conn *websocket.Conn) boardMessage {
+	t.Helper()
+	var reply boardMessage
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, conn.ReadJSON(&reply))
+	return reply
+}
+
+func TestBoardSocket_Subscriptions(t *testing.T) {
+	bus := services.NewEventBus(10, nil)
+	conn := dialBoard(t, bus, new(MockTaskService))
+
+	reply := roundTrip(t, conn, boardMessage{Type: boardSubscribe, ID: "1", Subscription: "mine", Filter: &models.TaskEventFilter{AssignedTo: "alice"}})
+	assert.Equal(t, boardMessage{Type: boardOK, ID: "1", Subscription: "mine"}, reply)
+
+	reply = roundTrip(t, conn, boardMessage{Type: boardSubscribe, ID: "2", Subscription: "mine"})
+	assert.Equal(t, boardError, reply.Type)
+	assert.Equal(t, "subscription", reply.Field)
+
+	bus.Publish(models.TaskEvent{ID: "e1", Type: models.EventTaskCreated, Task: models.Task{ID: "t1", Workspace: constants.DefaultWorkspace, AssignedTo: "bob"}})
+	bus.Publish(models.TaskEvent{ID: "e2", Type: models.EventTaskCreated, Task: models.Task{ID: "t2", Workspace: constants.DefaultWorkspace, AssignedTo: "alice"}})
+	event := readBoard(t, conn)
+	assert.Equal(t, boardEvent, event.Type)
+	assert.Equal(t, "mine", event.Subscription)
+	require.NotNil(t, event.Event)
+	assert.Equal(t, "e2", event.Event.ID)
+
+	// A new subscription can resume from an earlier event
+	reply = roundTrip(t, conn, boardMessage{Type: boardSubscribe, ID: "3", Subscription: "all", LastEventID: strings.TrimSuffix(event.EventID, "-2") + "-1"})
+	assert.Equal(t, boardOK, reply.Type)
+	replayed := readBoard(t, conn)
+	assert.Equal(t, "all", replayed.Subscription)
+	assert.Equal(t, event.EventID, replayed.EventID)
+
+	reply = roundTrip(t, conn, boardMessage{Type: boardUnsubscribe, ID: "4", Subscription: "mine"})
+	assert.Equal(t, boardOK, reply.Type)
+	reply = roundTrip(t, conn, boardMessage{Type: boardUnsubscribe, ID: "5", Subscription: "mine"})
+	assert.Equal(t, 404, reply.Code)
+}
+
+func TestBoardSocket_Commands(t *testing.T) {
+	mockService := new(MockTaskService)
+	created := models.Task{ID: "t1", Title: "Plan sprint", Status: constants.StatusPending, Priority: constants.PriorityLow, Version: 1}
+	mockService.On("CreateTask", mock.Anything, mock.MatchedBy(func(task models.Task) bool { return task.Title == "Plan sprint" })).Return(created, nil)
+	mockService.On("UpdateTask", mock.Anything, "t1", mock.Anything, int64(1)).
+		Return(models.Task{}, errors.NewValidationError("title", constants.ValidationTitleRequired))
+	conn := dialBoard(t, services.NewEventBus(10, nil), mockService)
+
+	reply := roundTrip(t, conn, boardMessage{Type: boardCreate, ID: "c1", Task: &models.Task{Title: "Plan sprint", Status: constants.StatusPending, Priority: constants.PriorityLow}})
+	assert.Equal(t, boardOK, reply.Type)
+	assert.Equal(t, "c1", reply.ID)
+	require.NotNil(t, reply.Task)
+	assert.Equal(t, "t1", reply.Task.ID)
+
+	reply = roundTrip(t, conn, boardMessage{Type: boardUpdate, ID: "u1", TaskID: "t1", Version: 1, Task: &models.Task{}})
+	assert.Equal(t, boardMessage{Type: boardError, ID: "u1", Code: 400, Field: "title", Error: "title: " + constants.ValidationTitleRequired}, reply)
+
+	reply = roundTrip(t, conn, boardMessage{Type: boardUpdate, ID: "u2", Task: &models.Task{}})
+	assert.Equal(t, "taskId", reply.Field)
+
+	reply = roundTrip(t, conn, boardMessage{Type: "delete", ID: "d1"})
+	assert.Equal(t, boardError, reply.Type)
+	assert.Equal(t, constants.MessageUnknownMessageType, reply.Error)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Equal(t, 400, readBoard(t, conn).Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestBoardSession_DropsSlowClients(t *testing.T) {
+	bus := services.NewEventBus(services.DefaultReplaySize, nil)
+	sub, err := bus.Subscribe(context.Background(), models.TaskEventFilter{}, "")
+	require.NoError(t, err)
+
+	// Nothing drains the queue, as if the client had stopped reading
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &boardSession{ctx: ctx, cancel: cancel, send: make(chan boardMessage)}
+	s.wg.Add(1)
+	go s.pump("all", sub)
+	for i := 0; i < 1000; i++ {
+		bus.Publish(models.TaskEvent{ID: "e", Type: models.EventTaskUpdated, Task: models.Task{Workspace: constants.DefaultWorkspace}})
+	}
+
+	// Once the client catches up with what was buffered it is disconnected
+	for done := false; !done; {
+		select {
+		case <-s.send:
+		case <-ctx.Done():
+			done = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("slow client was not disconnected")
+		}
+	}
+	s.wg.Wait()
+	assert.Equal(t, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client is not keeping up with events"), s.closeMsg)
+}
+
+func TestCloseBoardSockets(t *testing.T) {
+	Setup(new(MockTaskService))
+	SetupStream(services.NewEventBus(10, nil))
+	router := setupTestRouter()
+	router.GET("/ws", BoardSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	reply := roundTrip(t, conn, boardMessage{Type: boardSubscribe, ID: "1", Subscription: "all"})
+	require.Equal(t, boardOK, reply.Type)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, CloseBoardSockets(ctx))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "read after shutdown error = %v, want going away", err)
+
+	// Sockets opened during shutdown are ended straight away
+	late, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer late.Close()
+	late.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = late.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "read on a late socket error = %v, want going away", err)
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"net/http"
+	"taskmanager/constants"
+	"taskmanager/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var commentService services.CommentService
+
+// SetupComments injects the service behind the comment endpoints
+func SetupComments(commentSvc services.CommentService) {
+	commentService = commentSvc
+}
+
+// commentRequest is the body of a comment create or edit
+type commentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// GetTaskComments lists the comments on a task
+// @Summary List comments
+// @Description List the comments on a task, oldest first
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.Comment
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments [get]
+func GetTaskComments(c *gin.Context) {
+	comments, err := commentService.ListComments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comments, "count": len(comments)})
+}
+
+// AddTaskComment posts a comment on a task
+// @Summary Add a comment
+// @Description Comment on a task as the authenticated caller
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param comment body commentRequest true "Comment"
+// @Success 201 {object} models.Comment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments [post]
+func AddTaskComment(c *gin.Context) {
+	var req commentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := commentService.AddComment(c.Request.Context(), c.Param("id"), req.Body)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    comment,
+		"message": constants.MessageCommentCreated,
+	})
+}
+
+// GetTaskComment retrieves a comment on a task
+// @Summary Get a comment
+// @Description Get a comment, including its edit history
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} models.Comment
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments/{commentId} [get]
+func GetTaskComment(c *gin.Context) {
+	comment, err := commentService.GetComment(c.Request.Context(), c.Param("id"), c.Param("commentId"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// UpdateTaskComment edits a comment
+// @Summary Edit a comment
+// @Description Replace a comment's body. The previous body is kept in the edit history.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Param comment body commentRequest true "Comment"
+// @Success 200 {object} models.Comment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments/{commentId} [put]
+func UpdateTaskComment(c *gin.Context) {
+	var req commentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := commentService.EditComment(c.Request.Context(), c.Param("id"), c.Param("commentId"), req.Body)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    comment,
+		"message": constants.MessageCommentUpdated,
+	})
+}
+
+// DeleteTaskComment removes a comment
+// @Summary Delete a comment
+// @Description Delete a comment from a task
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments/{commentId} [delete]
+func DeleteTaskComment(c *gin.Context) {
+	if err := commentService.DeleteComment(c.Request.Context(), c.Param("id"), c.Param("commentId")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": constants.MessageCommentDeleted})
+}
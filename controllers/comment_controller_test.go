@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCommentService is a mock implementation of CommentService for testing
+type MockCommentService struct {
+	mock.Mock
+}
+
+func (m *MockCommentService) ListComments(ctx context.Context, taskID string) ([]models.Comment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]models.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) GetComment(ctx context.Context, taskID, id string) (models.Comment, error) {
+	args := m.Called(ctx, taskID, id)
+	return args.Get(0).(models.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) AddComment(ctx context.Context, taskID, body string) (models.Comment, error) {
+	args := m.Called(ctx, taskID, body)
+	return args.Get(0).(models.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) EditComment(ctx context.Context, taskID, id, body string) (models.Comment, error) {
+	args := m.Called(ctx, taskID, id, body)
+	return args.Get(0).(models.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) DeleteComment(ctx context.Context, taskID, id string) error {
+	args := m.Called(ctx, taskID, id)
+	return args.Error(0)
+}
+
+func TestAddTaskComment(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockCommentService)
+		expectedStatus int
+	}{
+		{
+			name: "Valid comment",
+			body: `{"body":"Looks good"}`,
+			setupMock: func(m *MockCommentService) {
+				m.On("AddComment", mock.Anything, "t1", "Looks good").
+					Return(models.Comment{ID: "c1", TaskID: "t1", Author: "alice", Body: "Looks good"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing body",
+			body:           `{}`,
+			setupMock:      func(m *MockCommentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Blank body",
+			body: `{"body":"   "}`,
+			setupMock: func(m *MockCommentService) {
+				m.On("AddComment", mock.Anything, "t1", "   ").
+					Return(models.Comment{}, errors.NewValidationError("body", constants.ValidationCommentBodyRequired))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Task not found",
+			body: `{"body":"Hello"}`,
+			setupMock: func(m *MockCommentService) {
+				m.On("AddComment", mock.Anything, "t1", "Hello").Return(models.Comment{}, errors.NewNotFoundError("Task"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCommentService)
+			SetupComments(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/tasks/:id/comments", AddTaskComment)
+
+			req, _ := http.NewRequest("POST", "/tasks/t1/comments", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTaskComments(t *testing.T) {
+	mockService := new(MockCommentService)
+	SetupComments(mockService)
+	mockService.On("ListComments", mock.Anything, "t1").
+		Return([]models.Comment{{ID: "c1", TaskID: "t1"}, {ID: "c2", TaskID: "t1"}}, nil)
+	mockService.On("GetComment", mock.Anything, "t1", "c1").
+		Return(models.Comment{ID: "c1", TaskID: "t1", Body: "now", Edits: []models.CommentEdit{{Body: "before"}}}, nil)
+	mockService.On("GetComment", mock.Anything, "t1", "missing").
+		Return(models.Comment{}, errors.NewNotFoundError("Comment"))
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id/comments", GetTaskComments)
+	router.GET("/tasks/:id/comments/:commentId", GetTaskComment)
+
+	req, _ := http.NewRequest("GET", "/tasks/t1/comments", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(2), response["count"])
+
+	req, _ = http.NewRequest("GET", "/tasks/t1/comments/c1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var comment struct {
+		Data models.Comment `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &comment)
+	assert.Equal(t, "before", comment.Data.Edits[0].Body)
+
+	req, _ = http.NewRequest("GET", "/tasks/t1/comments/missing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAndDeleteTaskComment(t *testing.T) {
+	mockService := new(MockCommentService)
+	SetupComments(mockService)
+	mockService.On("EditComment", mock.Anything, "t1", "c1", "edited").
+		Return(models.Comment{ID: "c1", Body: "edited"}, nil)
+	mockService.On("DeleteComment", mock.Anything, "t1", "c2").
+		Return(errors.NewForbiddenError(`permission "comments:moderate" is required, or "comments:write" for your own comments`))
+	mockService.On("DeleteComment", mock.Anything, "t1", "c1").Return(nil)
+
+	router := setupTestRouter()
+	router.PUT("/tasks/:id/comments/:commentId", UpdateTaskComment)
+	router.DELETE("/tasks/:id/comments/:commentId", DeleteTaskComment)
+
+	req, _ := http.NewRequest("PUT", "/tasks/t1/comments/c1", bytes.NewBufferString(`{"body":"edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/tasks/t1/comments/c2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/tasks/t1/comments/c1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"net/http"
+	"taskmanager/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+// renameLabelRequest is the body of a label rename
+type renameLabelRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// mergeLabelsRequest is the body of a label merge
+type mergeLabelsRequest struct {
+	From []string `json:"from" binding:"required"`
+	To   string   `json:"to" binding:"required"`
+}
+
+// GetLabels lists the labels in use
+// @Summary List labels
+// @Description List every label on a live task with the number of tasks carrying it
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.LabelCount
+// @Router /labels [get]
+func GetLabels(c *gin.Context) {
+	labels, err := taskService.ListLabels(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": labels, "count": len(labels)})
+}
+
+// RenameLabel renames a label on every task
+// @Summary Rename a label
+// @Description Replace a label with a new one on every task, including tasks in the trash
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param rename body renameLabelRequest true "Current and new label"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /labels/rename [post]
+func RenameLabel(c *gin.Context) {
+	var req renameLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := taskService.RenameLabel(c.Request.Context(), req.From, req.To)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updated": updated,
+		"message": constants.MessageLabelRenamed,
+	})
+}
+
+// MergeLabels folds several labels into one
+// @Summary Merge labels
+// @Description Replace each of the given labels with the target label on every task, including tasks in the trash
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param merge body mergeLabelsRequest true "Labels to merge and the label they become"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /labels/merge [post]
+func MergeLabels(c *gin.Context) {
+	var req mergeLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := taskService.MergeLabels(c.Request.Context(), req.From, req.To)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updated": updated,
+		"message": constants.MessageLabelsMerged,
+	})
+}
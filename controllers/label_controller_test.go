@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLabels(t *testing.T) {
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("ListLabels", mock.Anything).
+		Return([]models.LabelCount{{Label: "bug", Count: 2}, {Label: "frontend", Count: 1}}, nil)
+
+	router := setupTestRouter()
+	router.GET("/labels", GetLabels)
+
+	req, _ := http.NewRequest("GET", "/labels", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data  []models.LabelCount `json:"data"`
+		Count int                 `json:"count"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, models.LabelCount{Label: "bug", Count: 2}, response.Data[0])
+	mockService.AssertExpectations(t)
+}
+
+func TestRenameLabel(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockTaskService)
+		expectedStatus int
+	}{
+		{
+			name: "Renamed",
+			body: `{"from":"bug","to":"defect"}`,
+			setupMock: func(m *MockTaskService) {
+				m.On("RenameLabel", mock.Anything, "bug", "defect").Return(3, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing target",
+			body:           `{"from":"bug"}`,
+			setupMock:      func(m *MockTaskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid label",
+			body: `{"from":"bug","to":"Not Valid"}`,
+			setupMock: func(m *MockTaskService) {
+				m.On("RenameLabel", mock.Anything, "bug", "Not Valid").
+					Return(0, errors.NewValidationError("to", constants.ValidationInvalidLabel))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown label",
+			body: `{"from":"nope","to":"defect"}`,
+			setupMock: func(m *MockTaskService) {
+				m.On("RenameLabel", mock.Anything, "nope", "defect").Return(0, errors.NewNotFoundError("Label"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Target in use",
+			body: `{"from":"bug","to":"frontend"}`,
+			setupMock: func(m *MockTaskService) {
+				m.On("RenameLabel", mock.Anything, "bug", "frontend").
+					Return(0, errors.NewConflictError(`label "frontend" is already in use; merge the labels instead`))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/labels/rename", RenameLabel)
+
+			req, _ := http.NewRequest("POST", "/labels/rename", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, float64(3), response["updated"])
+				assert.Equal(t, constants.MessageLabelRenamed, response["message"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("MergeLabels", mock.Anything, []string{"bug", "defect"}, "issue").Return(4, nil)
+
+	router := setupTestRouter()
+	router.POST("/labels/merge", MergeLabels)
+
+	req, _ := http.NewRequest("POST", "/labels/merge", bytes.NewBufferString(`{"from":["bug","defect"],"to":"issue"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(4), response["updated"])
+
+	req, _ = http.NewRequest("POST", "/labels/merge", bytes.NewBufferString(`{"to":"issue"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
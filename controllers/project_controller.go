@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var projectService services.ProjectService
+
+// SetupProjects injects the service behind the project endpoints
+func SetupProjects(projectSvc services.ProjectService) {
+	projectService = projectSvc
+}
+
+// projectRequest is the body of a project create or update
+type projectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func (r projectRequest) project() models.Project {
+	return models.Project{Name: r.Name, Description: r.Description}
+}
+
+// GetProjects lists the projects in the workspace
+// @Summary List projects
+// @Description List every project in the workspace, oldest first
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Project
+// @Router /projects [get]
+func GetProjects(c *gin.Context) {
+	projects, err := projectService.ListProjects(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": projects, "count": len(projects)})
+}
+
+// CreateProject creates a new project
+// @Summary Create a project
+// @Description Create a project to group tasks
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param project body projectRequest true "Project"
+// @Success 201 {object} models.Project
+// @Failure 400 {object} map[string]string
+// @Router /projects [post]
+func CreateProject(c *gin.Context) {
+	var req projectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := projectService.CreateProject(c.Request.Context(), req.project())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    created,
+		"message": constants.MessageProjectCreated,
+	})
+}
+
+// GetProject retrieves a project
+// @Summary Get a project
+// @Description Get a project by ID
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id} [get]
+func GetProject(c *gin.Context) {
+	project, err := projectService.GetProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": project})
+}
+
+// UpdateProject replaces a project's name and description
+// @Summary Update a project
+// @Description Replace a project's name and description
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param project body projectRequest true "Project"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id} [put]
+func UpdateProject(c *gin.Context) {
+	var req projectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := projectService.UpdateProject(c.Request.Context(), c.Param("id"), req.project())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageProjectUpdated,
+	})
+}
+
+// DeleteProject removes an empty project
+// @Summary Delete a project
+// @Description Delete a project. Projects that still have live tasks cannot be deleted.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /projects/{id} [delete]
+func DeleteProject(c *gin.Context) {
+	if err := projectService.DeleteProject(c.Request.Context(), c.Param("id")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": constants.MessageProjectDeleted})
+}
+
+// GetProjectTasks retrieves a filtered, sorted page of a project's tasks
+// @Summary Get a project's tasks
+// @Description Get a page of the project's tasks. Accepts the same filters as GET /tasks.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param status query string false "Filter by status"
+// @Param sort query string false "Sort field, prefixed with - for descending (default createdAt)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from a previous page's nextCursor"
+// @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/tasks [get]
+func GetProjectTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	page, err := projectService.QueryProjectTasks(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       page.Tasks,
+		"count":      len(page.Tasks),
+		"nextCursor": page.NextCursor,
+	})
+}
+
+// GetProjectStats summarizes a project's tasks
+// @Summary Get project stats
+// @Description Count a project's live tasks by status, and those that are overdue
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} models.ProjectStats
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/stats [get]
+func GetProjectStats(c *gin.Context) {
+	stats, err := projectService.ProjectStats(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
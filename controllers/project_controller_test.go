@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProjectService is a mock implementation of ProjectService for testing
+type MockProjectService struct {
+	mock.Mock
+}
+
+func (m *MockProjectService) ListProjects(ctx context.Context) ([]models.Project, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetProject(ctx context.Context, id string) (models.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) CreateProject(ctx context.Context, project models.Project) (models.Project, error) {
+	args := m.Called(ctx, project)
+	return args.Get(0).(models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) UpdateProject(ctx context.Context, id string, project models.Project) (models.Project, error) {
+	args := m.Called(ctx, id, project)
+	return args.Get(0).(models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) DeleteProject(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProjectService) QueryProjectTasks(ctx context.Context, id string, q models.TaskQuery) (models.TaskPage, error) {
+	args := m.Called(ctx, id, q)
+	return args.Get(0).(models.TaskPage), args.Error(1)
+}
+
+func (m *MockProjectService) ProjectStats(ctx context.Context, id string) (models.ProjectStats, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.ProjectStats), args.Error(1)
+}
+
+func TestCreateProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockProjectService)
+		expectedStatus int
+	}{
+		{
+			name: "Valid project",
+			body: `{"name":"Launch","description":"Ship it"}`,
+			setupMock: func(m *MockProjectService) {
+				m.On("CreateProject", mock.Anything, models.Project{Name: "Launch", Description: "Ship it"}).
+					Return(models.Project{ID: "p1", Name: "Launch", Description: "Ship it"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing name",
+			body:           `{"description":"Ship it"}`,
+			setupMock:      func(m *MockProjectService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Not permitted",
+			body: `{"name":"Launch"}`,
+			setupMock: func(m *MockProjectService) {
+				m.On("CreateProject", mock.Anything, models.Project{Name: "Launch"}).
+					Return(models.Project{}, errors.NewForbiddenError(`permission "projects:manage" is required`))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProjectService)
+			SetupProjects(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/projects", CreateProject)
+
+			req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	mockService := new(MockProjectService)
+	SetupProjects(mockService)
+	mockService.On("DeleteProject", mock.Anything, "p1").Return(errors.NewConflictError(constants.MessageProjectNotEmpty))
+	mockService.On("DeleteProject", mock.Anything, "p2").Return(nil)
+
+	router := setupTestRouter()
+	router.DELETE("/projects/:id", DeleteProject)
+
+	req, _ := http.NewRequest("DELETE", "/projects/p1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/projects/p2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProjectTasksAndStats(t *testing.T) {
+	mockService := new(MockProjectService)
+	SetupProjects(mockService)
+	mockService.On("QueryProjectTasks", mock.Anything, "p1", models.TaskQuery{Status: constants.StatusPending, Limit: 10}).
+		Return(models.TaskPage{Tasks: []models.Task{{ID: "t1", ProjectID: "p1"}}, NextCursor: "next"}, nil)
+	mockService.On("QueryProjectTasks", mock.Anything, "missing", models.TaskQuery{}).
+		Return(models.TaskPage{}, errors.NewNotFoundError("Project"))
+	mockService.On("ProjectStats", mock.Anything, "p1").
+		Return(models.ProjectStats{ProjectID: "p1", Total: 1, ByStatus: map[string]int{constants.StatusPending: 1}, Overdue: 1}, nil)
+
+	router := setupTestRouter()
+	router.GET("/projects/:id/tasks", GetProjectTasks)
+	router.GET("/projects/:id/stats", GetProjectStats)
+
+	req, _ := http.NewRequest("GET", "/projects/p1/tasks?status=Pending&limit=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(1), response["count"])
+	assert.Equal(t, "next", response["nextCursor"])
+
+	req, _ = http.NewRequest("GET", "/projects/missing/tasks", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/projects/p1/stats", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats struct {
+		Data models.ProjectStats `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, 1, stats.Data.Overdue)
+	assert.Equal(t, 1, stats.Data.ByStatus[constants.StatusPending])
+
+	mockService.AssertExpectations(t)
+}
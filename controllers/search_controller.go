@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskmanager/constants"
+	"taskmanager/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchTasks runs a full-text search over task titles and descriptions
+// @Summary Search tasks
+// @Description Search live tasks by title and description. Words match other forms of the same word; "quoted phrases" must appear together and word* matches words starting with word. Every part of the query must match. Results are ranked by relevance and carry HTML-escaped snippets with matches wrapped in <mark>.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum results (1-100, default 20)"
+// @Success 200 {array} models.TaskSearchResult
+// @Failure 400 {object} map[string]string
+// @Router /tasks/search [get]
+func SearchTasks(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			handleError(c, errors.NewValidationError("limit", constants.ValidationInvalidSearchLimit))
+			return
+		}
+	}
+
+	results, err := taskService.SearchTasks(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results, "count": len(results)})
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchTasks(t *testing.T) {
+	result := models.TaskSearchResult{
+		Task:       models.Task{ID: "1", Title: "Deploy the API"},
+		Score:      1.5,
+		Highlights: models.SearchHighlights{Title: "<mark>Deploy</mark> the API"},
+	}
+	tests := []struct {
+		name           string
+		url            string
+		setupMock      func(*MockTaskService)
+		expectedStatus int
+		expectedCount  int
+		expectedError  string
+	}{
+		{
+			name: "Results",
+			url:  `/tasks/search?q=deploy+%22the+api%22&limit=5`,
+			setupMock: func(m *MockTaskService) {
+				m.On("SearchTasks", mock.Anything, `deploy "the api"`, 5).Return([]models.TaskSearchResult{result}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name: "Default limit",
+			url:  "/tasks/search?q=nothing",
+			setupMock: func(m *MockTaskService) {
+				m.On("SearchTasks", mock.Anything, "nothing", 0).Return([]models.TaskSearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Invalid query",
+			url:  `/tasks/search?q=%22open`,
+			setupMock: func(m *MockTaskService) {
+				m.On("SearchTasks", mock.Anything, `"open`, 0).
+					Return([]models.TaskSearchResult(nil), errors.NewValidationError("q", "unterminated phrase at column 1"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			url:            "/tasks/search?q=deploy&limit=lots",
+			setupMock:      func(m *MockTaskService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  constants.ValidationInvalidSearchLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.GET("/tasks/search", SearchTasks)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data  []models.TaskSearchResult `json:"data"`
+					Count int                       `json:"count"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, tt.expectedCount, response.Count)
+				if tt.expectedCount > 0 {
+					assert.Equal(t, result, response.Data[0])
+				}
+			}
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
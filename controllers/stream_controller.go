@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var eventBus *services.EventBus
+
+// streamHeartbeat is how often an idle stream sends a comment so proxies do
+// not close the connection
+const streamHeartbeat = 15 * time.Second
+
+// streamResetEvent tells a resuming client that it missed events the server
+// no longer holds and should reload the tasks it shows
+const streamResetEvent = "reset"
+
+// SetupStream injects the event bus behind the task stream and board sockets
+func SetupStream(bus *services.EventBus) {
+	eventBus = bus
+	boardSockets = newBoardRegistry()
+}
+
+// StreamTasks streams task changes as Server-Sent Events
+// @Summary Stream task events
+// @Description Stream task.created, task.updated and task.deleted events as text/event-stream. Reconnecting clients send Last-Event-ID to receive the events they missed.
+// @Tags tasks
+// @Produce text/event-stream
+// @Param status query string false "Only events about tasks with this status, before or after the change"
+// @Param assignedTo query string false "Only events about tasks assigned to this user, before or after the change"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} models.TaskEvent
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /tasks/stream [get]
+func StreamTasks(c *gin.Context) {
+	filter := models.TaskEventFilter{
+		Status:     c.Query("status"),
+		AssignedTo: c.Query("assignedTo"),
+	}
+	sub, err := eventBus.Subscribe(c.Request.Context(), filter, c.GetHeader(constants.HeaderLastEventID))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if sub.Gap {
+		if err := writeSSE(c.Writer, "", streamResetEvent, []byte("{}")); err != nil {
+			return
+		}
+	}
+	for _, event := range sub.Replay {
+		if err := writeTaskEvent(c.Writer, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			// A closed channel means the server is shutting down or the
+			// client fell behind; it reconnects with Last-Event-ID either way
+			if !ok {
+				return
+			}
+			if err := writeTaskEvent(c.Writer, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeTaskEvent(w io.Writer, event services.BusEvent) error {
+	data, err := json.Marshal(event.TaskEvent)
+	if err != nil {
+		return err
+	}
+	return writeSSE(w, event.ID, event.Type, data)
+}
+
+// writeSSE writes one event in the text/event-stream format. data must not
+// contain newlines, which holds for encoded JSON.
+func writeSSE(w io.Writer, id, event string, data []byte) error {
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is one event read back from a stream
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSE reads the next event from r, skipping comments
+func readSSE(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if e.event != "" {
+				return e
+			}
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set(constants.HeaderLastEventID, lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestStreamTasks(t *testing.T) {
+	bus := services.NewEventBus(10, nil)
+	SetupStream(bus)
+	router := setupTestRouter()
+	router.GET("/tasks/stream", StreamTasks)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	defer bus.Close()
+
+	resp, stream := openStream(t, server.URL+"/tasks/stream?status=InProgress", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	// Headers are only sent once the stream has subscribed
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	pending := models.Task{ID: "1", Workspace: constants.DefaultWorkspace, Title: "Write docs", Status: constants.StatusPending}
+	started := pending
+	started.Status = constants.StatusInProgress
+	bus.Publish(models.TaskEvent{ID: "e1", Type: models.EventTaskCreated, Task: pending})
+	bus.Publish(models.TaskEvent{ID: "e2", Type: models.EventTaskUpdated, Task: started, Previous: &pending})
+
+	// The pending task's creation is filtered out
+	got := readSSE(t, stream)
+	assert.Equal(t, models.EventTaskUpdated, got.event)
+	assert.NotEmpty(t, got.id)
+	var event models.TaskEvent
+	require.NoError(t, json.Unmarshal([]byte(got.data), &event))
+	assert.Equal(t, "e2", event.ID)
+	assert.Equal(t, constants.StatusInProgress, event.Task.Status)
+
+	// Resuming from the first event replays the second without a filter
+	firstID := strings.TrimSuffix(got.id, "-2") + "-1"
+	_, resumed := openStream(t, server.URL+"/tasks/stream", firstID)
+	got = readSSE(t, resumed)
+	assert.Equal(t, models.EventTaskUpdated, got.event)
+	assert.Contains(t, got.data, `"id":"e2"`)
+
+	// IDs from before a restart start with a reset
+	_, stale := openStream(t, server.URL+"/tasks/stream?assignedTo=nobody", "old-1")
+	assert.Equal(t, "reset", readSSE(t, stale).event)
+}
+
+func TestStreamTasks_BadRequest(t *testing.T) {
+	bus := services.NewEventBus(10, nil)
+	SetupStream(bus)
+	router := setupTestRouter()
+	router.GET("/tasks/stream", StreamTasks)
+
+	tests := []struct {
+		name          string
+		url           string
+		lastEventID   string
+		expectedField string
+	}{
+		{"Invalid status", "/tasks/stream?status=Sleeping", "", "status"},
+		{"Malformed Last-Event-ID", "/tasks/stream", "nonsense", constants.HeaderLastEventID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(constants.HeaderLastEventID, tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, tt.expectedField, response["field"])
+		})
+	}
+
+	bus.Close()
+	req, _ := http.NewRequest("GET", "/tasks/stream", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
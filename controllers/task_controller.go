@@ -2,10 +2,14 @@ package controllers
 
 import (
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"taskmanager/constants"
 	"taskmanager/errors"
 	"taskmanager/models"
 	"taskmanager/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,22 +27,123 @@ func Setup(taskSvc services.TaskService) {
 	taskService = taskSvc
 }
 
-// GetTasks retrieves all tasks
-// @Summary Get all tasks
-// @Description Get a list of all tasks
+// GetTasks retrieves a filtered, sorted page of tasks
+// @Summary Get tasks
+// @Description Get a page of tasks, optionally filtered and sorted
 // @Tags tasks
 // @Accept json
 // @Produce json
+// @Param status query string false "Filter by status"
+// @Param priority query string false "Filter by priority"
+// @Param assignedTo query string false "Filter by assignee"
+// @Param projectId query string false "Filter by project"
+// @Param parentId query string false "Filter by parent task"
+// @Param labelsAny query string false "Comma-separated labels; match tasks with any of them"
+// @Param labelsAll query string false "Comma-separated labels; match tasks with all of them"
+// @Param labelsNone query string false "Comma-separated labels; match tasks with none of them"
+// @Param filter query string false "Filter expression, e.g. status:InProgress AND due<2026-11-01"
+// @Param dueAfter query string false "Due on or after (RFC 3339)"
+// @Param dueBefore query string false "Due before (RFC 3339)"
+// @Param createdAfter query string false "Created on or after (RFC 3339)"
+// @Param createdBefore query string false "Created before (RFC 3339)"
+// @Param updatedAfter query string false "Updated on or after (RFC 3339)"
+// @Param updatedBefore query string false "Updated before (RFC 3339)"
+// @Param sort query string false "Sort field, prefixed with - for descending (default createdAt)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from a previous page's nextCursor"
 // @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string
 // @Router /tasks [get]
 func GetTasks(c *gin.Context) {
-	tasks := taskService.GetTasks()
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	page, err := taskService.QueryTasks(c.Request.Context(), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"data": tasks,
-		"count": len(tasks),
+		"data":       page.Tasks,
+		"count":      len(page.Tasks),
+		"nextCursor": page.NextCursor,
 	})
 }
 
+// parseTaskQuery builds a TaskQuery from the request's query parameters
+func parseTaskQuery(c *gin.Context) (models.TaskQuery, error) {
+	query := models.TaskQuery{
+		Status:     c.Query("status"),
+		Priority:   c.Query("priority"),
+		AssignedTo: c.Query("assignedTo"),
+		ProjectID:  c.Query("projectId"),
+		ParentID:   c.Query("parentId"),
+		Cursor:     c.Query("cursor"),
+		LabelsAny:  splitList(c.Query("labelsAny")),
+		LabelsAll:  splitList(c.Query("labelsAll")),
+		LabelsNone: splitList(c.Query("labelsNone")),
+	}
+
+	times := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"dueAfter", &query.DueAfter},
+		{"dueBefore", &query.DueBefore},
+		{"createdAfter", &query.CreatedAfter},
+		{"createdBefore", &query.CreatedBefore},
+		{"updatedAfter", &query.UpdatedAfter},
+		{"updatedBefore", &query.UpdatedBefore},
+	}
+	for _, tp := range times {
+		raw := c.Query(tp.param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return models.TaskQuery{}, errors.NewValidationError(tp.param, constants.ValidationInvalidTime)
+		}
+		*tp.dest = &t
+	}
+
+	if raw := c.Query("filter"); raw != "" {
+		f, err := models.ParseTaskFilter(raw)
+		if err != nil {
+			return models.TaskQuery{}, err
+		}
+		query.Filter = f
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		query.SortDesc = strings.HasPrefix(sort, "-")
+		query.SortBy = strings.TrimPrefix(sort, "-")
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return models.TaskQuery{}, errors.NewValidationError("limit", constants.ValidationInvalidLimit)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
+// splitList splits a comma-separated query parameter, dropping empty items
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // GetTaskByID retrieves a task by ID
 // @Summary Get task by ID
 // @Description Get a specific task by its ID
@@ -47,15 +152,17 @@ func GetTasks(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Task ID"
 // @Success 200 {object} models.Task
+// @Header 200 {string} ETag "Task version"
 // @Failure 404 {object} map[string]string
 // @Router /tasks/{id} [get]
 func GetTaskByID(c *gin.Context) {
 	id := c.Param("id")
-	task, err := taskService.GetTask(id)
+	task, err := taskService.GetTask(c.Request.Context(), id)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
+	setETag(c, task)
 	c.JSON(http.StatusOK, gin.H{"data": task})
 }
 
@@ -75,15 +182,15 @@ func CreateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	
-	created, err := taskService.CreateTask(task)
+
+	created, err := taskService.CreateTask(c.Request.Context(), task)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
-	
+
 	c.JSON(http.StatusCreated, gin.H{
-		"data": created,
+		"data":    created,
 		"message": constants.MessageTaskCreated,
 	})
 }
@@ -95,10 +202,12 @@ func CreateTask(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param If-Match header string false "ETag the update is conditional on"
 // @Param task body models.Task true "Updated task information"
 // @Success 200 {object} models.Task
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
 // @Router /tasks/{id} [put]
 func UpdateTask(c *gin.Context) {
 	id := c.Param("id")
@@ -107,48 +216,468 @@ func UpdateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	
-	updated, err := taskService.UpdateTask(id, task)
+	version, err := ifMatchVersion(c, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	updated, err := taskService.UpdateTask(c.Request.Context(), id, task, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setETag(c, updated)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageTaskUpdated,
+	})
+}
+
+// PatchTask partially updates an existing task
+// @Summary Patch a task
+// @Description Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) to a task
+// @Tags tasks
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param If-Match header string false "ETag the update is conditional on"
+// @Param patch body object true "Merge patch object or JSON Patch operation list"
+// @Success 200 {object} models.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Router /tasks/{id} [patch]
+func PatchTask(c *gin.Context) {
+	id := c.Param("id")
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	version, err := ifMatchVersion(c, id)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
-	
+
+	updated, err := taskService.PatchTask(c.Request.Context(), id, c.ContentType(), patch, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setETag(c, updated)
+
 	c.JSON(http.StatusOK, gin.H{
-		"data": updated,
+		"data":    updated,
 		"message": constants.MessageTaskUpdated,
 	})
 }
 
-// DeleteTask deletes a task
+// DeleteTask moves a task to the trash
 // @Summary Delete a task
-// @Description Delete a task by ID
+// @Description Move a task to the trash; it can be restored until it is purged
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param If-Match header string false "ETag the delete is conditional on"
 // @Success 200 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
 // @Router /tasks/{id} [delete]
 func DeleteTask(c *gin.Context) {
 	id := c.Param("id")
-	err := taskService.DeleteTask(id)
+	version, err := ifMatchVersion(c, id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	err = taskService.DeleteTask(c.Request.Context(), id, version)
 	if err != nil {
 		handleError(c, err)
 		return
 	}
-	
+
 	c.JSON(http.StatusOK, gin.H{"message": constants.MessageTaskDeleted})
 }
 
+// GetTaskChildren lists the direct subtasks of a task
+// @Summary List subtasks
+// @Description List the direct subtasks of a task with their progress
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.Task
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/children [get]
+func GetTaskChildren(c *gin.Context) {
+	children, err := taskService.GetChildren(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": children, "count": len(children)})
+}
+
+// GetTaskSubtree returns a task with all of its descendants
+// @Summary Get task subtree
+// @Description Get a task and its subtasks, nested to any depth
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.TaskTree
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/subtree [get]
+func GetTaskSubtree(c *gin.Context) {
+	tree, err := taskService.GetSubtree(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+// dependencyRequest is the body of a new dependency edge
+type dependencyRequest struct {
+	BlockedBy string `json:"blockedBy" binding:"required"`
+}
+
+// GetTaskDependencies lists the tasks a task waits on and the tasks it blocks
+// @Summary Get task dependencies
+// @Description List the tasks blocking a task and the tasks it blocks
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.TaskDependencies
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/dependencies [get]
+func GetTaskDependencies(c *gin.Context) {
+	deps, err := taskService.GetDependencies(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": deps})
+}
+
+// AddTaskDependency marks a task as blocked by another task
+// @Summary Add a dependency
+// @Description Mark a task as blocked by another task
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param dependency body dependencyRequest true "Blocking task"
+// @Success 200 {object} models.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/dependencies [post]
+func AddTaskDependency(c *gin.Context) {
+	var req dependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := taskService.AddDependency(c.Request.Context(), c.Param("id"), req.BlockedBy)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setETag(c, updated)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageTaskUpdated,
+	})
+}
+
+// RemoveTaskDependency removes a blocking task from a task
+// @Summary Remove a dependency
+// @Description Stop a task from being blocked by another task
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param blockerId path string true "Blocking task ID"
+// @Success 200 {object} models.Task
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/dependencies/{blockerId} [delete]
+func RemoveTaskDependency(c *gin.Context) {
+	updated, err := taskService.RemoveDependency(c.Request.Context(), c.Param("id"), c.Param("blockerId"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setETag(c, updated)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageTaskUpdated,
+	})
+}
+
+// GetNextTasks plans the pending work in dependency order
+// @Summary What can I start next
+// @Description List pending tasks so that each comes after the tasks blocking it, marking the ones that can start now
+// @Tags dependencies
+// @Accept json
+// @Produce json
+// @Param ready query bool false "Only list tasks that can start now"
+// @Success 200 {array} models.PlannedTask
+// @Failure 400 {object} map[string]string
+// @Router /tasks/next [get]
+func GetNextTasks(c *gin.Context) {
+	readyOnly := false
+	if raw := c.Query("ready"); raw != "" {
+		var err error
+		if readyOnly, err = strconv.ParseBool(raw); err != nil {
+			handleError(c, errors.NewValidationError("ready", constants.ValidationInvalidBool))
+			return
+		}
+	}
+
+	plan, err := taskService.NextTasks(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if readyOnly {
+		ready := make([]models.PlannedTask, 0, len(plan))
+		for _, planned := range plan {
+			if planned.Ready {
+				ready = append(ready, planned)
+			}
+		}
+		plan = ready
+	}
+	c.JSON(http.StatusOK, gin.H{"data": plan, "count": len(plan)})
+}
+
+// GetTaskOccurrences previews when a recurring task will repeat
+// @Summary Preview task occurrences
+// @Description List the due dates of the next occurrences of a recurring task, after its own
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param count query int false "Number of occurrences (1-100, default 5)"
+// @Success 200 {array} string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/occurrences [get]
+func GetTaskOccurrences(c *gin.Context) {
+	count := models.DefaultOccurrenceCount
+	if raw := c.Query("count"); raw != "" {
+		var err error
+		if count, err = strconv.Atoi(raw); err != nil {
+			handleError(c, errors.NewValidationError("count", constants.ValidationInvalidCount))
+			return
+		}
+	}
+
+	occurrences, err := taskService.PreviewOccurrences(c.Request.Context(), c.Param("id"), count)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": occurrences, "count": len(occurrences)})
+}
+
+// transitionRequest is the body of a status transition
+type transitionRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// GetTaskTransitions lists the statuses a task can move to
+// @Summary List allowed status transitions
+// @Description List the statuses a task may move to from its current status
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/transitions [get]
+func GetTaskTransitions(c *gin.Context) {
+	id := c.Param("id")
+	allowed, err := taskService.AllowedTransitions(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": allowed})
+}
+
+// TransitionTask moves a task to a new status
+// @Summary Change task status
+// @Description Move a task to a new status if the transition is allowed
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param transition body transitionRequest true "Target status"
+// @Success 200 {object} models.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /tasks/{id}/transitions [post]
+func TransitionTask(c *gin.Context) {
+	id := c.Param("id")
+	var req transitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := taskService.TransitionTask(c.Request.Context(), id, req.Status)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setETag(c, updated)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageTaskTransitioned,
+	})
+}
+
+// GetTrash lists deleted tasks that have not been purged yet
+// @Summary List deleted tasks
+// @Description Get a page of tasks in the trash, most recently deleted first
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param priority query string false "Filter by priority"
+// @Param assignedTo query string false "Filter by assignee"
+// @Param sort query string false "Sort field, prefixed with - for descending (default -deletedAt)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from a previous page's nextCursor"
+// @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string
+// @Router /trash [get]
+func GetTrash(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	page, err := taskService.ListTrash(c.Request.Context(), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       page.Tasks,
+		"count":      len(page.Tasks),
+		"nextCursor": page.NextCursor,
+	})
+}
+
+// RestoreTask moves a deleted task out of the trash
+// @Summary Restore a deleted task
+// @Description Restore a task from the trash
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.Task
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /tasks/{id}/restore [post]
+func RestoreTask(c *gin.Context) {
+	restored, err := taskService.RestoreTask(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setETag(c, restored)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    restored,
+		"message": constants.MessageTaskRestored,
+	})
+}
+
+// setETag exposes the task version as a strong entity tag
+func setETag(c *gin.Context, task models.Task) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(task.Version, 10)))
+}
+
+// ifMatchVersion returns the task version required by the If-Match header, or
+// 0 when the request is unconditional. The header may list several tags; if
+// more than one can match, the task's current version is required when it is
+// among them. "*" only requires the task to exist. Weak and malformed tags can
+// never match a task version, so they fail the precondition.
+func ifMatchVersion(c *gin.Context, id string) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, nil
+	}
+	if header == "*" {
+		if _, err := currentTask(c, id); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		unquoted, err := strconv.Unquote(strings.TrimSpace(tag))
+		if err != nil {
+			continue
+		}
+		if version, err := strconv.ParseInt(unquoted, 10, 64); err == nil && version >= 1 {
+			versions = append(versions, version)
+		}
+	}
+	switch len(versions) {
+	case 0:
+		return 0, errors.NewPreconditionFailedError(constants.MessageVersionConflict)
+	case 1:
+		return versions[0], nil
+	}
+	task, err := currentTask(c, id)
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(versions, task.Version) {
+		return 0, errors.NewPreconditionFailedError(constants.MessageVersionConflict)
+	}
+	return task.Version, nil
+}
+
+// currentTask loads the task an If-Match header is checked against. A missing
+// task fails the precondition rather than being reported as not found.
+func currentTask(c *gin.Context, id string) (models.Task, error) {
+	task, err := taskService.GetTask(c.Request.Context(), id)
+	if e, ok := err.(*errors.AppError); ok && e.Code == http.StatusNotFound {
+		return models.Task{}, errors.NewPreconditionFailedError(constants.MessageVersionConflict)
+	}
+	return task, err
+}
+
 // handleError handles different types of errors and returns appropriate HTTP responses
 func handleError(c *gin.Context, err error) {
 	switch e := err.(type) {
 	case *errors.ValidationError:
-		c.JSON(http.StatusBadRequest, gin.H{
+		body := gin.H{
 			"error": e.Error(),
 			"field": e.Field,
-		})
+		}
+		if e.Column > 0 {
+			body["column"] = e.Column
+		}
+		c.JSON(http.StatusBadRequest, body)
 	case *errors.AppError:
 		c.JSON(e.Code, gin.H{"error": e.Message})
 	default:
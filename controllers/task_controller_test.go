@@ -2,14 +2,18 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"taskmanager/constants"
 	"taskmanager/errors"
 	"taskmanager/models"
 	"taskmanager/testutils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -21,31 +25,126 @@ type MockTaskService struct {
 	mock.Mock
 }
 
-func (m *MockTaskService) GetTasks() []models.Task {
-	args := m.Called()
-	return args.Get(0).([]models.Task)
+func (m *MockTaskService) GetTasks(ctx context.Context) ([]models.Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) GetTask(id string) (models.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskService) GetTask(ctx context.Context, id string) (models.Task, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) CreateTask(task models.Task) (models.Task, error) {
-	args := m.Called(task)
+func (m *MockTaskService) QueryTasks(ctx context.Context, q models.TaskQuery) (models.TaskPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(models.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskService) CreateTask(ctx context.Context, task models.Task) (models.Task, error) {
+	args := m.Called(ctx, task)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) UpdateTask(id string, task models.Task) (models.Task, error) {
-	args := m.Called(id, task)
+func (m *MockTaskService) UpdateTask(ctx context.Context, id string, task models.Task, expectedVersion int64) (models.Task, error) {
+	args := m.Called(ctx, id, task, expectedVersion)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) DeleteTask(id string) error {
-	args := m.Called(id)
+func (m *MockTaskService) PatchTask(ctx context.Context, id, contentType string, patch []byte, expectedVersion int64) (models.Task, error) {
+	args := m.Called(ctx, id, contentType, patch, expectedVersion)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) DeleteTask(ctx context.Context, id string, expectedVersion int64) error {
+	args := m.Called(ctx, id, expectedVersion)
 	return args.Error(0)
 }
 
+func (m *MockTaskService) AllowedTransitions(ctx context.Context, id string) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTaskService) TransitionTask(ctx context.Context, id, status string) (models.Task, error) {
+	args := m.Called(ctx, id, status)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) ListTrash(ctx context.Context, q models.TaskQuery) (models.TaskPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(models.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskService) RestoreTask(ctx context.Context, id string) (models.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) GetChildren(ctx context.Context, id string) ([]models.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetSubtree(ctx context.Context, id string) (models.TaskTree, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.TaskTree), args.Error(1)
+}
+
+func (m *MockTaskService) GetDependencies(ctx context.Context, id string) (models.TaskDependencies, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.TaskDependencies), args.Error(1)
+}
+
+func (m *MockTaskService) AddDependency(ctx context.Context, id, blockerID string) (models.Task, error) {
+	args := m.Called(ctx, id, blockerID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) RemoveDependency(ctx context.Context, id, blockerID string) (models.Task, error) {
+	args := m.Called(ctx, id, blockerID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) NextTasks(ctx context.Context) ([]models.PlannedTask, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.PlannedTask), args.Error(1)
+}
+
+func (m *MockTaskService) PreviewOccurrences(ctx context.Context, id string, count int) ([]time.Time, error) {
+	args := m.Called(ctx, id, count)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockTaskService) ListLabels(ctx context.Context) ([]models.LabelCount, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.LabelCount), args.Error(1)
+}
+
+func (m *MockTaskService) RenameLabel(ctx context.Context, from, to string) (int, error) {
+	args := m.Called(ctx, from, to)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) MergeLabels(ctx context.Context, from []string, to string) (int, error) {
+	args := m.Called(ctx, from, to)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) SearchTasks(ctx context.Context, q string, limit int) ([]models.TaskSearchResult, error) {
+	args := m.Called(ctx, q, limit)
+	return args.Get(0).([]models.TaskSearchResult), args.Error(1)
+}
+
+func (m *MockTaskService) Workspaces(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -53,9 +152,6 @@ func setupTestRouter() *gin.Engine {
 }
 
 func TestGetTasks(t *testing.T) {
-	mockService := new(MockTaskService)
-	Setup(mockService)
-
 	tests := []struct {
 		name           string
 		mockTasks      []models.Task
@@ -81,7 +177,9 @@ func TestGetTasks(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService.On("GetTasks").Return(tt.mockTasks)
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			mockService.On("QueryTasks", mock.Anything, models.TaskQuery{}).Return(models.TaskPage{Tasks: tt.mockTasks}, nil)
 
 			router := setupTestRouter()
 			router.GET("/tasks", GetTasks)
@@ -106,6 +204,107 @@ func TestGetTasks(t *testing.T) {
 	}
 }
 
+func TestGetTasks_QueryParams(t *testing.T) {
+	dueBefore := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedQuery  *models.TaskQuery
+		expectedStatus int
+	}{
+		{
+			name: "Filters, sort and pagination",
+			url:  "/tasks?status=Pending&assignedTo=test@example.com&dueBefore=2024-12-31T00:00:00Z&sort=-dueDate&limit=10&cursor=abc",
+			expectedQuery: &models.TaskQuery{
+				Status:     constants.StatusPending,
+				AssignedTo: "test@example.com",
+				DueBefore:  &dueBefore,
+				SortBy:     models.SortByDueDate,
+				SortDesc:   true,
+				Limit:      10,
+				Cursor:     "abc",
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Label filters",
+			url:  "/tasks?labelsAny=bug,%20q3&labelsAll=frontend&labelsNone=wontfix,",
+			expectedQuery: &models.TaskQuery{
+				LabelsAny:  []string{"bug", "q3"},
+				LabelsAll:  []string{"frontend"},
+				LabelsNone: []string{"wontfix"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid date",
+			url:            "/tasks?createdAfter=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			url:            "/tasks?limit=zero",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			if tt.expectedQuery != nil {
+				mockService.On("QueryTasks", mock.Anything, *tt.expectedQuery).Return(models.TaskPage{Tasks: []models.Task{}, NextCursor: "next"}, nil)
+			}
+
+			router := setupTestRouter()
+			router.GET("/tasks", GetTasks)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "next", response["nextCursor"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTasks_Filter(t *testing.T) {
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("QueryTasks", mock.Anything, mock.MatchedBy(func(q models.TaskQuery) bool {
+		return q.Filter != nil && q.Filter.Source == "status:InProgress AND NOT assignedTo:bob@x" && q.Priority == "High"
+	})).Return(models.TaskPage{Tasks: []models.Task{}}, nil)
+
+	router := setupTestRouter()
+	router.GET("/tasks", GetTasks)
+
+	req, _ := http.NewRequest("GET", "/tasks?priority=High&filter="+url.QueryEscape("status:InProgress AND NOT assignedTo:bob@x"), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	req, _ = http.NewRequest("GET", "/tasks?filter="+url.QueryEscape("status:InProgress AND due<someday"), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "filter", response["field"])
+	assert.Equal(t, float64(27), response["column"])
+	assert.Contains(t, response["error"], `invalid time "someday"`)
+}
+
 func TestGetTaskByID(t *testing.T) {
 	mockService := new(MockTaskService)
 	Setup(mockService)
@@ -135,7 +334,7 @@ func TestGetTaskByID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService.On("GetTask", tt.taskID).Return(tt.mockTask, tt.mockError)
+			mockService.On("GetTask", mock.Anything, tt.taskID).Return(tt.mockTask, tt.mockError)
 
 			router := setupTestRouter()
 			router.GET("/tasks/:id", GetTaskByID)
@@ -189,9 +388,9 @@ func TestCreateTask(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.mockError == nil {
-				mockService.On("CreateTask", mock.AnythingOfType("models.Task")).Return(tt.mockTask, tt.mockError)
+				mockService.On("CreateTask", mock.Anything, mock.AnythingOfType("models.Task")).Return(tt.mockTask, tt.mockError)
 			} else {
-				mockService.On("CreateTask", mock.AnythingOfType("models.Task")).Return(tt.mockTask, tt.mockError)
+				mockService.On("CreateTask", mock.Anything, mock.AnythingOfType("models.Task")).Return(tt.mockTask, tt.mockError)
 			}
 
 			router := setupTestRouter()
@@ -260,7 +459,7 @@ func TestUpdateTask(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService.On("UpdateTask", tt.taskID, mock.AnythingOfType("models.Task")).Return(tt.mockTask, tt.mockError)
+			mockService.On("UpdateTask", mock.Anything, tt.taskID, mock.AnythingOfType("models.Task"), int64(0)).Return(tt.mockTask, tt.mockError)
 
 			router := setupTestRouter()
 			router.PUT("/tasks/:id", UpdateTask)
@@ -314,7 +513,7 @@ func TestDeleteTask(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService.On("DeleteTask", tt.taskID).Return(tt.mockError)
+			mockService.On("DeleteTask", mock.Anything, tt.taskID, int64(0)).Return(tt.mockError)
 
 			router := setupTestRouter()
 			router.DELETE("/tasks/:id", DeleteTask)
@@ -338,3 +537,644 @@ func TestDeleteTask(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTaskTransitions(t *testing.T) {
+	tests := []struct {
+		name           string
+		taskID         string
+		mockAllowed    []string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "Existing task",
+			taskID:         "test-id",
+			mockAllowed:    []string{constants.StatusInProgress, constants.StatusCancelled},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Non-existent task",
+			taskID:         "non-existent",
+			mockAllowed:    []string(nil),
+			mockError:      errors.NewNotFoundError("Task"),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			mockService.On("AllowedTransitions", mock.Anything, tt.taskID).Return(tt.mockAllowed, tt.mockError)
+
+			router := setupTestRouter()
+			router.GET("/tasks/:id/transitions", GetTaskTransitions)
+
+			req, _ := http.NewRequest("GET", "/tasks/"+tt.taskID+"/transitions", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Len(t, response["data"], len(tt.mockAllowed))
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransitionTask(t *testing.T) {
+	tests := []struct {
+		name           string
+		taskID         string
+		body           string
+		mockError      error
+		callsService   bool
+		expectedStatus int
+	}{
+		{
+			name:           "Allowed transition",
+			taskID:         "test-id",
+			body:           `{"status":"InProgress"}`,
+			callsService:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Rejected transition",
+			taskID:         "test-id",
+			body:           `{"status":"Pending"}`,
+			mockError:      errors.NewConflictError("cannot change status from Completed to Pending"),
+			callsService:   true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Missing status",
+			taskID:         "test-id",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			if tt.callsService {
+				var status map[string]string
+				json.Unmarshal([]byte(tt.body), &status)
+				mockService.On("TransitionTask", mock.Anything, tt.taskID, status["status"]).Return(testutils.CreateTestTask(), tt.mockError)
+			}
+
+			router := setupTestRouter()
+			router.POST("/tasks/:id/transitions", TransitionTask)
+
+			req, _ := http.NewRequest("POST", "/tasks/"+tt.taskID+"/transitions", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, constants.MessageTaskTransitioned, response["message"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	tests := []struct {
+		name           string
+		taskID         string
+		contentType    string
+		body           string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "Merge patch",
+			taskID:         "test-id",
+			contentType:    constants.ContentTypeMergePatch,
+			body:           `{"status":"InProgress"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "JSON patch with charset",
+			taskID:         "test-id",
+			contentType:    constants.ContentTypeJSONPatch + "; charset=utf-8",
+			body:           `[{"op":"replace","path":"/title","value":"New"}]`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unsupported content type",
+			taskID:         "test-id",
+			contentType:    "application/json",
+			body:           `{"status":"InProgress"}`,
+			mockError:      errors.NewUnsupportedMediaTypeError(constants.MessageUnsupportedPatchType),
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "Non-existent task",
+			taskID:         "non-existent",
+			contentType:    constants.ContentTypeMergePatch,
+			body:           `{}`,
+			mockError:      errors.NewNotFoundError("Task"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid result",
+			taskID:         "test-id",
+			contentType:    constants.ContentTypeMergePatch,
+			body:           `{"title":null}`,
+			mockError:      errors.NewValidationError("title", constants.ValidationTitleRequired),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			mediaType := strings.Split(tt.contentType, ";")[0]
+			mockService.On("PatchTask", mock.Anything, tt.taskID, mediaType, []byte(tt.body), int64(0)).Return(testutils.CreateTestTask(), tt.mockError)
+
+			router := setupTestRouter()
+			router.PATCH("/tasks/:id", PatchTask)
+
+			req, _ := http.NewRequest("PATCH", "/tasks/"+tt.taskID, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTaskByID_ETag(t *testing.T) {
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	task := testutils.CreateTestTask()
+	task.Version = 7
+	mockService.On("GetTask", mock.Anything, "test-id").Return(task, nil)
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id", GetTaskByID)
+
+	req, _ := http.NewRequest("GET", "/tasks/test-id", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+}
+
+func TestIfMatchPreconditions(t *testing.T) {
+	tests := []struct {
+		name            string
+		ifMatch         string
+		expectedVersion int64
+		mockError       error
+		expectedStatus  int
+	}{
+		{"No header", "", 0, nil, http.StatusOK},
+		{"Wildcard", "*", 0, nil, http.StatusOK},
+		{"Matching version", `"3"`, 3, nil, http.StatusOK},
+		{"Stale version", `"2"`, 2, errors.NewPreconditionFailedError(constants.MessageVersionConflict), http.StatusPreconditionFailed},
+		{"Weak tag", `W/"3"`, -1, nil, http.StatusPreconditionFailed},
+		{"Unquoted tag", `3`, -1, nil, http.StatusPreconditionFailed},
+		{"Several tags including the current one", `"2", "3"`, 3, nil, http.StatusOK},
+		{"Several stale tags", `"1","2"`, -1, nil, http.StatusPreconditionFailed},
+		{"Weak and strong tags", `W/"3", "3"`, 3, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			current := testutils.CreateTestTask()
+			current.Version = 3
+			mockService.On("GetTask", mock.Anything, "test-id").Return(current, nil).Maybe()
+			updated := testutils.CreateTestTask()
+			updated.Version = 4
+			if tt.expectedVersion >= 0 {
+				mockService.On("UpdateTask", mock.Anything, "test-id", mock.AnythingOfType("models.Task"), tt.expectedVersion).Return(updated, tt.mockError)
+				mockService.On("DeleteTask", mock.Anything, "test-id", tt.expectedVersion).Return(tt.mockError)
+			}
+
+			router := setupTestRouter()
+			router.PUT("/tasks/:id", UpdateTask)
+			router.DELETE("/tasks/:id", DeleteTask)
+
+			jsonBody, _ := json.Marshal(testutils.CreateTestTask())
+			req, _ := http.NewRequest("PUT", "/tasks/test-id", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code == http.StatusOK {
+				assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+			}
+
+			req, _ = http.NewRequest("DELETE", "/tasks/test-id", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIfMatchWildcardMissingTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("GetTask", mock.Anything, "missing").Return(models.Task{}, errors.NewNotFoundError("Task"))
+
+	router := setupTestRouter()
+	router.DELETE("/tasks/:id", DeleteTask)
+
+	req, _ := http.NewRequest("DELETE", "/tasks/missing", nil)
+	req.Header.Set("If-Match", "*")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTrash(t *testing.T) {
+	deleted := testutils.CreateTestTask()
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("ListTrash", mock.Anything, models.TaskQuery{AssignedTo: "bob"}).
+		Return(models.TaskPage{Tasks: []models.Task{deleted}}, nil)
+
+	router := setupTestRouter()
+	router.GET("/trash", GetTrash)
+
+	req, _ := http.NewRequest("GET", "/trash?assignedTo=bob", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(1), response["count"])
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreTask(t *testing.T) {
+	restored := testutils.CreateTestTask()
+	restored.Version = 3
+
+	tests := []struct {
+		name           string
+		id             string
+		setupMock      func(*MockTaskService)
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name: "Deleted task",
+			id:   "1",
+			setupMock: func(m *MockTaskService) {
+				m.On("RestoreTask", mock.Anything, "1").Return(restored, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+		},
+		{
+			name: "Task not in trash",
+			id:   "2",
+			setupMock: func(m *MockTaskService) {
+				m.On("RestoreTask", mock.Anything, "2").Return(models.Task{}, errors.NewConflictError(constants.MessageTaskNotDeleted))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Unknown task",
+			id:   "3",
+			setupMock: func(m *MockTaskService) {
+				m.On("RestoreTask", mock.Anything, "3").Return(models.Task{}, errors.NewNotFoundError("Task"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/tasks/:id/restore", RestoreTask)
+
+			req, _ := http.NewRequest("POST", "/tasks/"+tt.id+"/restore", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTaskChildren(t *testing.T) {
+	child := testutils.CreateTestTask()
+	child.ParentID = "1"
+
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("GetChildren", mock.Anything, "1").Return([]models.Task{child}, nil)
+	mockService.On("GetChildren", mock.Anything, "2").Return([]models.Task(nil), errors.NewNotFoundError("Task"))
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id/children", GetTaskChildren)
+
+	req, _ := http.NewRequest("GET", "/tasks/1/children", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(1), response["count"])
+
+	req, _ = http.NewRequest("GET", "/tasks/2/children", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskSubtree(t *testing.T) {
+	root := testutils.CreateTestTask()
+	root.ID = "1"
+	child := testutils.CreateTestTaskWithStatus(constants.StatusCompleted)
+	child.ID = "2"
+	child.ParentID = "1"
+	tree := models.BuildTaskTree(root, map[string][]models.Task{"1": {child}})
+
+	mockService := new(MockTaskService)
+	Setup(mockService)
+	mockService.On("GetSubtree", mock.Anything, "1").Return(tree, nil)
+
+	router := setupTestRouter()
+	router.GET("/tasks/:id/subtree", GetTaskSubtree)
+
+	req, _ := http.NewRequest("GET", "/tasks/1/subtree", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			ID       string           `json:"id"`
+			Progress *models.Progress `json:"progress"`
+			Children []struct {
+				ID       string `json:"id"`
+				ParentID string `json:"parentId"`
+			} `json:"children"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "1", response.Data.ID)
+	assert.Equal(t, &models.Progress{Subtasks: 1, Completed: 1, Percent: 100}, response.Data.Progress)
+	if assert.Len(t, response.Data.Children, 1) {
+		assert.Equal(t, "1", response.Data.Children[0].ParentID)
+	}
+}
+
+func TestTaskDependencyEndpoints(t *testing.T) {
+	blocked := testutils.CreateTestTask()
+	blocked.ID = "1"
+	blocked.BlockedBy = []string{"2"}
+	blocked.Version = 2
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		setupMock      func(*MockTaskService)
+		expectedStatus int
+	}{
+		{
+			name:   "Get dependencies",
+			method: "GET",
+			url:    "/tasks/1/dependencies",
+			setupMock: func(m *MockTaskService) {
+				m.On("GetDependencies", mock.Anything, "1").Return(models.TaskDependencies{Blocked: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Add dependency",
+			method: "POST",
+			url:    "/tasks/1/dependencies",
+			body:   `{"blockedBy":"2"}`,
+			setupMock: func(m *MockTaskService) {
+				m.On("AddDependency", mock.Anything, "1", "2").Return(blocked, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Add dependency creating a cycle",
+			method: "POST",
+			url:    "/tasks/1/dependencies",
+			body:   `{"blockedBy":"3"}`,
+			setupMock: func(m *MockTaskService) {
+				m.On("AddDependency", mock.Anything, "1", "3").Return(models.Task{}, errors.NewValidationError("blockedBy", constants.ValidationDependencyCycle))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Add dependency without blocker",
+			method:         "POST",
+			url:            "/tasks/1/dependencies",
+			body:           `{}`,
+			setupMock:      func(m *MockTaskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Remove dependency",
+			method: "DELETE",
+			url:    "/tasks/1/dependencies/2",
+			setupMock: func(m *MockTaskService) {
+				m.On("RemoveDependency", mock.Anything, "1", "2").Return(blocked, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Remove missing dependency",
+			method: "DELETE",
+			url:    "/tasks/1/dependencies/9",
+			setupMock: func(m *MockTaskService) {
+				m.On("RemoveDependency", mock.Anything, "1", "9").Return(models.Task{}, errors.NewNotFoundError("Dependency"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.GET("/tasks/:id/dependencies", GetTaskDependencies)
+			router.POST("/tasks/:id/dependencies", AddTaskDependency)
+			router.DELETE("/tasks/:id/dependencies/:blockerId", RemoveTaskDependency)
+
+			req, _ := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetNextTasks(t *testing.T) {
+	ready := models.PlannedTask{Task: testutils.CreateTestTask(), Ready: true}
+	waiting := models.PlannedTask{Task: testutils.CreateTestTask()}
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedCount  float64
+	}{
+		{"Full plan", "/tasks/next", http.StatusOK, 2},
+		{"Ready only", "/tasks/next?ready=true", http.StatusOK, 1},
+		{"Invalid flag", "/tasks/next?ready=maybe", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			mockService.On("NextTasks", mock.Anything).Return([]models.PlannedTask{ready, waiting}, nil)
+
+			router := setupTestRouter()
+			router.GET("/tasks/next", GetNextTasks)
+			router.GET("/tasks/:id", GetTaskByID)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, tt.expectedCount, response["count"])
+			}
+		})
+	}
+}
+
+func TestGetTaskOccurrences(t *testing.T) {
+	due := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	occurrences := []time.Time{due.AddDate(0, 0, 7), due.AddDate(0, 0, 14)}
+
+	tests := []struct {
+		name           string
+		url            string
+		setupMock      func(*MockTaskService)
+		expectedStatus int
+	}{
+		{
+			name: "Default count",
+			url:  "/tasks/1/occurrences",
+			setupMock: func(m *MockTaskService) {
+				m.On("PreviewOccurrences", mock.Anything, "1", models.DefaultOccurrenceCount).Return(occurrences, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Explicit count",
+			url:  "/tasks/1/occurrences?count=2",
+			setupMock: func(m *MockTaskService) {
+				m.On("PreviewOccurrences", mock.Anything, "1", 2).Return(occurrences, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Count not a number",
+			url:            "/tasks/1/occurrences?count=many",
+			setupMock:      func(m *MockTaskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Count out of range",
+			url:  "/tasks/1/occurrences?count=1000",
+			setupMock: func(m *MockTaskService) {
+				m.On("PreviewOccurrences", mock.Anything, "1", 1000).
+					Return([]time.Time(nil), errors.NewValidationError("count", constants.ValidationInvalidCount))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Task not found",
+			url:  "/tasks/missing/occurrences",
+			setupMock: func(m *MockTaskService) {
+				m.On("PreviewOccurrences", mock.Anything, "missing", models.DefaultOccurrenceCount).
+					Return([]time.Time(nil), errors.NewNotFoundError("Task"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			Setup(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.GET("/tasks/:id/occurrences", GetTaskOccurrences)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, float64(2), response["count"])
+				assert.Equal(t, "2026-10-26T09:00:00Z", response["data"].([]interface{})[0])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var viewService services.ViewService
+
+// SetupViews injects the service behind the saved view endpoints
+func SetupViews(viewSvc services.ViewService) {
+	viewService = viewSvc
+}
+
+// viewRequest is the body of a view create or update
+type viewRequest struct {
+	Name    string   `json:"name" binding:"required"`
+	Filter  string   `json:"filter"`
+	Sort    string   `json:"sort"`
+	Columns []string `json:"columns"`
+	Shared  bool     `json:"shared"`
+}
+
+func (r viewRequest) view() models.View {
+	return models.View{Name: r.Name, Filter: r.Filter, Sort: r.Sort, Columns: r.Columns, Shared: r.Shared}
+}
+
+// GetViews lists the saved views the caller can see
+// @Summary List saved views
+// @Description List the caller's own views and those shared with the workspace, oldest first
+// @Tags views
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.View
+// @Router /views [get]
+func GetViews(c *gin.Context) {
+	views, err := viewService.ListViews(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": views, "count": len(views)})
+}
+
+// CreateView saves a new view
+// @Summary Create a saved view
+// @Description Save a filter expression, sort order and column choice under a name. The caller owns the view.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param view body viewRequest true "View"
+// @Success 201 {object} models.View
+// @Failure 400 {object} map[string]string
+// @Router /views [post]
+func CreateView(c *gin.Context) {
+	var req viewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := viewService.CreateView(c.Request.Context(), req.view())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    created,
+		"message": constants.MessageViewCreated,
+	})
+}
+
+// GetView retrieves a saved view
+// @Summary Get a saved view
+// @Description Get one of the caller's views, or a shared one, by ID
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path string true "View ID"
+// @Success 200 {object} models.View
+// @Failure 404 {object} map[string]string
+// @Router /views/{id} [get]
+func GetView(c *gin.Context) {
+	view, err := viewService.GetView(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": view})
+}
+
+// UpdateView replaces a saved view
+// @Summary Update a saved view
+// @Description Replace a view's name, filter, sort, columns and sharing. Views belonging to others need views:manage.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path string true "View ID"
+// @Param view body viewRequest true "View"
+// @Success 200 {object} models.View
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /views/{id} [put]
+func UpdateView(c *gin.Context) {
+	var req viewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := viewService.UpdateView(c.Request.Context(), c.Param("id"), req.view())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageViewUpdated,
+	})
+}
+
+// DeleteView removes a saved view
+// @Summary Delete a saved view
+// @Description Delete a view. Views belonging to others need views:manage.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path string true "View ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /views/{id} [delete]
+func DeleteView(c *gin.Context) {
+	if err := viewService.DeleteView(c.Request.Context(), c.Param("id")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": constants.MessageViewDeleted})
+}
+
+// GetViewTasks runs a saved view
+// @Summary Run a saved view
+// @Description Get a page of the tasks matching the view's filter, in the view's sort order, along with the columns to show. @me in the filter stands for the caller.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param id path string true "View ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from a previous page's nextCursor"
+// @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /views/{id}/tasks [get]
+func GetViewTasks(c *gin.Context) {
+	query := models.TaskQuery{Cursor: c.Query("cursor")}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			handleError(c, errors.NewValidationError("limit", constants.ValidationInvalidLimit))
+			return
+		}
+		query.Limit = limit
+	}
+
+	view, page, err := viewService.QueryViewTasks(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       page.Tasks,
+		"count":      len(page.Tasks),
+		"nextCursor": page.NextCursor,
+		"columns":    view.Columns,
+	})
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockViewService is a mock implementation of ViewService for testing
+type MockViewService struct {
+	mock.Mock
+}
+
+func (m *MockViewService) ListViews(ctx context.Context) ([]models.View, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.View), args.Error(1)
+}
+
+func (m *MockViewService) GetView(ctx context.Context, id string) (models.View, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.View), args.Error(1)
+}
+
+func (m *MockViewService) CreateView(ctx context.Context, view models.View) (models.View, error) {
+	args := m.Called(ctx, view)
+	return args.Get(0).(models.View), args.Error(1)
+}
+
+func (m *MockViewService) UpdateView(ctx context.Context, id string, view models.View) (models.View, error) {
+	args := m.Called(ctx, id, view)
+	return args.Get(0).(models.View), args.Error(1)
+}
+
+func (m *MockViewService) DeleteView(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockViewService) QueryViewTasks(ctx context.Context, id string, q models.TaskQuery) (models.View, models.TaskPage, error) {
+	args := m.Called(ctx, id, q)
+	return args.Get(0).(models.View), args.Get(1).(models.TaskPage), args.Error(2)
+}
+
+func TestCreateView(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockViewService)
+		expectedStatus int
+	}{
+		{
+			name: "Valid view",
+			body: `{"name":"Mine","filter":"assignedTo:@me","sort":"-dueDate","columns":["title","dueDate"],"shared":true}`,
+			setupMock: func(m *MockViewService) {
+				view := models.View{Name: "Mine", Filter: "assignedTo:@me", Sort: "-dueDate", Columns: []string{"title", "dueDate"}, Shared: true}
+				created := view
+				created.ID = "v1"
+				m.On("CreateView", mock.Anything, view).Return(created, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing name",
+			body:           `{"filter":"status:Pending"}`,
+			setupMock:      func(m *MockViewService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid filter",
+			body: `{"name":"Broken","filter":"status:"}`,
+			setupMock: func(m *MockViewService) {
+				m.On("CreateView", mock.Anything, models.View{Name: "Broken", Filter: "status:"}).
+					Return(models.View{}, errors.NewSyntaxError("filter", 8, "expected a value"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockViewService)
+			SetupViews(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/views", CreateView)
+
+			req, _ := http.NewRequest("POST", "/views", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateAndDeleteView(t *testing.T) {
+	mockService := new(MockViewService)
+	SetupViews(mockService)
+	forbidden := errors.NewForbiddenError(`permission "views:manage" is required to change views you do not own`)
+	mockService.On("UpdateView", mock.Anything, "v1", models.View{Name: "Renamed"}).Return(models.View{}, forbidden)
+	mockService.On("DeleteView", mock.Anything, "v1").Return(nil)
+
+	router := setupTestRouter()
+	router.PUT("/views/:id", UpdateView)
+	router.DELETE("/views/:id", DeleteView)
+
+	req, _ := http.NewRequest("PUT", "/views/v1", bytes.NewBufferString(`{"name":"Renamed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/views/v1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetViewTasks(t *testing.T) {
+	mockService := new(MockViewService)
+	SetupViews(mockService)
+	view := models.View{ID: "v1", Name: "Mine", Columns: []string{"title", "dueDate"}}
+	mockService.On("QueryViewTasks", mock.Anything, "v1", models.TaskQuery{Cursor: "abc", Limit: 10}).
+		Return(view, models.TaskPage{Tasks: []models.Task{{ID: "t1"}}, NextCursor: "next"}, nil)
+	mockService.On("QueryViewTasks", mock.Anything, "missing", models.TaskQuery{}).
+		Return(models.View{}, models.TaskPage{}, errors.NewNotFoundError("View"))
+
+	router := setupTestRouter()
+	router.GET("/views/:id/tasks", GetViewTasks)
+
+	req, _ := http.NewRequest("GET", "/views/v1/tasks?cursor=abc&limit=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(1), response["count"])
+	assert.Equal(t, "next", response["nextCursor"])
+	assert.Equal(t, []interface{}{"title", "dueDate"}, response["columns"])
+
+	req, _ = http.NewRequest("GET", "/views/missing/tasks", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/views/v1/tasks?limit=0", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "limit", response["field"])
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var webhookService services.WebhookService
+
+// SetupWebhooks injects the service behind the webhook endpoints
+func SetupWebhooks(webhookSvc services.WebhookService) {
+	webhookService = webhookSvc
+}
+
+// webhookRequest is the body of a webhook create or update. Webhooks are
+// active unless the request says otherwise.
+type webhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+func (r webhookRequest) webhook() models.Webhook {
+	return models.Webhook{
+		URL:    r.URL,
+		Events: r.Events,
+		Secret: r.Secret,
+		Active: r.Active == nil || *r.Active,
+	}
+}
+
+// GetWebhooks lists every webhook subscription
+// @Summary List webhooks
+// @Description List every webhook subscription; secrets are not included
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Router /webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	webhooks, err := webhookService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": webhooks, "count": len(webhooks)})
+}
+
+// CreateWebhook subscribes a URL to task events
+// @Summary Create a webhook
+// @Description Subscribe a URL to task events. The response is the only time the signing secret is shown.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body webhookRequest true "Webhook subscription"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {object} map[string]string
+// @Router /webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := webhookService.CreateWebhook(c.Request.Context(), req.webhook())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    created,
+		"message": constants.MessageWebhookCreated,
+	})
+}
+
+// GetWebhook retrieves a webhook subscription
+// @Summary Get a webhook
+// @Description Get a webhook subscription by ID; its secret is not included
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.Webhook
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [get]
+func GetWebhook(c *gin.Context) {
+	webhook, err := webhookService.GetWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": webhook})
+}
+
+// UpdateWebhook replaces a webhook subscription
+// @Summary Update a webhook
+// @Description Replace a webhook's URL, events and active flag. The secret is kept unless a new one is given.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body webhookRequest true "Webhook subscription"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := webhookService.UpdateWebhook(c.Request.Context(), c.Param("id"), req.webhook())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": constants.MessageWebhookUpdated,
+	})
+}
+
+// DeleteWebhook removes a webhook subscription
+// @Summary Delete a webhook
+// @Description Remove a webhook subscription and its delivery log
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	if err := webhookService.DeleteWebhook(c.Request.Context(), c.Param("id")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": constants.MessageWebhookDeleted})
+}
+
+// GetWebhookDeliveries lists recent delivery attempts for a webhook
+// @Summary List webhook deliveries
+// @Description List the most recent delivery attempts for a webhook, newest first
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Maximum deliveries (1-500, default 50)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	limit := models.DefaultDeliveryLimit
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			handleError(c, errors.NewValidationError("limit", constants.ValidationInvalidDeliveryLimit))
+			return
+		}
+	}
+
+	deliveries, err := webhookService.ListDeliveries(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": deliveries, "count": len(deliveries)})
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookService is a mock implementation of WebhookService for testing
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id string) (models.Webhook, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	args := m.Called(ctx, webhook)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, id string, webhook models.Webhook) (models.Webhook, error) {
+	args := m.Called(ctx, id, webhook)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, id string, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, id, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockWebhookService)
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name: "Active by default",
+			body: `{"url":"https://example.com/hook","events":["task.created"]}`,
+			setupMock: func(m *MockWebhookService) {
+				m.On("CreateWebhook", mock.Anything, models.Webhook{URL: "https://example.com/hook", Events: []string{"task.created"}, Active: true}).
+					Return(models.Webhook{ID: "w1", URL: "https://example.com/hook", Secret: "abc", Active: true}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Created inactive",
+			body: `{"url":"https://example.com/hook","secret":"s3cret","active":false}`,
+			setupMock: func(m *MockWebhookService) {
+				m.On("CreateWebhook", mock.Anything, models.Webhook{URL: "https://example.com/hook", Secret: "s3cret"}).
+					Return(models.Webhook{ID: "w1", URL: "https://example.com/hook", Secret: "s3cret"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing URL",
+			body:           `{"events":["task.created"]}`,
+			setupMock:      func(m *MockWebhookService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid URL",
+			body: `{"url":"ftp://example.com"}`,
+			setupMock: func(m *MockWebhookService) {
+				m.On("CreateWebhook", mock.Anything, mock.Anything).
+					Return(models.Webhook{}, errors.NewValidationError("url", "url must be an absolute http or https URL"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			SetupWebhooks(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.POST("/webhooks", CreateWebhook)
+
+			req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedField != "" {
+				var response map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, tt.expectedField, response["field"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateAndDeleteWebhook(t *testing.T) {
+	mockService := new(MockWebhookService)
+	SetupWebhooks(mockService)
+	mockService.On("UpdateWebhook", mock.Anything, "w1", models.Webhook{URL: "https://example.com/v2", Active: true}).
+		Return(models.Webhook{ID: "w1", URL: "https://example.com/v2", Active: true}, nil)
+	mockService.On("DeleteWebhook", mock.Anything, "w2").Return(errors.NewNotFoundError("Webhook"))
+
+	router := setupTestRouter()
+	router.PUT("/webhooks/:id", UpdateWebhook)
+	router.DELETE("/webhooks/:id", DeleteWebhook)
+
+	req, _ := http.NewRequest("PUT", "/webhooks/w1", bytes.NewBufferString(`{"url":"https://example.com/v2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/webhooks/w2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		setupMock      func(*MockWebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Default limit",
+			url:  "/webhooks/w1/deliveries",
+			setupMock: func(m *MockWebhookService) {
+				m.On("ListDeliveries", mock.Anything, "w1", models.DefaultDeliveryLimit).
+					Return([]models.WebhookDelivery{{ID: "d1", WebhookID: "w1", Attempt: 1, Success: true}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Explicit limit",
+			url:  "/webhooks/w1/deliveries?limit=5",
+			setupMock: func(m *MockWebhookService) {
+				m.On("ListDeliveries", mock.Anything, "w1", 5).Return([]models.WebhookDelivery{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Non-numeric limit",
+			url:            "/webhooks/w1/deliveries?limit=all",
+			setupMock:      func(m *MockWebhookService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown webhook",
+			url:  "/webhooks/w2/deliveries",
+			setupMock: func(m *MockWebhookService) {
+				m.On("ListDeliveries", mock.Anything, "w2", models.DefaultDeliveryLimit).
+					Return([]models.WebhookDelivery(nil), errors.NewNotFoundError("Webhook"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			SetupWebhooks(mockService)
+			tt.setupMock(mockService)
+
+			router := setupTestRouter()
+			router.GET("/webhooks/:id/deliveries", GetWebhookDeliveries)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -19,13 +19,19 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
-// ValidationError represents a validation error
+// ValidationError represents a validation error. Column is set for errors
+// in an expression, such as a filter, and is the 1-based character position
+// of the problem.
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+	Column  int    `json:"column,omitempty"`
 }
 
 func (e *ValidationError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s: %s at column %d", e.Field, e.Message, e.Column)
+	}
 	return fmt.Sprintf("%s: %s", e.Field, e.Message)
 }
 
@@ -37,6 +43,16 @@ func NewValidationError(field, message string) *ValidationError {
 	}
 }
 
+// NewSyntaxError creates a validation error pointing at a column of an
+// expression
+func NewSyntaxError(field string, column int, message string) *ValidationError {
+	return &ValidationError{
+		Field:   field,
+		Message: message,
+		Column:  column,
+	}
+}
+
 // NewAppError creates a new application error
 func NewAppError(code int, message string) *AppError {
 	return &AppError{
@@ -61,6 +77,46 @@ func NewBadRequestError(message string) *AppError {
 	}
 }
 
+// NewUnauthorizedError creates a new unauthorized error
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Message: message,
+	}
+}
+
+// NewForbiddenError creates a new forbidden error
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusForbidden,
+		Message: message,
+	}
+}
+
+// NewConflictError creates a new conflict error
+func NewConflictError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: message,
+	}
+}
+
+// NewPreconditionFailedError creates a new precondition failed error
+func NewPreconditionFailedError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusPreconditionFailed,
+		Message: message,
+	}
+}
+
+// NewUnsupportedMediaTypeError creates a new unsupported media type error
+func NewUnsupportedMediaTypeError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusUnsupportedMediaType,
+		Message: message,
+	}
+}
+
 // NewInternalServerError creates a new internal server error
 func NewInternalServerError(message string) *AppError {
 	return &AppError{
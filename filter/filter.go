@@ -0,0 +1,124 @@
+// Package filter parses a small boolean language for filtering records by
+// field:
+//
+//	status:InProgress AND priority>=High AND due<2026-11-01 AND NOT assignedTo:bob@x
+//
+// A comparison is a field name, an operator and a value. Comparisons combine
+// with AND, OR and NOT (in falling order of precedence) and parentheses;
+// comparisons next to each other are ANDed. Keywords are case-insensitive.
+// Values that contain spaces or parentheses are written in double quotes,
+// with \" and \\ escaping a quote and a backslash.
+//
+// Parse checks the expression against a Schema and simplifies it so that the
+// result only uses equality on string fields and < or >= on time fields.
+// That keeps whatever compiles the tree, to a predicate or to a query
+// language, small, and makes the compiled forms agree with each other.
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a field
+type Kind int
+
+const (
+	// String fields match exactly
+	String Kind = iota
+	// Enum fields take one of a fixed list of values, matched regardless of
+	// case
+	Enum
+	// Time fields compare against a date (YYYY-MM-DD, a whole UTC day) or
+	// an RFC 3339 instant. Records without a time never match a comparison.
+	Time
+	// Set fields hold several strings; field:value matches records
+	// holding value
+	Set
+)
+
+// Field describes a field that expressions may refer to
+type Field struct {
+	Name    string
+	Aliases []string
+	Kind    Kind
+	// Values lists the values of an Enum field in ascending order
+	Values []string
+	// Ordered allows <, <=, > and >= on an Enum field, comparing by
+	// position in Values
+	Ordered bool
+}
+
+// Schema is the set of fields an expression may use
+type Schema struct {
+	fields map[string]Field
+}
+
+// NewSchema builds a schema. Field names and aliases are matched
+// regardless of case.
+func NewSchema(fields ...Field) Schema {
+	s := Schema{fields: map[string]Field{}}
+	for _, f := range fields {
+		s.fields[strings.ToLower(f.Name)] = f
+		for _, alias := range f.Aliases {
+			s.fields[strings.ToLower(alias)] = f
+		}
+	}
+	return s
+}
+
+func (s Schema) lookup(name string) (Field, bool) {
+	f, ok := s.fields[strings.ToLower(name)]
+	return f, ok
+}
+
+// Op is a comparison operator
+type Op string
+
+// Operators left in a parsed expression. Parse rewrites the others in terms
+// of these.
+const (
+	// OpEq is equality on String and Enum fields, and membership on Set
+	// fields
+	OpEq Op = "="
+	// OpLt and OpGe compare Time fields
+	OpLt Op = "<"
+	OpGe Op = ">="
+)
+
+// Expr is a parsed expression: an And, Or, Not or Comparison
+type Expr interface {
+	String() string
+}
+
+// And matches records matching both sides
+type And struct{ Left, Right Expr }
+
+// Or matches records matching either side
+type Or struct{ Left, Right Expr }
+
+// Not matches records that X does not match
+type Not struct{ X Expr }
+
+// Comparison compares a field with a value. Field is the canonical name
+// from the schema. Value holds the value of a String, Enum or Set
+// comparison, with Enum values in their canonical case; Time holds the
+// value of a Time comparison.
+type Comparison struct {
+	Field string
+	Op    Op
+	Value string
+	Time  time.Time
+}
+
+func (e And) String() string { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+func (e Or) String() string  { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+func (e Not) String() string { return "NOT " + e.X.String() }
+
+func (c Comparison) String() string {
+	if c.Op == OpLt || c.Op == OpGe {
+		return c.Field + string(c.Op) + c.Time.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s%s%q", c.Field, c.Op, c.Value)
+}
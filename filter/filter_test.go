@@ -0,0 +1,114 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+var testSchema = NewSchema(
+	Field{Name: "status", Kind: Enum, Values: []string{"Pending", "InProgress", "Completed"}},
+	Field{Name: "priority", Kind: Enum, Values: []string{"Low", "Medium", "High"}, Ordered: true},
+	Field{Name: "assignedTo", Kind: String},
+	Field{Name: "label", Aliases: []string{"labels"}, Kind: Set},
+	Field{Name: "due", Aliases: []string{"dueDate"}, Kind: Time},
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"status:InProgress", `status="InProgress"`},
+		{"STATUS = inprogress", `status="InProgress"`},
+		{"assignedTo:bob@x.com", `assignedTo="bob@x.com"`},
+		{`assignedTo:"Bob \"B\" Smith"`, `assignedTo="Bob \"B\" Smith"`},
+		{`assignedTo:""`, `assignedTo=""`},
+		{"labels!=bug", `NOT label="bug"`},
+		{"status:Pending priority:High", `(status="Pending" AND priority="High")`},
+		{"status:Pending and priority:High or label:x", `((status="Pending" AND priority="High") OR label="x")`},
+		{"status:Pending AND (priority:High OR label:x)", `(status="Pending" AND (priority="High" OR label="x"))`},
+		{"NOT NOT label:x", `NOT NOT label="x"`},
+		{"not(label:x)", `NOT label="x"`},
+		{"priority>=Medium", `(priority="Medium" OR priority="High")`},
+		{"priority<High", `(priority="Low" OR priority="Medium")`},
+		{"priority>medium", `priority="High"`},
+		{"due<2026-11-01", "due<2026-11-01T00:00:00Z"},
+		{"due<=2026-11-01", "due<2026-11-02T00:00:00Z"},
+		{"due>2026-11-01", "due>=2026-11-02T00:00:00Z"},
+		{"due >= 2026-11-01", "due>=2026-11-01T00:00:00Z"},
+		{"due:2026-11-01", "(due>=2026-11-01T00:00:00Z AND due<2026-11-02T00:00:00Z)"},
+		{"dueDate!=2026-11-01", "NOT (due>=2026-11-01T00:00:00Z AND due<2026-11-02T00:00:00Z)"},
+		{"due<2026-11-01T10:00:00+02:00", "due<2026-11-01T08:00:00Z"},
+		{"due=2026-11-01T10:00:00Z", "(due>=2026-11-01T10:00:00Z AND due<2026-11-01T10:00:00.000000001Z)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src, testSchema)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := e.String(); got != tt.want {
+				t.Errorf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	long := "label:x"
+	for len(long) <= MaxLength {
+		long += " label:x"
+	}
+	tests := []struct {
+		src    string
+		column int
+		msg    string
+	}{
+		{"", 1, "filter is empty"},
+		{"   ", 4, "filter is empty"},
+		{"status:Pending AND", 19, "unexpected end of filter"},
+		{"status:Pending AND OR label:x", 20, "unexpected OR"},
+		{"label:x AND :y", 13, "expected a field name"},
+		{"colour:red", 1, `unknown field "colour"`},
+		{"label:x AND colour:red", 13, `unknown field "colour"`},
+		{"status", 7, "expected an operator such as ':' after status"},
+		{"status~Pending", 7, "expected an operator such as ':' after status"},
+		{"status:", 8, "expected a value"},
+		{"status:Done", 8, `invalid status "Done"; expected one of Pending, InProgress, Completed`},
+		{"status<Completed", 7, "status only supports ':', '=' and '!='"},
+		{"label>=x", 6, "label only supports ':', '=' and '!='"},
+		{"priority<Low", 9, "priority<Low can never match"},
+		{"due<tomorrow", 5, `invalid time "tomorrow"; use YYYY-MM-DD or RFC 3339`},
+		{"due<2026-13-01", 5, `invalid time "2026-13-01"; use YYYY-MM-DD or RFC 3339`},
+		{`assignedTo:"bob`, 12, "unterminated string"},
+		{"(label:x", 1, "'(' is never closed"},
+		{"label:x)", 8, "unexpected ')'"},
+		{"()", 2, "unexpected ')'"},
+		{"label:é AND colour:x", 13, `unknown field "colour"`},
+		{long, MaxLength + 1, "filter must be at most 1000 characters"},
+	}
+	for _, tt := range tests {
+		name := tt.src
+		if len(name) > 40 {
+			name = name[:40]
+		}
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tt.src, testSchema)
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("Parse() error = %v, want a SyntaxError", err)
+			}
+			if syntax.Column != tt.column || syntax.Msg != tt.msg {
+				t.Errorf("Parse() error = %q at column %d, want %q at column %d", syntax.Msg, syntax.Column, tt.msg, tt.column)
+			}
+		})
+	}
+
+	deep := ""
+	for i := 0; i <= maxDepth; i++ {
+		deep += "NOT "
+	}
+	if _, err := Parse(deep+"label:x", testSchema); err == nil {
+		t.Error("Parse() accepted a too deeply nested filter")
+	}
+}
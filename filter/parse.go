@@ -0,0 +1,348 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest expression Parse accepts, in bytes. It keeps the
+// nesting of the parsed tree within what databases will evaluate.
+const MaxLength = 1000
+
+// maxDepth bounds how deeply parentheses and NOT may nest
+const maxDepth = 32
+
+// dateLayout is the layout of a date value
+const dateLayout = "2006-01-02"
+
+// SyntaxError describes a malformed expression
+type SyntaxError struct {
+	// Column is the 1-based character position of the problem
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Column)
+}
+
+// operators lists the operators a comparison may use, longest first so
+// that "<=" is not read as "<"
+var operators = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+type parser struct {
+	src    string
+	pos    int
+	schema Schema
+	depth  int
+}
+
+// Parse parses src into an expression over the fields of schema
+func Parse(src string, schema Schema) (Expr, error) {
+	p := &parser{src: src, schema: schema}
+	if len(src) > MaxLength {
+		return nil, p.errorAt(MaxLength, fmt.Sprintf("filter must be at most %d characters", MaxLength))
+	}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorAt(p.pos, "filter is empty")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		// parseAnd only stops early at a ')'
+		return nil, p.errorAt(p.pos, "unexpected ')'")
+	}
+	return e, nil
+}
+
+func (p *parser) errorAt(offset int, msg string) error {
+	return &SyntaxError{Column: utf8.RuneCountInString(p.src[:offset]) + 1, Msg: msg}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// keyword consumes word, in any case, if it comes next as a whole word
+func (p *parser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.src) || !strings.EqualFold(p.src[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.src) && isNameByte(p.src[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// parseOr parses and-expressions separated by OR
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.keyword("OR") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+}
+
+// parseAnd parses unary expressions separated by AND or by nothing at all
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.src[p.pos] == ')' {
+			return left, nil
+		}
+		save := p.pos
+		if p.keyword("OR") {
+			p.pos = save
+			return left, nil
+		}
+		p.keyword("AND")
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+// parseUnary parses NOT, a parenthesised expression or a comparison
+func (p *parser) parseUnary() (Expr, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.keyword("NOT") {
+		if p.depth++; p.depth > maxDepth {
+			return nil, p.errorAt(start, "filter is nested too deeply")
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		p.depth--
+		return Not{X: x}, nil
+	}
+	if !p.eof() && p.src[p.pos] == '(' {
+		if p.depth++; p.depth > maxDepth {
+			return nil, p.errorAt(start, "filter is nested too deeply")
+		}
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, p.errorAt(start, "'(' is never closed")
+		}
+		p.pos++
+		p.depth--
+		return e, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses field, operator and value
+func (p *parser) parseComparison() (Expr, error) {
+	start := p.pos
+	for !p.eof() && isNameByte(p.src[p.pos]) {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	if name == "" {
+		switch {
+		case p.eof():
+			return nil, p.errorAt(p.pos, "unexpected end of filter")
+		case p.src[p.pos] == ')':
+			return nil, p.errorAt(p.pos, "unexpected ')'")
+		default:
+			return nil, p.errorAt(p.pos, "expected a field name")
+		}
+	}
+	if strings.EqualFold(name, "AND") || strings.EqualFold(name, "OR") {
+		return nil, p.errorAt(start, "unexpected "+strings.ToUpper(name))
+	}
+	field, ok := p.schema.lookup(name)
+	if !ok {
+		return nil, p.errorAt(start, fmt.Sprintf("unknown field %q", name))
+	}
+
+	p.skipSpace()
+	opStart := p.pos
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(p.src[p.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, p.errorAt(p.pos, fmt.Sprintf("expected an operator such as ':' after %s", name))
+	}
+	p.pos += len(op)
+
+	p.skipSpace()
+	valueStart := p.pos
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return p.comparison(field, op, value, opStart, valueStart)
+}
+
+// parseValue parses a quoted or bare value
+func (p *parser) parseValue() (string, error) {
+	start := p.pos
+	if p.eof() || p.src[p.pos] != '"' {
+		for !p.eof() && !isSpace(p.src[p.pos]) && !strings.ContainsRune(`()"`, rune(p.src[p.pos])) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.errorAt(p.pos, "expected a value")
+		}
+		return p.src[start:p.pos], nil
+	}
+
+	var b strings.Builder
+	for p.pos++; !p.eof(); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if p.pos+1 < len(p.src) && (p.src[p.pos+1] == '"' || p.src[p.pos+1] == '\\') {
+				p.pos++
+				b.WriteByte(p.src[p.pos])
+				continue
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorAt(start, "unterminated string")
+}
+
+// comparison checks a comparison against its field and rewrites it in terms
+// of OpEq, OpLt and OpGe
+func (p *parser) comparison(field Field, op, value string, opStart, valueStart int) (Expr, error) {
+	eq := func(value string) Expr {
+		return Comparison{Field: field.Name, Op: OpEq, Value: value}
+	}
+	ordered := op == "<" || op == "<=" || op == ">" || op == ">="
+
+	switch field.Kind {
+	case Time:
+		from, to, err := timeRange(value)
+		if err != nil {
+			return nil, p.errorAt(valueStart, fmt.Sprintf("invalid time %q; use YYYY-MM-DD or RFC 3339", value))
+		}
+		lt := func(t time.Time) Expr { return Comparison{Field: field.Name, Op: OpLt, Time: t} }
+		ge := func(t time.Time) Expr { return Comparison{Field: field.Name, Op: OpGe, Time: t} }
+		switch op {
+		case "<":
+			return lt(from), nil
+		case "<=":
+			return lt(to), nil
+		case ">":
+			return ge(to), nil
+		case ">=":
+			return ge(from), nil
+		case "!=":
+			return Not{X: And{Left: ge(from), Right: lt(to)}}, nil
+		default:
+			return And{Left: ge(from), Right: lt(to)}, nil
+		}
+
+	case Enum:
+		index := -1
+		for i, v := range field.Values {
+			if strings.EqualFold(v, value) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, p.errorAt(valueStart, fmt.Sprintf("invalid %s %q; expected one of %s", field.Name, value, strings.Join(field.Values, ", ")))
+		}
+		if ordered && !field.Ordered {
+			return nil, p.errorAt(opStart, fmt.Sprintf("%s only supports ':', '=' and '!='", field.Name))
+		}
+		var values []string
+		switch op {
+		case "<":
+			values = field.Values[:index]
+		case "<=":
+			values = field.Values[:index+1]
+		case ">":
+			values = field.Values[index+1:]
+		case ">=":
+			values = field.Values[index:]
+		case "!=":
+			return Not{X: eq(field.Values[index])}, nil
+		default:
+			return eq(field.Values[index]), nil
+		}
+		if len(values) == 0 {
+			return nil, p.errorAt(opStart, fmt.Sprintf("%s%s%s can never match", field.Name, op, field.Values[index]))
+		}
+		e := eq(values[0])
+		for _, v := range values[1:] {
+			e = Or{Left: e, Right: eq(v)}
+		}
+		return e, nil
+
+	default:
+		if ordered {
+			return nil, p.errorAt(opStart, fmt.Sprintf("%s only supports ':', '=' and '!='", field.Name))
+		}
+		if op == "!=" {
+			return Not{X: eq(value)}, nil
+		}
+		return eq(value), nil
+	}
+}
+
+// timeRange returns the span a time value denotes: a whole UTC day for a
+// date, or a single instant
+func timeRange(value string) (from, to time.Time, err error) {
+	if day, err := time.Parse(dateLayout, value); err == nil {
+		return day, day.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return t, t.Add(time.Nanosecond), nil
+}
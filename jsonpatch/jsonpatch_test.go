@@ -0,0 +1,140 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// assertJSONEqual compares two JSON documents semantically
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	g, err := decode(got)
+	if err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	w, err := decode([]byte(want))
+	if err != nil {
+		t.Fatalf("expected value is not valid JSON: %v", err)
+	}
+	if !jsonEqual(g, w) {
+		t.Errorf("result = %s, want %s", got, want)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7396 Appendix A
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch() unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{not json`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("MergePatch() with malformed patch error = %v, want %v", err, ErrInvalidPatch)
+	}
+}
+
+func TestApply(t *testing.T) {
+	// Test cases adapted from RFC 6902 Appendix A
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"Add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"Add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"Append array element", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{"Add null value", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"foo":"bar","baz":null}`},
+		{"Remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"Remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"Replace value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"Move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"Move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"Copy value", `{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":{"bar":1},"baz":{"bar":1}}`},
+		{"Test passes", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"Escaped pointer", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"replace","path":"/~1","value":1}]`, `{"/":1,"~1":10}`},
+		{"Replace root", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply() unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		wantErr error
+	}{
+		{"Not an array", `{}`, `{"op":"add"}`, ErrInvalidPatch},
+		{"Unknown op", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ErrInvalidPatch},
+		{"Missing value", `{}`, `[{"op":"add","path":"/a"}]`, ErrInvalidPatch},
+		{"Add to missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ErrInvalidPatch},
+		{"Replace missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, ErrInvalidPatch},
+		{"Remove missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, ErrInvalidPatch},
+		{"Array index out of range", `{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":2}]`, ErrInvalidPatch},
+		{"Leading zero index", `{"foo":[1,2]}`, `[{"op":"remove","path":"/foo/01"}]`, ErrInvalidPatch},
+		{"Pointer without slash", `{"foo":1}`, `[{"op":"remove","path":"foo"}]`, ErrInvalidPatch},
+		{"Move into child", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, ErrInvalidPatch},
+		{"Test fails", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrTestFailed},
+		{"Test string against number", `{"baz":"1"}`, `[{"op":"test","path":"/baz","value":1}]`, ErrTestFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply_IsAtomic(t *testing.T) {
+	doc := []byte(`{"foo":"bar"}`)
+	_, err := Apply(doc, []byte(`[{"op":"replace","path":"/foo","value":"baz"},{"op":"remove","path":"/missing"}]`))
+	if err == nil {
+		t.Fatalf("Apply() expected error but got none")
+	}
+	var v map[string]string
+	json.Unmarshal(doc, &v)
+	if v["foo"] != "bar" {
+		t.Errorf("Apply() modified the input document")
+	}
+}
@@ -1,29 +1,482 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"taskmanager/auth"
 	"taskmanager/controllers"
+	"taskmanager/models"
+	"taskmanager/notifier"
 	"taskmanager/repository"
 	"taskmanager/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	_ "modernc.org/sqlite"
 )
 
+// stores holds the repositories picked by newStores so they can be closed together
+type stores struct {
+	tasks       repository.TaskRepository
+	audit       repository.AuditRepository
+	reminders   repository.ReminderRepository
+	webhooks    repository.WebhookRepository
+	projects    repository.ProjectRepository
+	comments    repository.CommentRepository
+	attachments repository.AttachmentRepository
+	views       repository.ViewRepository
+	blobs       repository.BlobStore
+	closers     []func() error
+}
+
+// Close closes every store, returning the first error
+func (s *stores) Close() error {
+	var first error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := s.closers[i](); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// newStores picks the storage backend from the environment.
+// TASKS_DB_PATH selects an SQLite database, TASKS_DATA_DIR the file-backed
+// write-ahead log store; otherwise tasks live in memory only. The audit log,
+// the record of sent reminders, webhook subscriptions, projects, comments,
+// attachment metadata and saved views are kept alongside the tasks.
+// Attachment content goes to TASKS_BLOB_DIR, defaulting to a blobs directory
+// next to the tasks.
+func newStores() (*stores, error) {
+	s := &stores{}
+	if path := os.Getenv("TASKS_DB_PATH"); path != "" {
+		db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, db.Close)
+		if s.tasks, err = repository.NewSQLTaskRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.audit, err = repository.NewSQLAuditRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.reminders, err = repository.NewSQLReminderRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.webhooks, err = repository.NewSQLWebhookRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.projects, err = repository.NewSQLProjectRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.comments, err = repository.NewSQLCommentRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.attachments, err = repository.NewSQLAttachmentRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.views, err = repository.NewSQLViewRepo(db); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.blobs, err = newBlobStore(filepath.Join(filepath.Dir(path), "blobs")); err != nil {
+			s.Close()
+			return nil, err
+		}
+		return s, nil
+	}
+
+	if dir := os.Getenv("TASKS_DATA_DIR"); dir != "" {
+		tasks, err := repository.NewFileTaskRepo(dir, time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		s.tasks = tasks
+		s.closers = append(s.closers, tasks.Close)
+		audit, err := repository.NewFileAuditRepo(dir)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.audit = audit
+		s.closers = append(s.closers, audit.Close)
+		reminders, err := repository.NewFileReminderRepo(dir)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.reminders = reminders
+		s.closers = append(s.closers, reminders.Close)
+		webhooks, err := repository.NewFileWebhookRepo(dir)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.webhooks = webhooks
+		s.closers = append(s.closers, webhooks.Close)
+		if s.projects, err = repository.NewFileProjectRepo(dir); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.comments, err = repository.NewFileCommentRepo(dir); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.attachments, err = repository.NewFileAttachmentRepo(dir); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.views, err = repository.NewFileViewRepo(dir); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if s.blobs, err = newBlobStore(filepath.Join(dir, "blobs")); err != nil {
+			s.Close()
+			return nil, err
+		}
+		return s, nil
+	}
+
+	s.tasks = repository.NewInMemoryTaskRepo()
+	s.audit = repository.NewInMemoryAuditRepo()
+	s.reminders = repository.NewInMemoryReminderRepo()
+	s.webhooks = repository.NewInMemoryWebhookRepo()
+	s.projects = repository.NewInMemoryProjectRepo()
+	s.comments = repository.NewInMemoryCommentRepo()
+	s.attachments = repository.NewInMemoryAttachmentRepo()
+	s.views = repository.NewInMemoryViewRepo()
+	if dir := os.Getenv("TASKS_BLOB_DIR"); dir != "" {
+		blobs, err := repository.NewFileBlobStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		s.blobs = blobs
+	} else {
+		s.blobs = repository.NewInMemoryBlobStore()
+	}
+	return s, nil
+}
+
+// newBlobStore opens the blob store in TASKS_BLOB_DIR, or in def when that
+// is not set
+func newBlobStore(def string) (repository.BlobStore, error) {
+	dir := os.Getenv("TASKS_BLOB_DIR")
+	if dir == "" {
+		dir = def
+	}
+	return repository.NewFileBlobStore(dir)
+}
+
+// attachmentLimitsFromEnv overrides the default attachment limits with
+// TASKS_ATTACHMENT_MAX_BYTES and the comma-separated TASKS_ATTACHMENT_TYPES
+func attachmentLimitsFromEnv() (models.AttachmentLimits, error) {
+	limits := models.DefaultAttachmentLimits()
+	if raw := os.Getenv("TASKS_ATTACHMENT_MAX_BYTES"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			return limits, fmt.Errorf("TASKS_ATTACHMENT_MAX_BYTES: %q is not a positive number", raw)
+		}
+		limits.MaxSize = n
+	}
+	if raw := os.Getenv("TASKS_ATTACHMENT_TYPES"); raw != "" {
+		limits.AllowedTypes = nil
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				limits.AllowedTypes = append(limits.AllowedTypes, t)
+			}
+		}
+	}
+	return limits, nil
+}
+
+// Background job defaults, used when the matching variable is not set
+const (
+	defaultTrashRetention   = 30 * 24 * time.Hour
+	defaultReminderInterval = time.Minute
+	defaultReminderLead     = 24 * time.Hour
+	webhookWorkers          = 4
+)
+
+// durationFromEnv reads the environment variable name as a non-negative Go
+// duration, falling back to def when it is not set
+func durationFromEnv(name string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s: %s is negative", name, raw)
+	}
+	return d, nil
+}
+
+// boolFromEnv reads the environment variable name as a boolean, which is
+// false when it is not set
+func boolFromEnv(name string) (bool, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s: %q is not true or false", name, raw)
+	}
+	return b, nil
+}
+
+// newNotifier emails reminders through TASKS_SMTP_ADDR when it is set and
+// logs them otherwise
+func newNotifier() (notifier.Notifier, error) {
+	addr := os.Getenv("TASKS_SMTP_ADDR")
+	if addr == "" {
+		return notifier.NewLogNotifier(nil), nil
+	}
+	cfg := notifier.SMTPConfig{
+		Addr:     addr,
+		From:     os.Getenv("TASKS_SMTP_FROM"),
+		Username: os.Getenv("TASKS_SMTP_USERNAME"),
+		Password: os.Getenv("TASKS_SMTP_PASSWORD"),
+	}
+	for _, to := range strings.Split(os.Getenv("TASKS_SMTP_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			cfg.To = append(cfg.To, to)
+		}
+	}
+	return notifier.NewSMTPNotifier(cfg)
+}
+
+// newAuthenticator accepts the API keys listed in TASKS_API_KEYS_FILE and
+// bearer tokens signed with a key in TASKS_JWKS_FILE. It returns nil, which
+// leaves the API open, when neither is set.
+func newAuthenticator() (*auth.Authenticator, error) {
+	keysPath, jwksPath := os.Getenv("TASKS_API_KEYS_FILE"), os.Getenv("TASKS_JWKS_FILE")
+	if keysPath == "" && jwksPath == "" {
+		return nil, nil
+	}
+	cfg := auth.Config{
+		Issuer:   os.Getenv("TASKS_JWT_ISSUER"),
+		Audience: os.Getenv("TASKS_JWT_AUDIENCE"),
+	}
+	if keysPath != "" {
+		keys, err := auth.LoadAPIKeys(keysPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.APIKeys = keys
+	}
+	if jwksPath != "" {
+		keys, err := auth.LoadKeySet(jwksPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Keys = keys
+	}
+	return auth.NewAuthenticator(cfg)
+}
+
+// loadTransitionGraph reads a JSON object mapping each status to the list of
+// statuses it may move to
+func loadTransitionGraph(path string) (models.TransitionGraph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var graph models.TransitionGraph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return nil, err
+	}
+	return graph, graph.Validate()
+}
+
+// loadRolePolicy reads a JSON object mapping each role to the permissions it
+// grants. It replaces the default policy entirely.
+func loadRolePolicy(path string) (models.RolePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var policy models.RolePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, err
+	}
+	return policy, policy.Validate()
+}
+
 func main() {
-	repo := repository.NewInMemoryTaskRepo()
-	service := services.NewTaskService(repo)
+	store, err := newStores()
+	if err != nil {
+		log.Fatal("Failed to open task repository:", err)
+	}
+	indexed, err := repository.NewIndexedTaskRepo(store.tasks)
+	if err != nil {
+		log.Fatal("Failed to build search index:", err)
+	}
+	store.tasks = indexed
+
+	policy := models.DefaultRolePolicy()
+	if path := os.Getenv("TASKS_ROLES_FILE"); path != "" {
+		if policy, err = loadRolePolicy(path); err != nil {
+			log.Fatal("Failed to load role policy:", err)
+		}
+	}
+
+	allowInternal, err := boolFromEnv("TASKS_WEBHOOK_ALLOW_INTERNAL")
+	if err != nil {
+		log.Fatal("Invalid webhook settings:", err)
+	}
+	var webhookOpts []services.WebhookServiceOption
+	var dispatcherOpts []services.WebhookDispatcherOption
+	if allowInternal {
+		webhookOpts = append(webhookOpts, services.AllowInternalURLs())
+		dispatcherOpts = append(dispatcherOpts, services.AllowInternalTargets())
+	}
+	dispatcher := services.NewWebhookDispatcher(store.webhooks, dispatcherOpts...)
+	attachments := services.NewAttachmentStore(store.attachments, store.blobs)
+	bus := services.NewEventBus(services.DefaultReplaySize, policy)
+	opts := []services.TaskServiceOption{
+		services.WithPolicy(policy),
+		services.WithAuditLog(store.audit),
+		services.WithProjects(store.projects),
+		services.WithComments(store.comments),
+		services.WithAttachments(attachments),
+		services.WithSearchIndex(indexed),
+		services.WithEventPublisher(dispatcher),
+		services.WithEventPublisher(bus),
+	}
+	if path := os.Getenv("TASKS_TRANSITIONS_FILE"); path != "" {
+		graph, err := loadTransitionGraph(path)
+		if err != nil {
+			log.Fatal("Failed to load transition graph:", err)
+		}
+		opts = append(opts, services.WithTransitions(graph))
+	}
+
+	// Zero retention keeps deleted tasks forever; a zero reminder interval
+	// turns reminders off
+	retention, err := durationFromEnv("TASKS_TRASH_RETENTION", defaultTrashRetention)
+	if err != nil {
+		log.Fatal("Invalid trash retention:", err)
+	}
+	reminderInterval, err := durationFromEnv("TASKS_REMINDER_INTERVAL", defaultReminderInterval)
+	if err != nil {
+		log.Fatal("Invalid reminder interval:", err)
+	}
+	reminderLead, err := durationFromEnv("TASKS_REMINDER_LEAD", defaultReminderLead)
+	if err != nil {
+		log.Fatal("Invalid reminder lead time:", err)
+	}
+	notify, err := newNotifier()
+	if err != nil {
+		log.Fatal("Invalid SMTP settings:", err)
+	}
+	attachmentLimits, err := attachmentLimitsFromEnv()
+	if err != nil {
+		log.Fatal("Invalid attachment limits:", err)
+	}
+	authn, err := newAuthenticator()
+	if err != nil {
+		log.Fatal("Failed to load credentials:", err)
+	}
+
+	service := services.NewTaskService(store.tasks, opts...)
 	controllers.Setup(service)
+	controllers.SetupAudit(services.NewAuditService(store.audit, store.tasks, policy))
+	controllers.SetupWebhooks(services.NewWebhookService(store.webhooks, policy, webhookOpts...))
+	controllers.SetupProjects(services.NewProjectService(store.projects, service, policy))
+	controllers.SetupComments(services.NewCommentService(store.comments, service, policy))
+	controllers.SetupAttachments(services.NewAttachmentService(attachments, service, policy, attachmentLimits))
+	controllers.SetupViews(services.NewViewService(store.views, service, policy))
+	controllers.SetupStream(bus)
 
 	router := gin.Default()
 
 	// API routes
 	api := router.Group("/api/v1")
+	if authn != nil {
+		api.Use(controllers.Authenticate(authn))
+	} else {
+		log.Println("Authentication is disabled; set TASKS_API_KEYS_FILE or TASKS_JWKS_FILE to require it")
+		api.Use(controllers.ActorFromHeader())
+	}
+	api.Use(controllers.ScopeWorkspace(policy))
 	{
 		api.GET("/tasks", controllers.GetTasks)
+		api.GET("/tasks/next", controllers.GetNextTasks)
+		api.GET("/tasks/search", controllers.SearchTasks)
+		api.GET("/tasks/stream", controllers.StreamTasks)
+		api.GET("/ws", controllers.BoardSocket)
 		api.POST("/tasks", controllers.CreateTask)
 		api.GET("/tasks/:id", controllers.GetTaskByID)
 		api.PUT("/tasks/:id", controllers.UpdateTask)
+		api.PATCH("/tasks/:id", controllers.PatchTask)
 		api.DELETE("/tasks/:id", controllers.DeleteTask)
+		api.GET("/tasks/:id/transitions", controllers.GetTaskTransitions)
+		api.POST("/tasks/:id/transitions", controllers.TransitionTask)
+		api.GET("/tasks/:id/occurrences", controllers.GetTaskOccurrences)
+		api.GET("/tasks/:id/history", controllers.GetTaskHistory)
+		api.GET("/tasks/:id/children", controllers.GetTaskChildren)
+		api.GET("/tasks/:id/subtree", controllers.GetTaskSubtree)
+		api.GET("/tasks/:id/dependencies", controllers.GetTaskDependencies)
+		api.POST("/tasks/:id/dependencies", controllers.AddTaskDependency)
+		api.DELETE("/tasks/:id/dependencies/:blockerId", controllers.RemoveTaskDependency)
+		api.POST("/tasks/:id/restore", controllers.RestoreTask)
+		api.GET("/tasks/:id/comments", controllers.GetTaskComments)
+		api.POST("/tasks/:id/comments", controllers.AddTaskComment)
+		api.GET("/tasks/:id/comments/:commentId", controllers.GetTaskComment)
+		api.PUT("/tasks/:id/comments/:commentId", controllers.UpdateTaskComment)
+		api.DELETE("/tasks/:id/comments/:commentId", controllers.DeleteTaskComment)
+		api.GET("/tasks/:id/attachments", controllers.GetTaskAttachments)
+		api.POST("/tasks/:id/attachments", controllers.UploadTaskAttachment)
+		api.GET("/tasks/:id/attachments/:attachmentId", controllers.GetTaskAttachment)
+		api.GET("/tasks/:id/attachments/:attachmentId/content", controllers.DownloadTaskAttachment)
+		api.DELETE("/tasks/:id/attachments/:attachmentId", controllers.DeleteTaskAttachment)
+		api.GET("/trash", controllers.GetTrash)
+		api.GET("/audit", controllers.GetAuditLog)
+		api.GET("/webhooks", controllers.GetWebhooks)
+		api.POST("/webhooks", controllers.CreateWebhook)
+		api.GET("/webhooks/:id", controllers.GetWebhook)
+		api.PUT("/webhooks/:id", controllers.UpdateWebhook)
+		api.DELETE("/webhooks/:id", controllers.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", controllers.GetWebhookDeliveries)
+		api.GET("/labels", controllers.GetLabels)
+		api.POST("/labels/rename", controllers.RenameLabel)
+		api.POST("/labels/merge", controllers.MergeLabels)
+		api.GET("/projects", controllers.GetProjects)
+		api.POST("/projects", controllers.CreateProject)
+		api.GET("/projects/:id", controllers.GetProject)
+		api.PUT("/projects/:id", controllers.UpdateProject)
+		api.DELETE("/projects/:id", controllers.DeleteProject)
+		api.GET("/projects/:id/tasks", controllers.GetProjectTasks)
+		api.GET("/projects/:id/stats", controllers.GetProjectStats)
+		api.GET("/views", controllers.GetViews)
+		api.POST("/views", controllers.CreateView)
+		api.GET("/views/:id", controllers.GetView)
+		api.PUT("/views/:id", controllers.UpdateView)
+		api.DELETE("/views/:id", controllers.DeleteView)
+		api.GET("/views/:id/tasks", controllers.GetViewTasks)
 	}
 
 	// Health check endpoint
@@ -31,8 +484,56 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
-	log.Println("Starting server on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	srv := &http.Server{Addr: ":8080", Handler: router}
+	// Shutdown waits for open requests, so end event streams first
+	srv.RegisterOnShutdown(bus.Close)
+	go func() {
+		log.Println("Starting server on :8080")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	// Shut down cleanly on SIGINT/SIGTERM so the repository can flush its state
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var jobs sync.WaitGroup
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		dispatcher.Run(ctx, webhookWorkers)
+	}()
+	if retention > 0 {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			services.RunTrashPurger(ctx, service, retention, min(retention, time.Hour))
+		}()
+	}
+	if reminderInterval > 0 {
+		scheduler := services.NewReminderScheduler(service, store.reminders, notify, reminderLead)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			scheduler.Run(ctx, reminderInterval)
+		}()
+	}
+
+	<-ctx.Done()
+
+	log.Println("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Shutdown leaves WebSockets open, so end board sessions first
+	if err := controllers.CloseBoardSockets(shutdownCtx); err != nil {
+		log.Println("Board socket shutdown error:", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown error:", err)
+	}
+	jobs.Wait()
+	if err := store.Close(); err != nil {
+		log.Println("Failed to close task repository:", err)
 	}
 }
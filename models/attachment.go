@@ -0,0 +1,69 @@
+package models
+
+import (
+	"mime"
+	"path/filepath"
+	"slices"
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxFilenameLength limits how long an attachment's filename may be
+const MaxFilenameLength = 255
+
+// Attachment describes a file attached to a task. The content itself lives
+// in a blob store under its SHA-256 hash, so identical files share a blob.
+type Attachment struct {
+	ID          string    `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	TaskID      string    `json:"taskId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Workspace   string    `json:"workspace,omitempty" example:"default"`
+	Filename    string    `json:"filename" example:"screenshot.png"`
+	ContentType string    `json:"contentType" example:"image/png"`
+	Size        int64     `json:"size" example:"48213"`
+	SHA256      string    `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	UploadedBy  string    `json:"uploadedBy" example:"alice@example.com"`
+	CreatedAt   time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+}
+
+// AttachmentLimits bounds what may be uploaded. AllowedTypes holds media
+// types such as "image/png"; "image/*" allows a whole family.
+type AttachmentLimits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+// DefaultAttachmentLimits allows screenshots, logs, PDFs and archives of up
+// to 10 MiB
+func DefaultAttachmentLimits() AttachmentLimits {
+	return AttachmentLimits{
+		MaxSize: 10 << 20,
+		AllowedTypes: []string{
+			"image/png", "image/jpeg", "image/gif", "image/webp",
+			"text/plain", "application/pdf", "application/zip", "application/x-gzip",
+		},
+	}
+}
+
+// Allows reports whether contentType is one of the allowed types. Parameters
+// such as charset are ignored.
+func (l AttachmentLimits) Allows(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	family, _, _ := strings.Cut(mediaType, "/")
+	return slices.Contains(l.AllowedTypes, mediaType) || slices.Contains(l.AllowedTypes, family+"/*")
+}
+
+// CleanFilename strips any directory from an uploaded file's name and checks
+// what is left
+func CleanFilename(name string) (string, error) {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "." || name == "/" || name == "" || len(name) > MaxFilenameLength || !utf8.ValidString(name) {
+		return "", errors.NewValidationError("file", constants.ValidationInvalidFilename)
+	}
+	return name, nil
+}
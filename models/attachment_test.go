@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"strings"
+	"taskmanager/models"
+	"testing"
+)
+
+func TestAttachmentLimits_Allows(t *testing.T) {
+	limits := models.AttachmentLimits{AllowedTypes: []string{"text/plain", "image/*"}}
+	tests := []struct {
+		contentType string
+		expected    bool
+	}{
+		{"text/plain", true},
+		{"text/plain; charset=utf-8", true},
+		{"image/png", true},
+		{"image/svg+xml", true},
+		{"text/html", false},
+		{"application/octet-stream", false},
+		{"not a type", false},
+	}
+	for _, tt := range tests {
+		if got := limits.Allows(tt.contentType); got != tt.expected {
+			t.Errorf("Allows(%q) = %v, want %v", tt.contentType, got, tt.expected)
+		}
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"screenshot.png", "screenshot.png", false},
+		{"../../etc/passwd", "passwd", false},
+		{`C:\Users\me\build.log`, "build.log", false},
+		{"  notes.txt ", "notes.txt", false},
+		{"", "", true},
+		{"/", "", true},
+		{strings.Repeat("x", models.MaxFilenameLength+1), "", true},
+	}
+	for _, tt := range tests {
+		got, err := models.CleanFilename(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("CleanFilename(%q) = %q, %v, want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
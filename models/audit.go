@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// Audit log paging limits
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+// FieldChange records the old and new value of a single task field
+type FieldChange struct {
+	Field string `json:"field" example:"status"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditEntry records one create, update or delete of a task
+type AuditEntry struct {
+	ID        string        `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	TaskID    string        `json:"taskId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Workspace string        `json:"workspace,omitempty" example:"default"`
+	Action    string        `json:"action" example:"update"`
+	Actor     string        `json:"actor" example:"john.doe@example.com"`
+	Timestamp time.Time     `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// AuditQuery selects audit entries. From is inclusive and To exclusive;
+// entries are returned oldest first.
+type AuditQuery struct {
+	Workspace string
+	TaskID    string
+	Actor     string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+}
+
+// Normalize fills in defaults for unset fields
+func (q *AuditQuery) Normalize() {
+	if q.Limit <= 0 {
+		q.Limit = DefaultAuditLimit
+	}
+}
+
+// Validate checks the query's limit and time range
+func (q *AuditQuery) Validate() error {
+	if q.Limit < 0 || q.Limit > MaxAuditLimit {
+		return errors.NewValidationError("limit", constants.ValidationInvalidAuditLimit)
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return errors.NewValidationError("from", constants.ValidationInvalidTimeRange)
+	}
+	return nil
+}
+
+// Matches reports whether entry satisfies every filter in the query
+func (q *AuditQuery) Matches(entry AuditEntry) bool {
+	if q.Workspace != "" && entry.Workspace != q.Workspace {
+		return false
+	}
+	if q.TaskID != "" && entry.TaskID != q.TaskID {
+		return false
+	}
+	if q.Actor != "" && entry.Actor != q.Actor {
+		return false
+	}
+	return inRange(entry.Timestamp, q.From, q.To)
+}
+
+// auditIgnoredFields are maintained by the server on every write and would
+// only add noise to a diff
+var auditIgnoredFields = map[string]bool{
+	"id":        true,
+	"createdAt": true,
+	"updatedAt": true,
+	"version":   true,
+	"workspace": true,
+}
+
+// DiffTasks lists the fields that differ between before and after, keyed by
+// their JSON names and sorted by field. Diffing against a zero Task gives the
+// initial or final state of a created or deleted task.
+func DiffTasks(before, after Task) []FieldChange {
+	from, to := taskFields(before), taskFields(after)
+
+	names := make(map[string]bool)
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+
+	changes := []FieldChange{}
+	for name := range names {
+		if auditIgnoredFields[name] || reflect.DeepEqual(from[name], to[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Old: from[name], New: to[name]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// taskFields returns task's JSON representation as a map
+func taskFields(task Task) map[string]any {
+	data, _ := json.Marshal(task)
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	return fields
+}
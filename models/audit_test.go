@@ -0,0 +1,93 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestDiffTasks(t *testing.T) {
+	before := testutils.CreateTestTask()
+	before.DueDate = nil
+
+	after := before
+	after.Status = constants.StatusInProgress
+	after.Priority = ""
+	due := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	after.DueDate = &due
+	after.Version = before.Version + 1
+	after.UpdatedAt = before.UpdatedAt.Add(time.Minute)
+
+	changes := models.DiffTasks(before, after)
+	want := []models.FieldChange{
+		{Field: "dueDate", Old: nil, New: "2024-12-31T00:00:00Z"},
+		{Field: "priority", Old: constants.PriorityMedium, New: nil},
+		{Field: "status", Old: constants.StatusPending, New: constants.StatusInProgress},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("DiffTasks() = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("DiffTasks()[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	if changes := models.DiffTasks(before, before); len(changes) != 0 {
+		t.Errorf("DiffTasks() of identical tasks = %+v, want none", changes)
+	}
+
+	// Diffing a new task against the zero value lists its initial state
+	created := models.DiffTasks(models.Task{}, before)
+	fields := make(map[string]bool)
+	for _, c := range created {
+		fields[c.Field] = true
+	}
+	for _, f := range []string{"title", "description", "status", "priority", "assignedTo"} {
+		if !fields[f] {
+			t.Errorf("DiffTasks() from zero task missing field %q", f)
+		}
+	}
+	for _, f := range []string{"id", "createdAt", "updatedAt", "version"} {
+		if fields[f] {
+			t.Errorf("DiffTasks() includes server-managed field %q", f)
+		}
+	}
+}
+
+func TestAuditQuery_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	tests := []struct {
+		name      string
+		query     models.AuditQuery
+		wantField string
+	}{
+		{"Empty query", models.AuditQuery{}, ""},
+		{"Valid range", models.AuditQuery{From: &from, To: &to, Limit: 10}, ""},
+		{"Inverted range", models.AuditQuery{From: &to, To: &from}, "from"},
+		{"Limit too large", models.AuditQuery{Limit: models.MaxAuditLimit + 1}, "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			verr, ok := err.(*errors.ValidationError)
+			if !ok {
+				t.Fatalf("Validate() expected ValidationError, got %T", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("Validate() field = %v, want %v", verr.Field, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// MaxCommentLength limits how long a comment body may be
+const MaxCommentLength = 10000
+
+// Comment is a message in the discussion on a task
+type Comment struct {
+	ID        string        `json:"id" example:"3f1c2b7a-8d4e-4a5b-9c6d-7e8f9a0b1c2d"`
+	TaskID    string        `json:"taskId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Workspace string        `json:"workspace,omitempty" example:"default"`
+	Author    string        `json:"author" example:"alice@example.com"`
+	Body      string        `json:"body" example:"Blocked on the design review"`
+	Edits     []CommentEdit `json:"edits,omitempty"`
+	CreatedAt time.Time     `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt time.Time     `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+}
+
+// CommentEdit records the body a comment had before an edit, oldest first
+type CommentEdit struct {
+	Body     string    `json:"body" example:"Blocked on design"`
+	EditedBy string    `json:"editedBy" example:"alice@example.com"`
+	EditedAt time.Time `json:"editedAt" example:"2024-01-01T01:00:00Z"`
+}
+
+// Validate performs validation on the comment
+func (c *Comment) Validate() error {
+	if strings.TrimSpace(c.Body) == "" {
+		return errors.NewValidationError("body", constants.ValidationCommentBodyRequired)
+	}
+	if len(c.Body) > MaxCommentLength {
+		return errors.NewValidationError("body", constants.ValidationCommentBodyTooLong)
+	}
+	return nil
+}
+
+// Edit replaces the body, keeping the old one in the edit history
+func (c *Comment) Edit(body, editor string, at time.Time) {
+	c.Edits = append(c.Edits, CommentEdit{Body: c.Body, EditedBy: editor, EditedAt: at})
+	c.Body = body
+	c.UpdatedAt = at
+}
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"strings"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+func TestComment_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		comment   models.Comment
+		wantField string
+	}{
+		{"Valid", models.Comment{Body: "Looks good"}, ""},
+		{"Missing body", models.Comment{}, "body"},
+		{"Blank body", models.Comment{Body: " \n\t"}, "body"},
+		{"Body too long", models.Comment{Body: strings.Repeat("x", models.MaxCommentLength+1)}, "body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.comment.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if vErr, ok := err.(*errors.ValidationError); !ok || vErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want validation error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestComment_Edit(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := models.Comment{Body: "first", Author: "alice", CreatedAt: created, UpdatedAt: created}
+	c.Edit("second", "alice", created.Add(time.Hour))
+	c.Edit("third", "bob", created.Add(2*time.Hour))
+
+	if c.Body != "third" || !c.UpdatedAt.Equal(created.Add(2*time.Hour)) || !c.CreatedAt.Equal(created) {
+		t.Errorf("Edit() = %+v, want body third updated two hours in", c)
+	}
+	if len(c.Edits) != 2 || c.Edits[0].Body != "first" || c.Edits[1].Body != "second" || c.Edits[1].EditedBy != "bob" {
+		t.Errorf("Edit() history = %+v, want first then second", c.Edits)
+	}
+}
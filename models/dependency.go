@@ -0,0 +1,132 @@
+package models
+
+import (
+	"container/heap"
+	"taskmanager/constants"
+)
+
+// TaskDependencies lists the tasks a task waits on and the tasks waiting on
+// it. Blocked is true while any blocker is not yet completed.
+type TaskDependencies struct {
+	BlockedBy []Task `json:"blockedBy"`
+	Blocks    []Task `json:"blocks"`
+	Blocked   bool   `json:"blocked"`
+}
+
+// PlannedTask is a pending task's place in a dependency-ordered work plan.
+// Ready tasks can be started now.
+type PlannedTask struct {
+	Task
+	Ready bool `json:"ready"`
+}
+
+// IsBlockedBy reports whether blocker keeps a dependent task from starting
+func IsBlockedBy(blocker Task) bool {
+	return blocker.Status != constants.StatusCompleted
+}
+
+// PlanTasks orders the pending tasks among tasks so that each one comes after
+// every open task blocking it. Among tasks whose blockers have all been
+// placed, higher priority goes first, then earlier due date, then earlier
+// creation. Blockers missing from tasks are treated as gone and do not block.
+func PlanTasks(tasks []Task) []PlannedTask {
+	byID := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	indegree := make(map[string]int)
+	dependents := make(map[string][]string)
+	blocked := make(map[string]bool)
+	var open []Task
+	for _, task := range tasks {
+		if !task.IsOpen() {
+			continue
+		}
+		open = append(open, task)
+		for _, blockerID := range task.BlockedBy {
+			blocker, ok := byID[blockerID]
+			if !ok || !IsBlockedBy(blocker) {
+				continue
+			}
+			blocked[task.ID] = true
+			// Cancelled blockers hold the task back but never get placed
+			if blocker.IsOpen() {
+				indegree[task.ID]++
+				dependents[blockerID] = append(dependents[blockerID], task.ID)
+			}
+		}
+	}
+
+	available := &planQueue{}
+	for _, task := range open {
+		if indegree[task.ID] == 0 {
+			heap.Push(available, task)
+		}
+	}
+
+	plan := make([]PlannedTask, 0, len(open))
+	placed := make(map[string]bool, len(open))
+	for available.Len() > 0 {
+		task := heap.Pop(available).(Task)
+		placed[task.ID] = true
+		if task.Status == constants.StatusPending {
+			plan = append(plan, PlannedTask{Task: task, Ready: !blocked[task.ID]})
+		}
+		for _, dependentID := range dependents[task.ID] {
+			indegree[dependentID]--
+			if indegree[dependentID] == 0 {
+				heap.Push(available, byID[dependentID])
+			}
+		}
+	}
+
+	// Tasks caught in a cycle can never start; list them last
+	for _, task := range open {
+		if !placed[task.ID] && task.Status == constants.StatusPending {
+			plan = append(plan, PlannedTask{Task: task})
+		}
+	}
+	return plan
+}
+
+var planPriority = map[string]int{
+	constants.PriorityHigh:   3,
+	constants.PriorityMedium: 2,
+	constants.PriorityLow:    1,
+}
+
+// planQueue is a heap of tasks in the order PlanTasks prefers them
+type planQueue []Task
+
+func (q planQueue) Len() int { return len(q) }
+
+func (q planQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if pa, pb := planPriority[a.Priority], planPriority[b.Priority]; pa != pb {
+		return pa > pb
+	}
+	switch {
+	case a.DueDate != nil && b.DueDate == nil:
+		return true
+	case a.DueDate == nil && b.DueDate != nil:
+		return false
+	case a.DueDate != nil && !a.DueDate.Equal(*b.DueDate):
+		return a.DueDate.Before(*b.DueDate)
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+func (q planQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *planQueue) Push(x any) { *q = append(*q, x.(Task)) }
+
+func (q *planQueue) Pop() any {
+	old := *q
+	task := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return task
+}
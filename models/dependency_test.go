@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+func TestPlanTasks(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := func(id, status, priority string, blockedBy ...string) models.Task {
+		return models.Task{ID: id, Title: id, Status: status, Priority: priority, BlockedBy: blockedBy, CreatedAt: base}
+	}
+	due := base.AddDate(0, 0, 1)
+	dueSoon := task("soon", constants.StatusPending, constants.PriorityLow)
+	dueSoon.DueDate = &due
+
+	tasks := []models.Task{
+		task("done", constants.StatusCompleted, constants.PriorityHigh),
+		task("doing", constants.StatusInProgress, constants.PriorityLow),
+		task("dropped", constants.StatusCancelled, constants.PriorityLow),
+		// Ready: blocked only by a completed task or by tasks that no longer exist
+		task("low", constants.StatusPending, constants.PriorityLow, "done", "gone"),
+		task("high", constants.StatusPending, constants.PriorityHigh),
+		dueSoon,
+		// Waiting on an open task: placed after it
+		task("after-high", constants.StatusPending, constants.PriorityHigh, "high"),
+		task("after-doing", constants.StatusPending, constants.PriorityHigh, "doing"),
+		// Waiting on a cancelled task: never ready
+		task("stuck", constants.StatusPending, constants.PriorityMedium, "dropped"),
+		// A cycle can never be scheduled
+		task("loop-a", constants.StatusPending, constants.PriorityHigh, "loop-b"),
+		task("loop-b", constants.StatusPending, constants.PriorityHigh, "loop-a"),
+	}
+
+	plan := models.PlanTasks(tasks)
+
+	var order string
+	ready := make(map[string]bool)
+	position := make(map[string]int)
+	for i, planned := range plan {
+		order += planned.ID + " "
+		ready[planned.ID] = planned.Ready
+		position[planned.ID] = i
+	}
+	if len(plan) != 8 {
+		t.Fatalf("PlanTasks() = %v, want the 8 pending tasks", order)
+	}
+	for id, want := range map[string]bool{
+		"low": true, "high": true, "soon": true,
+		"after-high": false, "after-doing": false, "stuck": false, "loop-a": false, "loop-b": false,
+	} {
+		if ready[id] != want {
+			t.Errorf("PlanTasks() %s ready = %v, want %v (order %v)", id, ready[id], want, order)
+		}
+	}
+	if position["high"] != 0 {
+		t.Errorf("PlanTasks() order = %v, want high priority first", order)
+	}
+	if position["soon"] > position["low"] {
+		t.Errorf("PlanTasks() order = %v, want earlier due date before later", order)
+	}
+	if position["after-high"] < position["high"] {
+		t.Errorf("PlanTasks() order = %v, want blocked task after its blocker", order)
+	}
+	if position["loop-a"] < 6 || position["loop-b"] < 6 {
+		t.Errorf("PlanTasks() order = %v, want cycle last", order)
+	}
+}
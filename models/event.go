@@ -0,0 +1,64 @@
+package models
+
+import (
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// Task event types
+const (
+	EventTaskCreated = "task.created"
+	EventTaskUpdated = "task.updated"
+	EventTaskDeleted = "task.deleted"
+)
+
+// TaskEventTypes lists every event a task change can produce
+var TaskEventTypes = []string{EventTaskCreated, EventTaskUpdated, EventTaskDeleted}
+
+// TaskEvent announces a stored change to a task. Task is the task as it was
+// stored; for deletions that is its state in the trash. Previous is the task
+// before the change and is not set for creations.
+type TaskEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor"`
+	Task      Task      `json:"task"`
+	Previous  *Task     `json:"previous,omitempty"`
+}
+
+// TaskEventFilter selects the events about tasks with a given status or
+// assignee. Empty fields do not filter.
+type TaskEventFilter struct {
+	Status     string `json:"status,omitempty"`
+	AssignedTo string `json:"assignedTo,omitempty"`
+}
+
+// Validate performs validation on the filter
+func (f *TaskEventFilter) Validate() error {
+	if f.Status != "" && !(&Task{Status: f.Status}).IsValidStatus() {
+		return errors.NewValidationError("status", constants.ValidationInvalidStatus)
+	}
+	return nil
+}
+
+// Matches reports whether event concerns a task the filter selects, either
+// before or after the change, so subscribers also learn when a task leaves
+// the set they are watching
+func (f *TaskEventFilter) Matches(event TaskEvent) bool {
+	if f.matchesTask(event.Task) {
+		return true
+	}
+	return event.Previous != nil && f.matchesTask(*event.Previous)
+}
+
+func (f *TaskEventFilter) matchesTask(task Task) bool {
+	if f.Status != "" && task.Status != f.Status {
+		return false
+	}
+	if f.AssignedTo != "" && task.AssignedTo != f.AssignedTo {
+		return false
+	}
+	return true
+}
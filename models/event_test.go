@@ -0,0 +1,42 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+)
+
+func TestTaskEventFilter(t *testing.T) {
+	pending := models.Task{ID: "1", Status: constants.StatusPending, AssignedTo: "alice"}
+	started := models.Task{ID: "1", Status: constants.StatusInProgress, AssignedTo: "alice"}
+	created := models.TaskEvent{Type: models.EventTaskCreated, Task: pending}
+	moved := models.TaskEvent{Type: models.EventTaskUpdated, Task: started, Previous: &pending}
+
+	tests := []struct {
+		name   string
+		filter models.TaskEventFilter
+		event  models.TaskEvent
+		want   bool
+	}{
+		{"No filter", models.TaskEventFilter{}, created, true},
+		{"Status matches", models.TaskEventFilter{Status: constants.StatusPending}, created, true},
+		{"Status differs", models.TaskEventFilter{Status: constants.StatusInProgress}, created, false},
+		{"Assignee differs", models.TaskEventFilter{AssignedTo: "bob"}, created, false},
+		{"Task enters the set", models.TaskEventFilter{Status: constants.StatusInProgress}, moved, true},
+		{"Task leaves the set", models.TaskEventFilter{Status: constants.StatusPending}, moved, true},
+		{"Neither side matches", models.TaskEventFilter{Status: constants.StatusCompleted}, moved, false},
+		{"Both fields must match", models.TaskEventFilter{Status: constants.StatusPending, AssignedTo: "bob"}, moved, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	bad := models.TaskEventFilter{Status: "Sleeping"}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() with unknown status expected an error")
+	}
+}
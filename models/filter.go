@@ -0,0 +1,151 @@
+package models
+
+import (
+	stderrors "errors"
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/filter"
+	"time"
+)
+
+// Fields of the task filter language
+const (
+	FilterStatus     = "status"
+	FilterPriority   = "priority"
+	FilterAssignedTo = "assignedTo"
+	FilterProjectID  = "projectId"
+	FilterParentID   = "parentId"
+	FilterLabel      = "label"
+	FilterDue        = "due"
+	FilterCreated    = "created"
+	FilterUpdated    = "updated"
+)
+
+var taskFilterSchema = filter.NewSchema(
+	filter.Field{Name: FilterStatus, Kind: filter.Enum, Values: []string{
+		constants.StatusPending, constants.StatusInProgress, constants.StatusCompleted, constants.StatusCancelled,
+	}},
+	filter.Field{Name: FilterPriority, Kind: filter.Enum, Ordered: true, Values: []string{
+		constants.PriorityLow, constants.PriorityMedium, constants.PriorityHigh,
+	}},
+	filter.Field{Name: FilterAssignedTo, Aliases: []string{"assignee"}, Kind: filter.String},
+	filter.Field{Name: FilterProjectID, Aliases: []string{"project"}, Kind: filter.String},
+	filter.Field{Name: FilterParentID, Aliases: []string{"parent"}, Kind: filter.String},
+	filter.Field{Name: FilterLabel, Aliases: []string{"labels"}, Kind: filter.Set},
+	filter.Field{Name: FilterDue, Aliases: []string{"dueDate"}, Kind: filter.Time},
+	filter.Field{Name: FilterCreated, Aliases: []string{"createdAt"}, Kind: filter.Time},
+	filter.Field{Name: FilterUpdated, Aliases: []string{"updatedAt"}, Kind: filter.Time},
+)
+
+// FilterMe stands for the caller in assignedTo comparisons, so
+// `assignedTo:@me` lists the caller's own tasks. See TaskFilter.ForUser.
+const FilterMe = "@me"
+
+// taskStringFields and taskTimeFields read the filterable fields of a task
+var (
+	taskStringFields = map[string]func(Task) []string{
+		FilterStatus:     func(t Task) []string { return []string{t.Status} },
+		FilterPriority:   func(t Task) []string { return []string{t.Priority} },
+		FilterAssignedTo: func(t Task) []string { return []string{t.AssignedTo} },
+		FilterProjectID:  func(t Task) []string { return []string{t.ProjectID} },
+		FilterParentID:   func(t Task) []string { return []string{t.ParentID} },
+		FilterLabel:      func(t Task) []string { return t.Labels },
+	}
+	taskTimeFields = map[string]func(Task) *time.Time{
+		FilterDue:     func(t Task) *time.Time { return t.DueDate },
+		FilterCreated: func(t Task) *time.Time { return &t.CreatedAt },
+		FilterUpdated: func(t Task) *time.Time { return &t.UpdatedAt },
+	}
+)
+
+// TaskFilter is a parsed filter expression such as
+// `status:InProgress AND due<2026-11-01`. See package filter for the syntax.
+type TaskFilter struct {
+	// Source is the expression as written
+	Source string
+	Expr   filter.Expr
+	match  func(Task) bool
+}
+
+// ParseTaskFilter parses a filter expression over task fields and compiles
+// it into a predicate. Errors are validation errors on "filter" carrying
+// the column of the problem.
+func ParseTaskFilter(src string) (*TaskFilter, error) {
+	expr, err := filter.Parse(src, taskFilterSchema)
+	var syntax *filter.SyntaxError
+	if stderrors.As(err, &syntax) {
+		return nil, errors.NewSyntaxError("filter", syntax.Column, syntax.Msg)
+	}
+	if err != nil {
+		return nil, errors.NewValidationError("filter", err.Error())
+	}
+	return &TaskFilter{Source: src, Expr: expr, match: compileTaskFilter(expr)}, nil
+}
+
+// Matches reports whether task satisfies the filter
+func (f *TaskFilter) Matches(task Task) bool {
+	return f.match(task)
+}
+
+// ForUser returns the filter with FilterMe standing for user. Filters that
+// do not mention FilterMe are returned as they are.
+func (f *TaskFilter) ForUser(user string) *TaskFilter {
+	expr, changed := bindMe(f.Expr, user)
+	if !changed {
+		return f
+	}
+	return &TaskFilter{Source: f.Source, Expr: expr, match: compileTaskFilter(expr)}
+}
+
+// bindMe replaces FilterMe in the assignedTo comparisons of e with user
+func bindMe(e filter.Expr, user string) (filter.Expr, bool) {
+	switch e := e.(type) {
+	case filter.And:
+		left, l := bindMe(e.Left, user)
+		right, r := bindMe(e.Right, user)
+		return filter.And{Left: left, Right: right}, l || r
+	case filter.Or:
+		left, l := bindMe(e.Left, user)
+		right, r := bindMe(e.Right, user)
+		return filter.Or{Left: left, Right: right}, l || r
+	case filter.Not:
+		x, changed := bindMe(e.X, user)
+		return filter.Not{X: x}, changed
+	case filter.Comparison:
+		if e.Field == FilterAssignedTo && e.Value == FilterMe {
+			e.Value = user
+			return e, true
+		}
+	}
+	return e, false
+}
+
+// compileTaskFilter turns a parsed expression into a predicate
+func compileTaskFilter(e filter.Expr) func(Task) bool {
+	switch e := e.(type) {
+	case filter.And:
+		left, right := compileTaskFilter(e.Left), compileTaskFilter(e.Right)
+		return func(t Task) bool { return left(t) && right(t) }
+	case filter.Or:
+		left, right := compileTaskFilter(e.Left), compileTaskFilter(e.Right)
+		return func(t Task) bool { return left(t) || right(t) }
+	case filter.Not:
+		x := compileTaskFilter(e.X)
+		return func(t Task) bool { return !x(t) }
+	case filter.Comparison:
+		if e.Op == filter.OpEq {
+			get, value := taskStringFields[e.Field], e.Value
+			return func(t Task) bool { return slices.Contains(get(t), value) }
+		}
+		// A task without the time, such as one with no due date, matches
+		// neither side of a comparison
+		get, bound, before := taskTimeFields[e.Field], e.Time, e.Op == filter.OpLt
+		return func(t Task) bool {
+			v := get(t)
+			return v != nil && v.Before(bound) == before
+		}
+	}
+	// filter.Parse produces no other expressions
+	return func(Task) bool { return false }
+}
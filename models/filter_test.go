@@ -0,0 +1,128 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+func TestParseTaskFilter(t *testing.T) {
+	due := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+	task := models.Task{
+		Status:     constants.StatusInProgress,
+		Priority:   constants.PriorityHigh,
+		AssignedTo: "alice@x",
+		ProjectID:  "apollo",
+		Labels:     []string{"bug", "frontend"},
+		DueDate:    &due,
+		CreatedAt:  time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+	undated := models.Task{
+		Status:    constants.StatusPending,
+		CreatedAt: time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		filter        string
+		want, undated bool
+	}{
+		{"status:InProgress AND priority:High AND due<2026-11-01 AND NOT assignedTo:bob@x", true, false},
+		{"status:pending OR label:bug", true, true},
+		{"label:frontend labels:bug", true, false},
+		{"label!=backend", true, true},
+		{"priority>=Medium", true, false},
+		{"priority<High", false, false},
+		{`priority:High OR assignee:""`, true, true},
+		{"project:apollo", true, false},
+		{"parentId:x", false, false},
+		{"due:2026-10-31", true, false},
+		{"dueDate!=2026-10-31", false, true},
+		{"NOT due<2026-11-01", false, true},
+		{"created:2026-09-01 updated>=2026-09-15", true, false},
+		{"createdAt<2026-09-01T12:00:00Z", false, false},
+		{"(status:Pending OR status:InProgress) AND NOT (label:bug AND priority:Low)", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := models.ParseTaskFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseTaskFilter() unexpected error: %v", err)
+			}
+			if f.Source != tt.filter {
+				t.Errorf("Source = %q, want %q", f.Source, tt.filter)
+			}
+			if got := f.Matches(task); got != tt.want {
+				t.Errorf("Matches(task) = %v, want %v", got, tt.want)
+			}
+			if got := f.Matches(undated); got != tt.undated {
+				t.Errorf("Matches(undated task) = %v, want %v", got, tt.undated)
+			}
+		})
+	}
+}
+
+func TestParseTaskFilter_Errors(t *testing.T) {
+	tests := []struct {
+		filter string
+		column int
+		want   string
+	}{
+		{"status:Started", 8, `filter: invalid status "Started"; expected one of Pending, InProgress, Completed, Cancelled at column 8`},
+		{"status:Pending AND title:x", 20, `filter: unknown field "title" at column 20`},
+		{"due<soon", 5, `filter: invalid time "soon"; use YYYY-MM-DD or RFC 3339 at column 5`},
+	}
+	for _, tt := range tests {
+		_, err := models.ParseTaskFilter(tt.filter)
+		verr, ok := err.(*errors.ValidationError)
+		if !ok {
+			t.Fatalf("ParseTaskFilter(%q) error = %v, want a validation error", tt.filter, err)
+		}
+		if verr.Field != "filter" || verr.Column != tt.column || verr.Error() != tt.want {
+			t.Errorf("ParseTaskFilter(%q) error = %q (column %d), want %q (column %d)", tt.filter, verr.Error(), verr.Column, tt.want, tt.column)
+		}
+	}
+}
+
+func TestTaskQuery_MatchesFilter(t *testing.T) {
+	f, err := models.ParseTaskFilter("label:bug")
+	if err != nil {
+		t.Fatalf("ParseTaskFilter() unexpected error: %v", err)
+	}
+	q := models.TaskQuery{Status: constants.StatusPending, Filter: f}
+	if !q.Matches(models.Task{Status: constants.StatusPending, Labels: []string{"bug"}}) {
+		t.Error("Matches() = false for a task matching the filter and the other fields")
+	}
+	if q.Matches(models.Task{Status: constants.StatusPending}) {
+		t.Error("Matches() = true for a task not matching the filter")
+	}
+	if q.Matches(models.Task{Status: constants.StatusCompleted, Labels: []string{"bug"}}) {
+		t.Error("Matches() = true for a task matching only the filter")
+	}
+}
+
+func TestTaskFilter_ForUser(t *testing.T) {
+	f, err := models.ParseTaskFilter("assignedTo:@me OR NOT assignee:@me AND priority:High")
+	if err != nil {
+		t.Fatalf("ParseTaskFilter() unexpected error: %v", err)
+	}
+	bound := f.ForUser("alice")
+	if want := `(assignedTo="alice" OR (NOT assignedTo="alice" AND priority="High"))`; bound.Expr.String() != want || bound.Source != f.Source {
+		t.Errorf("ForUser() = %s, want %s", bound.Expr, want)
+	}
+	alice, me := models.Task{AssignedTo: "alice"}, models.Task{AssignedTo: models.FilterMe}
+	if !bound.Matches(alice) || bound.Matches(me) {
+		t.Error("ForUser() result does not match tasks by the user's name")
+	}
+	if f.Matches(alice) || !f.Matches(me) {
+		t.Error("ForUser() changed the original filter")
+	}
+
+	plain, _ := models.ParseTaskFilter("assignedTo:bob")
+	if plain.ForUser("alice") != plain {
+		t.Error("ForUser() copied a filter that does not mention @me")
+	}
+}
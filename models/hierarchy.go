@@ -0,0 +1,60 @@
+package models
+
+import (
+	"math"
+	"taskmanager/constants"
+)
+
+// Progress summarizes how far along a task's subtasks are. Cancelled subtasks
+// are left out. Percent rolls up the whole subtree: each subtask counts as
+// 100 when completed and as its own Percent otherwise.
+type Progress struct {
+	Subtasks  int     `json:"subtasks" example:"4"`
+	Completed int     `json:"completed" example:"1"`
+	Percent   float64 `json:"percent" example:"37.5"`
+}
+
+// TaskTree is a task together with all of its descendants
+type TaskTree struct {
+	Task
+	Children []TaskTree `json:"children"`
+}
+
+// BuildTaskTree assembles the tree rooted at root from a map of parent ID to
+// direct children, filling in Progress on every task that has subtasks
+func BuildTaskTree(root Task, children map[string][]Task) TaskTree {
+	tree := TaskTree{Task: root, Children: []TaskTree{}}
+	tree.Progress = nil
+
+	var total float64
+	for _, child := range children[root.ID] {
+		subtree := BuildTaskTree(child, children)
+		tree.Children = append(tree.Children, subtree)
+		if child.Status == constants.StatusCancelled {
+			continue
+		}
+		if tree.Progress == nil {
+			tree.Progress = &Progress{}
+		}
+		tree.Progress.Subtasks++
+		if child.Status == constants.StatusCompleted {
+			tree.Progress.Completed++
+		}
+		total += subtree.completion()
+	}
+	if tree.Progress != nil {
+		tree.Progress.Percent = math.Round(total/float64(tree.Progress.Subtasks)*10) / 10
+	}
+	return tree
+}
+
+// completion is the share of the task that is done, from 0 to 100
+func (t TaskTree) completion() float64 {
+	if t.Status == constants.StatusCompleted {
+		return 100
+	}
+	if t.Progress != nil {
+		return t.Progress.Percent
+	}
+	return 0
+}
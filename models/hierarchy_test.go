@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+)
+
+func TestBuildTaskTree(t *testing.T) {
+	task := func(id, parent, status string) models.Task {
+		return models.Task{ID: id, ParentID: parent, Title: id, Status: status}
+	}
+	// root
+	// ├── a (Completed)
+	// ├── b (InProgress)
+	// │   ├── b1 (Completed)
+	// │   └── b2 (Pending)
+	// ├── c (Pending)
+	// └── d (Cancelled)
+	children := map[string][]models.Task{
+		"root": {
+			task("a", "root", constants.StatusCompleted),
+			task("b", "root", constants.StatusInProgress),
+			task("c", "root", constants.StatusPending),
+			task("d", "root", constants.StatusCancelled),
+		},
+		"b": {
+			task("b1", "b", constants.StatusCompleted),
+			task("b2", "b", constants.StatusPending),
+		},
+	}
+	tree := models.BuildTaskTree(task("root", "", constants.StatusInProgress), children)
+
+	if len(tree.Children) != 4 {
+		t.Fatalf("BuildTaskTree() root has %d children, want 4", len(tree.Children))
+	}
+	// a counts 100, b counts its own 50, c counts 0 and d is left out
+	want := &models.Progress{Subtasks: 3, Completed: 1, Percent: 50}
+	if *tree.Progress != *want {
+		t.Errorf("root progress = %+v, want %+v", *tree.Progress, *want)
+	}
+	b := tree.Children[1]
+	if b.Progress == nil || *b.Progress != (models.Progress{Subtasks: 2, Completed: 1, Percent: 50}) {
+		t.Errorf("b progress = %+v, want 1 of 2 at 50%%", b.Progress)
+	}
+	if leaf := tree.Children[0]; leaf.Progress != nil || leaf.Children == nil {
+		t.Errorf("leaf = %+v, want no progress and empty children", leaf)
+	}
+
+	// Percentages are rounded to one decimal place
+	thirds := models.BuildTaskTree(task("p", "", constants.StatusPending), map[string][]models.Task{
+		"p": {task("x", "p", constants.StatusCompleted), task("y", "p", constants.StatusPending), task("z", "p", constants.StatusPending)},
+	})
+	if thirds.Progress.Percent != 33.3 {
+		t.Errorf("progress percent = %v, want 33.3", thirds.Progress.Percent)
+	}
+}
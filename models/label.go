@@ -0,0 +1,79 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"taskmanager/constants"
+	"taskmanager/errors"
+)
+
+// MaxLabels limits how many labels a task may carry
+const MaxLabels = 20
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,49}$`)
+
+// IsValidLabel reports whether label can be attached to a task: 1-50
+// lowercase letters, digits, '-', '_', '.' or ':', starting with a letter
+// or digit
+func IsValidLabel(label string) bool {
+	return labelPattern.MatchString(label)
+}
+
+// LabelCount is how many live tasks carry a label
+type LabelCount struct {
+	Label string `json:"label" example:"frontend"`
+	Count int    `json:"count" example:"4"`
+}
+
+// CountLabels tallies the labels of tasks, ordered by label
+func CountLabels(tasks []Task) []LabelCount {
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		for _, label := range task.Labels {
+			counts[label]++
+		}
+	}
+	result := make([]LabelCount, 0, len(counts))
+	for label, n := range counts {
+		result = append(result, LabelCount{Label: label, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Label < result[j].Label })
+	return result
+}
+
+// SortLabels returns a sorted copy of labels, or nil if there are none, so
+// a task's label set is stored in one canonical order
+func SortLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(labels)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// ReplaceLabels swaps every label in from for to, keeping the result sorted
+// and free of duplicates
+func ReplaceLabels(labels, from []string, to string) []string {
+	result := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if slices.Contains(from, label) {
+			label = to
+		}
+		result = append(result, label)
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+func validateLabels(field string, labels []string) error {
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		if !IsValidLabel(label) || seen[label] {
+			return errors.NewValidationError(field, constants.ValidationInvalidLabels)
+		}
+		seen[label] = true
+	}
+	return nil
+}
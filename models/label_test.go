@@ -0,0 +1,47 @@
+package models_test
+
+import (
+	"slices"
+	"strings"
+	"taskmanager/models"
+	"testing"
+)
+
+func TestIsValidLabel(t *testing.T) {
+	valid := []string{"bug", "q3", "team:web", "v1.2", "needs_review", "wont-fix", strings.Repeat("a", 50)}
+	for _, label := range valid {
+		if !models.IsValidLabel(label) {
+			t.Errorf("IsValidLabel(%q) = false, want true", label)
+		}
+	}
+	invalid := []string{"", "Bug", "needs review", "-bug", ":web", strings.Repeat("a", 51)}
+	for _, label := range invalid {
+		if models.IsValidLabel(label) {
+			t.Errorf("IsValidLabel(%q) = true, want false", label)
+		}
+	}
+}
+
+func TestReplaceLabels(t *testing.T) {
+	got := models.ReplaceLabels([]string{"bug", "defect", "frontend"}, []string{"bug", "defect"}, "issue")
+	if want := []string{"frontend", "issue"}; !slices.Equal(got, want) {
+		t.Errorf("ReplaceLabels() = %v, want %v", got, want)
+	}
+	got = models.ReplaceLabels([]string{"bug", "issue"}, []string{"bug"}, "issue")
+	if want := []string{"issue"}; !slices.Equal(got, want) {
+		t.Errorf("ReplaceLabels() into an existing label = %v, want %v", got, want)
+	}
+}
+
+func TestCountLabels(t *testing.T) {
+	tasks := []models.Task{
+		{Labels: []string{"frontend", "bug"}},
+		{Labels: []string{"bug"}},
+		{},
+	}
+	got := models.CountLabels(tasks)
+	want := []models.LabelCount{{Label: "bug", Count: 2}, {Label: "frontend", Count: 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("CountLabels() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Permissions a role can grant. The ":own" variants only cover tasks
+// assigned to the caller.
+const (
+	PermReadTasks        = "tasks:read"
+	PermCreateTasks      = "tasks:create"
+	PermEditOwnTasks     = "tasks:edit:own"
+	PermEditTasks        = "tasks:edit"
+	PermDeleteOwnTasks   = "tasks:delete:own"
+	PermDeleteTasks      = "tasks:delete"
+	PermReadAudit        = "audit:read"
+	PermManageWebhooks   = "webhooks:manage"
+	PermManageProjects   = "projects:manage"
+	PermWriteComments    = "comments:write"
+	PermModerateComments = "comments:moderate"
+	PermWriteAttachments = "attachments:write"
+	PermManageViews      = "views:manage"
+	PermAllWorkspaces    = "workspaces:all"
+)
+
+// Permissions lists every permission a policy can grant
+var Permissions = []string{
+	PermReadTasks, PermCreateTasks, PermEditOwnTasks, PermEditTasks,
+	PermDeleteOwnTasks, PermDeleteTasks, PermReadAudit, PermManageWebhooks,
+	PermManageProjects, PermWriteComments, PermModerateComments, PermWriteAttachments,
+	PermManageViews, PermAllWorkspaces,
+}
+
+// Built-in roles
+const (
+	RoleViewer = "viewer"
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+// RolePolicy maps each role to the permissions it grants. A principal holds
+// the union of the permissions of all its roles; unknown roles grant nothing.
+type RolePolicy map[string][]string
+
+// DefaultRolePolicy lets viewers read, members also create tasks, edit the
+// ones assigned to them, comment and attach files, and admins do anything
+func DefaultRolePolicy() RolePolicy {
+	return RolePolicy{
+		RoleViewer: {PermReadTasks, PermReadAudit},
+		RoleMember: {PermReadTasks, PermReadAudit, PermCreateTasks, PermEditOwnTasks, PermWriteComments, PermWriteAttachments},
+		RoleAdmin:  slices.Clone(Permissions),
+	}
+}
+
+// Grants reports whether any of roles grants perm
+func (p RolePolicy) Grants(roles []string, perm string) bool {
+	for _, role := range roles {
+		if slices.Contains(p[role], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the policy only grants known permissions
+func (p RolePolicy) Validate() error {
+	for role, perms := range p {
+		for _, perm := range perms {
+			if !slices.Contains(Permissions, perm) {
+				return fmt.Errorf("role policy: unknown permission %q for role %q", perm, role)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package models_test
+
+import (
+	"taskmanager/models"
+	"testing"
+)
+
+func TestRolePolicy_Grants(t *testing.T) {
+	policy := models.DefaultRolePolicy()
+
+	tests := []struct {
+		name     string
+		roles    []string
+		perm     string
+		expected bool
+	}{
+		{"Viewer reads", []string{models.RoleViewer}, models.PermReadTasks, true},
+		{"Viewer cannot create", []string{models.RoleViewer}, models.PermCreateTasks, false},
+		{"Member edits own tasks", []string{models.RoleMember}, models.PermEditOwnTasks, true},
+		{"Member cannot edit others' tasks", []string{models.RoleMember}, models.PermEditTasks, false},
+		{"Admin deletes anything", []string{models.RoleAdmin}, models.PermDeleteTasks, true},
+		{"Member comments", []string{models.RoleMember}, models.PermWriteComments, true},
+		{"Member cannot moderate comments", []string{models.RoleMember}, models.PermModerateComments, false},
+		{"Member cannot manage views", []string{models.RoleMember}, models.PermManageViews, false},
+		{"Admin manages views", []string{models.RoleAdmin}, models.PermManageViews, true},
+		{"Member stays in one workspace", []string{models.RoleMember}, models.PermAllWorkspaces, false},
+		{"Roles combine", []string{"unknown", models.RoleAdmin}, models.PermManageWebhooks, true},
+		{"No roles", nil, models.PermReadTasks, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Grants(tt.roles, tt.perm); got != tt.expected {
+				t.Errorf("Grants(%v, %v) = %v, want %v", tt.roles, tt.perm, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRolePolicy_Validate(t *testing.T) {
+	if err := models.DefaultRolePolicy().Validate(); err != nil {
+		t.Errorf("DefaultRolePolicy().Validate() unexpected error: %v", err)
+	}
+	if err := (models.RolePolicy{"viewer": {"tasks:read", "tasks:sudo"}}).Validate(); err == nil {
+		t.Error("Validate() with an unknown permission expected an error")
+	}
+}
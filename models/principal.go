@@ -0,0 +1,17 @@
+package models
+
+// How a principal was authenticated
+const (
+	AuthMethodAPIKey = "apiKey"
+	AuthMethodToken  = "token"
+)
+
+// Principal is the authenticated caller of a request. A principal with a
+// Workspace may only work in that workspace. One without works in the
+// default workspace unless it has PermAllWorkspaces, which lets it pick any.
+type Principal struct {
+	Subject   string   `json:"subject"`
+	Roles     []string `json:"roles,omitempty"`
+	Workspace string   `json:"workspace,omitempty"`
+	Method    string   `json:"method"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// MaxProjectNameLength limits how long a project name may be
+const MaxProjectNameLength = 100
+
+// Project groups related tasks within a workspace
+type Project struct {
+	ID          string    `json:"id" example:"9b2f6c1e-3d4a-4f5b-8c7d-0e1f2a3b4c5d"`
+	Workspace   string    `json:"workspace,omitempty" example:"default"`
+	Name        string    `json:"name" binding:"required" example:"Website relaunch"`
+	Description string    `json:"description,omitempty" example:"Everything needed to ship the new site"`
+	CreatedAt   time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+}
+
+// Validate performs validation on the project
+func (p *Project) Validate() error {
+	if p.Name == "" {
+		return errors.NewValidationError("name", constants.ValidationProjectNameRequired)
+	}
+	if len(p.Name) > MaxProjectNameLength {
+		return errors.NewValidationError("name", constants.ValidationProjectNameTooLong)
+	}
+	return nil
+}
+
+// ProjectStats summarizes the live tasks of a project. ByStatus has an
+// entry for every status, including those with no tasks.
+type ProjectStats struct {
+	ProjectID string         `json:"projectId" example:"9b2f6c1e-3d4a-4f5b-8c7d-0e1f2a3b4c5d"`
+	Total     int            `json:"total" example:"12"`
+	ByStatus  map[string]int `json:"byStatus"`
+	Overdue   int            `json:"overdue" example:"2"`
+}
+
+// NewProjectStats counts tasks by status, and counts the open ones whose
+// due date has passed at now as overdue
+func NewProjectStats(projectID string, tasks []Task, now time.Time) ProjectStats {
+	stats := ProjectStats{
+		ProjectID: projectID,
+		ByStatus: map[string]int{
+			constants.StatusPending:    0,
+			constants.StatusInProgress: 0,
+			constants.StatusCompleted:  0,
+			constants.StatusCancelled:  0,
+		},
+	}
+	for _, task := range tasks {
+		stats.Total++
+		stats.ByStatus[task.Status]++
+		if task.IsOpen() && task.DueDate != nil && !now.Before(*task.DueDate) {
+			stats.Overdue++
+		}
+	}
+	return stats
+}
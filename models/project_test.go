@@ -0,0 +1,64 @@
+package models_test
+
+import (
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+func TestProject_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		project   models.Project
+		wantField string
+	}{
+		{"Valid", models.Project{Name: "Website relaunch"}, ""},
+		{"Missing name", models.Project{Description: "no name"}, "name"},
+		{"Name too long", models.Project{Name: strings.Repeat("x", models.MaxProjectNameLength+1)}, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.project.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if vErr, ok := err.(*errors.ValidationError); !ok || vErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want validation error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestNewProjectStats(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tasks := []models.Task{
+		{Status: constants.StatusPending, DueDate: &past},
+		{Status: constants.StatusInProgress, DueDate: &now},
+		{Status: constants.StatusInProgress, DueDate: &future},
+		{Status: constants.StatusCompleted, DueDate: &past},
+		{Status: constants.StatusPending},
+	}
+
+	stats := models.NewProjectStats("p1", tasks, now)
+	if stats.ProjectID != "p1" || stats.Total != 5 || stats.Overdue != 2 {
+		t.Errorf("NewProjectStats() = %+v, want 5 tasks with 2 overdue", stats)
+	}
+	want := map[string]int{
+		constants.StatusPending:    2,
+		constants.StatusInProgress: 2,
+		constants.StatusCompleted:  1,
+		constants.StatusCancelled:  0,
+	}
+	for status, n := range want {
+		if got, ok := stats.ByStatus[status]; !ok || got != n {
+			t.Errorf("ByStatus[%s] = %d, want %d", status, got, n)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// Sortable task fields, named after their JSON keys
+const (
+	SortByID         = "id"
+	SortByTitle      = "title"
+	SortByStatus     = "status"
+	SortByPriority   = "priority"
+	SortByDueDate    = "dueDate"
+	SortByCreatedAt  = "createdAt"
+	SortByUpdatedAt  = "updatedAt"
+	SortByAssignedTo = "assignedTo"
+	SortByDeletedAt  = "deletedAt"
+)
+
+// Pagination limits for task queries
+const (
+	DefaultQueryLimit = 50
+	MaxQueryLimit     = 200
+)
+
+// TaskQuery describes a filtered, sorted and paginated task listing.
+// Empty fields do not filter. Range lower bounds are inclusive and upper
+// bounds exclusive. A task must carry at least one of LabelsAny, every one
+// of LabelsAll and none of LabelsNone. Filter, when set, must match as
+// well. Deleted selects tasks in the trash instead of live ones.
+type TaskQuery struct {
+	Status        string
+	Priority      string
+	AssignedTo    string
+	ProjectID     string
+	LabelsAny     []string
+	LabelsAll     []string
+	LabelsNone    []string
+	Filter        *TaskFilter
+	ParentID      string
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Deleted       bool
+	DeletedBefore *time.Time
+	SortBy        string
+	SortDesc      bool
+	Cursor        string
+	Limit         int
+}
+
+// TaskPage is one page of a TaskQuery result. NextCursor is empty on the last page.
+type TaskPage struct {
+	Tasks      []Task `json:"data"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// IsValidSortField checks if field can be used to sort tasks
+func IsValidSortField(field string) bool {
+	switch field {
+	case SortByID, SortByTitle, SortByStatus, SortByPriority, SortByDueDate,
+		SortByCreatedAt, SortByUpdatedAt, SortByAssignedTo, SortByDeletedAt:
+		return true
+	default:
+		return false
+	}
+}
+
+// Normalize fills in the default sort order and page size
+func (q *TaskQuery) Normalize() {
+	if q.SortBy == "" {
+		q.SortBy = SortByCreatedAt
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultQueryLimit
+	}
+}
+
+// Validate performs validation on the query
+func (q *TaskQuery) Validate() error {
+	if q.Status != "" && !(&Task{Status: q.Status}).IsValidStatus() {
+		return errors.NewValidationError("status", constants.ValidationInvalidStatus)
+	}
+	if !(&Task{Priority: q.Priority}).IsValidPriority() {
+		return errors.NewValidationError("priority", constants.ValidationInvalidPriority)
+	}
+	labelFilters := []struct {
+		field  string
+		labels []string
+	}{
+		{"labelsAny", q.LabelsAny},
+		{"labelsAll", q.LabelsAll},
+		{"labelsNone", q.LabelsNone},
+	}
+	for _, f := range labelFilters {
+		if err := validateLabels(f.field, f.labels); err != nil {
+			return err
+		}
+	}
+	if q.SortBy != "" && !IsValidSortField(q.SortBy) {
+		return errors.NewValidationError("sort", constants.ValidationInvalidSortField)
+	}
+	if q.Limit < 0 || q.Limit > MaxQueryLimit {
+		return errors.NewValidationError("limit", constants.ValidationInvalidLimit)
+	}
+	return nil
+}
+
+// Matches reports whether task satisfies the query's filters
+func (q *TaskQuery) Matches(task Task) bool {
+	if task.IsDeleted() != q.Deleted {
+		return false
+	}
+	if q.DeletedBefore != nil && (task.DeletedAt == nil || !task.DeletedAt.Before(*q.DeletedBefore)) {
+		return false
+	}
+	if q.Status != "" && task.Status != q.Status {
+		return false
+	}
+	if q.Priority != "" && task.Priority != q.Priority {
+		return false
+	}
+	if q.AssignedTo != "" && task.AssignedTo != q.AssignedTo {
+		return false
+	}
+	if q.ProjectID != "" && task.ProjectID != q.ProjectID {
+		return false
+	}
+	if q.ParentID != "" && task.ParentID != q.ParentID {
+		return false
+	}
+	if len(q.LabelsAny) > 0 && !slices.ContainsFunc(q.LabelsAny, func(l string) bool { return slices.Contains(task.Labels, l) }) {
+		return false
+	}
+	for _, label := range q.LabelsAll {
+		if !slices.Contains(task.Labels, label) {
+			return false
+		}
+	}
+	for _, label := range q.LabelsNone {
+		if slices.Contains(task.Labels, label) {
+			return false
+		}
+	}
+	if q.DueAfter != nil || q.DueBefore != nil {
+		if task.DueDate == nil || !inRange(*task.DueDate, q.DueAfter, q.DueBefore) {
+			return false
+		}
+	}
+	if !inRange(task.CreatedAt, q.CreatedAfter, q.CreatedBefore) {
+		return false
+	}
+	if !inRange(task.UpdatedAt, q.UpdatedAfter, q.UpdatedBefore) {
+		return false
+	}
+	if q.Filter != nil && !q.Filter.Matches(task) {
+		return false
+	}
+	return true
+}
+
+func inRange(t time.Time, after, before *time.Time) bool {
+	if after != nil && t.Before(*after) {
+		return false
+	}
+	if before != nil && !t.Before(*before) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestTaskQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     models.TaskQuery
+		wantField string
+	}{
+		{"Empty query", models.TaskQuery{}, ""},
+		{"Valid filters", models.TaskQuery{Status: constants.StatusPending, Priority: constants.PriorityHigh, SortBy: models.SortByDueDate, Limit: 10}, ""},
+		{"Invalid status", models.TaskQuery{Status: "InvalidStatus"}, "status"},
+		{"Invalid priority", models.TaskQuery{Priority: "InvalidPriority"}, "priority"},
+		{"Invalid sort field", models.TaskQuery{SortBy: "color"}, "sort"},
+		{"Label filters", models.TaskQuery{LabelsAny: []string{"bug", "q3"}, LabelsAll: []string{"frontend"}, LabelsNone: []string{"wontfix"}}, ""},
+		{"Invalid label", models.TaskQuery{LabelsAll: []string{"Bug"}}, "labelsAll"},
+		{"Duplicate label", models.TaskQuery{LabelsNone: []string{"bug", "bug"}}, "labelsNone"},
+		{"Negative limit", models.TaskQuery{Limit: -1}, "limit"},
+		{"Limit too large", models.TaskQuery{Limit: models.MaxQueryLimit + 1}, "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			verr, ok := err.(*errors.ValidationError)
+			if !ok {
+				t.Fatalf("Validate() expected ValidationError, got %T", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("Validate() field = %v, want %v", verr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestTaskQuery_Normalize(t *testing.T) {
+	query := models.TaskQuery{}
+	query.Normalize()
+	if query.SortBy != models.SortByCreatedAt {
+		t.Errorf("Normalize() sortBy = %v, want %v", query.SortBy, models.SortByCreatedAt)
+	}
+	if query.Limit != models.DefaultQueryLimit {
+		t.Errorf("Normalize() limit = %v, want %v", query.Limit, models.DefaultQueryLimit)
+	}
+}
+
+func TestTaskQuery_Matches(t *testing.T) {
+	due := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := due.Add(-time.Hour)
+	after := due.Add(time.Hour)
+
+	task := testutils.CreateTestTask()
+	task.DueDate = &due
+	undated := testutils.CreateTestTask()
+	undated.DueDate = nil
+	labelled := testutils.CreateTestTask()
+	labelled.Labels = []string{"bug", "frontend"}
+
+	tests := []struct {
+		name     string
+		query    models.TaskQuery
+		task     models.Task
+		expected bool
+	}{
+		{"Empty query matches", models.TaskQuery{}, task, true},
+		{"Status mismatch", models.TaskQuery{Status: constants.StatusCompleted}, task, false},
+		{"Assignee match", models.TaskQuery{AssignedTo: task.AssignedTo}, task, true},
+		{"Due range inclusive lower bound", models.TaskQuery{DueAfter: &due}, task, true},
+		{"Due range exclusive upper bound", models.TaskQuery{DueBefore: &due}, task, false},
+		{"Due range inside", models.TaskQuery{DueAfter: &before, DueBefore: &after}, task, true},
+		{"Due range excludes undated", models.TaskQuery{DueAfter: &before}, undated, false},
+		{"Any label", models.TaskQuery{LabelsAny: []string{"q3", "bug"}}, labelled, true},
+		{"None of any labels", models.TaskQuery{LabelsAny: []string{"q3", "backend"}}, labelled, false},
+		{"All labels", models.TaskQuery{LabelsAll: []string{"bug", "frontend"}}, labelled, true},
+		{"Missing one of all labels", models.TaskQuery{LabelsAll: []string{"bug", "q3"}}, labelled, false},
+		{"Excluded label", models.TaskQuery{LabelsNone: []string{"frontend"}}, labelled, false},
+		{"Unlabelled task has no excluded label", models.TaskQuery{LabelsNone: []string{"bug"}}, task, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Matches(tt.task); got != tt.expected {
+				t.Errorf("Matches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/recurrence"
+	"time"
+)
+
+// Limits for previewing the occurrences of a recurring task
+const (
+	DefaultOccurrenceCount = 5
+	MaxOccurrenceCount     = 100
+)
+
+// IsRecurring reports whether the task repeats on a schedule
+func (t *Task) IsRecurring() bool {
+	return t.RRule != "" && t.DueDate != nil
+}
+
+// UpcomingOccurrences returns the due dates of up to n occurrences that
+// follow this one in the task's series. The result is empty for tasks that
+// do not recur or whose series ends with this occurrence.
+func (t *Task) UpcomingOccurrences(n int) []time.Time {
+	if !t.IsRecurring() || n <= 0 {
+		return []time.Time{}
+	}
+	rule, err := recurrence.Parse(t.RRule)
+	if err != nil {
+		return []time.Time{}
+	}
+	return rule.Occurrences(*t.DueDate, n+1)[1:]
+}
+
+// NextOccurrence builds the task for the occurrence after this one: a
+// pending copy due on the next date of the series. The rule's COUNT is
+// reduced by one so the series still ends after the same number of
+// occurrences. It returns false when the series ends with this task.
+func (t *Task) NextOccurrence() (Task, bool) {
+	if !t.IsRecurring() {
+		return Task{}, false
+	}
+	rule, err := recurrence.Parse(t.RRule)
+	if err != nil {
+		return Task{}, false
+	}
+	due, ok := rule.Next(*t.DueDate)
+	if !ok {
+		return Task{}, false
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+	return Task{
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      constants.StatusPending,
+		Priority:    t.Priority,
+		DueDate:     &due,
+		AssignedTo:  t.AssignedTo,
+		ProjectID:   t.ProjectID,
+		Labels:      slices.Clone(t.Labels),
+		ParentID:    t.ParentID,
+		RRule:       rule.String(),
+	}, true
+}
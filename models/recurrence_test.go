@@ -0,0 +1,51 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+func TestTask_NextOccurrence(t *testing.T) {
+	due := time.Date(2026, 1, 31, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		rrule    string
+		wantDue  time.Time
+		wantRule string
+		wantOK   bool
+	}{
+		{"Not recurring", "", time.Time{}, "", false},
+		{"Monthly skips short months", "FREQ=MONTHLY", time.Date(2026, 3, 31, 17, 0, 0, 0, time.UTC), "FREQ=MONTHLY", true},
+		{"Count is reduced", "FREQ=DAILY;COUNT=3", due.AddDate(0, 0, 1), "FREQ=DAILY;COUNT=2", true},
+		{"Last of the count", "FREQ=DAILY;COUNT=1", time.Time{}, "", false},
+		{"Past until", "FREQ=WEEKLY;UNTIL=20260205", time.Time{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := models.Task{
+				Title:      "Pay rent",
+				Status:     constants.StatusCompleted,
+				Priority:   constants.PriorityHigh,
+				AssignedTo: "alice@example.com",
+				DueDate:    &due,
+				RRule:      tt.rrule,
+			}
+			next, ok := task.NextOccurrence()
+			if ok != tt.wantOK {
+				t.Fatalf("NextOccurrence() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !next.DueDate.Equal(tt.wantDue) || next.RRule != tt.wantRule {
+				t.Errorf("NextOccurrence() due %v rrule %q, want %v %q", next.DueDate, next.RRule, tt.wantDue, tt.wantRule)
+			}
+			if next.Status != constants.StatusPending || next.Title != task.Title || next.AssignedTo != task.AssignedTo {
+				t.Errorf("NextOccurrence() = %+v, want a pending copy", next)
+			}
+		})
+	}
+}
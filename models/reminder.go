@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// Reminder kinds
+const (
+	ReminderDueSoon = "dueSoon"
+	ReminderOverdue = "overdue"
+)
+
+// Reminder is a notification that a task is approaching or past its due date
+type Reminder struct {
+	TaskID  string    `json:"taskId"`
+	Kind    string    `json:"kind"`
+	DueDate time.Time `json:"dueDate"`
+	SentAt  time.Time `json:"sentAt"`
+}
+
+// Key identifies a reminder. It includes the due date, so moving a task's
+// due date makes it eligible for fresh reminders.
+func (r Reminder) Key() string {
+	return r.TaskID + "/" + r.Kind + "/" + r.DueDate.UTC().Format(time.RFC3339)
+}
+
+// ReminderFor returns the reminder a task calls for at now: overdue once its
+// due date has passed, due soon within lead of it. Only open, live tasks
+// with a due date get reminders.
+func ReminderFor(task Task, now time.Time, lead time.Duration) (Reminder, bool) {
+	if task.DueDate == nil || !task.IsOpen() || task.IsDeleted() {
+		return Reminder{}, false
+	}
+	reminder := Reminder{TaskID: task.ID, DueDate: *task.DueDate}
+	switch {
+	case !now.Before(*task.DueDate):
+		reminder.Kind = ReminderOverdue
+	case !now.Before(task.DueDate.Add(-lead)):
+		reminder.Kind = ReminderDueSoon
+	default:
+		return Reminder{}, false
+	}
+	return reminder, true
+}
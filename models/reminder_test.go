@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+func TestReminderFor(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	tests := []struct {
+		name     string
+		task     models.Task
+		wantKind string
+	}{
+		{"No due date", models.Task{Status: constants.StatusPending}, ""},
+		{"Due later", models.Task{Status: constants.StatusPending, DueDate: at(48 * time.Hour)}, ""},
+		{"Due within lead", models.Task{Status: constants.StatusPending, DueDate: at(2 * time.Hour)}, models.ReminderDueSoon},
+		{"Due now", models.Task{Status: constants.StatusInProgress, DueDate: at(0)}, models.ReminderOverdue},
+		{"Past due", models.Task{Status: constants.StatusPending, DueDate: at(-time.Hour)}, models.ReminderOverdue},
+		{"Completed", models.Task{Status: constants.StatusCompleted, DueDate: at(-time.Hour)}, ""},
+		{"In the trash", models.Task{Status: constants.StatusPending, DueDate: at(-time.Hour), DeletedAt: at(0)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.task.ID = "a"
+			reminder, ok := models.ReminderFor(tt.task, now, 24*time.Hour)
+			if ok != (tt.wantKind != "") || reminder.Kind != tt.wantKind {
+				t.Fatalf("ReminderFor() = %+v, %v, want kind %q", reminder, ok, tt.wantKind)
+			}
+			if ok && (reminder.TaskID != "a" || !reminder.DueDate.Equal(*tt.task.DueDate)) {
+				t.Errorf("ReminderFor() = %+v, want task a due %v", reminder, tt.task.DueDate)
+			}
+		})
+	}
+}
+
+func TestReminder_KeyChangesWithDueDate(t *testing.T) {
+	due := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	a := models.Reminder{TaskID: "a", Kind: models.ReminderOverdue, DueDate: due}
+	b := a
+	b.DueDate = due.Add(time.Hour)
+	if a.Key() == b.Key() {
+		t.Errorf("Key() = %q for both due dates, want them to differ", a.Key())
+	}
+	c := a
+	c.DueDate = due.In(time.FixedZone("UTC+2", 2*60*60))
+	if a.Key() != c.Key() {
+		t.Errorf("Key() = %q and %q for the same instant, want them equal", a.Key(), c.Key())
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+// Search result limits
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// TaskSearchResult is a task matching a full-text search, with the parts of
+// it that matched highlighted
+type TaskSearchResult struct {
+	Task Task `json:"task"`
+	// Score ranks the result against the others for the same query; it
+	// has no meaning on its own
+	Score      float64          `json:"score"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// SearchHighlights holds HTML-escaped excerpts with matched words wrapped in
+// <mark> tags
+type SearchHighlights struct {
+	// Title is the whole title
+	Title string `json:"title"`
+	// Description is an excerpt around the first match in the description,
+	// omitted when only the title matched
+	Description string `json:"description,omitempty"`
+}
@@ -1,22 +1,44 @@
 package models
 
 import (
-	"time"
 	"taskmanager/constants"
 	"taskmanager/errors"
+	"taskmanager/recurrence"
+	"time"
 )
 
 // Task represents a task in the system
 type Task struct {
-	ID          string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Title       string    `json:"title" binding:"required" example:"Complete project documentation"`
-	Description string    `json:"description,omitempty" example:"Write comprehensive documentation for the API"`
-	Status      string    `json:"status" binding:"required" example:"Pending"`
-	Priority    string    `json:"priority,omitempty" example:"High"`
+	ID          string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Workspace   string     `json:"workspace,omitempty" example:"default"`
+	Title       string     `json:"title" binding:"required" example:"Complete project documentation"`
+	Description string     `json:"description,omitempty" example:"Write comprehensive documentation for the API"`
+	Status      string     `json:"status" binding:"required" example:"Pending"`
+	Priority    string     `json:"priority,omitempty" example:"High"`
 	DueDate     *time.Time `json:"dueDate,omitempty" example:"2024-12-31T23:59:59Z"`
-	CreatedAt   time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt   time.Time `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
-	AssignedTo  string    `json:"assignedTo,omitempty" example:"john.doe@example.com"`
+	CreatedAt   time.Time  `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time  `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+	AssignedTo  string     `json:"assignedTo,omitempty" example:"john.doe@example.com"`
+	ProjectID   string     `json:"projectId,omitempty" example:"9b2f6c1e-3d4a-4f5b-8c7d-0e1f2a3b4c5d"`
+	Labels      []string   `json:"labels,omitempty" example:"frontend"`
+	ParentID    string     `json:"parentId,omitempty" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+	BlockedBy   []string   `json:"blockedBy,omitempty" example:"6ba7b811-9dad-11d1-80b4-00c04fd430c8"`
+	RRule       string     `json:"rrule,omitempty" example:"FREQ=WEEKLY;BYDAY=MO"`
+	Version     int64      `json:"version" example:"1"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" example:"2024-01-02T00:00:00Z"`
+	// Progress is computed on read for tasks with subtasks and never stored
+	Progress *Progress `json:"progress,omitempty"`
+}
+
+// IsOpen reports whether the task still has work left, i.e. it is neither
+// completed nor cancelled
+func (t *Task) IsOpen() bool {
+	return t.Status != constants.StatusCompleted && t.Status != constants.StatusCancelled
+}
+
+// IsDeleted reports whether the task is in the trash
+func (t *Task) IsDeleted() bool {
+	return t.DeletedAt != nil
 }
 
 // IsValidStatus checks if the status is valid
@@ -56,5 +78,26 @@ func (t *Task) Validate() error {
 	if !t.IsValidPriority() {
 		return errors.NewValidationError("priority", constants.ValidationInvalidPriority)
 	}
+	seen := make(map[string]bool, len(t.BlockedBy))
+	for _, id := range t.BlockedBy {
+		if id == "" || seen[id] {
+			return errors.NewValidationError("blockedBy", constants.ValidationInvalidBlockers)
+		}
+		seen[id] = true
+	}
+	if len(t.Labels) > MaxLabels {
+		return errors.NewValidationError("labels", constants.ValidationInvalidLabels)
+	}
+	if err := validateLabels("labels", t.Labels); err != nil {
+		return err
+	}
+	if t.RRule != "" {
+		if _, err := recurrence.Parse(t.RRule); err != nil {
+			return errors.NewValidationError("rrule", err.Error())
+		}
+		if t.DueDate == nil {
+			return errors.NewValidationError("dueDate", constants.ValidationRRuleNeedsDueDate)
+		}
+	}
 	return nil
 }
@@ -1,9 +1,10 @@
-package models
+package models_test
 
 import (
 	"testing"
 	"taskmanager/constants"
 	"taskmanager/errors"
+	"taskmanager/models"
 	"taskmanager/testutils"
 )
 
@@ -110,6 +111,75 @@ func TestTask_Validate(t *testing.T) {
 			wantError: true,
 			errorType: "ValidationError",
 		},
+		{
+			name: "Duplicate blocker",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.BlockedBy = []string{"a", "a"}
+				return task
+			}(),
+			wantError: true,
+			errorType: "ValidationError",
+		},
+		{
+			name: "Labels",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.Labels = []string{"bug", "frontend", "team:web", "q3"}
+				return task
+			}(),
+			wantError: false,
+		},
+		{
+			name: "Invalid label",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.Labels = []string{"Needs Review"}
+				return task
+			}(),
+			wantError: true,
+			errorType: "ValidationError",
+		},
+		{
+			name: "Duplicate label",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.Labels = []string{"bug", "bug"}
+				return task
+			}(),
+			wantError: true,
+			errorType: "ValidationError",
+		},
+		{
+			name: "Recurring task",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.RRule = "FREQ=WEEKLY;BYDAY=MO"
+				return task
+			}(),
+			wantError: false,
+		},
+		{
+			name: "Invalid recurrence rule",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.RRule = "FREQ=HOURLY"
+				return task
+			}(),
+			wantError: true,
+			errorType: "ValidationError",
+		},
+		{
+			name: "Recurring task without due date",
+			task: func() models.Task {
+				task := testutils.CreateTestTask()
+				task.RRule = "FREQ=DAILY"
+				task.DueDate = nil
+				return task
+			}(),
+			wantError: true,
+			errorType: "ValidationError",
+		},
 	}
 
 	for _, tt := range tests {
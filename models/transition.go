@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"taskmanager/constants"
+)
+
+// TransitionGraph maps each task status to the statuses it may move to.
+// Staying in the same status is always allowed.
+type TransitionGraph map[string][]string
+
+// DefaultTransitionGraph returns the standard task lifecycle: work starts from
+// Pending, finishes from InProgress, Completed is final and Cancelled tasks can
+// only be reopened as Pending.
+func DefaultTransitionGraph() TransitionGraph {
+	return TransitionGraph{
+		constants.StatusPending:    {constants.StatusInProgress, constants.StatusCancelled},
+		constants.StatusInProgress: {constants.StatusPending, constants.StatusCompleted, constants.StatusCancelled},
+		constants.StatusCompleted:  {},
+		constants.StatusCancelled:  {constants.StatusPending},
+	}
+}
+
+// Allows checks if a task may move from one status to another
+func (g TransitionGraph) Allows(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range g[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedFrom lists the statuses reachable in one step from status
+func (g TransitionGraph) AllowedFrom(status string) []string {
+	allowed := make([]string, len(g[status]))
+	copy(allowed, g[status])
+	return allowed
+}
+
+// Validate checks that the graph only refers to known statuses
+func (g TransitionGraph) Validate() error {
+	for from, targets := range g {
+		if !(&Task{Status: from}).IsValidStatus() {
+			return fmt.Errorf("transition graph: unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !(&Task{Status: to}).IsValidStatus() {
+				return fmt.Errorf("transition graph: unknown status %q in transitions from %q", to, from)
+			}
+		}
+	}
+	return nil
+}
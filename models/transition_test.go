@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+)
+
+func TestTransitionGraph_Allows(t *testing.T) {
+	graph := models.DefaultTransitionGraph()
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected bool
+	}{
+		{"Start work", constants.StatusPending, constants.StatusInProgress, true},
+		{"Finish work", constants.StatusInProgress, constants.StatusCompleted, true},
+		{"Reopen cancelled", constants.StatusCancelled, constants.StatusPending, true},
+		{"Same status", constants.StatusCompleted, constants.StatusCompleted, true},
+		{"Reopen completed", constants.StatusCompleted, constants.StatusPending, false},
+		{"Resume cancelled", constants.StatusCancelled, constants.StatusInProgress, false},
+		{"Skip straight to completed", constants.StatusPending, constants.StatusCompleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graph.Allows(tt.from, tt.to); got != tt.expected {
+				t.Errorf("Allows(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransitionGraph_AllowedFrom(t *testing.T) {
+	graph := models.DefaultTransitionGraph()
+	allowed := graph.AllowedFrom(constants.StatusPending)
+	if len(allowed) != 2 {
+		t.Errorf("AllowedFrom(Pending) = %v, want 2 statuses", allowed)
+	}
+
+	// Callers must not be able to mutate the graph through the result
+	allowed[0] = "Mutated"
+	if graph.AllowedFrom(constants.StatusPending)[0] == "Mutated" {
+		t.Errorf("AllowedFrom() returned a slice aliasing the graph")
+	}
+
+	if got := graph.AllowedFrom(constants.StatusCompleted); len(got) != 0 {
+		t.Errorf("AllowedFrom(Completed) = %v, want none", got)
+	}
+}
+
+func TestTransitionGraph_Validate(t *testing.T) {
+	if err := models.DefaultTransitionGraph().Validate(); err != nil {
+		t.Errorf("Validate() on default graph unexpected error: %v", err)
+	}
+	bad := models.TransitionGraph{constants.StatusPending: {"Archived"}}
+	if err := bad.Validate(); err == nil {
+		t.Errorf("Validate() expected error for unknown target status")
+	}
+	bad = models.TransitionGraph{"Archived": {constants.StatusPending}}
+	if err := bad.Validate(); err == nil {
+		t.Errorf("Validate() expected error for unknown source status")
+	}
+}
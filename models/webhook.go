@@ -0,0 +1,63 @@
+package models
+
+import (
+	"net/url"
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// Limits for listing webhook deliveries
+const (
+	DefaultDeliveryLimit = 50
+	MaxDeliveryLimit     = 500
+)
+
+// Webhook is a subscription that receives task events over HTTP. An empty
+// Events list subscribes to every event type.
+type Webhook struct {
+	ID        string    `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Workspace string    `json:"workspace,omitempty" example:"default"`
+	URL       string    `json:"url" binding:"required" example:"https://ci.example.com/hooks/tasks"`
+	Events    []string  `json:"events,omitempty" example:"task.created"`
+	Secret    string    `json:"secret,omitempty" example:"3f1b0c..."`
+	Active    bool      `json:"active" example:"true"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+}
+
+// Subscribes reports whether the webhook wants events of eventType
+func (w *Webhook) Subscribes(eventType string) bool {
+	return w.Active && (len(w.Events) == 0 || slices.Contains(w.Events, eventType))
+}
+
+// Validate performs validation on the webhook
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewValidationError("url", constants.ValidationInvalidWebhookURL)
+	}
+	seen := make(map[string]bool, len(w.Events))
+	for _, event := range w.Events {
+		if !slices.Contains(TaskEventTypes, event) || seen[event] {
+			return errors.NewValidationError("events", constants.ValidationInvalidEvents)
+		}
+		seen[event] = true
+	}
+	return nil
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhookId"`
+	EventID    string    `json:"eventId"`
+	EventType  string    `json:"eventType"`
+	Attempt    int       `json:"attempt"`
+	Success    bool      `json:"success"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Timestamp  time.Time `json:"timestamp"`
+}
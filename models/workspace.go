@@ -0,0 +1,10 @@
+package models
+
+import "regexp"
+
+var workspacePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// IsValidWorkspace reports whether id can name a workspace
+func IsValidWorkspace(id string) bool {
+	return workspacePattern.MatchString(id)
+}
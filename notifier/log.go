@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"context"
+	"log"
+	"taskmanager/models"
+	"time"
+)
+
+// LogNotifier writes reminders to a logger. It is the default when no mail
+// server is configured.
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier logs to logger, or to the standard logger if it is nil
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, reminder models.Reminder, task models.Task) error {
+	subject, _ := Message(reminder, task)
+	assignee := task.AssignedTo
+	if assignee == "" {
+		assignee = "nobody"
+	}
+	n.logger.Printf("reminder: %s (task %s, assigned to %s, due %s)",
+		subject, task.ID, assignee, reminder.DueDate.UTC().Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package notifier delivers due-date reminders for tasks.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"taskmanager/models"
+	"time"
+)
+
+// Notifier sends a reminder about a task to whoever should hear about it
+type Notifier interface {
+	Notify(ctx context.Context, reminder models.Reminder, task models.Task) error
+}
+
+// Message renders the subject and plain-text body of a reminder
+func Message(reminder models.Reminder, task models.Task) (subject, body string) {
+	due := reminder.DueDate.UTC().Format(time.RFC1123)
+	switch reminder.Kind {
+	case models.ReminderOverdue:
+		subject = fmt.Sprintf("Overdue: %s", task.Title)
+	default:
+		subject = fmt.Sprintf("Due soon: %s", task.Title)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", task.Title)
+	if task.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", task.Description)
+	}
+	fmt.Fprintf(&b, "Due:      %s\n", due)
+	fmt.Fprintf(&b, "Status:   %s\n", task.Status)
+	if task.Priority != "" {
+		fmt.Fprintf(&b, "Priority: %s\n", task.Priority)
+	}
+	fmt.Fprintf(&b, "Task ID:  %s\n", task.ID)
+	return subject, b.String()
+}
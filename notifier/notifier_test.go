@@ -0,0 +1,237 @@
+package notifier
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"log"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// delivery is one message accepted by fakeSMTPServer
+type delivery struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTPServer accepts connections on a loopback port and records every
+// message it receives. It speaks just enough SMTP for net/smtp.
+type fakeSMTPServer struct {
+	addr        string
+	requireAuth bool
+	deliveries  chan delivery
+}
+
+func newFakeSMTPServer(t *testing.T, requireAuth bool) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeSMTPServer{addr: ln.Addr().String(), requireAuth: requireAuth, deliveries: make(chan delivery, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake.test ESMTP")
+	var d delivery
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			if s.requireAuth {
+				reply("250-fake.test")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 fake.test")
+			}
+		case "AUTH":
+			fields := strings.Fields(line)
+			creds, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			d.auth = string(creds)
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			if s.requireAuth && d.auth == "" {
+				reply("530 5.7.0 Authentication required")
+				continue
+			}
+			d.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			d.to = append(d.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			d.data = data.String()
+			s.deliveries <- d
+			d = delivery{auth: d.auth}
+			reply("250 OK: queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) next(t *testing.T) delivery {
+	t.Helper()
+	select {
+	case d := <-s.deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message delivered")
+		return delivery{}
+	}
+}
+
+func testReminder() (models.Reminder, models.Task) {
+	due := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	task := models.Task{
+		ID:          "550e8400-e29b-41d4-a716-446655440000",
+		Title:       "Ship the release",
+		Description: "Tag it and publish the notes",
+		Status:      constants.StatusInProgress,
+		Priority:    constants.PriorityHigh,
+		DueDate:     &due,
+		AssignedTo:  "alice@example.com",
+	}
+	return models.Reminder{TaskID: task.ID, Kind: models.ReminderDueSoon, DueDate: due}, task
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	n, err := NewSMTPNotifier(SMTPConfig{Addr: server.addr, From: "Tasks <tasks@example.com>", To: []string{"Team <team@example.com>"}})
+	if err != nil {
+		t.Fatalf("NewSMTPNotifier() unexpected error: %v", err)
+	}
+	reminder, task := testReminder()
+
+	if err := n.Notify(context.Background(), reminder, task); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	d := server.next(t)
+	if d.from != "tasks@example.com" || len(d.to) != 1 || d.to[0] != "alice@example.com" {
+		t.Errorf("envelope = from %q to %v, want tasks@example.com to alice@example.com", d.from, d.to)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(d.data))
+	if err != nil {
+		t.Fatalf("delivered message is not valid: %v", err)
+	}
+	if got := msg.Header.Get("Subject"); got != "Due soon: Ship the release" {
+		t.Errorf("Subject = %q, want %q", got, "Due soon: Ship the release")
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if !bytes.Contains(body, []byte("Tag it and publish the notes")) || !bytes.Contains(body, []byte(task.ID)) {
+		t.Errorf("body = %q, want the description and task ID", body)
+	}
+
+	// Unassigned tasks go to the fallback list
+	task.AssignedTo = ""
+	if err := n.Notify(context.Background(), reminder, task); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if d := server.next(t); len(d.to) != 1 || d.to[0] != "team@example.com" {
+		t.Errorf("recipients = %v, want the fallback team@example.com", d.to)
+	}
+}
+
+func TestSMTPNotifier_Auth(t *testing.T) {
+	server := newFakeSMTPServer(t, true)
+	n, _ := NewSMTPNotifier(SMTPConfig{Addr: server.addr, From: "tasks@example.com", Username: "bot", Password: "secret"})
+	reminder, task := testReminder()
+	reminder.Kind = models.ReminderOverdue
+
+	if err := n.Notify(context.Background(), reminder, task); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	d := server.next(t)
+	if d.auth != "\x00bot\x00secret" {
+		t.Errorf("auth = %q, want PLAIN credentials for bot", d.auth)
+	}
+	if !strings.Contains(d.data, "Subject: Overdue: Ship the release") {
+		t.Errorf("message = %q, want an overdue subject", d.data)
+	}
+}
+
+func TestSMTPNotifier_Errors(t *testing.T) {
+	if _, err := NewSMTPNotifier(SMTPConfig{Addr: "mail.example.com", From: "tasks@example.com"}); err == nil {
+		t.Error("NewSMTPNotifier() without a port expected an error")
+	}
+	if _, err := NewSMTPNotifier(SMTPConfig{Addr: "mail.example.com:25", From: "not an address"}); err == nil {
+		t.Error("NewSMTPNotifier() with a bad sender expected an error")
+	}
+
+	// Nobody to tell is not an error
+	n, _ := NewSMTPNotifier(SMTPConfig{Addr: "127.0.0.1:1", From: "tasks@example.com"})
+	reminder, task := testReminder()
+	task.AssignedTo = "bob"
+	if err := n.Notify(context.Background(), reminder, task); err != nil {
+		t.Errorf("Notify() with no recipients unexpected error: %v", err)
+	}
+
+	// An unreachable server is
+	task.AssignedTo = "alice@example.com"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := n.Notify(ctx, reminder, task); err == nil {
+		t.Error("Notify() to an unreachable server expected an error")
+	}
+}
+
+func TestLogNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewLogNotifier(log.New(&buf, "", 0))
+	reminder, task := testReminder()
+
+	if err := n.Notify(context.Background(), reminder, task); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	want := "reminder: Due soon: Ship the release (task 550e8400-e29b-41d4-a716-446655440000, assigned to alice@example.com, due 2026-10-20T09:00:00Z)\n"
+	if buf.String() != want {
+		t.Errorf("log = %q, want %q", buf.String(), want)
+	}
+}
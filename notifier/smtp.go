@@ -0,0 +1,164 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"taskmanager/models"
+	"time"
+)
+
+// smtpTimeout bounds a whole delivery when ctx has no earlier deadline
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig describes the mail server reminders are sent through
+type SMTPConfig struct {
+	// Addr is the server's host:port
+	Addr string
+	// From is the sender address
+	From string
+	// Username and Password enable PLAIN authentication when set
+	Username string
+	Password string
+	// To receives reminders for tasks that are not assigned to an email address
+	To []string
+}
+
+// SMTPNotifier emails reminders to the task's assignee. STARTTLS is used
+// whenever the server offers it.
+type SMTPNotifier struct {
+	cfg  SMTPConfig
+	host string
+	from *mail.Address
+	// fallback holds the bare addresses of cfg.To
+	fallback []string
+}
+
+// NewSMTPNotifier checks cfg and returns a notifier that sends through it
+func NewSMTPNotifier(cfg SMTPConfig) (*SMTPNotifier, error) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp address %q: %w", cfg.Addr, err)
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("smtp sender %q: %w", cfg.From, err)
+	}
+	n := &SMTPNotifier{cfg: cfg, host: host, from: from}
+	for _, to := range cfg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("smtp recipient %q: %w", to, err)
+		}
+		n.fallback = append(n.fallback, addr.Address)
+	}
+	return n, nil
+}
+
+// Notify emails the reminder. Reminders for tasks with nobody to send them
+// to are dropped.
+func (n *SMTPNotifier) Notify(ctx context.Context, reminder models.Reminder, task models.Task) error {
+	to := n.recipients(task)
+	if len(to) == 0 {
+		return nil
+	}
+	msg, err := n.compose(to, reminder, task)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, to, msg)
+}
+
+// recipients is the assignee when they have an email address, otherwise the
+// configured fallback list
+func (n *SMTPNotifier) recipients(task models.Task) []string {
+	if addr, err := mail.ParseAddress(task.AssignedTo); err == nil {
+		return []string{addr.Address}
+	}
+	return n.fallback
+}
+
+func (n *SMTPNotifier) compose(to []string, reminder models.Reminder, task models.Task) ([]byte, error) {
+	subject, body := Message(reminder, task)
+	// Titles come from users, so keep them from starting new header lines
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (n *SMTPNotifier) send(ctx context.Context, to []string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("connect to smtp server: %w", err)
+	}
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp greeting: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support authentication")
+		}
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(n.from.Address); err != nil {
+		return fmt.Errorf("smtp sender: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return c.Quit()
+}
@@ -0,0 +1,564 @@
+// Package recurrence parses RFC 5545 recurrence rules (RRULE) and expands
+// them into occurrence times.
+//
+// Daily, weekly, monthly and yearly rules are supported together with the
+// INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS and WKST rule
+// parts. Time-of-day frequencies and the BYHOUR, BYMINUTE, BYSECOND,
+// BYWEEKNO and BYYEARDAY parts are rejected because task due dates only
+// repeat on whole days.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRule is returned for rules that cannot be parsed or are not supported
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Frequency is the FREQ rule part
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry such as MO, 1FR or -1SU. N is zero when the
+// entry matches every such weekday in the period.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed RRULE
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	WeekStart  time.Weekday
+
+	// untilIsDate records that UNTIL was given as a DATE rather than a
+	// DATE-TIME, so it includes the whole day
+	untilIsDate bool
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// Layouts accepted for UNTIL
+const (
+	utcLayout      = "20060102T150405Z"
+	floatingLayout = "20060102T150405"
+	dateLayout     = "20060102"
+)
+
+// Parse parses a recurrence rule such as "FREQ=WEEKLY;BYDAY=MO,WE". An
+// optional "RRULE:" prefix is ignored.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%w: malformed rule part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given more than once", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		if err := r.setPart(name, value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRule, name, err)
+		}
+	}
+	if err := r.validate(seen); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return r, nil
+}
+
+func (r *Rule) setPart(name, value string) error {
+	var err error
+	switch name {
+	case "FREQ":
+		switch f := Frequency(value); f {
+		case Daily, Weekly, Monthly, Yearly:
+			r.Freq = f
+		case "SECONDLY", "MINUTELY", "HOURLY":
+			return fmt.Errorf("%s is not supported", value)
+		default:
+			return fmt.Errorf("unknown frequency %q", value)
+		}
+	case "INTERVAL":
+		r.Interval, err = parseInt(value, 1, 1<<20)
+	case "COUNT":
+		r.Count, err = parseInt(value, 1, 1<<20)
+	case "UNTIL":
+		err = r.parseUntil(value)
+	case "BYDAY":
+		r.ByDay, err = parseByDay(value)
+	case "BYMONTHDAY":
+		r.ByMonthDay, err = parseIntList(value, 1, 31, true)
+	case "BYMONTH":
+		var months []int
+		months, err = parseIntList(value, 1, 12, false)
+		for _, m := range months {
+			r.ByMonth = append(r.ByMonth, time.Month(m))
+		}
+	case "BYSETPOS":
+		r.BySetPos, err = parseIntList(value, 1, 366, true)
+	case "WKST":
+		wd, ok := weekdays[value]
+		if !ok {
+			return fmt.Errorf("unknown weekday %q", value)
+		}
+		r.WeekStart = wd
+	case "BYSECOND", "BYMINUTE", "BYHOUR", "BYWEEKNO", "BYYEARDAY":
+		return errors.New("not supported")
+	default:
+		return errors.New("unknown rule part")
+	}
+	return err
+}
+
+func (r *Rule) parseUntil(value string) error {
+	if t, err := time.Parse(utcLayout, value); err == nil {
+		r.Until = t
+		return nil
+	}
+	if t, err := time.Parse(floatingLayout, value); err == nil {
+		r.Until = t
+		return nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q", value)
+	}
+	r.Until = t
+	r.untilIsDate = true
+	return nil
+}
+
+func (r *Rule) validate(seen map[string]bool) error {
+	if r.Freq == "" {
+		return errors.New("FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return errors.New("COUNT and UNTIL cannot both be given")
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return fmt.Errorf("BYDAY ordinals need a MONTHLY or YEARLY frequency")
+		}
+		if wd.N != 0 && r.Freq == Monthly && (wd.N > 5 || wd.N < -5) {
+			return fmt.Errorf("BYDAY ordinal %d is out of range for a month", wd.N)
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return errors.New("BYMONTHDAY cannot be used with a WEEKLY frequency")
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return errors.New("BYSETPOS needs another BYxxx rule part")
+	}
+	return nil
+}
+
+// String formats the rule in a canonical form that Parse accepts
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.untilIsDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(dateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(utcLayout))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = weekdayNames[wd.Weekday]
+			if wd.N != 0 {
+				days[i] = strconv.Itoa(wd.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// emptyPeriodLimit stops expansion of rules whose filters can never match,
+// such as the 30th of February
+const emptyPeriodLimit = 10000
+
+// Occurrences returns up to limit occurrences of the rule for a series
+// starting at dtstart. dtstart is always the first occurrence, as in
+// RFC 5545; later ones take their time of day and location from it.
+func (r *Rule) Occurrences(dtstart time.Time, limit int) []time.Time {
+	if limit <= 0 {
+		return nil
+	}
+	if r.Count > 0 && limit > r.Count {
+		limit = r.Count
+	}
+	result := []time.Time{dtstart}
+
+	empty := 0
+	for period := 0; len(result) < limit && empty < emptyPeriodLimit; period++ {
+		days := r.expand(dtstart, period*r.Interval)
+		if len(days) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, day := range days {
+			t := time.Date(day.Year(), day.Month(), day.Day(),
+				dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+			if !t.After(dtstart) {
+				continue
+			}
+			if r.pastUntil(t) {
+				return result
+			}
+			result = append(result, t)
+			if len(result) == limit {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// Next returns the first occurrence after the one at dtstart, or false when
+// the series ends with dtstart
+func (r *Rule) Next(dtstart time.Time) (time.Time, bool) {
+	occurrences := r.Occurrences(dtstart, 2)
+	if len(occurrences) < 2 {
+		return time.Time{}, false
+	}
+	return occurrences[1], true
+}
+
+func (r *Rule) pastUntil(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.untilIsDate {
+		day := civilDate(t.Year(), t.Month(), t.Day())
+		return day.After(r.Until)
+	}
+	return t.After(r.Until)
+}
+
+// expand returns the sorted dates (as UTC midnights) of the period that lies
+// offset periods after the one containing dtstart
+func (r *Rule) expand(dtstart time.Time, offset int) []time.Time {
+	start := civilDate(dtstart.Year(), dtstart.Month(), dtstart.Day())
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := start.AddDate(0, 0, offset)
+		if r.monthAllowed(day.Month()) && r.monthDayAllowed(day) && r.weekdayAllowed(day) {
+			days = append(days, day)
+		}
+
+	case Weekly:
+		weekStart := start.AddDate(0, 0, -int((start.Weekday()-r.WeekStart+7)%7)+7*offset)
+		wanted := []time.Weekday{start.Weekday()}
+		if len(r.ByDay) > 0 {
+			wanted = wanted[:0]
+			for _, wd := range r.ByDay {
+				wanted = append(wanted, wd.Weekday)
+			}
+		}
+		for _, wd := range wanted {
+			day := weekStart.AddDate(0, 0, int((wd-r.WeekStart+7)%7))
+			if r.monthAllowed(day.Month()) {
+				days = append(days, day)
+			}
+		}
+
+	case Monthly:
+		month := civilDate(start.Year(), start.Month()+time.Month(offset), 1)
+		if r.monthAllowed(month.Month()) {
+			days = r.monthDays(month.Year(), month.Month(), start.Day())
+		}
+
+	case Yearly:
+		year := start.Year() + offset
+		if len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+			// Ordinals count within the whole year
+			first, last := civilDate(year, time.January, 1), civilDate(year, time.December, 31)
+			for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+				if r.byDayMatches(day, first, last) {
+					days = append(days, day)
+				}
+			}
+			break
+		}
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			} else {
+				months = []time.Month{start.Month()}
+			}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(year, m, start.Day())...)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	days = dedupe(days)
+	return r.applySetPos(days)
+}
+
+// monthDays returns the days of a month selected by BYMONTHDAY and BYDAY, or
+// defaultDay when neither is given. Days that do not exist are skipped.
+func (r *Rule) monthDays(year int, month time.Month, defaultDay int) []time.Time {
+	first := civilDate(year, month, 1)
+	last := first.AddDate(0, 1, -1)
+	n := last.Day()
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			d := md
+			if md < 0 {
+				d = n + md + 1
+			}
+			if d < 1 || d > n {
+				continue
+			}
+			day := civilDate(year, month, d)
+			if len(r.ByDay) == 0 || r.byDayMatches(day, first, last) {
+				days = append(days, day)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for d := 1; d <= n; d++ {
+			day := civilDate(year, month, d)
+			if r.byDayMatches(day, first, last) {
+				days = append(days, day)
+			}
+		}
+	default:
+		if defaultDay <= n {
+			days = append(days, civilDate(year, month, defaultDay))
+		}
+	}
+	return days
+}
+
+// byDayMatches reports whether day matches a BYDAY entry, counting ordinals
+// within the scope from first to last
+func (r *Rule) byDayMatches(day, first, last time.Time) bool {
+	for _, wd := range r.ByDay {
+		if day.Weekday() != wd.Weekday {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && daysBetween(first, day)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && daysBetween(day, last)/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) monthAllowed(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, allowed := range r.ByMonth {
+		if m == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) monthDayAllowed(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	n := civilDate(day.Year(), day.Month()+1, 0).Day()
+	for _, md := range r.ByMonthDay {
+		if md == day.Day() || (md < 0 && n+md+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) weekdayAllowed(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos keeps only the BYSETPOS positions of a period's sorted days
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) {
+			selected = append(selected, days[i])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return dedupe(selected)
+}
+
+func civilDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func dedupe(days []time.Time) []time.Time {
+	out := days[:0]
+	for i, day := range days {
+		if i == 0 || !day.Equal(days[i-1]) {
+			out = append(out, day)
+		}
+	}
+	return out
+}
+
+func parseInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+// parseIntList parses a comma-separated list of integers whose absolute
+// values lie in [min, max]; negative values are only allowed if signed
+func parseIntList(value string, min, max int, signed bool) ([]int, error) {
+	var list []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		abs := n
+		if n < 0 {
+			abs = -n
+		}
+		if err != nil || abs < min || abs > max || (n < 0 && !signed) {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var list []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		wd, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			if n, err = strconv.Atoi(prefix); err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid weekday %q", item)
+			}
+		}
+		list = append(list, WeekdayNum{N: n, Weekday: wd})
+	}
+	return list, nil
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,153 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrences(t *testing.T) {
+	// Most cases are examples from RFC 5545 section 3.8.5.3
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		limit   int
+		want    []string
+	}{
+		{"Daily for 10 occurrences", "FREQ=DAILY;COUNT=10", date(1997, 9, 2), 20,
+			[]string{"1997-09-02", "1997-09-03", "1997-09-04", "1997-09-05", "1997-09-06",
+				"1997-09-07", "1997-09-08", "1997-09-09", "1997-09-10", "1997-09-11"}},
+		{"Every other day", "FREQ=DAILY;INTERVAL=2", date(1997, 9, 2), 4,
+			[]string{"1997-09-02", "1997-09-04", "1997-09-06", "1997-09-08"}},
+		{"Daily until", "RRULE:FREQ=DAILY;UNTIL=19970905T090000Z", date(1997, 9, 2), 10,
+			[]string{"1997-09-02", "1997-09-03", "1997-09-04", "1997-09-05"}},
+		{"Until as a date includes the day", "FREQ=DAILY;UNTIL=19970904", date(1997, 9, 2), 10,
+			[]string{"1997-09-02", "1997-09-03", "1997-09-04"}},
+		{"Weekly on Tuesday and Thursday", "FREQ=WEEKLY;COUNT=6;BYDAY=TU,TH", date(1997, 9, 2), 10,
+			[]string{"1997-09-02", "1997-09-04", "1997-09-09", "1997-09-11", "1997-09-16", "1997-09-18"}},
+		{"Every other week on Monday, Wednesday and Friday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR", date(1997, 9, 1), 6,
+			[]string{"1997-09-01", "1997-09-03", "1997-09-05", "1997-09-15", "1997-09-17", "1997-09-19"}},
+		{"Week start changes the period", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", date(1997, 8, 5), 10,
+			[]string{"1997-08-05", "1997-08-17", "1997-08-19", "1997-08-31"}},
+		{"Monthly on the first Friday", "FREQ=MONTHLY;COUNT=4;BYDAY=1FR", date(1997, 9, 5), 10,
+			[]string{"1997-09-05", "1997-10-03", "1997-11-07", "1997-12-05"}},
+		{"Monthly on the first and last Sunday", "FREQ=MONTHLY;INTERVAL=2;COUNT=4;BYDAY=1SU,-1SU", date(1997, 9, 7), 10,
+			[]string{"1997-09-07", "1997-09-28", "1997-11-02", "1997-11-30"}},
+		{"Monthly on the third-to-last day", "FREQ=MONTHLY;BYMONTHDAY=-3", date(1997, 9, 28), 4,
+			[]string{"1997-09-28", "1997-10-29", "1997-11-28", "1997-12-29"}},
+		{"Monthly skips months without the day", "FREQ=MONTHLY", date(2025, 1, 31), 4,
+			[]string{"2025-01-31", "2025-03-31", "2025-05-31", "2025-07-31"}},
+		{"Last work day of the month", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", date(1997, 9, 30), 4,
+			[]string{"1997-09-30", "1997-10-31", "1997-11-28", "1997-12-31"}},
+		{"Friday the 13th", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", date(1997, 9, 2), 4,
+			[]string{"1997-09-02", "1998-02-13", "1998-03-13", "1998-11-13"}},
+		{"Yearly in June and July", "FREQ=YEARLY;COUNT=5;BYMONTH=6,7", date(1997, 6, 10), 10,
+			[]string{"1997-06-10", "1997-07-10", "1998-06-10", "1998-07-10", "1999-06-10"}},
+		{"Yearly on the 20th Monday", "FREQ=YEARLY;BYDAY=20MO", date(1997, 5, 19), 3,
+			[]string{"1997-05-19", "1998-05-18", "1999-05-17"}},
+		{"US Presidential Election day", "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", date(1996, 11, 5), 3,
+			[]string{"1996-11-05", "2000-11-07", "2004-11-02"}},
+		{"Yearly on a leap day", "FREQ=YEARLY", date(2024, 2, 29), 3,
+			[]string{"2024-02-29", "2028-02-29", "2032-02-29"}},
+		{"Impossible date stops", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", date(2024, 1, 1), 3,
+			[]string{"2024-01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.rule, err)
+			}
+			got := rule.Occurrences(tt.dtstart, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Occurrences() = %v, want %v", got, tt.want)
+			}
+			for i, occ := range got {
+				if occ.Format("2006-01-02") != tt.want[i] || occ.Hour() != 9 {
+					t.Fatalf("Occurrences()[%d] = %v, want %s at 09:00", i, occ, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOccurrences_KeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	rule, _ := Parse("FREQ=WEEKLY")
+	next, ok := rule.Next(time.Date(2026, 10, 19, 17, 30, 0, 0, loc))
+	if !ok {
+		t.Fatal("Next() reported the series ended")
+	}
+	if want := time.Date(2026, 10, 26, 17, 30, 0, 0, loc); !next.Equal(want) || next.Location() != loc {
+		t.Errorf("Next() = %v, want %v", next, want)
+	}
+}
+
+func TestNext_EndOfSeries(t *testing.T) {
+	rule, _ := Parse("FREQ=DAILY;COUNT=1")
+	if _, ok := rule.Next(date(2026, 1, 1)); ok {
+		t.Error("Next() with COUNT=1 should report the series ended")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=FORTNIGHTLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=-1",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=YEARLY;BYWEEKNO=20",
+		"FREQ=DAILY;COLOR=RED",
+		"FREQ=DAILY;COUNT",
+	}
+
+	for _, rule := range tests {
+		t.Run(rule, func(t *testing.T) {
+			if _, err := Parse(rule); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidRule", rule, err)
+			}
+		})
+	}
+}
+
+func TestRule_StringRoundTrips(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"freq=weekly;byday=mo,we", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;INTERVAL=2;COUNT=3;BYDAY=-1FR;WKST=SU", "FREQ=MONTHLY;INTERVAL=2;COUNT=3;BYDAY=-1FR;WKST=SU"},
+		{"FREQ=YEARLY;UNTIL=20301231;BYMONTH=1,7;BYMONTHDAY=1", "FREQ=YEARLY;UNTIL=20301231;BYMONTH=1,7;BYMONTHDAY=1"},
+		{"FREQ=DAILY;UNTIL=20301231T120000Z", "FREQ=DAILY;UNTIL=20301231T120000Z"},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q) unexpected error: %v", tt.in, err)
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if _, err := Parse(rule.String()); err != nil {
+			t.Errorf("Parse(String()) unexpected error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+var ErrAttachmentNotFound = errors.NewNotFoundError("Attachment")
+
+// AttachmentRepository stores attachment metadata, partitioned by workspace.
+// The content is kept separately in a BlobStore.
+type AttachmentRepository interface {
+	// ListAttachments returns the attachments on a task, oldest first
+	ListAttachments(workspace, taskID string) ([]models.Attachment, error)
+	GetAttachment(workspace, id string) (models.Attachment, error)
+	SaveAttachment(workspace string, attachment models.Attachment) (models.Attachment, error)
+	DeleteAttachment(workspace, id string) error
+	// DeleteTaskAttachments removes every attachment on a task and returns
+	// them, so their blobs can be released
+	DeleteTaskAttachments(workspace, taskID string) ([]models.Attachment, error)
+	// BlobInUse reports whether any attachment in any workspace refers to
+	// the blob with the given hash
+	BlobInUse(hash string) (bool, error)
+}
+
+type InMemoryAttachmentRepo struct {
+	attachments map[string]models.Attachment
+	mu          sync.RWMutex
+}
+
+func NewInMemoryAttachmentRepo() *InMemoryAttachmentRepo {
+	return &InMemoryAttachmentRepo{
+		attachments: make(map[string]models.Attachment),
+	}
+}
+
+func sortAttachments(attachments []models.Attachment) {
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return attachments[i].ID < attachments[j].ID
+	})
+}
+
+func (r *InMemoryAttachmentRepo) ListAttachments(workspace, taskID string) ([]models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attachments := []models.Attachment{}
+	for _, a := range r.attachments {
+		if a.Workspace == workspace && a.TaskID == taskID {
+			attachments = append(attachments, a)
+		}
+	}
+	sortAttachments(attachments)
+	return attachments, nil
+}
+
+func (r *InMemoryAttachmentRepo) GetAttachment(workspace, id string) (models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.attachments[id]
+	if !ok || a.Workspace != workspace {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+func (r *InMemoryAttachmentRepo) SaveAttachment(workspace string, attachment models.Attachment) (models.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.attachments[attachment.ID]; ok && stored.Workspace != workspace {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	attachment.Workspace = workspace
+	r.attachments[attachment.ID] = attachment
+	return attachment, nil
+}
+
+func (r *InMemoryAttachmentRepo) DeleteAttachment(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.attachments[id]; !ok || a.Workspace != workspace {
+		return ErrAttachmentNotFound
+	}
+	delete(r.attachments, id)
+	return nil
+}
+
+func (r *InMemoryAttachmentRepo) DeleteTaskAttachments(workspace, taskID string) ([]models.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := []models.Attachment{}
+	for id, a := range r.attachments {
+		if a.Workspace == workspace && a.TaskID == taskID {
+			removed = append(removed, a)
+			delete(r.attachments, id)
+		}
+	}
+	sortAttachments(removed)
+	return removed, nil
+}
+
+func (r *InMemoryAttachmentRepo) BlobInUse(hash string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, a := range r.attachments {
+		if a.SHA256 == hash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path/filepath"
+	"taskmanager/models"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// testAttachmentRepo exercises the AttachmentRepository contract against repo
+func testAttachmentRepo(t *testing.T, repo AttachmentRepository) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	attachment := func(id, taskID, hash string, created time.Time) models.Attachment {
+		return models.Attachment{ID: id, TaskID: taskID, Filename: id + ".png", ContentType: "image/png", Size: 42, SHA256: hash, UploadedBy: "alice", CreatedAt: created}
+	}
+	first := attachment("a1", "t1", "h1", base.Add(time.Hour))
+	second := attachment("a2", "t1", "h2", base)
+	elsewhere := attachment("a3", "t2", "h1", base)
+	other := attachment("other", "t1", "h3", base)
+	for _, a := range []models.Attachment{first, second, elsewhere} {
+		if _, err := repo.SaveAttachment(testWorkspace, a); err != nil {
+			t.Fatalf("SaveAttachment() unexpected error: %v", err)
+		}
+	}
+	if _, err := repo.SaveAttachment("team-b", other); err != nil {
+		t.Fatalf("SaveAttachment() unexpected error: %v", err)
+	}
+
+	attachments, err := repo.ListAttachments(testWorkspace, "t1")
+	if err != nil {
+		t.Fatalf("ListAttachments() unexpected error: %v", err)
+	}
+	if len(attachments) != 2 || attachments[0].ID != "a2" || attachments[1].ID != "a1" {
+		t.Fatalf("ListAttachments() = %+v, want a2 then a1", attachments)
+	}
+	got, err := repo.GetAttachment(testWorkspace, "a1")
+	first.Workspace = testWorkspace
+	if err != nil || got != first {
+		t.Errorf("GetAttachment() = %+v, %v, want %+v", got, err, first)
+	}
+
+	// Attachments in other workspaces are invisible and cannot be overwritten
+	if _, err := repo.GetAttachment(testWorkspace, "other"); err != ErrAttachmentNotFound {
+		t.Errorf("GetAttachment() across workspaces error = %v, want %v", err, ErrAttachmentNotFound)
+	}
+	if _, err := repo.SaveAttachment(testWorkspace, other); err != ErrAttachmentNotFound {
+		t.Errorf("SaveAttachment() across workspaces error = %v, want %v", err, ErrAttachmentNotFound)
+	}
+	if err := repo.DeleteAttachment(testWorkspace, "other"); err != ErrAttachmentNotFound {
+		t.Errorf("DeleteAttachment() across workspaces error = %v, want %v", err, ErrAttachmentNotFound)
+	}
+	// but still count as references to their blob
+	if inUse, err := repo.BlobInUse("h3"); err != nil || !inUse {
+		t.Errorf("BlobInUse(h3) = %v, %v, want true", inUse, err)
+	}
+
+	if err := repo.DeleteAttachment(testWorkspace, "a2"); err != nil {
+		t.Fatalf("DeleteAttachment() unexpected error: %v", err)
+	}
+	if err := repo.DeleteAttachment(testWorkspace, "a2"); err != ErrAttachmentNotFound {
+		t.Errorf("DeleteAttachment() twice error = %v, want %v", err, ErrAttachmentNotFound)
+	}
+	if inUse, _ := repo.BlobInUse("h2"); inUse {
+		t.Error("BlobInUse(h2) after its only attachment was deleted = true, want false")
+	}
+
+	removed, err := repo.DeleteTaskAttachments(testWorkspace, "t1")
+	if err != nil || len(removed) != 1 || removed[0].ID != "a1" {
+		t.Errorf("DeleteTaskAttachments() = %+v, %v, want a1", removed, err)
+	}
+	if attachments, _ := repo.ListAttachments(testWorkspace, "t1"); len(attachments) != 0 {
+		t.Errorf("ListAttachments() after DeleteTaskAttachments = %+v, want none", attachments)
+	}
+	if inUse, _ := repo.BlobInUse("h1"); !inUse {
+		t.Error("BlobInUse(h1) = false, want true while a3 still refers to it")
+	}
+	if attachments, _ := repo.ListAttachments("team-b", "t1"); len(attachments) != 1 {
+		t.Errorf("ListAttachments() in another workspace = %+v, want it untouched", attachments)
+	}
+}
+
+func TestInMemoryAttachmentRepo(t *testing.T) {
+	testAttachmentRepo(t, NewInMemoryAttachmentRepo())
+}
+
+func TestFileAttachmentRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileAttachmentRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileAttachmentRepo() unexpected error: %v", err)
+	}
+	testAttachmentRepo(t, repo)
+
+	reopened, err := NewFileAttachmentRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileAttachmentRepo() reopen unexpected error: %v", err)
+	}
+	if attachments, _ := reopened.ListAttachments(testWorkspace, "t2"); len(attachments) != 1 || attachments[0].SHA256 != "h1" {
+		t.Errorf("ListAttachments() after reopen = %+v, want attachment a3", attachments)
+	}
+}
+
+func TestSQLAttachmentRepo(t *testing.T) {
+	repo, err := NewSQLAttachmentRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLAttachmentRepo() unexpected error: %v", err)
+	}
+	testAttachmentRepo(t, repo)
+}
+
+// testBlobStore exercises the BlobStore contract against store
+func testBlobStore(t *testing.T, store BlobStore) {
+	t.Helper()
+	content := []byte("screenshot bytes")
+	hash, size, err := store.Put(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if hash != hex.EncodeToString(sum[:]) || size != int64(len(content)) {
+		t.Errorf("Put() = %q, %d, want the SHA-256 hash and size %d", hash, size, len(content))
+	}
+	again, _, err := store.Put(bytes.NewReader(content))
+	if err != nil || again != hash {
+		t.Errorf("Put() of the same content = %q, %v, want %q", again, err, hash)
+	}
+
+	rc, err := store.Open(hash)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, content) {
+		t.Errorf("Open() content = %q, want %q", got, content)
+	}
+
+	failing := io.MultiReader(bytes.NewReader([]byte("partial")), iotest.ErrReader(errors.New("connection reset")))
+	if _, _, err := store.Put(failing); err == nil {
+		t.Error("Put() with a failing reader expected an error")
+	}
+
+	if err := store.Delete(hash); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := store.Open(hash); err != ErrBlobNotFound {
+		t.Errorf("Open() after Delete error = %v, want %v", err, ErrBlobNotFound)
+	}
+	if err := store.Delete(hash); err != nil {
+		t.Errorf("Delete() of a missing blob unexpected error: %v", err)
+	}
+	if _, err := store.Open("../../etc/passwd"); err != ErrBlobNotFound {
+		t.Errorf("Open() of a malformed hash error = %v, want %v", err, ErrBlobNotFound)
+	}
+}
+
+func TestInMemoryBlobStore(t *testing.T) {
+	testBlobStore(t, NewInMemoryBlobStore())
+}
+
+func TestFileBlobStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() unexpected error: %v", err)
+	}
+	testBlobStore(t, store)
+
+	// Failed uploads leave no temporary files behind
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "upload-*")); len(leftovers) != 0 {
+		t.Errorf("temporary files left after Put() = %v", leftovers)
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"taskmanager/models"
+)
+
+// AuditRepository is an append-only store of task audit entries
+type AuditRepository interface {
+	Append(entry models.AuditEntry) error
+	List(q models.AuditQuery) ([]models.AuditEntry, error)
+}
+
+type InMemoryAuditRepo struct {
+	entries []models.AuditEntry
+	mu      sync.RWMutex
+}
+
+func NewInMemoryAuditRepo() *InMemoryAuditRepo {
+	return &InMemoryAuditRepo{}
+}
+
+func (r *InMemoryAuditRepo) Append(entry models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *InMemoryAuditRepo) List(q models.AuditQuery) ([]models.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return listAuditEntries(r.entries, q), nil
+}
+
+// listAuditEntries returns the entries matching q, oldest first, up to q.Limit
+func listAuditEntries(entries []models.AuditEntry, q models.AuditQuery) []models.AuditEntry {
+	q.Normalize()
+	result := []models.AuditEntry{}
+	for _, entry := range entries {
+		if q.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	// Entries are appended in commit order, but concurrent writers may take
+	// their timestamps in a different order
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	if len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testAuditRepo exercises the AuditRepository contract against repo
+func testAuditRepo(t *testing.T, repo AuditRepository) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []models.AuditEntry{
+		{ID: "e1", TaskID: "a", Action: "create", Actor: "alice", Timestamp: base,
+			Changes: []models.FieldChange{{Field: "title", Old: "", New: "Write docs"}}},
+		{ID: "e2", TaskID: "b", Workspace: testWorkspace, Action: "create", Actor: "bob", Timestamp: base.Add(time.Hour)},
+		{ID: "e3", TaskID: "a", Action: "update", Actor: "bob", Timestamp: base.Add(2 * time.Hour),
+			Changes: []models.FieldChange{{Field: "status", Old: "Pending", New: "InProgress"}}},
+		{ID: "e4", TaskID: "a", Action: "delete", Actor: "alice", Timestamp: base.Add(3 * time.Hour)},
+	}
+	// Append out of timestamp order; List must still return oldest first
+	for _, i := range []int{1, 0, 2, 3} {
+		if err := repo.Append(entries[i]); err != nil {
+			t.Fatalf("Append() unexpected error: %v", err)
+		}
+	}
+
+	from, to := base.Add(time.Hour), base.Add(3*time.Hour)
+	tests := []struct {
+		name  string
+		query models.AuditQuery
+		want  []string
+	}{
+		{"All", models.AuditQuery{}, []string{"e1", "e2", "e3", "e4"}},
+		{"By task", models.AuditQuery{TaskID: "a"}, []string{"e1", "e3", "e4"}},
+		{"By actor", models.AuditQuery{Actor: "bob"}, []string{"e2", "e3"}},
+		{"By workspace", models.AuditQuery{Workspace: testWorkspace}, []string{"e2"}},
+		{"Time range", models.AuditQuery{From: &from, To: &to}, []string{"e2", "e3"}},
+		{"Limit", models.AuditQuery{Limit: 2}, []string{"e1", "e2"}},
+		{"No match", models.AuditQuery{TaskID: "missing"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.List(tt.query)
+			if err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+			ids := make([]string, len(got))
+			for i, entry := range got {
+				ids[i] = entry.ID
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("List() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+
+	got, _ := repo.List(models.AuditQuery{TaskID: "a", Limit: 1})
+	if len(got) != 1 || !got[0].Timestamp.Equal(base) || got[0].Actor != "alice" {
+		t.Fatalf("List() first entry = %+v, want e1", got)
+	}
+	if len(got[0].Changes) != 1 || got[0].Changes[0].Field != "title" || got[0].Changes[0].New != "Write docs" {
+		t.Errorf("List() changes = %+v, want title change", got[0].Changes)
+	}
+}
+
+func TestInMemoryAuditRepo(t *testing.T) {
+	testAuditRepo(t, NewInMemoryAuditRepo())
+}
+
+func TestFileAuditRepo(t *testing.T) {
+	repo, err := NewFileAuditRepo(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileAuditRepo() unexpected error: %v", err)
+	}
+	defer repo.Close()
+	testAuditRepo(t, repo)
+}
+
+func TestSQLAuditRepo(t *testing.T) {
+	repo, err := NewSQLAuditRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLAuditRepo() unexpected error: %v", err)
+	}
+	testAuditRepo(t, repo)
+}
+
+func TestFileAuditRepo_ReloadsAndTrimsTornEntry(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileAuditRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileAuditRepo() unexpected error: %v", err)
+	}
+	repo.Append(models.AuditEntry{ID: "e1", TaskID: "a", Action: "create", Timestamp: time.Now()})
+	repo.Close()
+
+	// Simulate a crash part way through writing the next entry
+	f, _ := os.OpenFile(filepath.Join(dir, auditLogFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	f.WriteString(`{"id":"e2","taskId":`)
+	f.Close()
+
+	reopened, err := NewFileAuditRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileAuditRepo() after torn write unexpected error: %v", err)
+	}
+	if err := reopened.Append(models.AuditEntry{ID: "e3", TaskID: "a", Action: "update", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Append() unexpected error: %v", err)
+	}
+	reopened.Close()
+
+	again, err := NewFileAuditRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileAuditRepo() unexpected error: %v", err)
+	}
+	defer again.Close()
+	got, _ := again.List(models.AuditQuery{})
+	if len(got) != 2 || got[0].ID != "e1" || got[1].ID != "e3" {
+		t.Errorf("List() after reopen = %+v, want e1 and e3", got)
+	}
+}
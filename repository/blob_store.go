@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"taskmanager/errors"
+)
+
+var ErrBlobNotFound = errors.NewNotFoundError("Blob")
+
+// BlobStore keeps file contents addressed by their SHA-256 hash, so storing
+// the same content twice keeps a single copy. It does not track who refers
+// to a blob; deleting one that is still in use is the caller's mistake.
+type BlobStore interface {
+	// Put stores everything read from r and returns its hex SHA-256 hash
+	// and size. Nothing is stored if reading r fails.
+	Put(r io.Reader) (hash string, size int64, err error)
+	Open(hash string) (io.ReadCloser, error)
+	// Delete removes a blob. Deleting a missing blob is not an error.
+	Delete(hash string) error
+}
+
+// validBlobHash reports whether hash looks like a hex SHA-256 digest, which
+// keeps arbitrary strings out of FileBlobStore paths
+func validBlobHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+type InMemoryBlobStore struct {
+	blobs map[string][]byte
+	mu    sync.RWMutex
+}
+
+func NewInMemoryBlobStore() *InMemoryBlobStore {
+	return &InMemoryBlobStore{
+		blobs: make(map[string][]byte),
+	}
+}
+
+func (s *InMemoryBlobStore) Put(r io.Reader) (string, int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", 0, err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blobs[hash]; !ok {
+		s.blobs[hash] = data
+	}
+	return hash, int64(len(data)), nil
+}
+
+func (s *InMemoryBlobStore) Open(hash string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.blobs[hash]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *InMemoryBlobStore) Delete(hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, hash)
+	return nil
+}
+
+// FileBlobStore is a BlobStore on the local filesystem. Each blob is a file
+// named after its hash, in a subdirectory named after the hash's first two
+// characters. Uploads are written to a temporary file and renamed into
+// place, so a blob is never visible half-written.
+type FileBlobStore struct {
+	dir string
+}
+
+// NewFileBlobStore opens (or creates) a blob store in dir
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create blob dir: %w", err)
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+func (s *FileBlobStore) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+func (s *FileBlobStore) Put(r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", 0, fmt.Errorf("write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, fmt.Errorf("write blob: %w", err)
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, fmt.Errorf("create blob dir: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("store blob: %w", err)
+	}
+	return hash, size, nil
+}
+
+func (s *FileBlobStore) Open(hash string) (io.ReadCloser, error) {
+	if !validBlobHash(hash) {
+		return nil, ErrBlobNotFound
+	}
+	f, err := os.Open(s.path(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open blob: %w", err)
+	}
+	return f, nil
+}
+
+func (s *FileBlobStore) Delete(hash string) error {
+	if !validBlobHash(hash) {
+		return nil
+	}
+	if err := os.Remove(s.path(hash)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+var ErrCommentNotFound = errors.NewNotFoundError("Comment")
+
+// CommentRepository stores the comments on tasks, partitioned by workspace
+// like the tasks themselves. It does not check that the task exists; the
+// service does that, and removes a task's comments when the task is purged.
+type CommentRepository interface {
+	// ListComments returns the comments on a task, oldest first
+	ListComments(workspace, taskID string) ([]models.Comment, error)
+	GetComment(workspace, id string) (models.Comment, error)
+	SaveComment(workspace string, comment models.Comment) (models.Comment, error)
+	DeleteComment(workspace, id string) error
+	// DeleteTaskComments removes every comment on a task and reports how
+	// many there were
+	DeleteTaskComments(workspace, taskID string) (int, error)
+}
+
+type InMemoryCommentRepo struct {
+	comments map[string]models.Comment
+	mu       sync.RWMutex
+}
+
+func NewInMemoryCommentRepo() *InMemoryCommentRepo {
+	return &InMemoryCommentRepo{
+		comments: make(map[string]models.Comment),
+	}
+}
+
+func (r *InMemoryCommentRepo) ListComments(workspace, taskID string) ([]models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comments := []models.Comment{}
+	for _, c := range r.comments {
+		if c.Workspace == workspace && c.TaskID == taskID {
+			comments = append(comments, c)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+func (r *InMemoryCommentRepo) GetComment(workspace, id string) (models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.comments[id]
+	if !ok || c.Workspace != workspace {
+		return models.Comment{}, ErrCommentNotFound
+	}
+	return c, nil
+}
+
+func (r *InMemoryCommentRepo) SaveComment(workspace string, comment models.Comment) (models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.comments[comment.ID]; ok && stored.Workspace != workspace {
+		return models.Comment{}, ErrCommentNotFound
+	}
+	comment.Workspace = workspace
+	comment.Edits = slices.Clone(comment.Edits)
+	r.comments[comment.ID] = comment
+	return comment, nil
+}
+
+func (r *InMemoryCommentRepo) DeleteComment(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.comments[id]; !ok || c.Workspace != workspace {
+		return ErrCommentNotFound
+	}
+	delete(r.comments, id)
+	return nil
+}
+
+func (r *InMemoryCommentRepo) DeleteTaskComments(workspace, taskID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := 0
+	for id, c := range r.comments {
+		if c.Workspace == workspace && c.TaskID == taskID {
+			delete(r.comments, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"path/filepath"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testCommentRepo exercises the CommentRepository contract against repo
+func testCommentRepo(t *testing.T, repo CommentRepository) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := models.Comment{ID: "c1", TaskID: "t1", Author: "alice", Body: "first", CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)}
+	second := models.Comment{ID: "c2", TaskID: "t1", Author: "bob", Body: "second", CreatedAt: base, UpdatedAt: base}
+	elsewhere := models.Comment{ID: "c3", TaskID: "t2", Author: "alice", Body: "other task", CreatedAt: base, UpdatedAt: base}
+	other := models.Comment{ID: "other", TaskID: "t1", Author: "eve", Body: "other workspace", CreatedAt: base, UpdatedAt: base}
+	for _, c := range []models.Comment{first, second, elsewhere} {
+		if _, err := repo.SaveComment(testWorkspace, c); err != nil {
+			t.Fatalf("SaveComment() unexpected error: %v", err)
+		}
+	}
+	if _, err := repo.SaveComment("team-b", other); err != nil {
+		t.Fatalf("SaveComment() unexpected error: %v", err)
+	}
+
+	comments, err := repo.ListComments(testWorkspace, "t1")
+	if err != nil {
+		t.Fatalf("ListComments() unexpected error: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != "c2" || comments[1].ID != "c1" {
+		t.Fatalf("ListComments() = %+v, want c2 then c1", comments)
+	}
+
+	first.Edit("first, edited", "alice", base.Add(2*time.Hour))
+	if _, err := repo.SaveComment(testWorkspace, first); err != nil {
+		t.Fatalf("SaveComment() unexpected error: %v", err)
+	}
+	got, err := repo.GetComment(testWorkspace, "c1")
+	if err != nil || got.Body != "first, edited" || got.Workspace != testWorkspace || got.TaskID != "t1" ||
+		!got.CreatedAt.Equal(first.CreatedAt) || !got.UpdatedAt.Equal(first.UpdatedAt) {
+		t.Errorf("GetComment() = %+v, %v, want %+v", got, err, first)
+	}
+	if len(got.Edits) != 1 || got.Edits[0].Body != "first" || !got.Edits[0].EditedAt.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("GetComment() edits = %+v, want the original body", got.Edits)
+	}
+
+	// Comments in other workspaces are invisible and cannot be overwritten
+	if _, err := repo.GetComment(testWorkspace, "other"); err != ErrCommentNotFound {
+		t.Errorf("GetComment() across workspaces error = %v, want %v", err, ErrCommentNotFound)
+	}
+	if _, err := repo.SaveComment(testWorkspace, other); err != ErrCommentNotFound {
+		t.Errorf("SaveComment() across workspaces error = %v, want %v", err, ErrCommentNotFound)
+	}
+	if err := repo.DeleteComment(testWorkspace, "other"); err != ErrCommentNotFound {
+		t.Errorf("DeleteComment() across workspaces error = %v, want %v", err, ErrCommentNotFound)
+	}
+
+	if err := repo.DeleteComment(testWorkspace, "c2"); err != nil {
+		t.Fatalf("DeleteComment() unexpected error: %v", err)
+	}
+	if err := repo.DeleteComment(testWorkspace, "c2"); err != ErrCommentNotFound {
+		t.Errorf("DeleteComment() twice error = %v, want %v", err, ErrCommentNotFound)
+	}
+
+	if n, err := repo.DeleteTaskComments(testWorkspace, "t1"); err != nil || n != 1 {
+		t.Errorf("DeleteTaskComments() = %d, %v, want 1", n, err)
+	}
+	if comments, _ := repo.ListComments(testWorkspace, "t1"); len(comments) != 0 {
+		t.Errorf("ListComments() after DeleteTaskComments = %+v, want none", comments)
+	}
+	if comments, _ := repo.ListComments(testWorkspace, "t2"); len(comments) != 1 {
+		t.Errorf("ListComments() on another task = %+v, want it untouched", comments)
+	}
+	if comments, _ := repo.ListComments("team-b", "t1"); len(comments) != 1 {
+		t.Errorf("ListComments() in another workspace = %+v, want it untouched", comments)
+	}
+}
+
+func TestInMemoryCommentRepo(t *testing.T) {
+	testCommentRepo(t, NewInMemoryCommentRepo())
+}
+
+func TestFileCommentRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileCommentRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileCommentRepo() unexpected error: %v", err)
+	}
+	testCommentRepo(t, repo)
+
+	reopened, err := NewFileCommentRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileCommentRepo() reopen unexpected error: %v", err)
+	}
+	if comments, _ := reopened.ListComments(testWorkspace, "t2"); len(comments) != 1 || comments[0].Body != "other task" {
+		t.Errorf("ListComments() after reopen = %+v, want comment c3", comments)
+	}
+}
+
+func TestSQLCommentRepo(t *testing.T) {
+	repo, err := NewSQLCommentRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLCommentRepo() unexpected error: %v", err)
+	}
+	testCommentRepo(t, repo)
+}
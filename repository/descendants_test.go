@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"slices"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testTaskRepoDescendants exercises Descendants against repo
+func testTaskRepoDescendants(t *testing.T, repo TaskRepository) {
+	t.Helper()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	save := func(workspace, id, parentID string, deleted bool) {
+		task := models.Task{ID: id, Title: id, ParentID: parentID, CreatedAt: now, UpdatedAt: now}
+		if deleted {
+			task.DeletedAt = &now
+		}
+		if _, err := repo.Save(workspace, task); err != nil {
+			t.Fatalf("Save(%s) unexpected error: %v", id, err)
+		}
+	}
+	// root > a > b, root > trashed > hidden, and a cycle x <-> y below b
+	save(testWorkspace, "root", "", false)
+	save(testWorkspace, "a", "root", false)
+	save(testWorkspace, "b", "a", false)
+	save(testWorkspace, "trashed", "root", true)
+	save(testWorkspace, "hidden", "trashed", false)
+	save(testWorkspace, "x", "y", false)
+	save(testWorkspace, "y", "x", false)
+	save(testWorkspace, "unrelated", "", false)
+	save("team-b", "elsewhere", "root", false)
+
+	ids := func(id string) []string {
+		tasks, err := repo.Descendants(testWorkspace, id)
+		if err != nil {
+			t.Fatalf("Descendants(%s) unexpected error: %v", id, err)
+		}
+		result := []string{}
+		for _, task := range tasks {
+			result = append(result, task.ID)
+		}
+		slices.Sort(result)
+		return result
+	}
+	if got := ids("root"); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Descendants(root) = %v, want [a b]", got)
+	}
+	if got := ids("b"); len(got) != 0 {
+		t.Errorf("Descendants(b) = %v, want none", got)
+	}
+	if got := ids("x"); !slices.Equal(got, []string{"y"}) {
+		t.Errorf("Descendants(x) = %v, want [y] despite the cycle", got)
+	}
+}
+
+func TestInMemoryTaskRepo_Descendants(t *testing.T) {
+	testTaskRepoDescendants(t, NewInMemoryTaskRepo())
+}
+
+func TestFileTaskRepo_Descendants(t *testing.T) {
+	repo := openTestFileRepo(t, t.TempDir())
+	defer repo.Close()
+	testTaskRepoDescendants(t, repo)
+}
+
+func TestSQLTaskRepo_Descendants(t *testing.T) {
+	testTaskRepoDescendants(t, openTestSQLRepo(t))
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"taskmanager/models"
+)
+
+const attachmentsFileName = "attachments.json"
+
+// FileAttachmentRepo is an AttachmentRepository that rewrites
+// attachments.json on every change
+type FileAttachmentRepo struct {
+	mem *InMemoryAttachmentRepo
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileAttachmentRepo opens (or creates) the attachment file in dir
+func NewFileAttachmentRepo(dir string) (*FileAttachmentRepo, error) {
+	r := &FileAttachmentRepo{mem: NewInMemoryAttachmentRepo(), dir: dir}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, attachmentsFileName))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read attachments: %w", err)
+	}
+	var attachments []models.Attachment
+	if err := json.Unmarshal(data, &attachments); err != nil {
+		return nil, fmt.Errorf("decode attachments: %w", err)
+	}
+	for _, a := range attachments {
+		r.mem.attachments[a.ID] = a
+	}
+	return r, nil
+}
+
+func (r *FileAttachmentRepo) ListAttachments(workspace, taskID string) ([]models.Attachment, error) {
+	return r.mem.ListAttachments(workspace, taskID)
+}
+
+func (r *FileAttachmentRepo) GetAttachment(workspace, id string) (models.Attachment, error) {
+	return r.mem.GetAttachment(workspace, id)
+}
+
+func (r *FileAttachmentRepo) SaveAttachment(workspace string, attachment models.Attachment) (models.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved, err := r.mem.SaveAttachment(workspace, attachment)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	return saved, r.writeAttachments()
+}
+
+func (r *FileAttachmentRepo) DeleteAttachment(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.mem.DeleteAttachment(workspace, id); err != nil {
+		return err
+	}
+	return r.writeAttachments()
+}
+
+func (r *FileAttachmentRepo) DeleteTaskAttachments(workspace, taskID string) ([]models.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed, err := r.mem.DeleteTaskAttachments(workspace, taskID)
+	if err != nil || len(removed) == 0 {
+		return removed, err
+	}
+	return removed, r.writeAttachments()
+}
+
+func (r *FileAttachmentRepo) BlobInUse(hash string) (bool, error) {
+	return r.mem.BlobInUse(hash)
+}
+
+func (r *FileAttachmentRepo) writeAttachments() error {
+	r.mem.mu.RLock()
+	attachments := make([]models.Attachment, 0, len(r.mem.attachments))
+	for _, a := range r.mem.attachments {
+		attachments = append(attachments, a)
+	}
+	r.mem.mu.RUnlock()
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+
+	data, err := json.MarshalIndent(attachments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode attachments: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(r.dir, attachmentsFileName), data); err != nil {
+		return fmt.Errorf("write attachments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/models"
+)
+
+const auditLogFileName = "audit.log"
+
+// FileAuditRepo is an AuditRepository that appends each entry as a JSON line
+// to audit.log in its data directory and keeps every entry in memory for
+// queries. The log is never compacted since entries are never rewritten.
+type FileAuditRepo struct {
+	entries []models.AuditEntry
+	mu      sync.RWMutex
+	file    *os.File
+}
+
+// NewFileAuditRepo opens (or creates) the audit log in dir and loads the
+// entries already recorded there
+func NewFileAuditRepo(dir string) (*FileAuditRepo, error) {
+	r := &FileAuditRepo{}
+	f, err := openJSONLines(dir, auditLogFileName, func(line []byte) error {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return err
+		}
+		// Entries written before workspaces existed belong to the default one
+		if entry.Workspace == "" {
+			entry.Workspace = constants.DefaultWorkspace
+		}
+		r.entries = append(r.entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.file = f
+	return r, nil
+}
+
+func (r *FileAuditRepo) Append(entry models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := appendJSONLine(r.file, entry); err != nil {
+		return err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *FileAuditRepo) List(q models.AuditQuery) ([]models.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return listAuditEntries(r.entries, q), nil
+}
+
+// Close closes the audit log file
+func (r *FileAuditRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"taskmanager/models"
+)
+
+const commentsFileName = "comments.json"
+
+// FileCommentRepo is a CommentRepository that rewrites comments.json on
+// every change
+type FileCommentRepo struct {
+	mem *InMemoryCommentRepo
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileCommentRepo opens (or creates) the comment file in dir
+func NewFileCommentRepo(dir string) (*FileCommentRepo, error) {
+	r := &FileCommentRepo{mem: NewInMemoryCommentRepo(), dir: dir}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, commentsFileName))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read comments: %w", err)
+	}
+	var comments []models.Comment
+	if err := json.Unmarshal(data, &comments); err != nil {
+		return nil, fmt.Errorf("decode comments: %w", err)
+	}
+	for _, c := range comments {
+		r.mem.comments[c.ID] = c
+	}
+	return r, nil
+}
+
+func (r *FileCommentRepo) ListComments(workspace, taskID string) ([]models.Comment, error) {
+	return r.mem.ListComments(workspace, taskID)
+}
+
+func (r *FileCommentRepo) GetComment(workspace, id string) (models.Comment, error) {
+	return r.mem.GetComment(workspace, id)
+}
+
+func (r *FileCommentRepo) SaveComment(workspace string, comment models.Comment) (models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved, err := r.mem.SaveComment(workspace, comment)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return saved, r.writeComments()
+}
+
+func (r *FileCommentRepo) DeleteComment(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.mem.DeleteComment(workspace, id); err != nil {
+		return err
+	}
+	return r.writeComments()
+}
+
+func (r *FileCommentRepo) DeleteTaskComments(workspace, taskID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed, err := r.mem.DeleteTaskComments(workspace, taskID)
+	if err != nil || removed == 0 {
+		return removed, err
+	}
+	return removed, r.writeComments()
+}
+
+func (r *FileCommentRepo) writeComments() error {
+	r.mem.mu.RLock()
+	comments := make([]models.Comment, 0, len(r.mem.comments))
+	for _, c := range r.mem.comments {
+		comments = append(comments, c)
+	}
+	r.mem.mu.RUnlock()
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+
+	data, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode comments: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(r.dir, commentsFileName), data); err != nil {
+		return fmt.Errorf("write comments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"taskmanager/models"
+)
+
+const projectsFileName = "projects.json"
+
+// FileProjectRepo is a ProjectRepository that rewrites projects.json on
+// every change
+type FileProjectRepo struct {
+	mem *InMemoryProjectRepo
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileProjectRepo opens (or creates) the project file in dir
+func NewFileProjectRepo(dir string) (*FileProjectRepo, error) {
+	r := &FileProjectRepo{mem: NewInMemoryProjectRepo(), dir: dir}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, projectsFileName))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read projects: %w", err)
+	}
+	var projects []models.Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("decode projects: %w", err)
+	}
+	for _, p := range projects {
+		r.mem.projects[p.ID] = p
+	}
+	return r, nil
+}
+
+func (r *FileProjectRepo) ListProjects(workspace string) ([]models.Project, error) {
+	return r.mem.ListProjects(workspace)
+}
+
+func (r *FileProjectRepo) GetProject(workspace, id string) (models.Project, error) {
+	return r.mem.GetProject(workspace, id)
+}
+
+func (r *FileProjectRepo) SaveProject(workspace string, project models.Project) (models.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.staged()
+	saved, err := next.SaveProject(workspace, project)
+	if err != nil {
+		return models.Project{}, err
+	}
+	if err := r.commit(next); err != nil {
+		return models.Project{}, err
+	}
+	return saved, nil
+}
+
+func (r *FileProjectRepo) DeleteProject(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.staged()
+	if err := next.DeleteProject(workspace, id); err != nil {
+		return err
+	}
+	return r.commit(next)
+}
+
+// staged returns a copy of the stored projects for a change to be made on
+// before it is written
+func (r *FileProjectRepo) staged() *InMemoryProjectRepo {
+	return &InMemoryProjectRepo{projects: cloneJSONMap(&r.mem.mu, r.mem.projects)}
+}
+
+// commit writes staged to disk and then makes it the stored state
+func (r *FileProjectRepo) commit(staged *InMemoryProjectRepo) error {
+	if err := commitJSONMap(filepath.Join(r.dir, projectsFileName), &r.mem.mu, &r.mem.projects, staged.projects); err != nil {
+		return fmt.Errorf("write projects: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"taskmanager/models"
+)
+
+const reminderLogFileName = "reminders.log"
+
+// FileReminderRepo is a ReminderRepository that appends each sent reminder
+// as a JSON line to reminders.log in its data directory
+type FileReminderRepo struct {
+	sent map[string]models.Reminder
+	mu   sync.RWMutex
+	file *os.File
+}
+
+// NewFileReminderRepo opens (or creates) the reminder log in dir and loads
+// the reminders already sent
+func NewFileReminderRepo(dir string) (*FileReminderRepo, error) {
+	r := &FileReminderRepo{sent: make(map[string]models.Reminder)}
+	f, err := openJSONLines(dir, reminderLogFileName, func(line []byte) error {
+		var reminder models.Reminder
+		if err := json.Unmarshal(line, &reminder); err != nil {
+			return err
+		}
+		r.sent[reminder.Key()] = reminder
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.file = f
+	return r, nil
+}
+
+func (r *FileReminderRepo) Sent(key string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.sent[key]
+	return ok, nil
+}
+
+func (r *FileReminderRepo) Record(reminder models.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sent[reminder.Key()]; ok {
+		return nil
+	}
+	if err := appendJSONLine(r.file, reminder); err != nil {
+		return err
+	}
+	r.sent[reminder.Key()] = reminder
+	return nil
+}
+
+// Close closes the reminder log file
+func (r *FileReminderRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
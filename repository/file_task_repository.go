@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"time"
+)
+
+const (
+	walFileName      = "tasks.wal"
+	snapshotFileName = "tasks.snapshot.json"
+)
+
+// WAL operation types
+const (
+	walOpSave   = "save"
+	walOpDelete = "delete"
+)
+
+// walRecord is a single line of the write-ahead log
+type walRecord struct {
+	Op   string       `json:"op"`
+	ID   string       `json:"id"`
+	Task *models.Task `json:"task,omitempty"`
+}
+
+// walFile is the open write-ahead log; *os.File in production
+type walFile interface {
+	io.WriteCloser
+	Seek(offset int64, whence int) (int64, error)
+	Sync() error
+	Truncate(size int64) error
+}
+
+// FileTaskRepo is a TaskRepository that keeps tasks in memory and makes every
+// mutation durable by appending it to a write-ahead log before it is applied.
+// The log is periodically compacted into a snapshot so startup replay stays short.
+type FileTaskRepo struct {
+	tasks      map[string]models.Task
+	labels     labelIndex
+	mu         sync.RWMutex
+	dir        string
+	wal        walFile
+	walEntries int
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewFileTaskRepo opens (or creates) a file-backed repository in dir, replays the
+// snapshot and write-ahead log found there, and compacts the log every
+// compactInterval. A zero compactInterval disables background compaction.
+func NewFileTaskRepo(dir string, compactInterval time.Duration) (*FileTaskRepo, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileTaskRepo{
+		tasks:  make(map[string]models.Task),
+		labels: make(labelIndex),
+		dir:    dir,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := r.replayWAL(); err != nil {
+		return nil, err
+	}
+
+	if compactInterval > 0 {
+		go r.compactLoop(compactInterval)
+	} else {
+		close(r.done)
+	}
+	return r, nil
+}
+
+func (r *FileTaskRepo) GetAll(workspace string) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return tasksIn(r.tasks, workspace), nil
+}
+
+func (r *FileTaskRepo) Query(workspace string, q models.TaskQuery) (models.TaskPage, error) {
+	r.mu.RLock()
+	tasks := r.labels.candidates(r.tasks, workspace, q)
+	r.mu.RUnlock()
+	return queryTasks(tasks, q)
+}
+
+func (r *FileTaskRepo) Descendants(workspace, id string) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return descendantsIn(r.tasks, workspace, id), nil
+}
+
+func (r *FileTaskRepo) GetByID(workspace, id string) (models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return taskIn(r.tasks, workspace, id)
+}
+
+func (r *FileTaskRepo) Save(workspace string, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.tasks[task.ID]; ok && stored.Workspace != workspace {
+		return models.Task{}, ErrTaskIDTaken
+	}
+	task.Workspace = workspace
+	if err := r.appendWAL(walRecord{Op: walOpSave, ID: task.ID, Task: &task}); err != nil {
+		return models.Task{}, err
+	}
+	r.put(task)
+	return task, nil
+}
+
+func (r *FileTaskRepo) Update(workspace, id string, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, err := taskIn(r.tasks, workspace, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if stored.Version != task.Version {
+		return models.Task{}, ErrVersionConflict
+	}
+	task.ID = id
+	task.Workspace = workspace
+	task.Version++
+	task.UpdatedAt = time.Now()
+	if err := r.appendWAL(walRecord{Op: walOpSave, ID: id, Task: &task}); err != nil {
+		return models.Task{}, err
+	}
+	r.put(task)
+	return task, nil
+}
+
+func (r *FileTaskRepo) Delete(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := taskIn(r.tasks, workspace, id); err != nil {
+		return err
+	}
+	if err := r.appendWAL(walRecord{Op: walOpDelete, ID: id}); err != nil {
+		return err
+	}
+	r.remove(id)
+	return nil
+}
+
+func (r *FileTaskRepo) Workspaces() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return workspacesOf(r.tasks), nil
+}
+
+// Compact writes the current state to a new snapshot and truncates the log
+func (r *FileTaskRepo) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.compact()
+}
+
+// Close stops background compaction, compacts a final time and closes the log
+func (r *FileTaskRepo) Close() error {
+	select {
+	case <-r.stop:
+		return nil
+	default:
+		close(r.stop)
+	}
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.compact()
+	if r.wal != nil {
+		if cerr := r.wal.Close(); err == nil {
+			err = cerr
+		}
+		r.wal = nil
+	}
+	return err
+}
+
+func (r *FileTaskRepo) compactLoop(interval time.Duration) {
+	defer close(r.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.mu.Lock()
+			if r.walEntries > 0 {
+				// A failed compaction leaves the log intact, so it is safe to retry next tick
+				_ = r.compact()
+			}
+			r.mu.Unlock()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// appendWAL writes rec to the log and syncs it to disk. Callers must hold r.mu.
+func (r *FileTaskRepo) appendWAL(rec walRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode wal record: %w", err)
+	}
+	line = append(line, '\n')
+	if r.wal == nil {
+		f, err := os.OpenFile(r.path(walFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("open wal: %w", err)
+		}
+		r.wal = f
+	}
+	offset, err := r.wal.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("seek wal: %w", err)
+	}
+	if _, err := r.wal.Write(line); err != nil {
+		return r.abortWAL(offset, fmt.Errorf("write wal: %w", err))
+	}
+	if err := r.wal.Sync(); err != nil {
+		return r.abortWAL(offset, fmt.Errorf("sync wal: %w", err))
+	}
+	r.walEntries++
+	return nil
+}
+
+// abortWAL undoes a failed append by cutting the log back to offset, where
+// the record began, so neither a torn line nor a record the caller was told
+// failed is replayed. The log is closed so the next append reopens it.
+// Callers must hold r.mu.
+func (r *FileTaskRepo) abortWAL(offset int64, err error) error {
+	if terr := r.wal.Truncate(offset); terr != nil {
+		err = fmt.Errorf("%w; truncate wal: %v", err, terr)
+	}
+	r.wal.Close()
+	r.wal = nil
+	return err
+}
+
+// compact snapshots r.tasks and resets the log. Callers must hold r.mu.
+func (r *FileTaskRepo) compact() error {
+	tasks := make([]models.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	if err := writeFileAtomic(r.path(snapshotFileName), data); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	// The snapshot now covers every logged record, so the log can start over.
+	// Replaying a stale log on top of the snapshot is harmless because records
+	// carry the full task state.
+	if r.wal != nil {
+		if err := r.wal.Close(); err != nil {
+			return fmt.Errorf("close wal: %w", err)
+		}
+		r.wal = nil
+	}
+	if err := os.Truncate(r.path(walFileName), 0); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("truncate wal: %w", err)
+	}
+	r.walEntries = 0
+	return nil
+}
+
+func (r *FileTaskRepo) loadSnapshot() error {
+	data, err := os.ReadFile(r.path(snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	var tasks []models.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	for _, task := range tasks {
+		r.load(task)
+	}
+	return nil
+}
+
+// replayWAL applies every complete record in the log. A torn final record left
+// by a crash mid-write is discarded and trimmed from the file.
+func (r *FileTaskRepo) replayWAL() error {
+	f, err := os.OpenFile(r.path(walFileName), os.O_RDWR, 0o644)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open wal: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				// Incomplete trailing record
+				if terr := f.Truncate(offset); terr != nil {
+					return fmt.Errorf("truncate wal: %w", terr)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read wal: %w", err)
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("decode wal record at offset %d: %w", offset, err)
+		}
+		switch rec.Op {
+		case walOpSave:
+			if rec.Task == nil {
+				return fmt.Errorf("wal record at offset %d has no task", offset)
+			}
+			r.load(*rec.Task)
+		case walOpDelete:
+			r.remove(rec.ID)
+		default:
+			return fmt.Errorf("unknown wal op %q at offset %d", rec.Op, offset)
+		}
+		offset += int64(len(line))
+		r.walEntries++
+	}
+}
+
+// load adds a task read from disk. Tasks written before workspaces existed
+// belong to the default one.
+func (r *FileTaskRepo) load(task models.Task) {
+	if task.Workspace == "" {
+		task.Workspace = constants.DefaultWorkspace
+	}
+	r.put(task)
+}
+
+// put stores task in memory and indexes its labels. Callers must hold r.mu.
+func (r *FileTaskRepo) put(task models.Task) {
+	r.labels.update(task.ID, r.tasks[task.ID].Labels, task.Labels)
+	r.tasks[task.ID] = task
+}
+
+// remove drops task id from memory and from the label index. Callers must
+// hold r.mu.
+func (r *FileTaskRepo) remove(id string) {
+	r.labels.update(id, r.tasks[id].Labels, nil)
+	delete(r.tasks, id)
+}
+
+func (r *FileTaskRepo) path(name string) string {
+	return filepath.Join(r.dir, name)
+}
+
+// writeFileAtomic writes data to a temporary file, syncs it and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"taskmanager/constants"
+	"taskmanager/testutils"
+	"testing"
+)
+
+func openTestFileRepo(t *testing.T, dir string) *FileTaskRepo {
+	t.Helper()
+	repo, err := NewFileTaskRepo(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileTaskRepo() unexpected error: %v", err)
+	}
+	return repo
+}
+
+func TestFileTaskRepo_CRUD(t *testing.T) {
+	repo := openTestFileRepo(t, t.TempDir())
+	defer repo.Close()
+
+	task := testutils.CreateTestTask()
+	task.ID = "test-id"
+	if _, err := repo.Save(testWorkspace, task); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	retrieved, err := repo.GetByID(testWorkspace, "test-id")
+	if err != nil {
+		t.Errorf("GetByID() unexpected error: %v", err)
+	}
+	if retrieved.Title != task.Title {
+		t.Errorf("GetByID() title = %v, want %v", retrieved.Title, task.Title)
+	}
+
+	task.Status = constants.StatusCompleted
+	updated, err := repo.Update(testWorkspace, "test-id", task)
+	if err != nil {
+		t.Errorf("Update() unexpected error: %v", err)
+	}
+	if updated.Status != constants.StatusCompleted {
+		t.Errorf("Update() status = %v, want %v", updated.Status, constants.StatusCompleted)
+	}
+	if _, err := repo.Update(testWorkspace, "non-existent", task); err != ErrTaskNotFound {
+		t.Errorf("Update() error = %v, want %v", err, ErrTaskNotFound)
+	}
+
+	if err := repo.Delete(testWorkspace, "test-id"); err != nil {
+		t.Errorf("Delete() unexpected error: %v", err)
+	}
+	if err := repo.Delete(testWorkspace, "test-id"); err != ErrTaskNotFound {
+		t.Errorf("Delete() error = %v, want %v", err, ErrTaskNotFound)
+	}
+	if len(mustGetAll(t, repo)) != 0 {
+		t.Errorf("GetAll() after delete = %v tasks, want 0", len(mustGetAll(t, repo)))
+	}
+}
+
+func TestFileTaskRepo_ReplayAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	repo := openTestFileRepo(t, dir)
+
+	for _, id := range []string{"1", "2", "3"} {
+		task := testutils.CreateTestTask()
+		task.ID = id
+		repo.Save(testWorkspace, task)
+	}
+	task := testutils.CreateTestTask()
+	task.Title = "Updated Title"
+	repo.Update(testWorkspace, "2", task)
+	repo.Delete(testWorkspace, "3")
+
+	// Simulate a crash: drop the repo without compacting
+	repo.wal.Close()
+
+	reopened := openTestFileRepo(t, dir)
+	defer reopened.Close()
+
+	if got := len(mustGetAll(t, reopened)); got != 2 {
+		t.Fatalf("GetAll() after replay = %v tasks, want 2", got)
+	}
+	retrieved, err := reopened.GetByID(testWorkspace, "2")
+	if err != nil {
+		t.Fatalf("GetByID() after replay unexpected error: %v", err)
+	}
+	if retrieved.Title != "Updated Title" {
+		t.Errorf("GetByID() after replay title = %v, want %v", retrieved.Title, "Updated Title")
+	}
+	if _, err := reopened.GetByID(testWorkspace, "3"); err != ErrTaskNotFound {
+		t.Errorf("GetByID() for deleted task error = %v, want %v", err, ErrTaskNotFound)
+	}
+}
+
+func TestFileTaskRepo_Compact(t *testing.T) {
+	dir := t.TempDir()
+	repo := openTestFileRepo(t, dir)
+
+	for _, id := range []string{"1", "2"} {
+		task := testutils.CreateTestTask()
+		task.ID = id
+		repo.Save(testWorkspace, task)
+	}
+	if err := repo.Compact(); err != nil {
+		t.Fatalf("Compact() unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, walFileName))
+	if err != nil {
+		t.Fatalf("Stat() wal unexpected error: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("wal size after compaction = %v, want 0", info.Size())
+	}
+
+	// Writes after compaction land in the fresh log
+	repo.Delete(testWorkspace, "1")
+	repo.wal.Close()
+
+	reopened := openTestFileRepo(t, dir)
+	defer reopened.Close()
+	tasks := mustGetAll(t, reopened)
+	if len(tasks) != 1 || tasks[0].ID != "2" {
+		t.Errorf("GetAll() after compaction and replay = %v, want only task 2", tasks)
+	}
+}
+
+func TestFileTaskRepo_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+	repo := openTestFileRepo(t, dir)
+	task := testutils.CreateTestTask()
+	task.ID = "1"
+	repo.Save(testWorkspace, task)
+	repo.wal.Close()
+
+	// Append half a record, as if the process died mid-write
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile() unexpected error: %v", err)
+	}
+	f.WriteString(`{"op":"save","id":"2","task":{"id":"2"`)
+	f.Close()
+
+	reopened := openTestFileRepo(t, dir)
+	if got := len(mustGetAll(t, reopened)); got != 1 {
+		t.Errorf("GetAll() after torn write = %v tasks, want 1", got)
+	}
+
+	// The torn record is trimmed so new records append cleanly
+	task.ID = "3"
+	reopened.Save(testWorkspace, task)
+	reopened.wal.Close()
+
+	again := openTestFileRepo(t, dir)
+	defer again.Close()
+	if got := len(mustGetAll(t, again)); got != 2 {
+		t.Errorf("GetAll() after trim and append = %v tasks, want 2", got)
+	}
+}
+
+// failingWAL wraps the open log, writing only half of the next record or
+// failing to sync it
+type failingWAL struct {
+	*os.File
+	failWrite, failSync bool
+}
+
+func (w *failingWAL) Write(p []byte) (int, error) {
+	if w.failWrite {
+		n, _ := w.File.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return w.File.Write(p)
+}
+
+func (w *failingWAL) Sync() error {
+	if w.failSync {
+		return errors.New("i/o error")
+	}
+	return w.File.Sync()
+}
+
+func TestFileTaskRepo_FailedAppend(t *testing.T) {
+	tests := []struct {
+		name string
+		wal  func(*os.File) walFile
+	}{
+		{"Partial write", func(f *os.File) walFile { return &failingWAL{File: f, failWrite: true} }},
+		{"Failed sync", func(f *os.File) walFile { return &failingWAL{File: f, failSync: true} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo := openTestFileRepo(t, dir)
+			task := testutils.CreateTestTask()
+			task.ID = "1"
+			if _, err := repo.Save(testWorkspace, task); err != nil {
+				t.Fatalf("Save() unexpected error: %v", err)
+			}
+
+			repo.wal = tt.wal(repo.wal.(*os.File))
+			task.ID = "2"
+			if _, err := repo.Save(testWorkspace, task); err == nil {
+				t.Fatal("Save() error = nil, want the wal error")
+			}
+
+			// The next append reopens the log and lands after the first record
+			task.ID = "3"
+			if _, err := repo.Save(testWorkspace, task); err != nil {
+				t.Fatalf("Save() after a failed append unexpected error: %v", err)
+			}
+			repo.wal.Close()
+
+			reopened, err := NewFileTaskRepo(dir, 0)
+			if err != nil {
+				t.Fatalf("NewFileTaskRepo() after a failed append unexpected error: %v", err)
+			}
+			defer reopened.Close()
+			if _, err := reopened.GetByID(testWorkspace, "2"); err != ErrTaskNotFound {
+				t.Errorf("GetByID() of the failed save error = %v, want %v", err, ErrTaskNotFound)
+			}
+			if got := len(mustGetAll(t, reopened)); got != 2 {
+				t.Errorf("GetAll() after replay = %v tasks, want 2", got)
+			}
+		})
+	}
+}
+
+func TestFileTaskRepo_CompareAndSwap(t *testing.T) {
+	repo := openTestFileRepo(t, t.TempDir())
+	defer repo.Close()
+	testTaskRepoCompareAndSwap(t, repo)
+}
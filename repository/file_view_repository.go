@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"taskmanager/models"
+)
+
+const viewsFileName = "views.json"
+
+// FileViewRepo is a ViewRepository that rewrites views.json on every change
+type FileViewRepo struct {
+	mem *InMemoryViewRepo
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileViewRepo opens (or creates) the view file in dir
+func NewFileViewRepo(dir string) (*FileViewRepo, error) {
+	r := &FileViewRepo{mem: NewInMemoryViewRepo(), dir: dir}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, viewsFileName))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read views: %w", err)
+	}
+	var views []models.View
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("decode views: %w", err)
+	}
+	for _, v := range views {
+		r.mem.views[v.ID] = v
+	}
+	return r, nil
+}
+
+func (r *FileViewRepo) ListViews(workspace, owner string) ([]models.View, error) {
+	return r.mem.ListViews(workspace, owner)
+}
+
+func (r *FileViewRepo) GetView(workspace, id string) (models.View, error) {
+	return r.mem.GetView(workspace, id)
+}
+
+func (r *FileViewRepo) SaveView(workspace string, view models.View) (models.View, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.staged()
+	saved, err := next.SaveView(workspace, view)
+	if err != nil {
+		return models.View{}, err
+	}
+	if err := r.commit(next); err != nil {
+		return models.View{}, err
+	}
+	return saved, nil
+}
+
+func (r *FileViewRepo) DeleteView(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.staged()
+	if err := next.DeleteView(workspace, id); err != nil {
+		return err
+	}
+	return r.commit(next)
+}
+
+// staged returns a copy of the stored views for a change to be made on
+// before it is written
+func (r *FileViewRepo) staged() *InMemoryViewRepo {
+	return &InMemoryViewRepo{views: cloneJSONMap(&r.mem.mu, r.mem.views)}
+}
+
+// commit writes staged to disk and then makes it the stored state
+func (r *FileViewRepo) commit(staged *InMemoryViewRepo) error {
+	if err := commitJSONMap(filepath.Join(r.dir, viewsFileName), &r.mem.mu, &r.mem.views, staged.views); err != nil {
+		return fmt.Errorf("write views: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/models"
+)
+
+const (
+	webhooksFileName         = "webhooks.json"
+	webhookDeliveriesLogName = "webhook_deliveries.log"
+)
+
+// FileWebhookRepo is a WebhookRepository that rewrites webhooks.json on every
+// subscription change and appends deliveries as JSON lines to
+// webhook_deliveries.log. Deliveries that are no longer kept are dropped from
+// the log when it is reopened.
+type FileWebhookRepo struct {
+	mem        *InMemoryWebhookRepo
+	mu         sync.Mutex
+	dir        string
+	deliveries *os.File
+}
+
+// NewFileWebhookRepo opens (or creates) the webhook files in dir
+func NewFileWebhookRepo(dir string) (*FileWebhookRepo, error) {
+	r := &FileWebhookRepo{mem: NewInMemoryWebhookRepo(), dir: dir}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, webhooksFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read webhooks: %w", err)
+	}
+	if err == nil {
+		var webhooks []models.Webhook
+		if err := json.Unmarshal(data, &webhooks); err != nil {
+			return nil, fmt.Errorf("decode webhooks: %w", err)
+		}
+		for _, w := range webhooks {
+			// Webhooks registered before workspaces existed belong to the default one
+			if w.Workspace == "" {
+				w.Workspace = constants.DefaultWorkspace
+			}
+			r.mem.webhooks[w.ID] = w
+		}
+	}
+
+	lines := 0
+	f, err := openJSONLines(dir, webhookDeliveriesLogName, func(line []byte) error {
+		var d models.WebhookDelivery
+		if err := json.Unmarshal(line, &d); err != nil {
+			return err
+		}
+		lines++
+		if _, ok := r.mem.webhooks[d.WebhookID]; ok {
+			r.mem.AppendDelivery(d)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.deliveries = f
+
+	kept := 0
+	for _, log := range r.mem.deliveries {
+		kept += len(log)
+	}
+	if kept < lines {
+		if err := r.compactDeliveries(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *FileWebhookRepo) ListWebhooks() ([]models.Webhook, error) {
+	return r.mem.ListWebhooks()
+}
+
+func (r *FileWebhookRepo) GetWebhook(id string) (models.Webhook, error) {
+	return r.mem.GetWebhook(id)
+}
+
+func (r *FileWebhookRepo) SaveWebhook(webhook models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mem.SaveWebhook(webhook)
+	return r.writeWebhooks()
+}
+
+func (r *FileWebhookRepo) DeleteWebhook(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.mem.DeleteWebhook(id); err != nil {
+		return err
+	}
+	return r.writeWebhooks()
+}
+
+func (r *FileWebhookRepo) AppendDelivery(delivery models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := appendJSONLine(r.deliveries, delivery); err != nil {
+		return err
+	}
+	return r.mem.AppendDelivery(delivery)
+}
+
+func (r *FileWebhookRepo) ListDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	return r.mem.ListDeliveries(webhookID, limit)
+}
+
+// Close closes the delivery log
+func (r *FileWebhookRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deliveries.Close()
+}
+
+func (r *FileWebhookRepo) writeWebhooks() error {
+	webhooks, _ := r.mem.ListWebhooks()
+	data, err := json.MarshalIndent(webhooks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode webhooks: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(r.dir, webhooksFileName), data); err != nil {
+		return fmt.Errorf("write webhooks: %w", err)
+	}
+	return nil
+}
+
+// compactDeliveries rewrites the delivery log with only the kept deliveries
+func (r *FileWebhookRepo) compactDeliveries() error {
+	var data []byte
+	webhooks, _ := r.mem.ListWebhooks()
+	for _, w := range webhooks {
+		for _, d := range r.mem.deliveries[w.ID] {
+			line, err := json.Marshal(d)
+			if err != nil {
+				return fmt.Errorf("encode delivery: %w", err)
+			}
+			data = append(append(data, line...), '\n')
+		}
+	}
+	path := filepath.Join(r.dir, webhookDeliveriesLogName)
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("compact delivery log: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open delivery log: %w", err)
+	}
+	r.deliveries.Close()
+	r.deliveries = f
+	return nil
+}
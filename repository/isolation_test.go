@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testWorkspace is the workspace tests store their tasks in
+const testWorkspace = "team-a"
+
+// testTaskRepoIsolation checks that no call confined to one workspace can
+// see or change the tasks of another
+func testTaskRepoIsolation(t *testing.T, repo TaskRepository) {
+	t.Helper()
+	now := time.Now()
+	ours := models.Task{ID: "ours", Title: "Ours", Status: constants.StatusPending, CreatedAt: now, UpdatedAt: now, Version: 1}
+	theirs := models.Task{ID: "theirs", Title: "Theirs", Status: constants.StatusPending, CreatedAt: now, UpdatedAt: now, Version: 1}
+
+	if saved, err := repo.Save(testWorkspace, ours); err != nil || saved.Workspace != testWorkspace {
+		t.Fatalf("Save() = %+v, %v, want the task stamped with %s", saved, err, testWorkspace)
+	}
+	if _, err := repo.Save("team-b", theirs); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	if all := mustGetAll(t, repo); len(all) != 1 || all[0].ID != "ours" {
+		t.Errorf("GetAll() = %+v, want only ours", all)
+	}
+	if page, err := repo.Query(testWorkspace, models.TaskQuery{}); err != nil || len(page.Tasks) != 1 || page.Tasks[0].ID != "ours" {
+		t.Errorf("Query() = %+v, %v, want only ours", page.Tasks, err)
+	}
+	if _, err := repo.GetByID(testWorkspace, "theirs"); err != ErrTaskNotFound {
+		t.Errorf("GetByID() across workspaces error = %v, want %v", err, ErrTaskNotFound)
+	}
+	theirs.Title = "Hijacked"
+	if _, err := repo.Update(testWorkspace, "theirs", theirs); err != ErrTaskNotFound {
+		t.Errorf("Update() across workspaces error = %v, want %v", err, ErrTaskNotFound)
+	}
+	if err := repo.Delete(testWorkspace, "theirs"); err != ErrTaskNotFound {
+		t.Errorf("Delete() across workspaces error = %v, want %v", err, ErrTaskNotFound)
+	}
+	if _, err := repo.Save(testWorkspace, theirs); err != ErrTaskIDTaken {
+		t.Errorf("Save() over another workspace's task error = %v, want %v", err, ErrTaskIDTaken)
+	}
+
+	stored, err := repo.GetByID("team-b", "theirs")
+	if err != nil || stored.Title != "Theirs" || stored.Workspace != "team-b" {
+		t.Errorf("GetByID() in its own workspace = %+v, %v, want it untouched", stored, err)
+	}
+	if workspaces, err := repo.Workspaces(); err != nil || !slices.Equal(workspaces, []string{testWorkspace, "team-b"}) {
+		t.Errorf("Workspaces() = %v, %v, want both", workspaces, err)
+	}
+}
+
+func TestInMemoryTaskRepo_Isolation(t *testing.T) {
+	testTaskRepoIsolation(t, NewInMemoryTaskRepo())
+}
+
+func TestFileTaskRepo_Isolation(t *testing.T) {
+	repo, err := NewFileTaskRepo(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewFileTaskRepo() unexpected error: %v", err)
+	}
+	defer repo.Close()
+	testTaskRepoIsolation(t, repo)
+}
+
+func TestSQLTaskRepo_Isolation(t *testing.T) {
+	testTaskRepoIsolation(t, openTestSQLRepo(t))
+}
+
+func TestFileTaskRepo_LegacyTasksJoinDefaultWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"op":"save","id":"old","task":{"id":"old","title":"Old","status":"Pending","version":1}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, walFileName), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewFileTaskRepo(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileTaskRepo() unexpected error: %v", err)
+	}
+	defer repo.Close()
+	if task, err := repo.GetByID(constants.DefaultWorkspace, "old"); err != nil || task.Workspace != constants.DefaultWorkspace {
+		t.Errorf("GetByID() = %+v, %v, want the task in the default workspace", task, err)
+	}
+}
+
+func TestSQLTaskRepo_LegacyTasksJoinDefaultWorkspace(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "tasks.db"))
+	// Stop just before workspaces were added
+	before := slices.IndexFunc(taskMigrations, func(m migration) bool { return m.name == "add workspaces" })
+	if err := migrate(db, taskMigrations[:before]); err != nil {
+		t.Fatalf("migrate() unexpected error: %v", err)
+	}
+	now := formatTime(time.Now())
+	if _, err := db.Exec(`INSERT INTO tasks (id, title, description, status, priority, created_at, updated_at, assigned_to, version)
+		VALUES ('old', 'Old', '', 'Pending', '', ?, ?, '', 1)`, now, now); err != nil {
+		t.Fatalf("insert legacy task: %v", err)
+	}
+
+	repo, err := NewSQLTaskRepo(db)
+	if err != nil {
+		t.Fatalf("NewSQLTaskRepo() unexpected error: %v", err)
+	}
+	if task, err := repo.GetByID(constants.DefaultWorkspace, "old"); err != nil || task.Workspace != constants.DefaultWorkspace {
+		t.Errorf("GetByID() = %+v, %v, want the task in the default workspace", task, err)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"encoding/json"
+	"maps"
+	"sort"
+	"sync"
+)
+
+// commitJSONMap writes the values of next to path as a JSON array ordered by
+// key and only then installs next as *current under mu. Callers build next
+// from a copy of *current, so a failed write leaves memory matching the file.
+func commitJSONMap[T any](path string, mu *sync.RWMutex, current *map[string]T, next map[string]T) error {
+	keys := make([]string, 0, len(next))
+	for key := range next {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]T, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, next[key])
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	mu.Lock()
+	*current = next
+	mu.Unlock()
+	return nil
+}
+
+// cloneJSONMap copies m under mu for a change to be staged on
+func cloneJSONMap[T any](mu *sync.RWMutex, m map[string]T) map[string]T {
+	mu.RLock()
+	defer mu.RUnlock()
+	return maps.Clone(m)
+}
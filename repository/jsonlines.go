@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// openJSONLines opens (or creates) an append-only log of JSON lines at
+// dir/name, passes every complete line to decode and leaves the file
+// positioned for appending. A torn final line left by a crash is trimmed.
+func openJSONLines(dir, name string, decode func(line []byte) error) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				if terr := f.Truncate(offset); terr != nil {
+					f.Close()
+					return nil, fmt.Errorf("truncate %s: %w", name, terr)
+				}
+			}
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		if err := decode(line); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("decode %s at offset %d: %w", name, offset, err)
+		}
+		offset += int64(len(line))
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// appendJSONLine writes v as one line to f and syncs it to disk
+func appendJSONLine(f *os.File, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode entry: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(f.Name()), err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", filepath.Base(f.Name()), err)
+	}
+	return nil
+}
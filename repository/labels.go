@@ -0,0 +1,59 @@
+package repository
+
+import "taskmanager/models"
+
+// labelIndex maps each label to the IDs of the tasks carrying it, so label
+// queries on the map-based repositories only visit tasks that can match.
+// Callers synchronize access.
+type labelIndex map[string]map[string]bool
+
+// update re-indexes task id, whose labels change from before to after
+func (ix labelIndex) update(id string, before, after []string) {
+	for _, label := range before {
+		delete(ix[label], id)
+		if len(ix[label]) == 0 {
+			delete(ix, label)
+		}
+	}
+	for _, label := range after {
+		if ix[label] == nil {
+			ix[label] = make(map[string]bool)
+		}
+		ix[label][id] = true
+	}
+}
+
+// candidates returns the tasks of workspace that can satisfy q's label
+// filters. Without a filter that needs a label every task is a candidate.
+// The result still has to be checked against q.
+func (ix labelIndex) candidates(tasks map[string]models.Task, workspace string, q models.TaskQuery) []models.Task {
+	var ids map[string]bool
+	switch {
+	case len(q.LabelsAll) > 0:
+		// Every match carries the rarest of the labels, so start there
+		rarest := q.LabelsAll[0]
+		for _, label := range q.LabelsAll[1:] {
+			if len(ix[label]) < len(ix[rarest]) {
+				rarest = label
+			}
+		}
+		ids = ix[rarest]
+	case len(q.LabelsAny) > 0:
+		ids = make(map[string]bool)
+		for _, label := range q.LabelsAny {
+			for id := range ix[label] {
+				ids[id] = true
+			}
+		}
+	default:
+		return tasksIn(tasks, workspace)
+	}
+
+	result := make([]models.Task, 0, len(ids))
+	for id := range ids {
+		if task := tasks[id]; task.Workspace == workspace {
+			result = append(result, task)
+		}
+	}
+	return result
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single versioned schema change. Statements run in order
+// inside one transaction together with the version bookkeeping.
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// taskMigrations evolves the task schema. Append new entries with the next
+// version number; never edit or reorder ones that have shipped.
+var taskMigrations = []migration{
+	{
+		version: 1,
+		name:    "create tasks table",
+		statements: []string{
+			`CREATE TABLE tasks (
+				id          TEXT PRIMARY KEY,
+				title       TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				status      TEXT NOT NULL,
+				priority    TEXT NOT NULL DEFAULT '',
+				due_date    TEXT,
+				created_at  TEXT NOT NULL,
+				updated_at  TEXT NOT NULL,
+				assigned_to TEXT NOT NULL DEFAULT ''
+			)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "index task lookup columns",
+		statements: []string{
+			`CREATE INDEX idx_tasks_status ON tasks (status)`,
+			`CREATE INDEX idx_tasks_assigned_to ON tasks (assigned_to)`,
+			`CREATE INDEX idx_tasks_due_date ON tasks (due_date)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "add task version",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 4,
+		name:    "create audit log",
+		statements: []string{
+			`CREATE TABLE audit_log (
+				seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+				id        TEXT NOT NULL UNIQUE,
+				task_id   TEXT NOT NULL,
+				action    TEXT NOT NULL,
+				actor     TEXT NOT NULL,
+				timestamp TEXT NOT NULL,
+				changes   TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_audit_log_task_id ON audit_log (task_id, timestamp)`,
+			`CREATE INDEX idx_audit_log_timestamp ON audit_log (timestamp)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "add task soft delete",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN deleted_at TEXT`,
+			`CREATE INDEX idx_tasks_deleted_at ON tasks (deleted_at)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "add task parent",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN parent_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX idx_tasks_parent_id ON tasks (parent_id)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "add task dependencies",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN blocked_by TEXT NOT NULL DEFAULT '[]'`,
+		},
+	},
+	{
+		version: 8,
+		name:    "add recurrence rules",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN rrule TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 9,
+		name:    "create reminders",
+		statements: []string{
+			`CREATE TABLE reminders (
+				key      TEXT PRIMARY KEY,
+				task_id  TEXT NOT NULL,
+				kind     TEXT NOT NULL,
+				due_date TEXT NOT NULL,
+				sent_at  TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "create webhooks",
+		statements: []string{
+			`CREATE TABLE webhooks (
+				id         TEXT PRIMARY KEY,
+				url        TEXT NOT NULL,
+				events     TEXT NOT NULL,
+				secret     TEXT NOT NULL,
+				active     INTEGER NOT NULL,
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			)`,
+			`CREATE TABLE webhook_deliveries (
+				seq         INTEGER PRIMARY KEY AUTOINCREMENT,
+				id          TEXT NOT NULL UNIQUE,
+				webhook_id  TEXT NOT NULL,
+				event_id    TEXT NOT NULL,
+				event_type  TEXT NOT NULL,
+				attempt     INTEGER NOT NULL,
+				success     INTEGER NOT NULL,
+				status_code INTEGER NOT NULL,
+				error       TEXT NOT NULL,
+				duration_ms INTEGER NOT NULL,
+				timestamp   TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, seq)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "add workspaces",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN workspace TEXT NOT NULL DEFAULT 'default'`,
+			`CREATE INDEX idx_tasks_workspace ON tasks (workspace, created_at)`,
+			`ALTER TABLE audit_log ADD COLUMN workspace TEXT NOT NULL DEFAULT 'default'`,
+			`CREATE INDEX idx_audit_log_workspace ON audit_log (workspace, timestamp)`,
+			`ALTER TABLE webhooks ADD COLUMN workspace TEXT NOT NULL DEFAULT 'default'`,
+		},
+	},
+	{
+		version: 12,
+		name:    "add projects",
+		statements: []string{
+			`CREATE TABLE projects (
+				id          TEXT PRIMARY KEY,
+				workspace   TEXT NOT NULL,
+				name        TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				created_at  TEXT NOT NULL,
+				updated_at  TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_projects_workspace ON projects (workspace, created_at)`,
+			`ALTER TABLE tasks ADD COLUMN project_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX idx_tasks_project ON tasks (workspace, project_id)`,
+		},
+	},
+	{
+		version: 13,
+		name:    "add labels",
+		statements: []string{
+			`ALTER TABLE tasks ADD COLUMN labels TEXT NOT NULL DEFAULT '[]'`,
+			// task_labels indexes the labels column for label queries
+			`CREATE TABLE task_labels (
+				task_id TEXT NOT NULL,
+				label   TEXT NOT NULL,
+				PRIMARY KEY (task_id, label)
+			)`,
+			`CREATE INDEX idx_task_labels_label ON task_labels (label, task_id)`,
+		},
+	},
+	{
+		version: 14,
+		name:    "add comments",
+		statements: []string{
+			`CREATE TABLE comments (
+				id         TEXT PRIMARY KEY,
+				workspace  TEXT NOT NULL,
+				task_id    TEXT NOT NULL,
+				author     TEXT NOT NULL,
+				body       TEXT NOT NULL,
+				edits      TEXT NOT NULL DEFAULT '[]',
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_comments_task ON comments (workspace, task_id, created_at)`,
+		},
+	},
+	{
+		version: 15,
+		name:    "add attachments",
+		statements: []string{
+			`CREATE TABLE attachments (
+				id           TEXT PRIMARY KEY,
+				workspace    TEXT NOT NULL,
+				task_id      TEXT NOT NULL,
+				filename     TEXT NOT NULL,
+				content_type TEXT NOT NULL,
+				size         INTEGER NOT NULL,
+				sha256       TEXT NOT NULL,
+				uploaded_by  TEXT NOT NULL,
+				created_at   TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_attachments_task ON attachments (workspace, task_id, created_at)`,
+			`CREATE INDEX idx_attachments_sha256 ON attachments (sha256)`,
+		},
+	},
+	{
+		version: 16,
+		name:    "add views",
+		statements: []string{
+			`CREATE TABLE views (
+				id         TEXT PRIMARY KEY,
+				workspace  TEXT NOT NULL,
+				name       TEXT NOT NULL,
+				owner      TEXT NOT NULL,
+				filter     TEXT NOT NULL,
+				sort       TEXT NOT NULL,
+				columns    TEXT NOT NULL,
+				shared     INTEGER NOT NULL,
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_views_owner ON views (workspace, owner)`,
+		},
+	},
+}
+
+// migrate brings the database schema up to date by applying every migration
+// newer than the version recorded in schema_migrations.
+func migrate(db *sql.DB, migrations []migration) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, formatTime(time.Now()),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
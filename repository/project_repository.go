@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+var ErrProjectNotFound = errors.NewNotFoundError("Project")
+
+// ProjectRepository stores projects. Like tasks, projects are partitioned by
+// workspace: every call is confined to the workspace it is given, and
+// SaveProject refuses to overwrite a project that belongs to another one.
+type ProjectRepository interface {
+	// ListProjects returns the workspace's projects, oldest first
+	ListProjects(workspace string) ([]models.Project, error)
+	GetProject(workspace, id string) (models.Project, error)
+	SaveProject(workspace string, project models.Project) (models.Project, error)
+	DeleteProject(workspace, id string) error
+}
+
+type InMemoryProjectRepo struct {
+	projects map[string]models.Project
+	mu       sync.RWMutex
+}
+
+func NewInMemoryProjectRepo() *InMemoryProjectRepo {
+	return &InMemoryProjectRepo{
+		projects: make(map[string]models.Project),
+	}
+}
+
+func (r *InMemoryProjectRepo) ListProjects(workspace string) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	projects := []models.Project{}
+	for _, p := range r.projects {
+		if p.Workspace == workspace {
+			projects = append(projects, p)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if !projects[i].CreatedAt.Equal(projects[j].CreatedAt) {
+			return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects, nil
+}
+
+func (r *InMemoryProjectRepo) GetProject(workspace, id string) (models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.projects[id]
+	if !ok || p.Workspace != workspace {
+		return models.Project{}, ErrProjectNotFound
+	}
+	return p, nil
+}
+
+func (r *InMemoryProjectRepo) SaveProject(workspace string, project models.Project) (models.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.projects[project.ID]; ok && stored.Workspace != workspace {
+		return models.Project{}, ErrProjectNotFound
+	}
+	project.Workspace = workspace
+	r.projects[project.ID] = project
+	return project, nil
+}
+
+func (r *InMemoryProjectRepo) DeleteProject(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.projects[id]; !ok || p.Workspace != workspace {
+		return ErrProjectNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testProjectRepo exercises the ProjectRepository contract against repo
+func testProjectRepo(t *testing.T, repo ProjectRepository) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := models.Project{ID: "a", Name: "Launch", Description: "Ship it", CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)}
+	b := models.Project{ID: "b", Name: "Backlog", CreatedAt: base, UpdatedAt: base}
+	other := models.Project{ID: "other", Name: "Elsewhere", CreatedAt: base, UpdatedAt: base}
+	for _, p := range []models.Project{a, b} {
+		if _, err := repo.SaveProject(testWorkspace, p); err != nil {
+			t.Fatalf("SaveProject() unexpected error: %v", err)
+		}
+	}
+	if _, err := repo.SaveProject("team-b", other); err != nil {
+		t.Fatalf("SaveProject() unexpected error: %v", err)
+	}
+
+	projects, err := repo.ListProjects(testWorkspace)
+	if err != nil {
+		t.Fatalf("ListProjects() unexpected error: %v", err)
+	}
+	if len(projects) != 2 || projects[0].ID != "b" || projects[1].ID != "a" {
+		t.Fatalf("ListProjects() = %+v, want b then a", projects)
+	}
+	got, err := repo.GetProject(testWorkspace, "a")
+	if err != nil || got.Name != a.Name || got.Description != a.Description || got.Workspace != testWorkspace ||
+		!got.CreatedAt.Equal(a.CreatedAt) || !got.UpdatedAt.Equal(a.UpdatedAt) {
+		t.Errorf("GetProject() = %+v, %v, want %+v", got, err, a)
+	}
+
+	// Projects in other workspaces are invisible and cannot be overwritten
+	if _, err := repo.GetProject(testWorkspace, "other"); err != ErrProjectNotFound {
+		t.Errorf("GetProject() across workspaces error = %v, want %v", err, ErrProjectNotFound)
+	}
+	if _, err := repo.SaveProject(testWorkspace, other); err != ErrProjectNotFound {
+		t.Errorf("SaveProject() across workspaces error = %v, want %v", err, ErrProjectNotFound)
+	}
+	if err := repo.DeleteProject(testWorkspace, "other"); err != ErrProjectNotFound {
+		t.Errorf("DeleteProject() across workspaces error = %v, want %v", err, ErrProjectNotFound)
+	}
+	if got, _ := repo.GetProject("team-b", "other"); got.Name != other.Name {
+		t.Errorf("GetProject() in its own workspace = %+v, want it unchanged", got)
+	}
+
+	a.Name = "Launch v2"
+	repo.SaveProject(testWorkspace, a)
+	if got, _ := repo.GetProject(testWorkspace, "a"); got.Name != a.Name {
+		t.Errorf("GetProject() after update = %+v, want %+v", got, a)
+	}
+
+	if err := repo.DeleteProject(testWorkspace, "b"); err != nil {
+		t.Fatalf("DeleteProject() unexpected error: %v", err)
+	}
+	if err := repo.DeleteProject(testWorkspace, "b"); err != ErrProjectNotFound {
+		t.Errorf("DeleteProject() twice error = %v, want %v", err, ErrProjectNotFound)
+	}
+}
+
+func TestInMemoryProjectRepo(t *testing.T) {
+	testProjectRepo(t, NewInMemoryProjectRepo())
+}
+
+func TestFileProjectRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileProjectRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileProjectRepo() unexpected error: %v", err)
+	}
+	testProjectRepo(t, repo)
+
+	reopened, err := NewFileProjectRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileProjectRepo() reopen unexpected error: %v", err)
+	}
+	if projects, _ := reopened.ListProjects(testWorkspace); len(projects) != 1 || projects[0].Name != "Launch v2" {
+		t.Errorf("ListProjects() after reopen = %+v, want the updated project a", projects)
+	}
+}
+
+func TestSQLProjectRepo(t *testing.T) {
+	repo, err := NewSQLProjectRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLProjectRepo() unexpected error: %v", err)
+	}
+	testProjectRepo(t, repo)
+}
+
+func TestFileProjectRepo_FailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileProjectRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileProjectRepo() unexpected error: %v", err)
+	}
+	if _, err := repo.SaveProject(testWorkspace, models.Project{ID: "a", Name: "Launch"}); err != nil {
+		t.Fatalf("SaveProject() unexpected error: %v", err)
+	}
+
+	// A directory in the file's place makes every rewrite fail
+	path := filepath.Join(dir, projectsFileName)
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() unexpected error: %v", err)
+	}
+	if _, err := repo.SaveProject(testWorkspace, models.Project{ID: "b", Name: "Docs"}); err == nil {
+		t.Error("SaveProject() error = nil, want the write error")
+	}
+	if err := repo.DeleteProject(testWorkspace, "a"); err == nil {
+		t.Error("DeleteProject() error = nil, want the write error")
+	}
+	if projects, _ := repo.ListProjects(testWorkspace); len(projects) != 1 || projects[0].ID != "a" {
+		t.Errorf("ListProjects() after failed writes = %+v, want only project a", projects)
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+var (
+	ErrInvalidCursor = errors.NewValidationError("cursor", constants.ValidationInvalidCursor)
+)
+
+// statusRank and priorityRank order enum fields by meaning rather than by name
+var (
+	statusRank = map[string]string{
+		constants.StatusPending:    "1",
+		constants.StatusInProgress: "2",
+		constants.StatusCompleted:  "3",
+		constants.StatusCancelled:  "4",
+	}
+	priorityRank = map[string]string{
+		constants.PriorityLow:    "1",
+		constants.PriorityMedium: "2",
+		constants.PriorityHigh:   "3",
+	}
+)
+
+// cursor marks the last task of a page. It is bound to the sort order it was
+// produced for so it cannot be replayed against a different one.
+type cursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d"`
+	Key    string `json:"k"`
+	ID     string `json:"i"`
+}
+
+func encodeCursor(q models.TaskQuery, task models.Task) string {
+	data, _ := json.Marshal(cursor{SortBy: q.SortBy, Desc: q.SortDesc, Key: sortKey(task, q.SortBy), ID: task.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(q models.TaskQuery) (*cursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortBy != q.SortBy || c.Desc != q.SortDesc {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// sortKey renders the value task is ordered by as a string whose byte order
+// matches the intended order. The SQL repository sorts on equivalent
+// expressions (see sqlSortExpr) so both stores page identically.
+func sortKey(task models.Task, field string) string {
+	switch field {
+	case models.SortByID:
+		return task.ID
+	case models.SortByTitle:
+		return task.Title
+	case models.SortByStatus:
+		return rankOrZero(statusRank, task.Status)
+	case models.SortByPriority:
+		return rankOrZero(priorityRank, task.Priority)
+	case models.SortByDueDate:
+		if task.DueDate == nil {
+			return ""
+		}
+		return formatTime(*task.DueDate)
+	case models.SortByUpdatedAt:
+		return formatTime(task.UpdatedAt)
+	case models.SortByAssignedTo:
+		return task.AssignedTo
+	case models.SortByDeletedAt:
+		if task.DeletedAt == nil {
+			return ""
+		}
+		return formatTime(*task.DeletedAt)
+	default:
+		return formatTime(task.CreatedAt)
+	}
+}
+
+func rankOrZero(ranks map[string]string, value string) string {
+	if rank, ok := ranks[value]; ok {
+		return rank
+	}
+	return "0"
+}
+
+// queryTasks filters, sorts and pages tasks in memory. It backs the map-based
+// repositories.
+func queryTasks(tasks []models.Task, q models.TaskQuery) (models.TaskPage, error) {
+	q.Normalize()
+	after, err := decodeCursor(q)
+	if err != nil {
+		return models.TaskPage{}, err
+	}
+
+	type keyed struct {
+		key  string
+		task models.Task
+	}
+	matched := make([]keyed, 0, len(tasks))
+	for _, task := range tasks {
+		if q.Matches(task) {
+			matched = append(matched, keyed{key: sortKey(task, q.SortBy), task: task})
+		}
+	}
+
+	less := func(aKey, aID, bKey, bID string) bool {
+		if aKey != bKey {
+			return (aKey < bKey) != q.SortDesc
+		}
+		if aID != bID {
+			return (aID < bID) != q.SortDesc
+		}
+		return false
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[i].key, matched[i].task.ID, matched[j].key, matched[j].task.ID)
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return less(after.Key, after.ID, matched[i].key, matched[i].task.ID)
+		})
+	}
+
+	page := models.TaskPage{Tasks: make([]models.Task, 0, q.Limit)}
+	for i := start; i < len(matched) && len(page.Tasks) < q.Limit; i++ {
+		page.Tasks = append(page.Tasks, matched[i].task)
+	}
+	if len(page.Tasks) > 0 && start+len(page.Tasks) < len(matched) {
+		page.NextCursor = encodeCursor(q, page.Tasks[len(page.Tasks)-1])
+	}
+	return page, nil
+}
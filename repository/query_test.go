@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+// seedQueryTasks stores five tasks with distinct, predictable field values
+func seedQueryTasks(t *testing.T, repo TaskRepository) time.Time {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		id       string
+		status   string
+		priority string
+		assignee string
+		dueDays  int // 0 means no due date
+		project  string
+		labels   []string
+	}{
+		{"a", constants.StatusPending, constants.PriorityHigh, "alice@example.com", 3, "p1", []string{"bug", "frontend"}},
+		{"b", constants.StatusInProgress, constants.PriorityLow, "bob@example.com", 1, "", []string{"frontend"}},
+		{"c", constants.StatusPending, constants.PriorityMedium, "alice@example.com", 0, "p2", nil},
+		{"d", constants.StatusCompleted, constants.PriorityHigh, "bob@example.com", 2, "p1", []string{"bug", "q3"}},
+		{"e", constants.StatusPending, "", "alice@example.com", 5, "", []string{"q3"}},
+	}
+	for i, f := range fixtures {
+		task := testutils.CreateTestTask()
+		task.ID = f.id
+		task.Status = f.status
+		task.Priority = f.priority
+		task.AssignedTo = f.assignee
+		task.ProjectID = f.project
+		task.Labels = f.labels
+		task.DueDate = nil
+		if f.dueDays > 0 {
+			due := base.AddDate(0, 0, f.dueDays)
+			task.DueDate = &due
+		}
+		task.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		task.UpdatedAt = task.CreatedAt
+		if _, err := repo.Save(testWorkspace, task); err != nil {
+			t.Fatalf("Save() unexpected error: %v", err)
+		}
+	}
+	return base
+}
+
+func taskIDs(tasks []models.Task) string {
+	ids := ""
+	for _, task := range tasks {
+		ids += task.ID
+	}
+	return ids
+}
+
+// testTaskRepoQuery exercises Query identically against any repository so
+// every store is held to the same ordering and paging semantics.
+func testTaskRepoQuery(t *testing.T, newRepo func(t *testing.T) TaskRepository) {
+	repo := newRepo(t)
+	base := seedQueryTasks(t, repo)
+	at := func(days int) *time.Time {
+		ts := base.AddDate(0, 0, days)
+		return &ts
+	}
+	hours := func(h int) *time.Time {
+		ts := base.Add(time.Duration(h) * time.Hour)
+		return &ts
+	}
+
+	tests := []struct {
+		name    string
+		query   models.TaskQuery
+		wantIDs string
+	}{
+		{"Default order is createdAt", models.TaskQuery{}, "abcde"},
+		{"Filter by status", models.TaskQuery{Status: constants.StatusPending}, "ace"},
+		{"Filter by priority", models.TaskQuery{Priority: constants.PriorityHigh}, "ad"},
+		{"Filter by assignee", models.TaskQuery{AssignedTo: "bob@example.com"}, "bd"},
+		{"Filter by project", models.TaskQuery{ProjectID: "p1"}, "ad"},
+		{"Any of labels", models.TaskQuery{LabelsAny: []string{"bug", "q3"}}, "ade"},
+		{"All of labels", models.TaskQuery{LabelsAll: []string{"frontend", "bug"}}, "a"},
+		{"None of labels", models.TaskQuery{LabelsNone: []string{"bug"}}, "bce"},
+		{"Unused label", models.TaskQuery{LabelsAll: []string{"bug", "backend"}}, ""},
+		{"Combined label filters", models.TaskQuery{LabelsAny: []string{"frontend", "q3"}, LabelsNone: []string{"bug"}}, "be"},
+		{"Due range excludes undated", models.TaskQuery{DueAfter: at(2), DueBefore: at(5)}, "ad"},
+		{"Created range", models.TaskQuery{CreatedAfter: hours(1), CreatedBefore: hours(3)}, "bc"},
+		{"Updated lower bound", models.TaskQuery{UpdatedAfter: hours(4)}, "e"},
+		{"Sort by id descending", models.TaskQuery{SortBy: models.SortByID, SortDesc: true}, "edcba"},
+		{"Sort by due date puts undated first", models.TaskQuery{SortBy: models.SortByDueDate}, "cbdae"},
+		{"Sort by priority by rank", models.TaskQuery{SortBy: models.SortByPriority}, "ebcad"},
+		{"Sort by status by rank", models.TaskQuery{SortBy: models.SortByStatus, SortDesc: true}, "dbeca"},
+		{"Combined filters", models.TaskQuery{Status: constants.StatusPending, AssignedTo: "alice@example.com", Priority: constants.PriorityMedium}, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.Query(testWorkspace, tt.query)
+			if err != nil {
+				t.Fatalf("Query() unexpected error: %v", err)
+			}
+			if got := taskIDs(page.Tasks); got != tt.wantIDs {
+				t.Errorf("Query() = %v, want %v", got, tt.wantIDs)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("Query() nextCursor = %q, want none", page.NextCursor)
+			}
+		})
+	}
+
+	t.Run("Filter expressions", func(t *testing.T) {
+		tests := []struct {
+			filter  string
+			query   models.TaskQuery
+			wantIDs string
+		}{
+			{"status:Pending AND priority:High", models.TaskQuery{}, "a"},
+			{"priority>=Medium", models.TaskQuery{}, "acd"},
+			{"NOT assignedTo:bob@example.com", models.TaskQuery{}, "ace"},
+			{"due<2024-01-04", models.TaskQuery{}, "bd"},
+			{"NOT due<2024-01-04", models.TaskQuery{}, "ace"},
+			{"due:2024-01-03", models.TaskQuery{}, "d"},
+			{"label:bug OR label:q3", models.TaskQuery{}, "ade"},
+			{"label!=frontend AND status!=Completed", models.TaskQuery{}, "ce"},
+			{"(status:InProgress OR project:p2) AND NOT label:bug", models.TaskQuery{}, "bc"},
+			{"created>=2024-01-01T02:00:00Z", models.TaskQuery{SortBy: models.SortByID, SortDesc: true}, "edc"},
+			{"label:q3", models.TaskQuery{Status: constants.StatusPending}, "e"},
+		}
+		for _, tt := range tests {
+			f, err := models.ParseTaskFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseTaskFilter(%q) unexpected error: %v", tt.filter, err)
+			}
+			tt.query.Filter = f
+			page, err := repo.Query(testWorkspace, tt.query)
+			if err != nil {
+				t.Fatalf("%s: Query() unexpected error: %v", tt.filter, err)
+			}
+			if got := taskIDs(page.Tasks); got != tt.wantIDs {
+				t.Errorf("%s: Query() = %v, want %v", tt.filter, got, tt.wantIDs)
+			}
+		}
+	})
+
+	t.Run("Cursor pagination", func(t *testing.T) {
+		for _, desc := range []bool{false, true} {
+			query := models.TaskQuery{SortBy: models.SortByPriority, SortDesc: desc, Limit: 2}
+			got := ""
+			pages := 0
+			for {
+				page, err := repo.Query(testWorkspace, query)
+				if err != nil {
+					t.Fatalf("Query() unexpected error: %v", err)
+				}
+				got += taskIDs(page.Tasks)
+				pages++
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			want := "ebcad"
+			if desc {
+				want = "dacbe"
+			}
+			if got != want || pages != 3 {
+				t.Errorf("paged Query(desc=%v) = %v in %v pages, want %v in 3", desc, got, pages, want)
+			}
+		}
+	})
+
+	t.Run("Cursor bound to sort order", func(t *testing.T) {
+		page, _ := repo.Query(testWorkspace, models.TaskQuery{Limit: 1})
+		_, err := repo.Query(testWorkspace, models.TaskQuery{SortBy: models.SortByTitle, Cursor: page.NextCursor})
+		if err != ErrInvalidCursor {
+			t.Errorf("Query() with mismatched cursor error = %v, want %v", err, ErrInvalidCursor)
+		}
+		_, err = repo.Query(testWorkspace, models.TaskQuery{Cursor: "not a cursor"})
+		if err != ErrInvalidCursor {
+			t.Errorf("Query() with garbage cursor error = %v, want %v", err, ErrInvalidCursor)
+		}
+	})
+
+	t.Run("Parent and blockers", func(t *testing.T) {
+		task, _ := repo.GetByID(testWorkspace, "c")
+		task.ParentID = "a"
+		task.BlockedBy = []string{"b", "d"}
+		if _, err := repo.Update(testWorkspace, "c", task); err != nil {
+			t.Fatalf("Update() unexpected error: %v", err)
+		}
+		if stored, _ := repo.GetByID(testWorkspace, "c"); len(stored.BlockedBy) != 2 || stored.BlockedBy[1] != "d" {
+			t.Errorf("GetByID() blockedBy = %v, want [b d]", stored.BlockedBy)
+		}
+		page, err := repo.Query(testWorkspace, models.TaskQuery{ParentID: "a"})
+		if err != nil {
+			t.Fatalf("Query() unexpected error: %v", err)
+		}
+		if got := taskIDs(page.Tasks); got != "c" || page.Tasks[0].ParentID != "a" {
+			t.Errorf("Query(parentId=a) = %v, want c", got)
+		}
+	})
+
+	t.Run("Labels follow writes", func(t *testing.T) {
+		task, _ := repo.GetByID(testWorkspace, "c")
+		task.Labels = []string{"backend"}
+		if _, err := repo.Update(testWorkspace, "c", task); err != nil {
+			t.Fatalf("Update() unexpected error: %v", err)
+		}
+		task, _ = repo.GetByID(testWorkspace, "a")
+		task.Labels = []string{"backend", "frontend"}
+		if _, err := repo.Update(testWorkspace, "a", task); err != nil {
+			t.Fatalf("Update() unexpected error: %v", err)
+		}
+		if stored, _ := repo.GetByID(testWorkspace, "a"); len(stored.Labels) != 2 || stored.Labels[0] != "backend" {
+			t.Errorf("GetByID() labels = %v, want [backend frontend]", stored.Labels)
+		}
+		for want, q := range map[string]models.TaskQuery{
+			"ac": {LabelsAll: []string{"backend"}},
+			"d":  {LabelsAny: []string{"bug"}},
+		} {
+			page, _ := repo.Query(testWorkspace, q)
+			if got := taskIDs(page.Tasks); got != want {
+				t.Errorf("Query(%+v) = %v, want %v", q, got, want)
+			}
+		}
+	})
+
+	t.Run("Recurrence rule", func(t *testing.T) {
+		task, _ := repo.GetByID(testWorkspace, "e")
+		task.RRule = "FREQ=WEEKLY;BYDAY=MO"
+		if _, err := repo.Update(testWorkspace, "e", task); err != nil {
+			t.Fatalf("Update() unexpected error: %v", err)
+		}
+		if stored, _ := repo.GetByID(testWorkspace, "e"); stored.RRule != task.RRule {
+			t.Errorf("GetByID() rrule = %q, want %q", stored.RRule, task.RRule)
+		}
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		// Runs last because it moves b and d to the trash
+		for i, id := range []string{"b", "d"} {
+			task, _ := repo.GetByID(testWorkspace, id)
+			deletedAt := base.AddDate(0, 1, i)
+			task.DeletedAt = &deletedAt
+			if _, err := repo.Update(testWorkspace, id, task); err != nil {
+				t.Fatalf("Update() unexpected error: %v", err)
+			}
+		}
+		if task, _ := repo.GetByID(testWorkspace, "d"); task.DeletedAt == nil || !task.DeletedAt.Equal(base.AddDate(0, 1, 1)) {
+			t.Errorf("GetByID() deletedAt = %v, want %v", task.DeletedAt, base.AddDate(0, 1, 1))
+		}
+
+		cutoff := base.AddDate(0, 1, 1)
+		tests := []struct {
+			name  string
+			query models.TaskQuery
+			want  string
+		}{
+			{"Live tasks only", models.TaskQuery{}, "ace"},
+			{"Trash", models.TaskQuery{Deleted: true, SortBy: models.SortByDeletedAt, SortDesc: true}, "db"},
+			{"Deleted before", models.TaskQuery{Deleted: true, DeletedBefore: &cutoff}, "b"},
+		}
+		for _, tt := range tests {
+			page, err := repo.Query(testWorkspace, tt.query)
+			if err != nil {
+				t.Fatalf("%s: Query() unexpected error: %v", tt.name, err)
+			}
+			if got := taskIDs(page.Tasks); got != tt.want {
+				t.Errorf("%s: Query() = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestInMemoryTaskRepo_Query(t *testing.T) {
+	testTaskRepoQuery(t, func(t *testing.T) TaskRepository { return NewInMemoryTaskRepo() })
+}
+
+func TestFileTaskRepo_Query(t *testing.T) {
+	testTaskRepoQuery(t, func(t *testing.T) TaskRepository {
+		repo := openTestFileRepo(t, t.TempDir())
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func TestSQLTaskRepo_Query(t *testing.T) {
+	testTaskRepoQuery(t, func(t *testing.T) TaskRepository { return openTestSQLRepo(t) })
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"sync"
+	"taskmanager/models"
+)
+
+// ReminderRepository remembers which reminders have been sent so that each
+// one fires only once, even across restarts
+type ReminderRepository interface {
+	Sent(key string) (bool, error)
+	Record(reminder models.Reminder) error
+}
+
+type InMemoryReminderRepo struct {
+	sent map[string]models.Reminder
+	mu   sync.RWMutex
+}
+
+func NewInMemoryReminderRepo() *InMemoryReminderRepo {
+	return &InMemoryReminderRepo{sent: make(map[string]models.Reminder)}
+}
+
+func (r *InMemoryReminderRepo) Sent(key string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.sent[key]
+	return ok, nil
+}
+
+func (r *InMemoryReminderRepo) Record(reminder models.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[reminder.Key()] = reminder
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"path/filepath"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testReminderRepo exercises the ReminderRepository contract against repo
+func testReminderRepo(t *testing.T, repo ReminderRepository) {
+	t.Helper()
+	due := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	soon := models.Reminder{TaskID: "a", Kind: models.ReminderDueSoon, DueDate: due, SentAt: due.Add(-time.Hour)}
+	overdue := models.Reminder{TaskID: "a", Kind: models.ReminderOverdue, DueDate: due}
+
+	if sent, err := repo.Sent(soon.Key()); err != nil || sent {
+		t.Fatalf("Sent() before Record = %v, %v, want false", sent, err)
+	}
+	if err := repo.Record(soon); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	// Recording the same reminder twice is harmless
+	if err := repo.Record(soon); err != nil {
+		t.Fatalf("Record() again unexpected error: %v", err)
+	}
+	if sent, _ := repo.Sent(soon.Key()); !sent {
+		t.Error("Sent() after Record = false, want true")
+	}
+	if sent, _ := repo.Sent(overdue.Key()); sent {
+		t.Error("Sent() for another kind = true, want false")
+	}
+}
+
+func TestInMemoryReminderRepo(t *testing.T) {
+	testReminderRepo(t, NewInMemoryReminderRepo())
+}
+
+func TestFileReminderRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileReminderRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileReminderRepo() unexpected error: %v", err)
+	}
+	testReminderRepo(t, repo)
+	repo.Close()
+
+	reopened, err := NewFileReminderRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileReminderRepo() reopen unexpected error: %v", err)
+	}
+	defer reopened.Close()
+	key := models.Reminder{TaskID: "a", Kind: models.ReminderDueSoon, DueDate: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}.Key()
+	if sent, _ := reopened.Sent(key); !sent {
+		t.Error("Sent() after reopen = false, want the reminder remembered")
+	}
+}
+
+func TestSQLReminderRepo(t *testing.T) {
+	repo, err := NewSQLReminderRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLReminderRepo() unexpected error: %v", err)
+	}
+	testReminderRepo(t, repo)
+}
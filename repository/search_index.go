@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"taskmanager/models"
+	"taskmanager/search"
+)
+
+// TaskSearcher runs full-text searches over the live tasks of a workspace
+type TaskSearcher interface {
+	Search(workspace string, q search.Query, limit int) []search.Hit
+}
+
+// IndexedTaskRepo wraps a TaskRepository and keeps a full-text index of its
+// live tasks in step with every write. Trashed tasks are left out of the
+// index and come back when restored.
+type IndexedTaskRepo struct {
+	TaskRepository
+	index *search.Index
+	// mu orders writes so the index sees them in the order the store did
+	mu sync.Mutex
+}
+
+// NewIndexedTaskRepo indexes every task already in repo
+func NewIndexedTaskRepo(repo TaskRepository) (*IndexedTaskRepo, error) {
+	r := &IndexedTaskRepo{TaskRepository: repo, index: search.NewIndex()}
+	workspaces, err := repo.Workspaces()
+	if err != nil {
+		return nil, fmt.Errorf("index tasks: %w", err)
+	}
+	for _, ws := range workspaces {
+		tasks, err := repo.GetAll(ws)
+		if err != nil {
+			return nil, fmt.Errorf("index tasks: %w", err)
+		}
+		for _, task := range tasks {
+			r.reindex(ws, task)
+		}
+	}
+	return r, nil
+}
+
+func (r *IndexedTaskRepo) Save(workspace string, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved, err := r.TaskRepository.Save(workspace, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.reindex(workspace, saved)
+	return saved, nil
+}
+
+func (r *IndexedTaskRepo) Update(workspace, id string, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	updated, err := r.TaskRepository.Update(workspace, id, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.reindex(workspace, updated)
+	return updated, nil
+}
+
+func (r *IndexedTaskRepo) Delete(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.TaskRepository.Delete(workspace, id); err != nil {
+		return err
+	}
+	r.index.Remove(workspace, id)
+	return nil
+}
+
+func (r *IndexedTaskRepo) Search(workspace string, q search.Query, limit int) []search.Hit {
+	return r.index.Search(workspace, q, limit)
+}
+
+func (r *IndexedTaskRepo) reindex(workspace string, task models.Task) {
+	if task.IsDeleted() {
+		r.index.Remove(workspace, task.ID)
+		return
+	}
+	r.index.Put(workspace, search.Document{ID: task.ID, Title: task.Title, Description: task.Description})
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"path/filepath"
+	"taskmanager/search"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+// searchIDs returns the IDs of the tasks in workspace matching q
+func searchIDs(t *testing.T, repo TaskSearcher, workspace, q string) []string {
+	t.Helper()
+	query, err := search.Parse(q)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", q, err)
+	}
+	var ids []string
+	for _, hit := range repo.Search(workspace, query, 10) {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestIndexedTaskRepo(t *testing.T) {
+	inner := openTestDB(t, filepath.Join(t.TempDir(), "tasks.db"))
+	sqlRepo, err := NewSQLTaskRepo(inner)
+	if err != nil {
+		t.Fatalf("NewSQLTaskRepo() error = %v", err)
+	}
+	existing := testutils.CreateTestTask()
+	existing.ID = "existing"
+	existing.Title = "Rotate the certificates"
+	if _, err := sqlRepo.Save(testWorkspace, existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	trashed := testutils.CreateTestTask()
+	trashed.ID = "trashed"
+	trashed.Title = "Rotate old keys"
+	now := time.Now()
+	trashed.DeletedAt = &now
+	if _, err := sqlRepo.Save(testWorkspace, trashed); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	repo, err := NewIndexedTaskRepo(sqlRepo)
+	if err != nil {
+		t.Fatalf("NewIndexedTaskRepo() error = %v", err)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "rotating"); len(got) != 1 || got[0] != "existing" {
+		t.Fatalf("existing tasks not indexed, got %v", got)
+	}
+
+	task := testutils.CreateTestTask()
+	task.ID = "new"
+	task.Title = "Upgrade the database"
+	saved, err := repo.Save(testWorkspace, task)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "database"); len(got) != 1 || got[0] != "new" {
+		t.Errorf("after Save, Search(database) = %v", got)
+	}
+	if got := searchIDs(t, repo, "other", "database"); len(got) != 0 {
+		t.Errorf("Search(other workspace) = %v, want none", got)
+	}
+
+	saved.Title = "Upgrade the load balancer"
+	updated, err := repo.Update(testWorkspace, saved.ID, saved)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "database"); len(got) != 0 {
+		t.Errorf("after Update, Search(database) = %v, want none", got)
+	}
+	if got := searchIDs(t, repo, testWorkspace, `"load balancer"`); len(got) != 1 {
+		t.Errorf("after Update, Search(load balancer) = %v", got)
+	}
+
+	// a rejected write leaves the index alone
+	stale := saved
+	stale.Title = "Stale title"
+	if _, err := repo.Update(testWorkspace, stale.ID, stale); err != ErrVersionConflict {
+		t.Fatalf("Update(stale) error = %v, want %v", err, ErrVersionConflict)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "stale"); len(got) != 0 {
+		t.Errorf("rejected Update was indexed: %v", got)
+	}
+
+	updated.DeletedAt = &now
+	if updated, err = repo.Update(testWorkspace, updated.ID, updated); err != nil {
+		t.Fatalf("Update(trash) error = %v", err)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "balancer"); len(got) != 0 {
+		t.Errorf("trashed task still indexed: %v", got)
+	}
+	updated.DeletedAt = nil
+	if _, err = repo.Update(testWorkspace, updated.ID, updated); err != nil {
+		t.Fatalf("Update(restore) error = %v", err)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "balancer"); len(got) != 1 {
+		t.Errorf("restored task not indexed: %v", got)
+	}
+
+	if err := repo.Delete(testWorkspace, "existing"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := searchIDs(t, repo, testWorkspace, "certificates"); len(got) != 0 {
+		t.Errorf("deleted task still indexed: %v", got)
+	}
+	if err := repo.Delete(testWorkspace, "existing"); err != ErrTaskNotFound {
+		t.Errorf("Delete(missing) error = %v, want %v", err, ErrTaskNotFound)
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"taskmanager/models"
+)
+
+// SQLAttachmentRepo is an AttachmentRepository stored in the attachments
+// table
+type SQLAttachmentRepo struct {
+	db *sql.DB
+}
+
+// NewSQLAttachmentRepo wraps db and runs any pending schema migrations
+func NewSQLAttachmentRepo(db *sql.DB) (*SQLAttachmentRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLAttachmentRepo{db: db}, nil
+}
+
+const attachmentColumns = `id, workspace, task_id, filename, content_type, size, sha256, uploaded_by, created_at`
+
+func (r *SQLAttachmentRepo) ListAttachments(workspace, taskID string) ([]models.Attachment, error) {
+	rows, err := r.db.Query(`SELECT `+attachmentColumns+` FROM attachments WHERE workspace = ? AND task_id = ? ORDER BY created_at, id`, workspace, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *SQLAttachmentRepo) GetAttachment(workspace, id string) (models.Attachment, error) {
+	a, err := scanAttachment(r.db.QueryRow(`SELECT `+attachmentColumns+` FROM attachments WHERE workspace = ? AND id = ?`, workspace, id))
+	if err == sql.ErrNoRows {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("get attachment: %w", err)
+	}
+	return a, nil
+}
+
+func (r *SQLAttachmentRepo) SaveAttachment(workspace string, a models.Attachment) (models.Attachment, error) {
+	a.Workspace = workspace
+	res, err := r.db.Exec(
+		`INSERT INTO attachments (`+attachmentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			task_id = excluded.task_id,
+			filename = excluded.filename,
+			content_type = excluded.content_type,
+			size = excluded.size,
+			sha256 = excluded.sha256,
+			uploaded_by = excluded.uploaded_by,
+			created_at = excluded.created_at
+		WHERE attachments.workspace = excluded.workspace`,
+		a.ID, workspace, a.TaskID, a.Filename, a.ContentType, a.Size, a.SHA256, a.UploadedBy, formatTime(a.CreatedAt),
+	)
+	if err != nil {
+		return models.Attachment{}, fmt.Errorf("save attachment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.Attachment{}, err
+	} else if n == 0 {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+func (r *SQLAttachmentRepo) DeleteAttachment(workspace, id string) error {
+	res, err := r.db.Exec(`DELETE FROM attachments WHERE workspace = ? AND id = ?`, workspace, id)
+	if err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r *SQLAttachmentRepo) DeleteTaskAttachments(workspace, taskID string) ([]models.Attachment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+attachmentColumns+` FROM attachments WHERE workspace = ? AND task_id = ? ORDER BY created_at, id`, workspace, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("query attachments: %w", err)
+	}
+	removed := []models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		removed = append(removed, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM attachments WHERE workspace = ? AND task_id = ?`, workspace, taskID); err != nil {
+		return nil, fmt.Errorf("delete task attachments: %w", err)
+	}
+	return removed, tx.Commit()
+}
+
+func (r *SQLAttachmentRepo) BlobInUse(hash string) (bool, error) {
+	var inUse bool
+	if err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM attachments WHERE sha256 = ?)`, hash).Scan(&inUse); err != nil {
+		return false, fmt.Errorf("check blob references: %w", err)
+	}
+	return inUse, nil
+}
+
+func scanAttachment(row rowScanner) (models.Attachment, error) {
+	var (
+		a         models.Attachment
+		createdAt string
+	)
+	if err := row.Scan(&a.ID, &a.Workspace, &a.TaskID, &a.Filename, &a.ContentType, &a.Size, &a.SHA256, &a.UploadedBy, &createdAt); err != nil {
+		return models.Attachment{}, err
+	}
+	var err error
+	if a.CreatedAt, err = parseTime(createdAt); err != nil {
+		return models.Attachment{}, err
+	}
+	return a, nil
+}
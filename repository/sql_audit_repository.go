@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"taskmanager/models"
+)
+
+// SQLAuditRepo is an AuditRepository stored in the audit_log table next to
+// the tasks it describes
+type SQLAuditRepo struct {
+	db *sql.DB
+}
+
+// NewSQLAuditRepo wraps db and runs any pending schema migrations
+func NewSQLAuditRepo(db *sql.DB) (*SQLAuditRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLAuditRepo{db: db}, nil
+}
+
+func (r *SQLAuditRepo) Append(entry models.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("encode audit changes: %w", err)
+	}
+	_, err = r.db.Exec(
+		`INSERT INTO audit_log (id, task_id, workspace, action, actor, timestamp, changes) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.TaskID, entry.Workspace, entry.Action, entry.Actor, formatTime(entry.Timestamp), string(changes),
+	)
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLAuditRepo) List(q models.AuditQuery) ([]models.AuditEntry, error) {
+	q.Normalize()
+
+	var (
+		where []string
+		args  []any
+	)
+	addFilter := func(clause string, arg any) {
+		where = append(where, clause)
+		args = append(args, arg)
+	}
+	if q.Workspace != "" {
+		addFilter("workspace = ?", q.Workspace)
+	}
+	if q.TaskID != "" {
+		addFilter("task_id = ?", q.TaskID)
+	}
+	if q.Actor != "" {
+		addFilter("actor = ?", q.Actor)
+	}
+	if q.From != nil {
+		addFilter("timestamp >= ?", formatTime(*q.From))
+	}
+	if q.To != nil {
+		addFilter("timestamp < ?", formatTime(*q.To))
+	}
+
+	stmt := `SELECT id, task_id, workspace, action, actor, timestamp, changes FROM audit_log`
+	if len(where) > 0 {
+		stmt += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	stmt += ` ORDER BY timestamp, seq LIMIT ?`
+	args = append(args, q.Limit)
+
+	rows, err := r.db.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	result := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			entry     models.AuditEntry
+			timestamp string
+			changes   string
+		)
+		if err := rows.Scan(&entry.ID, &entry.TaskID, &entry.Workspace, &entry.Action, &entry.Actor, &timestamp, &changes); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if entry.Timestamp, err = parseTime(timestamp); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, fmt.Errorf("decode audit changes: %w", err)
+		}
+		result = append(result, entry)
+	}
+	return result, rows.Err()
+}
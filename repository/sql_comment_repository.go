@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"taskmanager/models"
+)
+
+// SQLCommentRepo is a CommentRepository stored in the comments table. Each
+// comment's edit history is kept as a JSON array in its edits column.
+type SQLCommentRepo struct {
+	db *sql.DB
+}
+
+// NewSQLCommentRepo wraps db and runs any pending schema migrations
+func NewSQLCommentRepo(db *sql.DB) (*SQLCommentRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLCommentRepo{db: db}, nil
+}
+
+const commentColumns = `id, workspace, task_id, author, body, edits, created_at, updated_at`
+
+func (r *SQLCommentRepo) ListComments(workspace, taskID string) ([]models.Comment, error) {
+	rows, err := r.db.Query(`SELECT `+commentColumns+` FROM comments WHERE workspace = ? AND task_id = ? ORDER BY created_at, id`, workspace, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *SQLCommentRepo) GetComment(workspace, id string) (models.Comment, error) {
+	c, err := scanComment(r.db.QueryRow(`SELECT `+commentColumns+` FROM comments WHERE workspace = ? AND id = ?`, workspace, id))
+	if err == sql.ErrNoRows {
+		return models.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("get comment: %w", err)
+	}
+	return c, nil
+}
+
+func (r *SQLCommentRepo) SaveComment(workspace string, c models.Comment) (models.Comment, error) {
+	c.Workspace = workspace
+	edits, err := json.Marshal(c.Edits)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("encode comment edits: %w", err)
+	}
+	res, err := r.db.Exec(
+		`INSERT INTO comments (`+commentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			task_id = excluded.task_id,
+			author = excluded.author,
+			body = excluded.body,
+			edits = excluded.edits,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+		WHERE comments.workspace = excluded.workspace`,
+		c.ID, workspace, c.TaskID, c.Author, c.Body, string(edits), formatTime(c.CreatedAt), formatTime(c.UpdatedAt),
+	)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("save comment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.Comment{}, err
+	} else if n == 0 {
+		return models.Comment{}, ErrCommentNotFound
+	}
+	return c, nil
+}
+
+func (r *SQLCommentRepo) DeleteComment(workspace, id string) error {
+	res, err := r.db.Exec(`DELETE FROM comments WHERE workspace = ? AND id = ?`, workspace, id)
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *SQLCommentRepo) DeleteTaskComments(workspace, taskID string) (int, error) {
+	res, err := r.db.Exec(`DELETE FROM comments WHERE workspace = ? AND task_id = ?`, workspace, taskID)
+	if err != nil {
+		return 0, fmt.Errorf("delete task comments: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanComment(row rowScanner) (models.Comment, error) {
+	var (
+		c                    models.Comment
+		edits                string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&c.ID, &c.Workspace, &c.TaskID, &c.Author, &c.Body, &edits, &createdAt, &updatedAt); err != nil {
+		return models.Comment{}, err
+	}
+	if err := json.Unmarshal([]byte(edits), &c.Edits); err != nil {
+		return models.Comment{}, fmt.Errorf("decode comment edits: %w", err)
+	}
+	var err error
+	if c.CreatedAt, err = parseTime(createdAt); err != nil {
+		return models.Comment{}, err
+	}
+	if c.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return models.Comment{}, err
+	}
+	return c, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"taskmanager/filter"
+	"taskmanager/models"
+)
+
+// sqlFilterColumns maps filter fields to task columns. Labels are looked up
+// in task_labels instead.
+var sqlFilterColumns = map[string]string{
+	models.FilterStatus:     "status",
+	models.FilterPriority:   "priority",
+	models.FilterAssignedTo: "assigned_to",
+	models.FilterProjectID:  "project_id",
+	models.FilterParentID:   "parent_id",
+	models.FilterDue:        "due_date",
+	models.FilterCreated:    "created_at",
+	models.FilterUpdated:    "updated_at",
+}
+
+// sqlFilter translates a parsed task filter into a WHERE clause and its
+// arguments, matching the same tasks as TaskFilter.Matches
+func sqlFilter(e filter.Expr) (string, []any) {
+	switch e := e.(type) {
+	case filter.And:
+		return sqlJoin("AND", e.Left, e.Right)
+	case filter.Or:
+		return sqlJoin("OR", e.Left, e.Right)
+	case filter.Not:
+		clause, args := sqlFilter(e.X)
+		return "NOT " + clause, args
+	case filter.Comparison:
+		if e.Field == models.FilterLabel {
+			return "id IN (SELECT task_id FROM task_labels WHERE label = ?)", []any{e.Value}
+		}
+		column := sqlFilterColumns[e.Field]
+		if e.Op == filter.OpEq {
+			return column + " = ?", []any{e.Value}
+		}
+		// The IS NOT NULL guard keeps a missing due date from turning the
+		// comparison, and so any NOT around it, into NULL
+		return "(" + column + " IS NOT NULL AND " + column + " " + string(e.Op) + " ?)", []any{formatTime(e.Time)}
+	}
+	// filter.Parse produces no other expressions
+	return "0", nil
+}
+
+func sqlJoin(op string, left, right filter.Expr) (string, []any) {
+	l, largs := sqlFilter(left)
+	r, rargs := sqlFilter(right)
+	return "(" + l + " " + op + " " + r + ")", append(largs, rargs...)
+}
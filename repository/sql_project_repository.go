@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"taskmanager/models"
+)
+
+// SQLProjectRepo is a ProjectRepository stored in the projects table
+type SQLProjectRepo struct {
+	db *sql.DB
+}
+
+// NewSQLProjectRepo wraps db and runs any pending schema migrations
+func NewSQLProjectRepo(db *sql.DB) (*SQLProjectRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLProjectRepo{db: db}, nil
+}
+
+const projectColumns = `id, workspace, name, description, created_at, updated_at`
+
+func (r *SQLProjectRepo) ListProjects(workspace string) ([]models.Project, error) {
+	rows, err := r.db.Query(`SELECT `+projectColumns+` FROM projects WHERE workspace = ? ORDER BY created_at, id`, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+func (r *SQLProjectRepo) GetProject(workspace, id string) (models.Project, error) {
+	p, err := scanProject(r.db.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE workspace = ? AND id = ?`, workspace, id))
+	if err == sql.ErrNoRows {
+		return models.Project{}, ErrProjectNotFound
+	}
+	if err != nil {
+		return models.Project{}, fmt.Errorf("get project: %w", err)
+	}
+	return p, nil
+}
+
+func (r *SQLProjectRepo) SaveProject(workspace string, p models.Project) (models.Project, error) {
+	p.Workspace = workspace
+	res, err := r.db.Exec(
+		`INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+		WHERE projects.workspace = excluded.workspace`,
+		p.ID, workspace, p.Name, p.Description, formatTime(p.CreatedAt), formatTime(p.UpdatedAt),
+	)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("save project: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.Project{}, err
+	} else if n == 0 {
+		return models.Project{}, ErrProjectNotFound
+	}
+	return p, nil
+}
+
+func (r *SQLProjectRepo) DeleteProject(workspace, id string) error {
+	res, err := r.db.Exec(`DELETE FROM projects WHERE workspace = ? AND id = ?`, workspace, id)
+	if err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+func scanProject(row rowScanner) (models.Project, error) {
+	var (
+		p                    models.Project
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&p.ID, &p.Workspace, &p.Name, &p.Description, &createdAt, &updatedAt); err != nil {
+		return models.Project{}, err
+	}
+	var err error
+	if p.CreatedAt, err = parseTime(createdAt); err != nil {
+		return models.Project{}, err
+	}
+	if p.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return models.Project{}, err
+	}
+	return p, nil
+}
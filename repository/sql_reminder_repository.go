@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"taskmanager/models"
+)
+
+// SQLReminderRepo is a ReminderRepository stored in the reminders table
+type SQLReminderRepo struct {
+	db *sql.DB
+}
+
+// NewSQLReminderRepo wraps db and runs any pending schema migrations
+func NewSQLReminderRepo(db *sql.DB) (*SQLReminderRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLReminderRepo{db: db}, nil
+}
+
+func (r *SQLReminderRepo) Sent(key string) (bool, error) {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM reminders WHERE key = ?`, key).Scan(&n); err != nil {
+		return false, fmt.Errorf("query reminders: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *SQLReminderRepo) Record(reminder models.Reminder) error {
+	_, err := r.db.Exec(
+		`INSERT INTO reminders (key, task_id, kind, due_date, sent_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO NOTHING`,
+		reminder.Key(), reminder.TaskID, reminder.Kind, formatTime(reminder.DueDate), formatTime(reminder.SentAt),
+	)
+	if err != nil {
+		return fmt.Errorf("insert reminder: %w", err)
+	}
+	return nil
+}
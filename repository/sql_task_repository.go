@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"taskmanager/models"
+	"time"
+)
+
+// sqlTimeLayout is a fixed-width UTC layout so stored timestamps compare and
+// sort correctly as plain text.
+const sqlTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+const taskColumns = `id, title, description, status, priority, due_date, created_at, updated_at, assigned_to, version, deleted_at, parent_id, blocked_by, rrule, workspace, project_id, labels`
+
+// SQLTaskRepo is a TaskRepository backed by a database/sql connection. Queries
+// use SQLite syntax and "?" placeholders.
+type SQLTaskRepo struct {
+	db *sql.DB
+}
+
+// NewSQLTaskRepo wraps db and runs any pending schema migrations
+func NewSQLTaskRepo(db *sql.DB) (*SQLTaskRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLTaskRepo{db: db}, nil
+}
+
+func (r *SQLTaskRepo) GetAll(workspace string) ([]models.Task, error) {
+	rows, err := r.db.Query(`SELECT `+taskColumns+` FROM tasks WHERE workspace = ?`, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, task)
+	}
+	return result, rows.Err()
+}
+
+// Descendants walks parent_id with a recursive query. UNION drops rows it
+// has already produced, so a cycle ends the walk.
+func (r *SQLTaskRepo) Descendants(workspace, id string) ([]models.Task, error) {
+	rows, err := r.db.Query(
+		`WITH RECURSIVE subtree (id) AS (
+			SELECT id FROM tasks WHERE workspace = ? AND parent_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			WHERE t.workspace = ? AND t.deleted_at IS NULL
+		)
+		SELECT `+taskColumns+` FROM tasks WHERE id IN (SELECT id FROM subtree) AND id <> ?`,
+		workspace, id, workspace, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query descendants: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, task)
+	}
+	return result, rows.Err()
+}
+
+func (r *SQLTaskRepo) Query(workspace string, q models.TaskQuery) (models.TaskPage, error) {
+	q.Normalize()
+	after, err := decodeCursor(q)
+	if err != nil {
+		return models.TaskPage{}, err
+	}
+
+	var (
+		where []string
+		args  []any
+	)
+	addFilter := func(clause string, arg any) {
+		where = append(where, clause)
+		args = append(args, arg)
+	}
+	addFilter("workspace = ?", workspace)
+	if q.Status != "" {
+		addFilter("status = ?", q.Status)
+	}
+	if q.Priority != "" {
+		addFilter("priority = ?", q.Priority)
+	}
+	if q.AssignedTo != "" {
+		addFilter("assigned_to = ?", q.AssignedTo)
+	}
+	if q.ProjectID != "" {
+		addFilter("project_id = ?", q.ProjectID)
+	}
+	if q.ParentID != "" {
+		addFilter("parent_id = ?", q.ParentID)
+	}
+	// Label filters look the labels up in task_labels rather than scanning
+	// the labels column
+	addLabelFilter := func(op string, labels []string) {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(labels)), ", ")
+		where = append(where, "id "+op+" (SELECT task_id FROM task_labels WHERE label IN ("+placeholders+"))")
+		for _, label := range labels {
+			args = append(args, label)
+		}
+	}
+	for _, label := range q.LabelsAll {
+		addLabelFilter("IN", []string{label})
+	}
+	if len(q.LabelsAny) > 0 {
+		addLabelFilter("IN", q.LabelsAny)
+	}
+	if len(q.LabelsNone) > 0 {
+		addLabelFilter("NOT IN", q.LabelsNone)
+	}
+	if q.Filter != nil {
+		clause, filterArgs := sqlFilter(q.Filter.Expr)
+		where = append(where, clause)
+		args = append(args, filterArgs...)
+	}
+	if q.Deleted {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "deleted_at IS NULL")
+	}
+	if q.DeletedBefore != nil {
+		addFilter("deleted_at < ?", formatTime(*q.DeletedBefore))
+	}
+	addRange := func(column string, from, to *time.Time) {
+		if from != nil {
+			addFilter(column+" >= ?", formatTime(*from))
+		}
+		if to != nil {
+			addFilter(column+" < ?", formatTime(*to))
+		}
+	}
+	addRange("due_date", q.DueAfter, q.DueBefore)
+	addRange("created_at", q.CreatedAfter, q.CreatedBefore)
+	addRange("updated_at", q.UpdatedAfter, q.UpdatedBefore)
+
+	expr := sqlSortExpr(q.SortBy)
+	op, dir := ">", "ASC"
+	if q.SortDesc {
+		op, dir = "<", "DESC"
+	}
+	if after != nil {
+		where = append(where, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", expr, op))
+		args = append(args, after.Key, after.Key, after.ID)
+	}
+
+	stmt := `SELECT ` + taskColumns + ` FROM tasks WHERE ` + strings.Join(where, " AND ")
+	stmt += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT ?`, expr, dir, dir)
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, q.Limit+1)
+
+	rows, err := r.db.Query(stmt, args...)
+	if err != nil {
+		return models.TaskPage{}, fmt.Errorf("query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	page := models.TaskPage{Tasks: make([]models.Task, 0, q.Limit)}
+	hasMore := false
+	for rows.Next() {
+		if len(page.Tasks) == q.Limit {
+			hasMore = true
+			break
+		}
+		task, err := scanTask(rows)
+		if err != nil {
+			return models.TaskPage{}, err
+		}
+		page.Tasks = append(page.Tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return models.TaskPage{}, err
+	}
+	if hasMore {
+		page.NextCursor = encodeCursor(q, page.Tasks[len(page.Tasks)-1])
+	}
+	return page, nil
+}
+
+func (r *SQLTaskRepo) GetByID(workspace, id string) (models.Task, error) {
+	row := r.db.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE workspace = ? AND id = ?`, workspace, id)
+	task, err := scanTask(row)
+	if err == sql.ErrNoRows {
+		return models.Task{}, ErrTaskNotFound
+	}
+	return task, err
+}
+
+func (r *SQLTaskRepo) Save(workspace string, task models.Task) (models.Task, error) {
+	task.Workspace = workspace
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Task{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO tasks (`+taskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			status = excluded.status,
+			priority = excluded.priority,
+			due_date = excluded.due_date,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at,
+			assigned_to = excluded.assigned_to,
+			version = excluded.version,
+			deleted_at = excluded.deleted_at,
+			parent_id = excluded.parent_id,
+			blocked_by = excluded.blocked_by,
+			rrule = excluded.rrule,
+			project_id = excluded.project_id,
+			labels = excluded.labels
+		WHERE tasks.workspace = excluded.workspace`,
+		task.ID, task.Title, task.Description, task.Status, task.Priority,
+		formatNullTime(task.DueDate), formatTime(task.CreatedAt), formatTime(task.UpdatedAt), task.AssignedTo,
+		task.Version, formatNullTime(task.DeletedAt), task.ParentID, formatStringList(task.BlockedBy),
+		task.RRule, workspace, task.ProjectID, formatStringList(task.Labels),
+	)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("save task: %w", err)
+	}
+	// The upsert skips rows owned by another workspace
+	if n, err := res.RowsAffected(); err != nil {
+		return models.Task{}, err
+	} else if n == 0 {
+		return models.Task{}, ErrTaskIDTaken
+	}
+	if err := setLabels(tx, task.ID, task.Labels); err != nil {
+		return models.Task{}, err
+	}
+	return task, tx.Commit()
+}
+
+func (r *SQLTaskRepo) Update(workspace, id string, task models.Task) (models.Task, error) {
+	task.ID = id
+	task.Workspace = workspace
+	task.UpdatedAt = time.Now()
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Task{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE tasks SET title = ?, description = ?, status = ?, priority = ?,
+			due_date = ?, updated_at = ?, assigned_to = ?, deleted_at = ?, parent_id = ?,
+			blocked_by = ?, rrule = ?, project_id = ?, labels = ?, version = version + 1
+		WHERE workspace = ? AND id = ? AND version = ?`,
+		task.Title, task.Description, task.Status, task.Priority,
+		formatNullTime(task.DueDate), formatTime(task.UpdatedAt), task.AssignedTo,
+		formatNullTime(task.DeletedAt), task.ParentID,
+		formatStringList(task.BlockedBy), task.RRule, task.ProjectID, formatStringList(task.Labels),
+		workspace, id, task.Version,
+	)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("update task: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.Task{}, err
+	} else if n == 0 {
+		// Distinguish a missing task from a lost compare-and-swap
+		var exists int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM tasks WHERE workspace = ? AND id = ?`, workspace, id).Scan(&exists)
+		if err != nil {
+			return models.Task{}, err
+		}
+		if exists == 0 {
+			return models.Task{}, ErrTaskNotFound
+		}
+		return models.Task{}, ErrVersionConflict
+	}
+	if err := setLabels(tx, id, task.Labels); err != nil {
+		return models.Task{}, err
+	}
+	task.Version++
+	return task, tx.Commit()
+}
+
+func (r *SQLTaskRepo) Delete(workspace, id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM tasks WHERE workspace = ? AND id = ?`, workspace, id)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTaskNotFound
+	}
+	if err := setLabels(tx, id, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setLabels replaces the task_labels rows of task id
+func setLabels(tx *sql.Tx, id string, labels []string) error {
+	if _, err := tx.Exec(`DELETE FROM task_labels WHERE task_id = ?`, id); err != nil {
+		return fmt.Errorf("clear task labels: %w", err)
+	}
+	for _, label := range labels {
+		if _, err := tx.Exec(`INSERT INTO task_labels (task_id, label) VALUES (?, ?)`, id, label); err != nil {
+			return fmt.Errorf("insert task label: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLTaskRepo) Workspaces() ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT workspace FROM tasks ORDER BY workspace`)
+	if err != nil {
+		return nil, fmt.Errorf("query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []string{}
+	for rows.Next() {
+		var workspace string
+		if err := rows.Scan(&workspace); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, rows.Err()
+}
+
+// sqlSortExpr returns the SQL expression equivalent to sortKey for field
+func sqlSortExpr(field string) string {
+	switch field {
+	case models.SortByID:
+		return "id"
+	case models.SortByTitle:
+		return "title"
+	case models.SortByStatus:
+		return sqlRankExpr("status", statusRank)
+	case models.SortByPriority:
+		return sqlRankExpr("priority", priorityRank)
+	case models.SortByDueDate:
+		return "COALESCE(due_date, '')"
+	case models.SortByUpdatedAt:
+		return "updated_at"
+	case models.SortByAssignedTo:
+		return "assigned_to"
+	case models.SortByDeletedAt:
+		return "COALESCE(deleted_at, '')"
+	default:
+		return "created_at"
+	}
+}
+
+// sqlRankExpr builds a CASE expression mapping column values to their rank.
+// Values and ranks come from fixed tables of constants, so inlining is safe.
+func sqlRankExpr(column string, ranks map[string]string) string {
+	values := make([]string, 0, len(ranks))
+	for value := range ranks {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var b strings.Builder
+	b.WriteString("CASE " + column)
+	for _, value := range values {
+		fmt.Fprintf(&b, " WHEN '%s' THEN '%s'", value, ranks[value])
+	}
+	b.WriteString(" ELSE '0' END")
+	return b.String()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner) (models.Task, error) {
+	var (
+		task                 models.Task
+		dueDate, deletedAt   sql.NullString
+		createdAt, updatedAt string
+		blockedBy, labels    string
+	)
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
+		&dueDate, &createdAt, &updatedAt, &task.AssignedTo, &task.Version, &deletedAt, &task.ParentID, &blockedBy, &task.RRule, &task.Workspace, &task.ProjectID, &labels)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if task.DueDate, err = parseNullTime(dueDate); err != nil {
+		return models.Task{}, err
+	}
+	if task.CreatedAt, err = parseTime(createdAt); err != nil {
+		return models.Task{}, err
+	}
+	if task.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return models.Task{}, err
+	}
+	if task.DeletedAt, err = parseNullTime(deletedAt); err != nil {
+		return models.Task{}, err
+	}
+	if task.BlockedBy, err = parseStringList(blockedBy); err != nil {
+		return models.Task{}, err
+	}
+	if task.Labels, err = parseStringList(labels); err != nil {
+		return models.Task{}, err
+	}
+	return task, nil
+}
+
+// formatStringList stores a list of strings, such as task IDs, as a JSON array
+func formatStringList(ids []string) string {
+	if len(ids) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(ids)
+	return string(data)
+}
+
+// parseStringList reads a list stored by formatStringList; an empty list
+// becomes nil to match values that never had one
+func parseStringList(s string) ([]string, error) {
+	var ids []string
+	if err := json.Unmarshal([]byte(s), &ids); err != nil {
+		return nil, fmt.Errorf("parse stored id list %q: %w", s, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return ids, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqlTimeLayout)
+}
+
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(sqlTimeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse stored time %q: %w", s, err)
+	}
+	return t, nil
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"taskmanager/constants"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatalf("sql.Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func openTestSQLRepo(t *testing.T) *SQLTaskRepo {
+	t.Helper()
+	repo, err := NewSQLTaskRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLTaskRepo() unexpected error: %v", err)
+	}
+	return repo
+}
+
+func TestSQLTaskRepo_CRUD(t *testing.T) {
+	repo := openTestSQLRepo(t)
+
+	if got := len(mustGetAll(t, repo)); got != 0 {
+		t.Errorf("GetAll() on empty repo = %v tasks, want 0", got)
+	}
+
+	task := testutils.CreateTestTask()
+	task.ID = "test-id"
+	if _, err := repo.Save(testWorkspace, task); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	retrieved, err := repo.GetByID(testWorkspace, "test-id")
+	if err != nil {
+		t.Fatalf("GetByID() unexpected error: %v", err)
+	}
+	if retrieved.Title != task.Title || retrieved.AssignedTo != task.AssignedTo {
+		t.Errorf("GetByID() = %+v, want %+v", retrieved, task)
+	}
+	if retrieved.DueDate == nil || !retrieved.DueDate.Equal(*task.DueDate) {
+		t.Errorf("GetByID() dueDate = %v, want %v", retrieved.DueDate, task.DueDate)
+	}
+	if !retrieved.CreatedAt.Equal(task.CreatedAt) {
+		t.Errorf("GetByID() createdAt = %v, want %v", retrieved.CreatedAt, task.CreatedAt)
+	}
+
+	task.Status = constants.StatusCompleted
+	task.DueDate = nil
+	updated, err := repo.Update(testWorkspace, "test-id", task)
+	if err != nil {
+		t.Errorf("Update() unexpected error: %v", err)
+	}
+	if updated.Status != constants.StatusCompleted {
+		t.Errorf("Update() status = %v, want %v", updated.Status, constants.StatusCompleted)
+	}
+	retrieved, _ = repo.GetByID(testWorkspace, "test-id")
+	if retrieved.DueDate != nil {
+		t.Errorf("GetByID() after clearing dueDate = %v, want nil", retrieved.DueDate)
+	}
+	if _, err := repo.Update(testWorkspace, "non-existent", task); err != ErrTaskNotFound {
+		t.Errorf("Update() error = %v, want %v", err, ErrTaskNotFound)
+	}
+
+	if err := repo.Delete(testWorkspace, "test-id"); err != nil {
+		t.Errorf("Delete() unexpected error: %v", err)
+	}
+	if _, err := repo.GetByID(testWorkspace, "test-id"); err != ErrTaskNotFound {
+		t.Errorf("GetByID() after delete error = %v, want %v", err, ErrTaskNotFound)
+	}
+	if err := repo.Delete(testWorkspace, "test-id"); err != ErrTaskNotFound {
+		t.Errorf("Delete() error = %v, want %v", err, ErrTaskNotFound)
+	}
+}
+
+func TestSQLTaskRepo_PersistsAcrossConnections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	repo, err := NewSQLTaskRepo(openTestDB(t, path))
+	if err != nil {
+		t.Fatalf("NewSQLTaskRepo() unexpected error: %v", err)
+	}
+	task := testutils.CreateTestTask()
+	task.ID = "1"
+	repo.Save(testWorkspace, task)
+
+	reopened, err := NewSQLTaskRepo(openTestDB(t, path))
+	if err != nil {
+		t.Fatalf("NewSQLTaskRepo() on existing db unexpected error: %v", err)
+	}
+	if _, err := reopened.GetByID(testWorkspace, "1"); err != nil {
+		t.Errorf("GetByID() after reopen unexpected error: %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "tasks.db"))
+
+	// Running twice must be a no-op the second time
+	for i := 0; i < 2; i++ {
+		if err := migrate(db, taskMigrations); err != nil {
+			t.Fatalf("migrate() run %d unexpected error: %v", i+1, err)
+		}
+	}
+
+	var version, count int
+	if err := db.QueryRow(`SELECT MAX(version), COUNT(*) FROM schema_migrations`).Scan(&version, &count); err != nil {
+		t.Fatalf("read schema_migrations: %v", err)
+	}
+	latest := taskMigrations[len(taskMigrations)-1].version
+	if version != latest || count != len(taskMigrations) {
+		t.Errorf("schema version = %v (%v rows), want %v (%v rows)", version, count, latest, len(taskMigrations))
+	}
+
+	// A failing migration rolls back and leaves the version unchanged
+	broken := append(taskMigrations, migration{
+		version:    latest + 1,
+		name:       "broken",
+		statements: []string{`CREATE TABLE broken (id TEXT)`, `NOT VALID SQL`},
+	})
+	if err := migrate(db, broken); err == nil {
+		t.Fatalf("migrate() with broken migration expected error but got none")
+	}
+	db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if version != latest {
+		t.Errorf("schema version after failed migration = %v, want %v", version, latest)
+	}
+	var tables int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'broken'`).Scan(&tables)
+	if tables != 0 {
+		t.Errorf("failed migration left table behind")
+	}
+}
+
+func TestSQLTimeRoundTrip(t *testing.T) {
+	in := time.Date(2024, 12, 31, 23, 59, 59, 123456789, time.FixedZone("EST", -5*3600))
+	out, err := parseTime(formatTime(in))
+	if err != nil {
+		t.Fatalf("parseTime() unexpected error: %v", err)
+	}
+	if !out.Equal(in) {
+		t.Errorf("time round trip = %v, want %v", out, in)
+	}
+}
+
+func TestSQLTaskRepo_CompareAndSwap(t *testing.T) {
+	testTaskRepoCompareAndSwap(t, openTestSQLRepo(t))
+}
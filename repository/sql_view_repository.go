@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"taskmanager/models"
+)
+
+// SQLViewRepo is a ViewRepository stored in the views table. Columns are
+// kept as a JSON array.
+type SQLViewRepo struct {
+	db *sql.DB
+}
+
+// NewSQLViewRepo wraps db and runs any pending schema migrations
+func NewSQLViewRepo(db *sql.DB) (*SQLViewRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLViewRepo{db: db}, nil
+}
+
+const viewColumns = `id, workspace, name, owner, filter, sort, columns, shared, created_at, updated_at`
+
+func (r *SQLViewRepo) ListViews(workspace, owner string) ([]models.View, error) {
+	rows, err := r.db.Query(
+		`SELECT `+viewColumns+` FROM views WHERE workspace = ? AND (owner = ? OR shared = 1) ORDER BY created_at, id`,
+		workspace, owner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []models.View{}
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan view: %w", err)
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+func (r *SQLViewRepo) GetView(workspace, id string) (models.View, error) {
+	v, err := scanView(r.db.QueryRow(`SELECT `+viewColumns+` FROM views WHERE workspace = ? AND id = ?`, workspace, id))
+	if err == sql.ErrNoRows {
+		return models.View{}, ErrViewNotFound
+	}
+	if err != nil {
+		return models.View{}, fmt.Errorf("get view: %w", err)
+	}
+	return v, nil
+}
+
+func (r *SQLViewRepo) SaveView(workspace string, v models.View) (models.View, error) {
+	v.Workspace = workspace
+	columns, err := json.Marshal(v.Columns)
+	if err != nil {
+		return models.View{}, fmt.Errorf("encode view columns: %w", err)
+	}
+	shared := 0
+	if v.Shared {
+		shared = 1
+	}
+	res, err := r.db.Exec(
+		`INSERT INTO views (`+viewColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			owner = excluded.owner,
+			filter = excluded.filter,
+			sort = excluded.sort,
+			columns = excluded.columns,
+			shared = excluded.shared,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+		WHERE views.workspace = excluded.workspace`,
+		v.ID, workspace, v.Name, v.Owner, v.Filter, v.Sort, string(columns), shared,
+		formatTime(v.CreatedAt), formatTime(v.UpdatedAt),
+	)
+	if err != nil {
+		return models.View{}, fmt.Errorf("save view: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.View{}, err
+	} else if n == 0 {
+		return models.View{}, ErrViewNotFound
+	}
+	return v, nil
+}
+
+func (r *SQLViewRepo) DeleteView(workspace, id string) error {
+	res, err := r.db.Exec(`DELETE FROM views WHERE workspace = ? AND id = ?`, workspace, id)
+	if err != nil {
+		return fmt.Errorf("delete view: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrViewNotFound
+	}
+	return nil
+}
+
+func scanView(row rowScanner) (models.View, error) {
+	var (
+		v                    models.View
+		columns              string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&v.ID, &v.Workspace, &v.Name, &v.Owner, &v.Filter, &v.Sort, &columns, &v.Shared, &createdAt, &updatedAt); err != nil {
+		return models.View{}, err
+	}
+	if err := json.Unmarshal([]byte(columns), &v.Columns); err != nil {
+		return models.View{}, err
+	}
+	var err error
+	if v.CreatedAt, err = parseTime(createdAt); err != nil {
+		return models.View{}, err
+	}
+	if v.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return models.View{}, err
+	}
+	return v, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"taskmanager/models"
+)
+
+// SQLWebhookRepo is a WebhookRepository stored in the webhooks and
+// webhook_deliveries tables
+type SQLWebhookRepo struct {
+	db *sql.DB
+}
+
+// NewSQLWebhookRepo wraps db and runs any pending schema migrations
+func NewSQLWebhookRepo(db *sql.DB) (*SQLWebhookRepo, error) {
+	if err := migrate(db, taskMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLWebhookRepo{db: db}, nil
+}
+
+const webhookColumns = `id, url, events, secret, active, created_at, updated_at, workspace`
+
+func (r *SQLWebhookRepo) ListWebhooks() ([]models.Webhook, error) {
+	rows, err := r.db.Query(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *SQLWebhookRepo) GetWebhook(id string) (models.Webhook, error) {
+	w, err := scanWebhook(r.db.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("get webhook: %w", err)
+	}
+	return w, nil
+}
+
+func (r *SQLWebhookRepo) SaveWebhook(w models.Webhook) error {
+	_, err := r.db.Exec(
+		`INSERT INTO webhooks (`+webhookColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			url = excluded.url,
+			events = excluded.events,
+			secret = excluded.secret,
+			active = excluded.active,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at,
+			workspace = excluded.workspace`,
+		w.ID, w.URL, formatStringList(w.Events), w.Secret, w.Active, formatTime(w.CreatedAt), formatTime(w.UpdatedAt), w.Workspace,
+	)
+	if err != nil {
+		return fmt.Errorf("save webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLWebhookRepo) DeleteWebhook(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrWebhookNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("delete webhook deliveries: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *SQLWebhookRepo) AppendDelivery(d models.WebhookDelivery) error {
+	_, err := r.db.Exec(
+		`INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, attempt, success,
+			status_code, error, duration_ms, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.WebhookID, d.EventID, d.EventType, d.Attempt, d.Success,
+		d.StatusCode, d.Error, d.DurationMs, formatTime(d.Timestamp),
+	)
+	if err != nil {
+		return fmt.Errorf("insert webhook delivery: %w", err)
+	}
+	// Keep only the most recent deliveries for this webhook
+	_, err = r.db.Exec(
+		`DELETE FROM webhook_deliveries WHERE webhook_id = ? AND seq NOT IN (
+			SELECT seq FROM webhook_deliveries WHERE webhook_id = ? ORDER BY seq DESC LIMIT ?
+		)`,
+		d.WebhookID, d.WebhookID, models.MaxDeliveryLimit,
+	)
+	if err != nil {
+		return fmt.Errorf("trim webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLWebhookRepo) ListDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Query(
+		`SELECT id, webhook_id, event_id, event_type, attempt, success, status_code, error, duration_ms, timestamp
+		FROM webhook_deliveries WHERE webhook_id = ? ORDER BY seq DESC LIMIT ?`,
+		webhookID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var (
+			d         models.WebhookDelivery
+			timestamp string
+		)
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Attempt, &d.Success,
+			&d.StatusCode, &d.Error, &d.DurationMs, &timestamp); err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		if d.Timestamp, err = parseTime(timestamp); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhook(row rowScanner) (models.Webhook, error) {
+	var (
+		w                    models.Webhook
+		events               string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.Active, &createdAt, &updatedAt, &w.Workspace); err != nil {
+		return models.Webhook{}, err
+	}
+	var err error
+	if w.Events, err = parseStringList(events); err != nil {
+		return models.Webhook{}, err
+	}
+	if w.CreatedAt, err = parseTime(createdAt); err != nil {
+		return models.Webhook{}, err
+	}
+	if w.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return models.Webhook{}, err
+	}
+	return w, nil
+}
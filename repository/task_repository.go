@@ -1,81 +1,186 @@
 package repository
 
 import (
+	"sort"
 	"sync"
+	"taskmanager/constants"
 	"taskmanager/errors"
 	"taskmanager/models"
 	"time"
 )
 
 var (
-	ErrTaskNotFound = errors.NewNotFoundError("Task")
+	ErrTaskNotFound    = errors.NewNotFoundError("Task")
+	ErrVersionConflict = errors.NewPreconditionFailedError(constants.MessageVersionConflict)
+	ErrTaskIDTaken     = errors.NewConflictError(constants.MessageTaskIDTaken)
 )
 
+// TaskRepository stores tasks. Update is a compare-and-swap: it only succeeds
+// when task.Version matches the stored version, and it stores the task with
+// the version incremented.
+//
+// Tasks are partitioned by workspace. Every call is confined to the
+// workspace it is given, so a task in another workspace is reported missing
+// even when its ID is known. Save stamps the task with its workspace.
 type TaskRepository interface {
-    GetAll() []models.Task
-    GetByID(id string) (models.Task, error)
-    Save(task models.Task) models.Task
-    Update(id string, task models.Task) (models.Task, error)
-    Delete(id string) error
+	GetAll(workspace string) ([]models.Task, error)
+	GetByID(workspace, id string) (models.Task, error)
+	Query(workspace string, q models.TaskQuery) (models.TaskPage, error)
+	// Descendants returns the live tasks nested under id at any depth, in
+	// no particular order. Subtasks of a trashed task are left out.
+	Descendants(workspace, id string) ([]models.Task, error)
+	Save(workspace string, task models.Task) (models.Task, error)
+	Update(workspace, id string, task models.Task) (models.Task, error)
+	Delete(workspace, id string) error
+	// Workspaces lists every workspace holding tasks, including trashed
+	// ones, so background jobs can visit each in turn
+	Workspaces() ([]string, error)
 }
 
 type InMemoryTaskRepo struct {
-    tasks map[string]models.Task
-    mu    sync.RWMutex
+	tasks  map[string]models.Task
+	labels labelIndex
+	mu     sync.RWMutex
 }
 
 func NewInMemoryTaskRepo() *InMemoryTaskRepo {
-    return &InMemoryTaskRepo{
-        tasks: make(map[string]models.Task),
-    }
+	return &InMemoryTaskRepo{
+		tasks:  make(map[string]models.Task),
+		labels: make(labelIndex),
+	}
 }
 
-func (r *InMemoryTaskRepo) GetAll() []models.Task {
-    r.mu.RLock()
-    defer r.mu.RUnlock()
-    result := make([]models.Task, 0, len(r.tasks))
-    for _, task := range r.tasks {
-        result = append(result, task)
-    }
-    return result
+func (r *InMemoryTaskRepo) GetAll(workspace string) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return tasksIn(r.tasks, workspace), nil
 }
 
-func (r *InMemoryTaskRepo) GetByID(id string) (models.Task, error) {
-    r.mu.RLock()
-    defer r.mu.RUnlock()
-    task, ok := r.tasks[id]
-    if !ok {
-        return models.Task{}, ErrTaskNotFound
-    }
-    return task, nil
+func (r *InMemoryTaskRepo) Query(workspace string, q models.TaskQuery) (models.TaskPage, error) {
+	r.mu.RLock()
+	tasks := r.labels.candidates(r.tasks, workspace, q)
+	r.mu.RUnlock()
+	return queryTasks(tasks, q)
 }
 
-func (r *InMemoryTaskRepo) Save(task models.Task) models.Task {
-    r.mu.Lock()
-    defer r.mu.Unlock()
-    r.tasks[task.ID] = task
-    return task
+func (r *InMemoryTaskRepo) Descendants(workspace, id string) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return descendantsIn(r.tasks, workspace, id), nil
 }
 
-func (r *InMemoryTaskRepo) Update(id string, task models.Task) (models.Task, error) {
-    r.mu.Lock()
-    defer r.mu.Unlock()
-    _, ok := r.tasks[id]
-    if !ok {
-        return models.Task{}, ErrTaskNotFound
-    }
-    task.ID = id
-    task.UpdatedAt = time.Now()
-    r.tasks[id] = task
-    return task, nil
+func (r *InMemoryTaskRepo) GetByID(workspace, id string) (models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return taskIn(r.tasks, workspace, id)
 }
 
-func (r *InMemoryTaskRepo) Delete(id string) error {
-    r.mu.Lock()
-    defer r.mu.Unlock()
-    if _, ok := r.tasks[id]; !ok {
-        return ErrTaskNotFound
-    }
-    delete(r.tasks, id)
-    return nil
+func (r *InMemoryTaskRepo) Save(workspace string, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.tasks[task.ID]; ok && stored.Workspace != workspace {
+		return models.Task{}, ErrTaskIDTaken
+	}
+	task.Workspace = workspace
+	r.labels.update(task.ID, r.tasks[task.ID].Labels, task.Labels)
+	r.tasks[task.ID] = task
+	return task, nil
+}
+
+func (r *InMemoryTaskRepo) Update(workspace, id string, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, err := taskIn(r.tasks, workspace, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if stored.Version != task.Version {
+		return models.Task{}, ErrVersionConflict
+	}
+	task.ID = id
+	task.Workspace = workspace
+	task.Version++
+	task.UpdatedAt = time.Now()
+	r.labels.update(id, stored.Labels, task.Labels)
+	r.tasks[id] = task
+	return task, nil
+}
+
+func (r *InMemoryTaskRepo) Delete(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, err := taskIn(r.tasks, workspace, id)
+	if err != nil {
+		return err
+	}
+	r.labels.update(id, stored.Labels, nil)
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *InMemoryTaskRepo) Workspaces() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return workspacesOf(r.tasks), nil
+}
+
+// taskIn looks id up in tasks, hiding tasks that belong to other workspaces
+func taskIn(tasks map[string]models.Task, workspace, id string) (models.Task, error) {
+	task, ok := tasks[id]
+	if !ok || task.Workspace != workspace {
+		return models.Task{}, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// tasksIn returns the tasks belonging to workspace
+func tasksIn(tasks map[string]models.Task, workspace string) []models.Task {
+	result := make([]models.Task, 0)
+	for _, task := range tasks {
+		if task.Workspace == workspace {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// descendantsIn walks the live tasks under id in a single pass over tasks.
+// Each task is visited once, so cycles written before parent checks existed
+// end the walk instead of looping.
+func descendantsIn(tasks map[string]models.Task, workspace, id string) []models.Task {
+	children := make(map[string][]models.Task)
+	for _, task := range tasks {
+		if task.Workspace == workspace && task.ParentID != "" && !task.IsDeleted() {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+	result := make([]models.Task, 0)
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+		for _, child := range children[parentID] {
+			if !seen[child.ID] {
+				seen[child.ID] = true
+				result = append(result, child)
+				queue = append(queue, child.ID)
+			}
+		}
+	}
+	return result
+}
+
+// workspacesOf lists the distinct workspaces of tasks in sorted order
+func workspacesOf(tasks map[string]models.Task) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, task := range tasks {
+		if !seen[task.Workspace] {
+			seen[task.Workspace] = true
+			result = append(result, task.Workspace)
+		}
+	}
+	sort.Strings(result)
+	return result
 }
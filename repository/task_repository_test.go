@@ -1,17 +1,27 @@
 package repository
 
 import (
-	"testing"
 	"taskmanager/constants"
-	"taskmanager/errors"
+	"taskmanager/models"
 	"taskmanager/testutils"
+	"testing"
 )
 
+// mustGetAll returns every task in repo, failing the test on error
+func mustGetAll(t *testing.T, repo TaskRepository) []models.Task {
+	t.Helper()
+	tasks, err := repo.GetAll(testWorkspace)
+	if err != nil {
+		t.Fatalf("GetAll() unexpected error: %v", err)
+	}
+	return tasks
+}
+
 func TestInMemoryTaskRepo_GetAll(t *testing.T) {
 	repo := NewInMemoryTaskRepo()
 	
 	// Test empty repository
-	tasks := repo.GetAll()
+	tasks := mustGetAll(t, repo)
 	if len(tasks) != 0 {
 		t.Errorf("GetAll() on empty repo = %v, want empty slice", tasks)
 	}
@@ -23,10 +33,10 @@ func TestInMemoryTaskRepo_GetAll(t *testing.T) {
 	task2.ID = "2"
 	task2.Status = constants.StatusCompleted
 
-	repo.Save(task1)
-	repo.Save(task2)
+	repo.Save(testWorkspace, task1)
+	repo.Save(testWorkspace, task2)
 
-	tasks = repo.GetAll()
+	tasks = mustGetAll(t, repo)
 	if len(tasks) != 2 {
 		t.Errorf("GetAll() = %v, want 2 tasks", len(tasks))
 	}
@@ -38,14 +48,14 @@ func TestInMemoryTaskRepo_GetByID(t *testing.T) {
 	task.ID = "test-id"
 
 	// Test getting non-existent task
-	_, err := repo.GetByID("non-existent")
+	_, err := repo.GetByID(testWorkspace, "non-existent")
 	if err != ErrTaskNotFound {
 		t.Errorf("GetByID() error = %v, want %v", err, ErrTaskNotFound)
 	}
 
 	// Test getting existing task
-	repo.Save(task)
-	retrieved, err := repo.GetByID("test-id")
+	repo.Save(testWorkspace, task)
+	retrieved, err := repo.GetByID(testWorkspace, "test-id")
 	if err != nil {
 		t.Errorf("GetByID() unexpected error: %v", err)
 	}
@@ -60,13 +70,16 @@ func TestInMemoryTaskRepo_Save(t *testing.T) {
 	task.ID = "test-id"
 
 	// Test saving task
-	saved := repo.Save(task)
+	saved, err := repo.Save(testWorkspace, task)
+	if err != nil {
+		t.Errorf("Save() unexpected error: %v", err)
+	}
 	if saved.ID != task.ID {
 		t.Errorf("Save() = %v, want %v", saved.ID, task.ID)
 	}
 
 	// Verify task was saved
-	retrieved, err := repo.GetByID("test-id")
+	retrieved, err := repo.GetByID(testWorkspace, "test-id")
 	if err != nil {
 		t.Errorf("GetByID() after save unexpected error: %v", err)
 	}
@@ -79,7 +92,7 @@ func TestInMemoryTaskRepo_Update(t *testing.T) {
 	repo := NewInMemoryTaskRepo()
 	task := testutils.CreateTestTask()
 	task.ID = "test-id"
-	repo.Save(task)
+	repo.Save(testWorkspace, task)
 
 	// Test updating existing task
 	updatedTask := testutils.CreateTestTask()
@@ -87,7 +100,7 @@ func TestInMemoryTaskRepo_Update(t *testing.T) {
 	updatedTask.Title = "Updated Title"
 	updatedTask.Status = constants.StatusCompleted
 
-	updated, err := repo.Update("test-id", updatedTask)
+	updated, err := repo.Update(testWorkspace, "test-id", updatedTask)
 	if err != nil {
 		t.Errorf("Update() unexpected error: %v", err)
 	}
@@ -99,7 +112,7 @@ func TestInMemoryTaskRepo_Update(t *testing.T) {
 	}
 
 	// Test updating non-existent task
-	_, err = repo.Update("non-existent", updatedTask)
+	_, err = repo.Update(testWorkspace, "non-existent", updatedTask)
 	if err != ErrTaskNotFound {
 		t.Errorf("Update() error = %v, want %v", err, ErrTaskNotFound)
 	}
@@ -109,22 +122,22 @@ func TestInMemoryTaskRepo_Delete(t *testing.T) {
 	repo := NewInMemoryTaskRepo()
 	task := testutils.CreateTestTask()
 	task.ID = "test-id"
-	repo.Save(task)
+	repo.Save(testWorkspace, task)
 
 	// Test deleting existing task
-	err := repo.Delete("test-id")
+	err := repo.Delete(testWorkspace, "test-id")
 	if err != nil {
 		t.Errorf("Delete() unexpected error: %v", err)
 	}
 
 	// Verify task was deleted
-	_, err = repo.GetByID("test-id")
+	_, err = repo.GetByID(testWorkspace, "test-id")
 	if err != ErrTaskNotFound {
 		t.Errorf("GetByID() after delete = %v, want %v", err, ErrTaskNotFound)
 	}
 
 	// Test deleting non-existent task
-	err = repo.Delete("non-existent")
+	err = repo.Delete(testWorkspace, "non-existent")
 	if err != ErrTaskNotFound {
 		t.Errorf("Delete() error = %v, want %v", err, ErrTaskNotFound)
 	}
@@ -139,7 +152,7 @@ func TestInMemoryTaskRepo_Concurrency(t *testing.T) {
 		go func(i int) {
 			task := testutils.CreateTestTask()
 			task.ID = string(rune('0' + i))
-			repo.Save(task)
+			repo.Save(testWorkspace, task)
 			done <- true
 		}(i)
 	}
@@ -150,8 +163,61 @@ func TestInMemoryTaskRepo_Concurrency(t *testing.T) {
 	}
 
 	// Verify all tasks were saved
-	tasks := repo.GetAll()
+	tasks := mustGetAll(t, repo)
 	if len(tasks) != 10 {
 		t.Errorf("Concurrent saves resulted in %v tasks, want 10", len(tasks))
 	}
 }
+
+// testTaskRepoCompareAndSwap checks that Update only applies on the stored
+// version and that concurrent writers from the same version cannot both win
+func testTaskRepoCompareAndSwap(t *testing.T, repo TaskRepository) {
+	task := testutils.CreateTestTask()
+	task.ID = "test-id"
+	task.Version = 1
+	repo.Save(testWorkspace, task)
+
+	updated, err := repo.Update(testWorkspace, "test-id", task)
+	if err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("Update() version = %v, want 2", updated.Version)
+	}
+	retrieved, _ := repo.GetByID(testWorkspace, "test-id")
+	if retrieved.Version != 2 {
+		t.Errorf("GetByID() after update version = %v, want 2", retrieved.Version)
+	}
+
+	// Writing again from the old version must fail
+	if _, err := repo.Update(testWorkspace, "test-id", task); err != ErrVersionConflict {
+		t.Errorf("Update() with stale version error = %v, want %v", err, ErrVersionConflict)
+	}
+	if _, err := repo.Update(testWorkspace, "non-existent", task); err != ErrTaskNotFound {
+		t.Errorf("Update() on missing task error = %v, want %v", err, ErrTaskNotFound)
+	}
+
+	const writers = 10
+	results := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		go func() {
+			_, err := repo.Update(testWorkspace, "test-id", updated)
+			results <- err
+		}()
+	}
+	wins := 0
+	for i := 0; i < writers; i++ {
+		if err := <-results; err == nil {
+			wins++
+		} else if err != ErrVersionConflict {
+			t.Errorf("concurrent Update() unexpected error: %v", err)
+		}
+	}
+	if wins != 1 {
+		t.Errorf("concurrent Update() from one version succeeded %v times, want 1", wins)
+	}
+}
+
+func TestInMemoryTaskRepo_CompareAndSwap(t *testing.T) {
+	testTaskRepoCompareAndSwap(t, NewInMemoryTaskRepo())
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+var ErrViewNotFound = errors.NewNotFoundError("View")
+
+// ViewRepository stores saved views, partitioned by workspace like projects
+type ViewRepository interface {
+	// ListViews returns the views owner can see in the workspace: their own
+	// and every shared one, oldest first
+	ListViews(workspace, owner string) ([]models.View, error)
+	GetView(workspace, id string) (models.View, error)
+	SaveView(workspace string, view models.View) (models.View, error)
+	DeleteView(workspace, id string) error
+}
+
+type InMemoryViewRepo struct {
+	views map[string]models.View
+	mu    sync.RWMutex
+}
+
+func NewInMemoryViewRepo() *InMemoryViewRepo {
+	return &InMemoryViewRepo{
+		views: make(map[string]models.View),
+	}
+}
+
+func (r *InMemoryViewRepo) ListViews(workspace, owner string) ([]models.View, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	views := []models.View{}
+	for _, v := range r.views {
+		if v.Workspace == workspace && (v.Owner == owner || v.Shared) {
+			views = append(views, cloneView(v))
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if !views[i].CreatedAt.Equal(views[j].CreatedAt) {
+			return views[i].CreatedAt.Before(views[j].CreatedAt)
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views, nil
+}
+
+func (r *InMemoryViewRepo) GetView(workspace, id string) (models.View, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.views[id]
+	if !ok || v.Workspace != workspace {
+		return models.View{}, ErrViewNotFound
+	}
+	return cloneView(v), nil
+}
+
+func (r *InMemoryViewRepo) SaveView(workspace string, view models.View) (models.View, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.views[view.ID]; ok && stored.Workspace != workspace {
+		return models.View{}, ErrViewNotFound
+	}
+	view.Workspace = workspace
+	r.views[view.ID] = cloneView(view)
+	return view, nil
+}
+
+func (r *InMemoryViewRepo) DeleteView(workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.views[id]; !ok || v.Workspace != workspace {
+		return ErrViewNotFound
+	}
+	delete(r.views, id)
+	return nil
+}
+
+// cloneView copies the view's columns so callers cannot change stored views
+func cloneView(v models.View) models.View {
+	v.Columns = slices.Clone(v.Columns)
+	return v
+}
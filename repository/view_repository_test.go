@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testViewRepo exercises the ViewRepository contract against repo
+func testViewRepo(t *testing.T, repo ViewRepository) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mine := models.View{
+		ID: "mine", Name: "Mine", Owner: "alice", Filter: "assignedTo:@me", Sort: "-dueDate",
+		Columns: []string{"title", "dueDate"}, CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour),
+	}
+	shared := models.View{ID: "shared", Name: "Team", Owner: "bob", Shared: true, CreatedAt: base, UpdatedAt: base}
+	private := models.View{ID: "private", Name: "Bob's", Owner: "bob", CreatedAt: base, UpdatedAt: base}
+	other := models.View{ID: "other", Name: "Elsewhere", Owner: "alice", Shared: true, CreatedAt: base, UpdatedAt: base}
+	for _, v := range []models.View{mine, shared, private} {
+		if _, err := repo.SaveView(testWorkspace, v); err != nil {
+			t.Fatalf("SaveView() unexpected error: %v", err)
+		}
+	}
+	if _, err := repo.SaveView("team-b", other); err != nil {
+		t.Fatalf("SaveView() unexpected error: %v", err)
+	}
+
+	views, err := repo.ListViews(testWorkspace, "alice")
+	if err != nil {
+		t.Fatalf("ListViews() unexpected error: %v", err)
+	}
+	if len(views) != 2 || views[0].ID != "shared" || views[1].ID != "mine" {
+		t.Fatalf("ListViews() = %+v, want shared then mine", views)
+	}
+	if views, _ := repo.ListViews(testWorkspace, "bob"); len(views) != 2 || views[0].ID != "private" || views[1].ID != "shared" {
+		t.Errorf("ListViews() for bob = %+v, want private then shared", views)
+	}
+	got, err := repo.GetView(testWorkspace, "mine")
+	if err != nil || got.Name != mine.Name || got.Owner != mine.Owner || got.Filter != mine.Filter ||
+		got.Sort != mine.Sort || !slices.Equal(got.Columns, mine.Columns) || got.Shared ||
+		got.Workspace != testWorkspace || !got.CreatedAt.Equal(mine.CreatedAt) || !got.UpdatedAt.Equal(mine.UpdatedAt) {
+		t.Errorf("GetView() = %+v, %v, want %+v", got, err, mine)
+	}
+	if got, _ := repo.GetView(testWorkspace, "shared"); !got.Shared {
+		t.Errorf("GetView() = %+v, want it shared", got)
+	}
+
+	// Views in other workspaces are invisible and cannot be overwritten
+	if _, err := repo.GetView(testWorkspace, "other"); err != ErrViewNotFound {
+		t.Errorf("GetView() across workspaces error = %v, want %v", err, ErrViewNotFound)
+	}
+	if _, err := repo.SaveView(testWorkspace, other); err != ErrViewNotFound {
+		t.Errorf("SaveView() across workspaces error = %v, want %v", err, ErrViewNotFound)
+	}
+	if err := repo.DeleteView(testWorkspace, "other"); err != ErrViewNotFound {
+		t.Errorf("DeleteView() across workspaces error = %v, want %v", err, ErrViewNotFound)
+	}
+
+	mine.Name = "Mine, shared"
+	mine.Shared = true
+	repo.SaveView(testWorkspace, mine)
+	if got, _ := repo.GetView(testWorkspace, "mine"); got.Name != mine.Name || !got.Shared {
+		t.Errorf("GetView() after update = %+v, want %+v", got, mine)
+	}
+	if views, _ := repo.ListViews(testWorkspace, "bob"); len(views) != 3 {
+		t.Errorf("ListViews() for bob after sharing = %+v, want 3 views", views)
+	}
+
+	if err := repo.DeleteView(testWorkspace, "private"); err != nil {
+		t.Fatalf("DeleteView() unexpected error: %v", err)
+	}
+	if err := repo.DeleteView(testWorkspace, "private"); err != ErrViewNotFound {
+		t.Errorf("DeleteView() twice error = %v, want %v", err, ErrViewNotFound)
+	}
+}
+
+func TestInMemoryViewRepo(t *testing.T) {
+	testViewRepo(t, NewInMemoryViewRepo())
+}
+
+func TestFileViewRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileViewRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileViewRepo() unexpected error: %v", err)
+	}
+	testViewRepo(t, repo)
+
+	reopened, err := NewFileViewRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileViewRepo() reopen unexpected error: %v", err)
+	}
+	if views, _ := reopened.ListViews(testWorkspace, "alice"); len(views) != 2 || views[1].Name != "Mine, shared" {
+		t.Errorf("ListViews() after reopen = %+v, want the updated view mine", views)
+	}
+}
+
+func TestFileViewRepo_FailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileViewRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileViewRepo() unexpected error: %v", err)
+	}
+	if _, err := repo.SaveView(testWorkspace, models.View{ID: "mine", Name: "Mine", Owner: "alice"}); err != nil {
+		t.Fatalf("SaveView() unexpected error: %v", err)
+	}
+
+	// A directory in the file's place makes every rewrite fail
+	path := filepath.Join(dir, viewsFileName)
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() unexpected error: %v", err)
+	}
+	if _, err := repo.SaveView(testWorkspace, models.View{ID: "theirs", Name: "Theirs", Owner: "alice"}); err == nil {
+		t.Error("SaveView() error = nil, want the write error")
+	}
+	if err := repo.DeleteView(testWorkspace, "mine"); err == nil {
+		t.Error("DeleteView() error = nil, want the write error")
+	}
+	if views, _ := repo.ListViews(testWorkspace, "alice"); len(views) != 1 || views[0].ID != "mine" {
+		t.Errorf("ListViews() after failed writes = %+v, want only view mine", views)
+	}
+}
+
+func TestSQLViewRepo(t *testing.T) {
+	repo, err := NewSQLViewRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLViewRepo() unexpected error: %v", err)
+	}
+	testViewRepo(t, repo)
+}
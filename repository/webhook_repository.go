@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+var ErrWebhookNotFound = errors.NewNotFoundError("Webhook")
+
+// WebhookRepository stores webhook subscriptions and the log of attempts to
+// deliver events to them. Only the most recent models.MaxDeliveryLimit
+// deliveries are kept for each webhook.
+type WebhookRepository interface {
+	ListWebhooks() ([]models.Webhook, error)
+	GetWebhook(id string) (models.Webhook, error)
+	SaveWebhook(webhook models.Webhook) error
+	DeleteWebhook(id string) error
+	AppendDelivery(delivery models.WebhookDelivery) error
+	// ListDeliveries returns up to limit deliveries, newest first
+	ListDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error)
+}
+
+type InMemoryWebhookRepo struct {
+	webhooks   map[string]models.Webhook
+	deliveries map[string][]models.WebhookDelivery // oldest first
+	mu         sync.RWMutex
+}
+
+func NewInMemoryWebhookRepo() *InMemoryWebhookRepo {
+	return &InMemoryWebhookRepo{
+		webhooks:   make(map[string]models.Webhook),
+		deliveries: make(map[string][]models.WebhookDelivery),
+	}
+}
+
+func (r *InMemoryWebhookRepo) ListWebhooks() ([]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	webhooks := make([]models.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+func (r *InMemoryWebhookRepo) GetWebhook(id string) (models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.webhooks[id]
+	if !ok {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return w, nil
+}
+
+func (r *InMemoryWebhookRepo) SaveWebhook(webhook models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (r *InMemoryWebhookRepo) DeleteWebhook(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	delete(r.deliveries, id)
+	return nil
+}
+
+func (r *InMemoryWebhookRepo) AppendDelivery(delivery models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log := append(r.deliveries[delivery.WebhookID], delivery)
+	if len(log) > models.MaxDeliveryLimit {
+		log = append([]models.WebhookDelivery(nil), log[len(log)-models.MaxDeliveryLimit:]...)
+	}
+	r.deliveries[delivery.WebhookID] = log
+	return nil
+}
+
+func (r *InMemoryWebhookRepo) ListDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	log := r.deliveries[webhookID]
+	result := make([]models.WebhookDelivery, 0, min(limit, len(log)))
+	for i := len(log) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, log[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+	"path/filepath"
+	"taskmanager/models"
+	"testing"
+	"time"
+)
+
+// testWebhookRepo exercises the WebhookRepository contract against repo
+func testWebhookRepo(t *testing.T, repo WebhookRepository) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := models.Webhook{ID: "a", Workspace: testWorkspace, URL: "https://a.example.com", Secret: "s1", Active: true,
+		Events: []string{models.EventTaskCreated}, CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)}
+	b := models.Webhook{ID: "b", URL: "https://b.example.com", Secret: "s2", CreatedAt: base, UpdatedAt: base}
+	for _, w := range []models.Webhook{a, b} {
+		if err := repo.SaveWebhook(w); err != nil {
+			t.Fatalf("SaveWebhook() unexpected error: %v", err)
+		}
+	}
+
+	webhooks, err := repo.ListWebhooks()
+	if err != nil {
+		t.Fatalf("ListWebhooks() unexpected error: %v", err)
+	}
+	if len(webhooks) != 2 || webhooks[0].ID != "b" || webhooks[1].ID != "a" {
+		t.Fatalf("ListWebhooks() = %+v, want b then a", webhooks)
+	}
+	got, err := repo.GetWebhook("a")
+	if err != nil || got.URL != a.URL || got.Workspace != testWorkspace || got.Secret != "s1" || !got.Active ||
+		len(got.Events) != 1 || got.Events[0] != models.EventTaskCreated || !got.CreatedAt.Equal(a.CreatedAt) {
+		t.Errorf("GetWebhook() = %+v, %v, want %+v", got, err, a)
+	}
+	if _, err := repo.GetWebhook("missing"); err != ErrWebhookNotFound {
+		t.Errorf("GetWebhook() missing error = %v, want %v", err, ErrWebhookNotFound)
+	}
+
+	a.URL = "https://a2.example.com"
+	a.Active = false
+	repo.SaveWebhook(a)
+	if got, _ := repo.GetWebhook("a"); got.URL != a.URL || got.Active {
+		t.Errorf("GetWebhook() after update = %+v, want %+v", got, a)
+	}
+
+	// Only the most recent deliveries are kept, newest first
+	for i := 0; i < models.MaxDeliveryLimit+5; i++ {
+		d := models.WebhookDelivery{ID: fmt.Sprintf("d%d", i), WebhookID: "a", EventID: "e", EventType: models.EventTaskCreated,
+			Attempt: 1, Success: i%2 == 0, StatusCode: 200, DurationMs: 3, Timestamp: base.Add(time.Duration(i) * time.Second)}
+		if err := repo.AppendDelivery(d); err != nil {
+			t.Fatalf("AppendDelivery() unexpected error: %v", err)
+		}
+	}
+	repo.AppendDelivery(models.WebhookDelivery{ID: "other", WebhookID: "b", Timestamp: base})
+
+	deliveries, err := repo.ListDeliveries("a", 3)
+	if err != nil {
+		t.Fatalf("ListDeliveries() unexpected error: %v", err)
+	}
+	last := models.MaxDeliveryLimit + 4
+	if len(deliveries) != 3 || deliveries[0].ID != fmt.Sprintf("d%d", last) || deliveries[2].ID != fmt.Sprintf("d%d", last-2) {
+		t.Fatalf("ListDeliveries() = %+v, want the three newest", deliveries)
+	}
+	if d := deliveries[0]; !d.Success || d.StatusCode != 200 || d.DurationMs != 3 || !d.Timestamp.Equal(base.Add(time.Duration(last)*time.Second)) {
+		t.Errorf("ListDeliveries()[0] = %+v, want all fields round-tripped", d)
+	}
+	if all, _ := repo.ListDeliveries("a", models.MaxDeliveryLimit*2); len(all) != models.MaxDeliveryLimit {
+		t.Errorf("ListDeliveries() kept %d, want %d", len(all), models.MaxDeliveryLimit)
+	}
+
+	// Deleting a webhook removes its deliveries
+	if err := repo.DeleteWebhook("b"); err != nil {
+		t.Fatalf("DeleteWebhook() unexpected error: %v", err)
+	}
+	if err := repo.DeleteWebhook("b"); err != ErrWebhookNotFound {
+		t.Errorf("DeleteWebhook() twice error = %v, want %v", err, ErrWebhookNotFound)
+	}
+	if deliveries, _ := repo.ListDeliveries("b", 10); len(deliveries) != 0 {
+		t.Errorf("ListDeliveries() after delete = %+v, want none", deliveries)
+	}
+}
+
+func TestInMemoryWebhookRepo(t *testing.T) {
+	testWebhookRepo(t, NewInMemoryWebhookRepo())
+}
+
+func TestFileWebhookRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileWebhookRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileWebhookRepo() unexpected error: %v", err)
+	}
+	testWebhookRepo(t, repo)
+	repo.Close()
+
+	reopened, err := NewFileWebhookRepo(dir)
+	if err != nil {
+		t.Fatalf("NewFileWebhookRepo() reopen unexpected error: %v", err)
+	}
+	defer reopened.Close()
+	if webhooks, _ := reopened.ListWebhooks(); len(webhooks) != 1 || webhooks[0].URL != "https://a2.example.com" {
+		t.Errorf("ListWebhooks() after reopen = %+v, want the updated webhook a", webhooks)
+	}
+	if all, _ := reopened.ListDeliveries("a", models.MaxDeliveryLimit*2); len(all) != models.MaxDeliveryLimit {
+		t.Errorf("ListDeliveries() after reopen kept %d, want %d", len(all), models.MaxDeliveryLimit)
+	}
+	if err := reopened.AppendDelivery(models.WebhookDelivery{ID: "new", WebhookID: "a"}); err != nil {
+		t.Fatalf("AppendDelivery() after compaction unexpected error: %v", err)
+	}
+	if latest, _ := reopened.ListDeliveries("a", 1); len(latest) != 1 || latest[0].ID != "new" {
+		t.Errorf("ListDeliveries() = %+v, want the new delivery", latest)
+	}
+}
+
+func TestSQLWebhookRepo(t *testing.T) {
+	repo, err := NewSQLWebhookRepo(openTestDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	if err != nil {
+		t.Fatalf("NewSQLWebhookRepo() unexpected error: %v", err)
+	}
+	testWebhookRepo(t, repo)
+}
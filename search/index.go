@@ -0,0 +1,409 @@
+// Package search is an in-process full-text index over task titles and
+// descriptions. Words are lowercased and stemmed, so a search for "deploy"
+// finds "deploying" and "deployed". Results are ranked with BM25, with
+// title matches counting double, and come with highlighted snippets.
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Ranking parameters
+const (
+	// titleWeight is how much more a word in the title counts than one in
+	// the description
+	titleWeight = 2
+	// bm25K1 controls how quickly repeated words stop adding to the score
+	bm25K1 = 1.2
+	// bm25B controls how much long documents are penalised
+	bm25B = 0.75
+	// descriptionStart offsets description positions from title positions
+	// so phrases never match across the two fields
+	descriptionStart = 1 << 20
+	// snippetWords is the number of description words a snippet shows
+	snippetWords = 30
+	// snippetLead is the number of words shown before the first match
+	snippetLead = 5
+)
+
+// Highlight markers wrapped around matched words in snippets. Snippets are
+// HTML-escaped, so the markers are the only markup they contain.
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// Document is the searchable text of one task
+type Document struct {
+	ID          string
+	Title       string
+	Description string
+}
+
+// Hit is one document matching a query
+type Hit struct {
+	ID    string
+	Score float64
+	// Title is the full title with matched words highlighted
+	Title string
+	// Description is an excerpt around the first matched word of the
+	// description, or empty when only the title matched
+	Description string
+}
+
+// Index is a full-text index partitioned by workspace. It is safe for
+// concurrent use.
+type Index struct {
+	mu     sync.RWMutex
+	spaces map[string]*space
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{spaces: map[string]*space{}}
+}
+
+// space holds the documents of one workspace
+type space struct {
+	docs map[string]*indexedDoc
+	// postings maps a stemmed word to the positions it occurs at in each
+	// document
+	postings map[string]map[string][]int
+	// words counts the documents each word as written occurs in; prefix
+	// queries expand against it
+	words map[string]int
+	// totalLength is the summed length of all documents, for BM25
+	totalLength int
+}
+
+type indexedDoc struct {
+	doc         Document
+	title, desc []Token
+}
+
+func (d *indexedDoc) length() int {
+	return len(d.title) + len(d.desc)
+}
+
+// Put adds a document to the workspace, replacing any previous version
+func (ix *Index) Put(workspace string, doc Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	sp := ix.spaces[workspace]
+	if sp == nil {
+		sp = &space{docs: map[string]*indexedDoc{}, postings: map[string]map[string][]int{}, words: map[string]int{}}
+		ix.spaces[workspace] = sp
+	}
+	sp.remove(doc.ID)
+	sp.add(&indexedDoc{doc: doc, title: Tokenize(doc.Title), desc: Tokenize(doc.Description)})
+}
+
+// Remove drops a document from the workspace. Removing a document that is
+// not indexed does nothing.
+func (ix *Index) Remove(workspace, id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	sp := ix.spaces[workspace]
+	if sp == nil {
+		return
+	}
+	sp.remove(id)
+	if len(sp.docs) == 0 {
+		delete(ix.spaces, workspace)
+	}
+}
+
+// Len returns the number of documents indexed in the workspace
+func (ix *Index) Len(workspace string) int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if sp := ix.spaces[workspace]; sp != nil {
+		return len(sp.docs)
+	}
+	return 0
+}
+
+func (sp *space) add(d *indexedDoc) {
+	sp.docs[d.doc.ID] = d
+	sp.totalLength += d.length()
+	seen := map[string]bool{}
+	index := func(tokens []Token, offset int) {
+		for i, t := range tokens {
+			stem := Stem(t.Word)
+			docs := sp.postings[stem]
+			if docs == nil {
+				docs = map[string][]int{}
+				sp.postings[stem] = docs
+			}
+			docs[d.doc.ID] = append(docs[d.doc.ID], offset+i)
+			if !seen[t.Word] {
+				seen[t.Word] = true
+				sp.words[t.Word]++
+			}
+		}
+	}
+	index(d.title, 0)
+	index(d.desc, descriptionStart)
+}
+
+func (sp *space) remove(id string) {
+	d := sp.docs[id]
+	if d == nil {
+		return
+	}
+	delete(sp.docs, id)
+	sp.totalLength -= d.length()
+	seen := map[string]bool{}
+	for _, tokens := range [][]Token{d.title, d.desc} {
+		for _, t := range tokens {
+			if seen[t.Word] {
+				continue
+			}
+			seen[t.Word] = true
+			if sp.words[t.Word]--; sp.words[t.Word] == 0 {
+				delete(sp.words, t.Word)
+			}
+			stem := Stem(t.Word)
+			if docs := sp.postings[stem]; docs != nil {
+				delete(docs, id)
+				if len(docs) == 0 {
+					delete(sp.postings, stem)
+				}
+			}
+		}
+	}
+}
+
+// Search returns up to limit documents in the workspace matching every
+// clause of q, best first. Equal scores are ordered by ID.
+func (ix *Index) Search(workspace string, q Query, limit int) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	sp := ix.spaces[workspace]
+	if sp == nil || limit <= 0 {
+		return nil
+	}
+
+	var scores map[string]float64
+	for _, cl := range q.clauses {
+		matched := sp.match(cl)
+		if scores == nil {
+			scores = matched
+		} else {
+			for id, score := range scores {
+				if extra, ok := matched[id]; ok {
+					scores[id] = score + extra
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	hl := newHighlighter(q)
+	for i := range hits {
+		d := sp.docs[hits[i].ID]
+		hits[i].Title = hl.all(d.doc.Title, d.title)
+		hits[i].Description = hl.snippet(d.doc.Description, d.desc)
+	}
+	return hits
+}
+
+// match scores every document matching a single clause
+func (sp *space) match(cl clause) map[string]float64 {
+	if cl.prefix != "" {
+		scores := map[string]float64{}
+		for _, stem := range sp.expand(cl.prefix) {
+			for id, score := range sp.score(sp.postings[stem]) {
+				scores[id] = math.Max(scores[id], score)
+			}
+		}
+		return scores
+	}
+	if len(cl.terms) == 1 {
+		return sp.score(sp.postings[cl.terms[0]])
+	}
+	return sp.score(sp.phrase(cl.terms))
+}
+
+// expand returns the stems of every indexed word starting with prefix
+func (sp *space) expand(prefix string) []string {
+	seen := map[string]bool{}
+	var stems []string
+	for word := range sp.words {
+		if !strings.HasPrefix(word, prefix) {
+			continue
+		}
+		if stem := Stem(word); !seen[stem] {
+			seen[stem] = true
+			stems = append(stems, stem)
+		}
+	}
+	return stems
+}
+
+// phrase returns the positions at which terms occur consecutively, in the
+// same shape as a posting list
+func (sp *space) phrase(terms []string) map[string][]int {
+	lists := make([]map[string][]int, len(terms))
+	for i, t := range terms {
+		if lists[i] = sp.postings[t]; lists[i] == nil {
+			return nil
+		}
+	}
+	out := map[string][]int{}
+	for id, starts := range lists[0] {
+		var matched []int
+	next:
+		for _, p := range starts {
+			for i := 1; i < len(terms); i++ {
+				if !contains(lists[i][id], p+i) {
+					continue next
+				}
+			}
+			matched = append(matched, p)
+		}
+		if len(matched) > 0 {
+			out[id] = matched
+		}
+	}
+	return out
+}
+
+// contains reports whether the sorted positions include p
+func contains(positions []int, p int) bool {
+	i := sort.SearchInts(positions, p)
+	return i < len(positions) && positions[i] == p
+}
+
+// score ranks the documents of a posting list with BM25
+func (sp *space) score(postings map[string][]int) map[string]float64 {
+	scores := make(map[string]float64, len(postings))
+	if len(postings) == 0 {
+		return scores
+	}
+	n := float64(len(sp.docs))
+	df := float64(len(postings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	avg := float64(sp.totalLength) / n
+	for id, positions := range postings {
+		tf := 0.0
+		for _, p := range positions {
+			if p < descriptionStart {
+				tf += titleWeight
+			} else {
+				tf++
+			}
+		}
+		norm := 1 - bm25B
+		if avg > 0 {
+			norm += bm25B * float64(sp.docs[id].length()) / avg
+		}
+		scores[id] = idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+	return scores
+}
+
+// highlighter marks the words of a text that a query matched
+type highlighter struct {
+	stems    map[string]bool
+	prefixes []string
+}
+
+func newHighlighter(q Query) highlighter {
+	hl := highlighter{stems: map[string]bool{}}
+	for _, cl := range q.clauses {
+		if cl.prefix != "" {
+			hl.prefixes = append(hl.prefixes, cl.prefix)
+		}
+		for _, t := range cl.terms {
+			hl.stems[t] = true
+		}
+	}
+	return hl
+}
+
+func (hl highlighter) matches(t Token) bool {
+	for _, p := range hl.prefixes {
+		if strings.HasPrefix(t.Word, p) {
+			return true
+		}
+	}
+	return hl.stems[Stem(t.Word)]
+}
+
+// all returns the whole text with matched words highlighted
+func (hl highlighter) all(text string, tokens []Token) string {
+	return hl.mark(text, tokens, 0, len(text))
+}
+
+// snippet returns an excerpt of text around its first matched word, or ""
+// if no word matched
+func (hl highlighter) snippet(text string, tokens []Token) string {
+	first := -1
+	for i, t := range tokens {
+		if hl.matches(t) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+	from := max(first-snippetLead, 0)
+	to := min(from+snippetWords, len(tokens))
+	start, end := 0, len(text)
+	var b strings.Builder
+	if from > 0 {
+		start = tokens[from].Start
+		b.WriteString("…")
+	}
+	if to < len(tokens) {
+		end = tokens[to-1].End
+	}
+	b.WriteString(strings.TrimSpace(hl.mark(text, tokens[from:to], start, end)))
+	if to < len(tokens) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// mark escapes text[start:end] and wraps the matched tokens, which must lie
+// within it, in highlight markers
+func (hl highlighter) mark(text string, tokens []Token, start, end int) string {
+	var b strings.Builder
+	pos := start
+	for _, t := range tokens {
+		if !hl.matches(t) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.Start]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[t.Start:t.End]))
+		b.WriteString(HighlightEnd)
+		pos = t.End
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyQuery is returned by Parse for a query without any words
+var ErrEmptyQuery = errors.New("query must contain at least one word")
+
+// MinPrefixLength is the shortest prefix a prefix query may use
+const MinPrefixLength = 2
+
+// SyntaxError describes a malformed query
+type SyntaxError struct {
+	// Column is the 1-based character position of the problem
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Column)
+}
+
+// Query is a parsed search query. A task matches when it matches every
+// clause.
+type Query struct {
+	clauses []clause
+}
+
+// clause is a single word, a quoted phrase or a prefix
+type clause struct {
+	// terms holds the stemmed words of a word or phrase clause in order
+	terms []string
+	// prefix is set for prefix clauses and matches words as written
+	prefix string
+}
+
+// Parse parses a query of space-separated clauses:
+//
+//	deploy          tasks mentioning deploy, deploying, deployed, ...
+//	"release notes" tasks with the words next to each other, in order
+//	migr*           tasks with a word starting with migr
+//
+// Punctuation inside a bare word splits it into a phrase, so front-end
+// matches "front end".
+func Parse(q string) (Query, error) {
+	var query Query
+	i := 0
+	for i < len(q) {
+		switch c := q[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := strings.IndexByte(q[i+1:], '"')
+			if end < 0 {
+				return Query{}, &SyntaxError{Column: column(q, i), Msg: "unterminated phrase"}
+			}
+			text := q[i+1 : i+1+end]
+			if cl, ok := wordClause(text); ok {
+				query.clauses = append(query.clauses, cl)
+			}
+			i += end + 2
+		default:
+			end := strings.IndexAny(q[i:], " \t\n\r\"")
+			if end < 0 {
+				end = len(q) - i
+			}
+			cl, ok, err := bareClause(q, i, i+end)
+			if err != nil {
+				return Query{}, err
+			}
+			if ok {
+				query.clauses = append(query.clauses, cl)
+			}
+			i += end
+		}
+	}
+	if len(query.clauses) == 0 {
+		return Query{}, ErrEmptyQuery
+	}
+	return query, nil
+}
+
+// bareClause parses the unquoted word q[start:end]
+func bareClause(q string, start, end int) (clause, bool, error) {
+	text := q[start:end]
+	star := strings.IndexByte(text, '*')
+	if star < 0 {
+		cl, ok := wordClause(text)
+		return cl, ok, nil
+	}
+	if star != len(text)-1 {
+		return clause{}, false, &SyntaxError{Column: column(q, start+star), Msg: "'*' is only allowed at the end of a word"}
+	}
+	tokens := Tokenize(text[:star])
+	if len(tokens) != 1 || tokens[0].Start != 0 || tokens[0].End != star {
+		return clause{}, false, &SyntaxError{Column: column(q, start), Msg: "a prefix must be a single word"}
+	}
+	if len([]rune(tokens[0].Word)) < MinPrefixLength {
+		return clause{}, false, &SyntaxError{
+			Column: column(q, start),
+			Msg:    fmt.Sprintf("a prefix must be at least %d characters", MinPrefixLength),
+		}
+	}
+	return clause{prefix: tokens[0].Word}, true, nil
+}
+
+// wordClause turns text into a word clause, or a phrase when it holds
+// several words
+func wordClause(text string) (clause, bool) {
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return clause{}, false
+	}
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = Stem(t.Word)
+	}
+	return clause{terms: terms}, true
+}
@@ -0,0 +1,238 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Fix the Front-end, café v2!")
+	want := []Token{
+		{Word: "fix", Start: 0, End: 3},
+		{Word: "the", Start: 4, End: 7},
+		{Word: "front", Start: 8, End: 13},
+		{Word: "end", Start: 14, End: 17},
+		{Word: "café", Start: 19, End: 24},
+		{Word: "v2", Start: 25, End: 27},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %+v, want %+v", got, want)
+	}
+	if got := Tokenize(" -- "); len(got) != 0 {
+		t.Errorf("Tokenize(punctuation) = %+v, want none", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []clause
+	}{
+		{"deploying", []clause{{terms: []string{"deploi"}}}},
+		{`bug "release notes"`, []clause{{terms: []string{"bug"}}, {terms: []string{"releas", "note"}}}},
+		{"front-end", []clause{{terms: []string{"front", "end"}}}},
+		{"migr*  DB", []clause{{prefix: "migr"}, {terms: []string{"db"}}}},
+		{`"" fix`, []clause{{terms: []string{"fix"}}}},
+		{"a\"b c\"", []clause{{terms: []string{"a"}}, {terms: []string{"b", "c"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(q.clauses, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", q.clauses, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		column int
+		msg    string
+	}{
+		{`fix "release notes`, 5, "unterminated phrase"},
+		{`é "x`, 3, "unterminated phrase"},
+		{"fix de*ploy", 7, "'*' is only allowed at the end of a word"},
+		{"x a*", 3, "a prefix must be at least 2 characters"},
+		{"front-en*", 1, "a prefix must be a single word"},
+		{"*", 1, "a prefix must be a single word"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("Parse() error = %v, want a SyntaxError", err)
+			}
+			if syntax.Column != tt.column || syntax.Msg != tt.msg {
+				t.Errorf("Parse() error = %q at %d, want %q at %d", syntax.Msg, syntax.Column, tt.msg, tt.column)
+			}
+		})
+	}
+
+	for _, q := range []string{"", "   ", "-- !", `" "`} {
+		if _, err := Parse(q); !errors.Is(err, ErrEmptyQuery) {
+			t.Errorf("Parse(%q) error = %v, want ErrEmptyQuery", q, err)
+		}
+	}
+}
+
+func search(t *testing.T, ix *Index, workspace, q string) []Hit {
+	t.Helper()
+	query, err := Parse(q)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", q, err)
+	}
+	return ix.Search(workspace, query, 10)
+}
+
+func ids(hits []Hit) []string {
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.ID
+	}
+	return out
+}
+
+func newTestIndex() *Index {
+	ix := NewIndex()
+	ix.Put("ws", Document{ID: "1", Title: "Deploy the release", Description: "Roll out version 2 to production"})
+	ix.Put("ws", Document{ID: "2", Title: "Write release notes", Description: "Summarise what deploying v2 changes"})
+	ix.Put("ws", Document{ID: "3", Title: "Database migration", Description: "Migrate the notes table before the release"})
+	ix.Put("ws", Document{ID: "4", Title: "Plan offsite"})
+	return ix
+}
+
+func TestIndexSearch(t *testing.T) {
+	ix := newTestIndex()
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// stemming matches other forms of the word, title matches rank first
+		{"deployed", []string{"1", "2"}},
+		// every clause must match
+		{"release notes", []string{"2", "3"}},
+		// phrases need the words together and in order
+		{`"release notes"`, []string{"2"}},
+		{`"notes release"`, nil},
+		// phrases do not run from the title into the description
+		{`"release roll"`, nil},
+		{"migr*", []string{"3"}},
+		{"migr* notes", []string{"3"}},
+		{"MIGRATION", []string{"3"}},
+		{"offsite", []string{"4"}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := ids(search(t, ix, "ws", tt.query))
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexRanking(t *testing.T) {
+	ix := NewIndex()
+	ix.Put("ws", Document{ID: "body", Title: "Chores", Description: "fix the printer"})
+	ix.Put("ws", Document{ID: "title", Title: "Fix the printer"})
+	ix.Put("ws", Document{ID: "long", Title: "Errands", Description: "buy milk, call the bank, water plants, book flights and fix the printer"})
+
+	hits := search(t, ix, "ws", "printer")
+	if got := ids(hits); !reflect.DeepEqual(got, []string{"title", "body", "long"}) {
+		t.Errorf("ranking = %v, want title, then short body, then long body", got)
+	}
+	for i := 1; i < len(hits); i++ {
+		if hits[i].Score >= hits[i-1].Score {
+			t.Errorf("scores not descending: %v", hits)
+		}
+	}
+
+	query, _ := Parse("printer")
+	if got := ix.Search("ws", query, 1); len(got) != 1 || got[0].ID != "title" {
+		t.Errorf("Search(limit 1) = %v", ids(got))
+	}
+}
+
+func TestIndexPutAndRemove(t *testing.T) {
+	ix := newTestIndex()
+
+	ix.Put("ws", Document{ID: "1", Title: "Cancel the launch"})
+	if got := ids(search(t, ix, "ws", "deploy")); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("after replace, Search(deploy) = %v", got)
+	}
+	if got := ids(search(t, ix, "ws", "launch")); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("after replace, Search(launch) = %v", got)
+	}
+
+	ix.Remove("ws", "3")
+	ix.Remove("ws", "3")
+	if got := search(t, ix, "ws", "migr*"); len(got) != 0 {
+		t.Errorf("after remove, Search(migr*) = %v", ids(got))
+	}
+	if ix.Len("ws") != 3 {
+		t.Errorf("Len() = %d, want 3", ix.Len("ws"))
+	}
+
+	for _, id := range []string{"1", "2", "4"} {
+		ix.Remove("ws", id)
+	}
+	if ix.Len("ws") != 0 || len(ix.spaces) != 0 {
+		t.Errorf("index not empty after removing every document: %+v", ix.spaces)
+	}
+}
+
+func TestIndexWorkspaces(t *testing.T) {
+	ix := newTestIndex()
+	ix.Put("other", Document{ID: "9", Title: "Deploy the website"})
+
+	if got := ids(search(t, ix, "other", "deploy")); !reflect.DeepEqual(got, []string{"9"}) {
+		t.Errorf("Search(other) = %v, want only the other workspace", got)
+	}
+	if got := ids(search(t, ix, "ws", "website")); len(got) != 0 {
+		t.Errorf("Search(ws, website) = %v, want none", got)
+	}
+	if got := search(t, ix, "missing", "deploy"); got != nil {
+		t.Errorf("Search(missing workspace) = %v", got)
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	ix := NewIndex()
+	long := strings.Repeat("filler ", 20) + "the <b>deploy</b> failed " + strings.Repeat("more ", 40)
+	ix.Put("ws", Document{ID: "1", Title: "Deploying & rolling back", Description: long})
+	ix.Put("ws", Document{ID: "2", Title: "Migrations", Description: "Migrating users, then migrate  orders."})
+
+	hit := search(t, ix, "ws", "deploy")[0]
+	if want := "<mark>Deploying</mark> &amp; rolling back"; hit.Title != want {
+		t.Errorf("Title = %q, want %q", hit.Title, want)
+	}
+	wantDesc := "…filler filler filler the &lt;b&gt;<mark>deploy</mark>&lt;/b&gt; failed" + strings.Repeat(" more", 22) + "…"
+	if hit.Description != wantDesc {
+		t.Errorf("Description = %q, want %q", hit.Description, wantDesc)
+	}
+
+	hit = search(t, ix, "ws", "migrat*")[0]
+	if want := "<mark>Migrations</mark>"; hit.Title != want {
+		t.Errorf("Title = %q, want %q", hit.Title, want)
+	}
+	if want := "<mark>Migrating</mark> users, then <mark>migrate</mark>  orders."; hit.Description != want {
+		t.Errorf("Description = %q, want %q", hit.Description, want)
+	}
+
+	hit = search(t, ix, "ws", "rolling")[0]
+	if hit.Description != "" {
+		t.Errorf("Description = %q, want empty when only the title matched", hit.Description)
+	}
+}
@@ -0,0 +1,260 @@
+package search
+
+// Stem reduces an English word to its stem with the Porter algorithm
+// (M.F. Porter, "An algorithm for suffix stripping", 1980), so that
+// "deploying", "deployed" and "deployment" all become "deploy". Words that
+// are not plain lowercase ASCII, and words of two letters or fewer, are
+// returned unchanged.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+	z := &stemmer{b: []byte(word), k: len(word) - 1}
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+	return string(z.b[:z.k+1])
+}
+
+// stemmer holds a word being stemmed in b[0..k]. j marks the end of the
+// stem left by the last successful ends call.
+type stemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant. Y is a consonant at the start
+// of a word or after a vowel.
+func (z *stemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !z.cons(i-1)
+	}
+	return true
+}
+
+// m measures the number of vowel-consonant sequences in b[0..j]
+func (z *stemmer) m() int {
+	n, i := 0, 0
+	for ; ; i++ {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+	}
+	i++
+	for {
+		for ; ; i++ {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+		}
+		i++
+		n++
+		for ; ; i++ {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[0..j] contains a vowel
+func (z *stemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doublec reports whether b[i-1..i] is a double consonant
+func (z *stemmer) doublec(i int) bool {
+	return i >= 1 && z.b[i] == z.b[i-1] && z.cons(i)
+}
+
+// cvc reports whether b[i-2..i] is consonant-vowel-consonant with the last
+// consonant not w, x or y, as in "hop" but not "snow"
+func (z *stemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether b[0..k] ends with s, and if so sets j to the end of
+// what precedes it
+func (z *stemmer) ends(s string) bool {
+	l := len(s)
+	if l > z.k+1 || string(z.b[z.k-l+1:z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - l
+	return true
+}
+
+// setto replaces b[j+1..k] with s
+func (z *stemmer) setto(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = len(z.b) - 1
+}
+
+// r replaces the suffix with s if the remaining stem has a measure above 0
+func (z *stemmer) r(s string) {
+	if z.m() > 0 {
+		z.setto(s)
+	}
+}
+
+// step1ab removes plurals and -ed or -ing
+func (z *stemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setto("i")
+		case z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+	} else if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+		switch {
+		case z.ends("at"):
+			z.setto("ate")
+		case z.ends("bl"):
+			z.setto("ble")
+		case z.ends("iz"):
+			z.setto("ize")
+		case z.doublec(z.k):
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		case z.m() == 1 && z.cvc(z.k):
+			z.setto("e")
+		}
+	}
+}
+
+// step1c turns a final y into i when there is another vowel in the stem
+func (z *stemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// suffixRule replaces a suffix with a shorter one
+type suffixRule struct{ suffix, replacement string }
+
+// applyFirst applies the first rule whose suffix matches
+func (z *stemmer) applyFirst(rules []suffixRule) {
+	for _, rule := range rules {
+		if z.ends(rule.suffix) {
+			z.r(rule.replacement)
+			return
+		}
+	}
+}
+
+// step2 maps double suffixes to single ones, so -ization becomes -ize
+func (z *stemmer) step2() {
+	rules := map[byte][]suffixRule{
+		'a': {{"ational", "ate"}, {"tional", "tion"}},
+		'c': {{"enci", "ence"}, {"anci", "ance"}},
+		'e': {{"izer", "ize"}},
+		'l': {{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}},
+		'o': {{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}},
+		's': {{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}},
+		't': {{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}},
+		'g': {{"logi", "log"}},
+	}
+	z.applyFirst(rules[z.b[z.k-1]])
+}
+
+// step3 handles -ic-, -full, -ness and similar
+func (z *stemmer) step3() {
+	rules := map[byte][]suffixRule{
+		'e': {{"icate", "ic"}, {"ative", ""}, {"alize", "al"}},
+		'i': {{"iciti", "ic"}},
+		'l': {{"ical", "ic"}, {"ful", ""}},
+		's': {{"ness", ""}},
+	}
+	z.applyFirst(rules[z.b[z.k]])
+}
+
+// step4 removes -ant, -ence and similar from stems with a measure above 1
+func (z *stemmer) step4() {
+	suffixes := map[byte][]string{
+		'a': {"al"},
+		'c': {"ance", "ence"},
+		'e': {"er"},
+		'i': {"ic"},
+		'l': {"able", "ible"},
+		'n': {"ant", "ement", "ment", "ent"},
+		's': {"ism"},
+		't': {"ate", "iti"},
+		'u': {"ous"},
+		'v': {"ive"},
+		'z': {"ize"},
+	}
+	matched := false
+	if z.b[z.k-1] == 'o' {
+		matched = (z.ends("ion") && z.j >= 0 && (z.b[z.j] == 's' || z.b[z.j] == 't')) || z.ends("ou")
+	} else {
+		for _, s := range suffixes[z.b[z.k-1]] {
+			if z.ends(s) {
+				matched = true
+				break
+			}
+		}
+	}
+	if matched && z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+// step5 removes a final -e and turns -ll into -l on longer stems
+func (z *stemmer) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		if a := z.m(); a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+	if z.b[z.k] == 'l' && z.doublec(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
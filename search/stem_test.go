@@ -0,0 +1,58 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	// Most cases are from Porter's paper
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"ties":           "ti",
+		"caress":         "caress",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"troubled":       "troubl",
+		"sized":          "size",
+		"hopping":        "hop",
+		"tanned":         "tan",
+		"falling":        "fall",
+		"hissing":        "hiss",
+		"fizzed":         "fizz",
+		"failing":        "fail",
+		"filing":         "file",
+		"happy":          "happi",
+		"sky":            "sky",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"rational":       "ration",
+		"valenci":        "valenc",
+		"digitizer":      "digit",
+		"generalization": "gener",
+		"hopefulness":    "hope",
+		"triplicate":     "triplic",
+		"formalize":      "formal",
+		"electrical":     "electr",
+		"goodness":       "good",
+		"revival":        "reviv",
+		"adoption":       "adopt",
+		"controll":       "control",
+		"roll":           "roll",
+		"deploying":      "deploi",
+		"deployed":       "deploi",
+		"deployment":     "deploy",
+		"deploys":        "deploi",
+		"go":             "go",
+		"v2":             "v2",
+		"café":           "café",
+	}
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is one word of a text
+type Token struct {
+	// Word is the lowercased word as written
+	Word string
+	// Start and End are the byte offsets of the word in the original text
+	Start, End int
+}
+
+// Tokenize splits text into lowercased words. A word is a run of letters
+// and digits; everything else separates words.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+	return tokens
+}
+
+func newToken(text string, start, end int) Token {
+	return Token{Word: strings.ToLower(text[start:end]), Start: start, End: end}
+}
+
+// column converts a byte offset in s to a 1-based character column
+func column(s string, offset int) int {
+	return utf8.RuneCountInString(s[:offset]) + 1
+}
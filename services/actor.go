@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"taskmanager/constants"
+	"taskmanager/models"
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx that attributes changes to actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, or AnonymousActor if none is
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return constants.AnonymousActor
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+// Changes made with it are attributed to the principal's subject.
+func WithPrincipal(ctx context.Context, p models.Principal) context.Context {
+	return WithActor(context.WithValue(ctx, principalKey{}, p), p.Subject)
+}
+
+// PrincipalFromContext returns the principal stored in ctx and whether there
+// is one. Requests are only unauthenticated when authentication is disabled.
+func PrincipalFromContext(ctx context.Context) (models.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(models.Principal)
+	return p, ok
+}
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a copy of ctx confined to workspace
+func WithWorkspace(ctx context.Context, workspace string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// WorkspaceFromContext returns the workspace stored in ctx, or
+// DefaultWorkspace if none is
+func WorkspaceFromContext(ctx context.Context) string {
+	if ws, ok := ctx.Value(workspaceKey{}).(string); ok && ws != "" {
+		return ws
+	}
+	return constants.DefaultWorkspace
+}
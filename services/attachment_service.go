@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttachmentStore keeps attachment metadata in a repository and their
+// content in a BlobStore. Identical files share one blob, which is deleted
+// along with the last attachment that refers to it. Blobs are written
+// outside the lock, but saving an attachment and releasing a blob hold it,
+// so a blob is never removed once an attachment refers to it.
+type AttachmentStore struct {
+	repo  repository.AttachmentRepository
+	blobs repository.BlobStore
+	mu    sync.Mutex
+	// uploads counts blobs being written. Their hashes are not known until
+	// the write finishes, so any of them may be a blob being released.
+	uploads int
+	// deferred holds blobs released while uploads were in progress
+	deferred map[string]bool
+}
+
+func NewAttachmentStore(repo repository.AttachmentRepository, blobs repository.BlobStore) *AttachmentStore {
+	return &AttachmentStore{repo: repo, blobs: blobs, deferred: make(map[string]bool)}
+}
+
+// add stores content and saves attachment pointing at it. The blob is
+// written without the lock so a slow upload holds up no one; blobs released
+// meanwhile are kept until it is saved, as it may share their content.
+func (s *AttachmentStore) add(workspace string, attachment models.Attachment, content io.Reader) (models.Attachment, error) {
+	s.mu.Lock()
+	s.uploads++
+	s.mu.Unlock()
+
+	hash, size, err := s.blobs.Put(content)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads--
+	defer s.releaseDeferred()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	attachment.SHA256 = hash
+	attachment.Size = size
+	saved, err := s.repo.SaveAttachment(workspace, attachment)
+	if err != nil {
+		if releaseErr := s.release(hash); releaseErr != nil {
+			log.Printf("release blob %s: %v", hash, releaseErr)
+		}
+		return models.Attachment{}, err
+	}
+	return saved, nil
+}
+
+func (s *AttachmentStore) remove(workspace, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attachment, err := s.repo.GetAttachment(workspace, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAttachment(workspace, id); err != nil {
+		return err
+	}
+	return s.release(attachment.SHA256)
+}
+
+// removeTask deletes every attachment on a task
+func (s *AttachmentStore) removeTask(workspace, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed, err := s.repo.DeleteTaskAttachments(workspace, taskID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range removed {
+		if err := s.release(attachment.SHA256); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release deletes a blob no attachment refers to any more, or defers that
+// while uploads are in progress. The caller holds the lock.
+func (s *AttachmentStore) release(hash string) error {
+	if s.uploads > 0 {
+		s.deferred[hash] = true
+		return nil
+	}
+	inUse, err := s.repo.BlobInUse(hash)
+	if err != nil || inUse {
+		return err
+	}
+	return s.blobs.Delete(hash)
+}
+
+// releaseDeferred releases the blobs deferred by release once no upload is
+// in progress. The caller holds the lock.
+func (s *AttachmentStore) releaseDeferred() {
+	if s.uploads > 0 {
+		return
+	}
+	for hash := range s.deferred {
+		delete(s.deferred, hash)
+		if err := s.release(hash); err != nil {
+			log.Printf("release blob %s: %v", hash, err)
+		}
+	}
+}
+
+type AttachmentService interface {
+	ListAttachments(ctx context.Context, taskID string) ([]models.Attachment, error)
+	GetAttachment(ctx context.Context, taskID, id string) (models.Attachment, error)
+	UploadAttachment(ctx context.Context, taskID, filename string, content io.Reader) (models.Attachment, error)
+	// OpenAttachment returns an attachment and its content, which the
+	// caller must close
+	OpenAttachment(ctx context.Context, taskID, id string) (models.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, taskID, id string) error
+}
+
+type attachmentService struct {
+	store  *AttachmentStore
+	tasks  TaskService
+	policy models.RolePolicy
+	limits models.AttachmentLimits
+}
+
+// NewAttachmentService lets principals who can read a task read its
+// attachments, and those with attachments:write upload files and delete
+// their own. Deleting someone else's takes tasks:edit. Uploads must fit
+// limits; their type is sniffed from the content rather than trusted from
+// the client. A nil policy means models.DefaultRolePolicy.
+func NewAttachmentService(store *AttachmentStore, tasks TaskService, policy models.RolePolicy, limits models.AttachmentLimits) AttachmentService {
+	return &attachmentService{store: store, tasks: tasks, policy: policyOrDefault(policy), limits: limits}
+}
+
+func (s *attachmentService) ListAttachments(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.store.repo.ListAttachments(WorkspaceFromContext(ctx), taskID)
+}
+
+// GetAttachment returns an attachment, provided it belongs to the task
+func (s *attachmentService) GetAttachment(ctx context.Context, taskID, id string) (models.Attachment, error) {
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return models.Attachment{}, err
+	}
+	attachment, err := s.store.repo.GetAttachment(WorkspaceFromContext(ctx), id)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	if attachment.TaskID != taskID {
+		return models.Attachment{}, repository.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// UploadAttachment attaches content to a task as the caller. Content larger
+// than the size limit is rejected with a 413 and content of a type that is
+// not allowed with a 415, without being stored.
+func (s *attachmentService) UploadAttachment(ctx context.Context, taskID, filename string, content io.Reader) (models.Attachment, error) {
+	if err := authorize(ctx, s.policy, models.PermWriteAttachments); err != nil {
+		return models.Attachment{}, err
+	}
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return models.Attachment{}, err
+	}
+	filename, err := models.CleanFilename(filename)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	buffered := bufio.NewReaderSize(content, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return models.Attachment{}, err
+	}
+	if len(head) == 0 {
+		return models.Attachment{}, errors.NewValidationError("file", constants.ValidationFileEmpty)
+	}
+	contentType := http.DetectContentType(head)
+	if !s.limits.Allows(contentType) {
+		return models.Attachment{}, errors.NewAppError(http.StatusUnsupportedMediaType, fmt.Sprintf(constants.MessageAttachmentType, contentType))
+	}
+
+	tooLarge := errors.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf(constants.MessageAttachmentTooLarge, s.limits.MaxSize))
+	attachment := models.Attachment{
+		ID:          uuid.NewString(),
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		UploadedBy:  ActorFromContext(ctx),
+		CreatedAt:   time.Now(),
+	}
+	return s.store.add(WorkspaceFromContext(ctx), attachment, &limitedReader{r: buffered, n: s.limits.MaxSize, err: tooLarge})
+}
+
+func (s *attachmentService) OpenAttachment(ctx context.Context, taskID, id string) (models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachment(ctx, taskID, id)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	content, err := s.store.blobs.Open(attachment.SHA256)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (s *attachmentService) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	attachment, err := s.GetAttachment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	p, ok := PrincipalFromContext(ctx)
+	if ok && !s.policy.Grants(p.Roles, models.PermEditTasks) &&
+		(attachment.UploadedBy != p.Subject || !s.policy.Grants(p.Roles, models.PermWriteAttachments)) {
+		return errors.NewForbiddenError(fmt.Sprintf(constants.MessageForbiddenAttachment, models.PermEditTasks, models.PermWriteAttachments))
+	}
+	return s.store.remove(WorkspaceFromContext(ctx), id)
+}
+
+// limitedReader fails with err once more than n bytes have been read
+type limitedReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, l.err
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return 0, l.err
+	}
+	return n, err
+}
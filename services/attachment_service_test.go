@@ -0,0 +1,297 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// trackedBlobs records which blobs are currently stored
+type trackedBlobs struct {
+	*repository.InMemoryBlobStore
+	stored map[string]bool
+}
+
+func (b *trackedBlobs) Put(r io.Reader) (string, int64, error) {
+	hash, size, err := b.InMemoryBlobStore.Put(r)
+	if err == nil {
+		b.stored[hash] = true
+	}
+	return hash, size, err
+}
+
+func (b *trackedBlobs) Delete(hash string) error {
+	delete(b.stored, hash)
+	return b.InMemoryBlobStore.Delete(hash)
+}
+
+func isAppError(err error, code int) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == code
+}
+
+func TestAttachmentService(t *testing.T) {
+	blobs := &trackedBlobs{repository.NewInMemoryBlobStore(), map[string]bool{}}
+	store := NewAttachmentStore(repository.NewInMemoryAttachmentRepo(), blobs)
+	tasks := NewTaskService(NewMockTaskRepository(), WithAttachments(store))
+	limits := models.AttachmentLimits{MaxSize: 64, AllowedTypes: []string{"image/png", "text/plain"}}
+	service := NewAttachmentService(store, tasks, nil, limits)
+	task, _ := tasks.CreateTask(ctx, testutils.CreateTestTask())
+	other, _ := tasks.CreateTask(ctx, testutils.CreateTestTask())
+
+	upload := func(taskID, name string, content []byte) (models.Attachment, error) {
+		return service.UploadAttachment(WithActor(ctx, "alice"), taskID, name, bytes.NewReader(content))
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+		check   func(error) bool
+	}{
+		{"Missing task", "a.png", pngHeader, func(err error) bool { return err == repository.ErrTaskNotFound }},
+		{"Empty file", "a.png", nil, func(err error) bool { return isValidationError(err, "file") }},
+		{"No filename", "", pngHeader, func(err error) bool { return isValidationError(err, "file") }},
+		{"Type not allowed", "page.html", []byte("<html><body>hi</body></html>"), func(err error) bool { return isAppError(err, http.StatusUnsupportedMediaType) }},
+		{"Too large", "big.log", bytes.Repeat([]byte("x"), 65), func(err error) bool { return isAppError(err, http.StatusRequestEntityTooLarge) }},
+	}
+	for _, tt := range tests {
+		taskID := task.ID
+		if tt.name == "Missing task" {
+			taskID = "missing"
+		}
+		if _, err := upload(taskID, tt.file, tt.content); !tt.check(err) {
+			t.Errorf("%s: UploadAttachment() error = %v", tt.name, err)
+		}
+	}
+	if len(blobs.stored) != 0 {
+		t.Fatalf("rejected uploads stored %d blobs, want none", len(blobs.stored))
+	}
+
+	log := []byte(strings.Repeat("line\n", 12))
+	first, err := upload(task.ID, "../build.log", log)
+	if err != nil {
+		t.Fatalf("UploadAttachment() unexpected error: %v", err)
+	}
+	if first.Filename != "build.log" || first.ContentType != "text/plain; charset=utf-8" || first.Size != 60 || first.UploadedBy != "alice" || len(first.SHA256) != 64 {
+		t.Errorf("UploadAttachment() = %+v", first)
+	}
+	dup, _ := upload(other.ID, "same.log", log)
+	if dup.SHA256 != first.SHA256 || len(blobs.stored) != 1 {
+		t.Errorf("identical uploads stored %d blobs, want them to share one", len(blobs.stored))
+	}
+	image, _ := upload(task.ID, "shot.png", pngHeader)
+
+	_, content, err := service.OpenAttachment(ctx, task.ID, first.ID)
+	if err != nil {
+		t.Fatalf("OpenAttachment() unexpected error: %v", err)
+	}
+	got, _ := io.ReadAll(content)
+	content.Close()
+	if !bytes.Equal(got, log) {
+		t.Errorf("OpenAttachment() content = %q, want %q", got, log)
+	}
+	if _, _, err := service.OpenAttachment(ctx, other.ID, first.ID); err != repository.ErrAttachmentNotFound {
+		t.Errorf("OpenAttachment() through another task error = %v, want %v", err, repository.ErrAttachmentNotFound)
+	}
+	if list, _ := service.ListAttachments(ctx, task.ID); len(list) != 2 {
+		t.Errorf("ListAttachments() = %+v, want 2", list)
+	}
+
+	// The shared blob survives until its last attachment goes
+	if err := service.DeleteAttachment(ctx, task.ID, first.ID); err != nil {
+		t.Fatalf("DeleteAttachment() unexpected error: %v", err)
+	}
+	if _, content, err := service.OpenAttachment(ctx, other.ID, dup.ID); err != nil {
+		t.Errorf("OpenAttachment() of the copy after deleting the original error = %v", err)
+	} else {
+		content.Close()
+	}
+
+	t.Run("Purge removes attachments and blobs", func(t *testing.T) {
+		tasks.DeleteTask(ctx, task.ID, 0)
+		if _, err := service.ListAttachments(ctx, task.ID); err != repository.ErrTaskNotFound {
+			t.Errorf("ListAttachments() on a trashed task error = %v, want %v", err, repository.ErrTaskNotFound)
+		}
+		if _, err := tasks.PurgeTrash(ctx, time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("PurgeTrash() unexpected error: %v", err)
+		}
+		if _, err := blobs.Open(image.SHA256); err != repository.ErrBlobNotFound {
+			t.Errorf("blob of a purged task's attachment error = %v, want %v", err, repository.ErrBlobNotFound)
+		}
+		if len(blobs.stored) != 1 {
+			t.Errorf("blobs after purge = %d, want only the other task's", len(blobs.stored))
+		}
+	})
+}
+
+func TestAttachmentService_EnforcesRolePolicy(t *testing.T) {
+	store := NewAttachmentStore(repository.NewInMemoryAttachmentRepo(), repository.NewInMemoryBlobStore())
+	tasks := NewTaskService(NewMockTaskRepository())
+	service := NewAttachmentService(store, tasks, nil, models.DefaultAttachmentLimits())
+	task, _ := tasks.CreateTask(ctx, testutils.CreateTestTask())
+	viewer := WithPrincipal(ctx, as("vera", models.RoleViewer))
+	member := WithPrincipal(ctx, as("mo", models.RoleMember))
+	other := WithPrincipal(ctx, as("max", models.RoleMember))
+	admin := WithPrincipal(ctx, as("ada", models.RoleAdmin))
+
+	if _, err := service.UploadAttachment(viewer, task.ID, "a.png", bytes.NewReader(pngHeader)); !isForbidden(err) {
+		t.Errorf("UploadAttachment() as viewer error = %v, want 403", err)
+	}
+	mine, err := service.UploadAttachment(member, task.ID, "a.png", bytes.NewReader(pngHeader))
+	if err != nil || mine.UploadedBy != "mo" {
+		t.Fatalf("UploadAttachment() as member = %+v, %v", mine, err)
+	}
+	theirs, _ := service.UploadAttachment(other, task.ID, "b.png", bytes.NewReader(pngHeader))
+	if _, err := service.ListAttachments(viewer, task.ID); err != nil {
+		t.Errorf("ListAttachments() as viewer unexpected error: %v", err)
+	}
+	if err := service.DeleteAttachment(member, task.ID, theirs.ID); !isForbidden(err) {
+		t.Errorf("DeleteAttachment() of someone else's upload error = %v, want 403", err)
+	}
+	if err := service.DeleteAttachment(member, task.ID, mine.ID); err != nil {
+		t.Errorf("DeleteAttachment() of own upload unexpected error: %v", err)
+	}
+	if err := service.DeleteAttachment(admin, task.ID, theirs.ID); err != nil {
+		t.Errorf("DeleteAttachment() as admin unexpected error: %v", err)
+	}
+}
+
+// failingAttachments refuses to save attachments
+type failingAttachments struct {
+	*repository.InMemoryAttachmentRepo
+}
+
+func (r failingAttachments) SaveAttachment(workspace string, attachment models.Attachment) (models.Attachment, error) {
+	return models.Attachment{}, errors.NewAppError(http.StatusInternalServerError, "disk full")
+}
+
+// racingBlobs runs afterPut once a blob is written, before the attachment
+// referring to it can be saved
+type racingBlobs struct {
+	*repository.InMemoryBlobStore
+	afterPut func()
+}
+
+func (b *racingBlobs) Put(r io.Reader) (string, int64, error) {
+	hash, size, err := b.InMemoryBlobStore.Put(r)
+	if err == nil && b.afterPut != nil {
+		afterPut := b.afterPut
+		b.afterPut = nil
+		afterPut()
+	}
+	return hash, size, err
+}
+
+func TestAttachmentStore_Add(t *testing.T) {
+	content := append(pngHeader, "image"...)
+	attachment := models.Attachment{ID: "a1", TaskID: "t1", Filename: "a.png", CreatedAt: time.Now()}
+
+	t.Run("Failed save releases the blob", func(t *testing.T) {
+		blobs := &trackedBlobs{repository.NewInMemoryBlobStore(), map[string]bool{}}
+		store := NewAttachmentStore(failingAttachments{repository.NewInMemoryAttachmentRepo()}, blobs)
+		if _, err := store.add("default", attachment, bytes.NewReader(content)); err == nil {
+			t.Fatal("add() error = nil, want the save error")
+		}
+		if len(blobs.stored) != 0 {
+			t.Errorf("add() left blobs %v behind", blobs.stored)
+		}
+	})
+
+	t.Run("Upload racing a delete of the same content keeps the blob", func(t *testing.T) {
+		blobs := &racingBlobs{InMemoryBlobStore: repository.NewInMemoryBlobStore()}
+		store := NewAttachmentStore(repository.NewInMemoryAttachmentRepo(), blobs)
+		if _, err := store.add("default", attachment, bytes.NewReader(content)); err != nil {
+			t.Fatalf("add() unexpected error: %v", err)
+		}
+		// The only attachment with this content goes away while a second
+		// upload of it is between writing the blob and being saved
+		blobs.afterPut = func() {
+			if err := store.remove("default", attachment.ID); err != nil {
+				t.Errorf("remove() unexpected error: %v", err)
+			}
+		}
+		second := attachment
+		second.ID = "a2"
+		saved, err := store.add("default", second, bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("add() unexpected error: %v", err)
+		}
+		blob, err := blobs.Open(saved.SHA256)
+		if err != nil {
+			t.Fatalf("Open() after add error = %v, want the blob kept", err)
+		}
+		defer blob.Close()
+		if got, _ := io.ReadAll(blob); !bytes.Equal(got, content) {
+			t.Errorf("blob content = %q, want %q", got, content)
+		}
+
+		// Once nothing refers to it, the blob goes as usual
+		if err := store.remove("default", second.ID); err != nil {
+			t.Fatalf("remove() unexpected error: %v", err)
+		}
+		if _, err := blobs.Open(saved.SHA256); err != repository.ErrBlobNotFound {
+			t.Errorf("Open() after the last remove error = %v, want %v", err, repository.ErrBlobNotFound)
+		}
+	})
+
+	t.Run("Deferred releases happen once uploads finish", func(t *testing.T) {
+		blobs := &racingBlobs{InMemoryBlobStore: repository.NewInMemoryBlobStore()}
+		store := NewAttachmentStore(repository.NewInMemoryAttachmentRepo(), blobs)
+		saved, err := store.add("default", attachment, bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("add() unexpected error: %v", err)
+		}
+		blobs.afterPut = func() { store.remove("default", attachment.ID) }
+		second := attachment
+		second.ID = "a2"
+		if _, err := store.add("default", second, strings.NewReader("other content")); err != nil {
+			t.Fatalf("add() unexpected error: %v", err)
+		}
+		if _, err := blobs.Open(saved.SHA256); err != repository.ErrBlobNotFound {
+			t.Errorf("Open() of the removed content error = %v, want %v", err, repository.ErrBlobNotFound)
+		}
+	})
+
+	t.Run("Uploads do not block deletions", func(t *testing.T) {
+		store := NewAttachmentStore(repository.NewInMemoryAttachmentRepo(), repository.NewInMemoryBlobStore())
+		if _, err := store.add("default", attachment, bytes.NewReader(content)); err != nil {
+			t.Fatalf("add() unexpected error: %v", err)
+		}
+
+		upload, writer := io.Pipe()
+		uploaded := make(chan error)
+		go func() {
+			second := attachment
+			second.ID = "a2"
+			_, err := store.add("default", second, upload)
+			uploaded <- err
+		}()
+		writer.Write(pngHeader)
+
+		removed := make(chan error)
+		go func() { removed <- store.remove("default", attachment.ID) }()
+		select {
+		case err := <-removed:
+			if err != nil {
+				t.Errorf("remove() unexpected error: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("remove() blocked behind an upload in progress")
+		}
+
+		writer.Close()
+		if err := <-uploaded; err != nil {
+			t.Errorf("add() unexpected error: %v", err)
+		}
+	})
+}
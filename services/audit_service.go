@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"taskmanager/models"
+	"taskmanager/repository"
+)
+
+type AuditService interface {
+	TaskHistory(ctx context.Context, taskID string, q models.AuditQuery) ([]models.AuditEntry, error)
+	ListAudit(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error)
+}
+
+type auditService struct {
+	audit  repository.AuditRepository
+	tasks  repository.TaskRepository
+	policy models.RolePolicy
+}
+
+// NewAuditService serves the audit log to principals whose roles in policy
+// grant audit:read. A nil policy means models.DefaultRolePolicy.
+func NewAuditService(audit repository.AuditRepository, tasks repository.TaskRepository, policy models.RolePolicy) AuditService {
+	return &auditService{audit: audit, tasks: tasks, policy: policyOrDefault(policy)}
+}
+
+// TaskHistory lists the changes made to one task. History outlives the task
+// itself, so a task is only reported missing if it has neither.
+func (s *auditService) TaskHistory(ctx context.Context, taskID string, q models.AuditQuery) ([]models.AuditEntry, error) {
+	q.TaskID = taskID
+	entries, err := s.ListAudit(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		if _, err := s.tasks.GetByID(WorkspaceFromContext(ctx), taskID); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (s *auditService) ListAudit(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	if err := authorize(ctx, s.policy, models.PermReadAudit); err != nil {
+		return nil, err
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	q.Normalize()
+	q.Workspace = WorkspaceFromContext(ctx)
+	return s.audit.List(q)
+}
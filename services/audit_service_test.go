@@ -0,0 +1,114 @@
+package services
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestTaskService_RecordsAuditLog(t *testing.T) {
+	audit := repository.NewInMemoryAuditRepo()
+	service := NewTaskService(NewMockTaskRepository(), WithAuditLog(audit))
+
+	created, err := service.CreateTask(WithActor(ctx, "alice"), testutils.CreateTestTask())
+	if err != nil {
+		t.Fatalf("CreateTask() unexpected error: %v", err)
+	}
+	update := created
+	update.Title = "Renamed"
+	if _, err := service.UpdateTask(WithActor(ctx, "bob"), created.ID, update, 0); err != nil {
+		t.Fatalf("UpdateTask() unexpected error: %v", err)
+	}
+	if _, err := service.PatchTask(ctx, created.ID, constants.ContentTypeMergePatch, []byte(`{"priority":"High"}`), 0); err != nil {
+		t.Fatalf("PatchTask() unexpected error: %v", err)
+	}
+	if _, err := service.TransitionTask(ctx, created.ID, constants.StatusInProgress); err != nil {
+		t.Fatalf("TransitionTask() unexpected error: %v", err)
+	}
+	// A rejected write leaves no trace
+	if _, err := service.TransitionTask(ctx, created.ID, "Bogus"); err == nil {
+		t.Fatalf("TransitionTask() expected error but got none")
+	}
+	if err := service.DeleteTask(WithActor(ctx, "alice"), created.ID, 0); err != nil {
+		t.Fatalf("DeleteTask() unexpected error: %v", err)
+	}
+
+	entries, _ := audit.List(models.AuditQuery{TaskID: created.ID})
+	want := []struct {
+		action, actor, field string
+		old, new             any
+	}{
+		{constants.AuditActionCreate, "alice", "title", "", "Test Task"},
+		{constants.AuditActionUpdate, "bob", "title", "Test Task", "Renamed"},
+		{constants.AuditActionUpdate, constants.AnonymousActor, "priority", constants.PriorityMedium, constants.PriorityHigh},
+		{constants.AuditActionUpdate, constants.AnonymousActor, "status", constants.StatusPending, constants.StatusInProgress},
+		{constants.AuditActionDelete, "alice", "deletedAt", nil, nil},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("audit log has %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Action != w.action || e.Actor != w.actor || e.TaskID != created.ID {
+			t.Errorf("entry %d = %s by %s on %s, want %s by %s", i, e.Action, e.Actor, e.TaskID, w.action, w.actor)
+		}
+		var found bool
+		for _, c := range e.Changes {
+			if c.Field == w.field {
+				found = true
+				if c.Field == "deletedAt" {
+					if c.Old != nil || c.New == nil {
+						t.Errorf("entry %d deletedAt change = %v -> %v, want nil -> timestamp", i, c.Old, c.New)
+					}
+				} else if c.Old != w.old || c.New != w.new {
+					t.Errorf("entry %d %s change = %v -> %v, want %v -> %v", i, w.field, c.Old, c.New, w.old, w.new)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("entry %d changes = %+v, missing %s", i, e.Changes, w.field)
+		}
+	}
+	if len(entries[1].Changes) != 1 {
+		t.Errorf("update entry changes = %+v, want only title", entries[1].Changes)
+	}
+}
+
+func TestAuditService(t *testing.T) {
+	tasks := NewMockTaskRepository()
+	audit := repository.NewInMemoryAuditRepo()
+	taskSvc := NewTaskService(tasks, WithAuditLog(audit))
+	auditSvc := NewAuditService(audit, tasks, nil)
+
+	kept, _ := taskSvc.CreateTask(ctx, testutils.CreateTestTask())
+	deleted, _ := taskSvc.CreateTask(ctx, testutils.CreateTestTask())
+	taskSvc.DeleteTask(ctx, deleted.ID, 0)
+
+	history, err := auditSvc.TaskHistory(ctx, kept.ID, models.AuditQuery{})
+	if err != nil || len(history) != 1 {
+		t.Errorf("TaskHistory() = %v entries, %v; want 1 entry", len(history), err)
+	}
+	history, err = auditSvc.TaskHistory(ctx, deleted.ID, models.AuditQuery{})
+	if err != nil || len(history) != 2 {
+		t.Errorf("TaskHistory() of deleted task = %v entries, %v; want 2 entries", len(history), err)
+	}
+	if _, err := auditSvc.TaskHistory(ctx, "non-existent", models.AuditQuery{}); err != repository.ErrTaskNotFound {
+		t.Errorf("TaskHistory() error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+
+	all, err := auditSvc.ListAudit(ctx, models.AuditQuery{})
+	if err != nil || len(all) != 3 {
+		t.Errorf("ListAudit() = %v entries, %v; want 3 entries", len(all), err)
+	}
+	future := time.Now().Add(time.Hour)
+	if recent, _ := auditSvc.ListAudit(ctx, models.AuditQuery{From: &future}); len(recent) != 0 {
+		t.Errorf("ListAudit() from the future = %v entries, want 0", len(recent))
+	}
+	past := future.Add(-2 * time.Hour)
+	if _, err := auditSvc.ListAudit(ctx, models.AuditQuery{From: &future, To: &past}); err == nil {
+		t.Errorf("ListAudit() with inverted range expected error but got none")
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CommentService interface {
+	ListComments(ctx context.Context, taskID string) ([]models.Comment, error)
+	GetComment(ctx context.Context, taskID, id string) (models.Comment, error)
+	AddComment(ctx context.Context, taskID, body string) (models.Comment, error)
+	EditComment(ctx context.Context, taskID, id, body string) (models.Comment, error)
+	DeleteComment(ctx context.Context, taskID, id string) error
+}
+
+type commentService struct {
+	repo   repository.CommentRepository
+	tasks  TaskService
+	policy models.RolePolicy
+}
+
+// NewCommentService lets principals who can read a task read its comments,
+// and those with comments:write add comments and change their own. Changing
+// someone else's comment takes comments:moderate. Tasks are looked up
+// through tasks, so comments on a task in the trash are out of reach until
+// it is restored. A nil policy means models.DefaultRolePolicy.
+func NewCommentService(repo repository.CommentRepository, tasks TaskService, policy models.RolePolicy) CommentService {
+	return &commentService{repo: repo, tasks: tasks, policy: policyOrDefault(policy)}
+}
+
+func (s *commentService) ListComments(ctx context.Context, taskID string) ([]models.Comment, error) {
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListComments(WorkspaceFromContext(ctx), taskID)
+}
+
+// GetComment returns a comment, provided it belongs to the task
+func (s *commentService) GetComment(ctx context.Context, taskID, id string) (models.Comment, error) {
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return models.Comment{}, err
+	}
+	comment, err := s.repo.GetComment(WorkspaceFromContext(ctx), id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if comment.TaskID != taskID {
+		return models.Comment{}, repository.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// AddComment posts a comment on a task as the caller
+func (s *commentService) AddComment(ctx context.Context, taskID, body string) (models.Comment, error) {
+	if err := authorize(ctx, s.policy, models.PermWriteComments); err != nil {
+		return models.Comment{}, err
+	}
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return models.Comment{}, err
+	}
+	now := time.Now()
+	comment := models.Comment{
+		ID:        uuid.NewString(),
+		TaskID:    taskID,
+		Author:    ActorFromContext(ctx),
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := comment.Validate(); err != nil {
+		return models.Comment{}, err
+	}
+	return s.repo.SaveComment(WorkspaceFromContext(ctx), comment)
+}
+
+// EditComment replaces a comment's body, keeping the previous one in its
+// edit history
+func (s *commentService) EditComment(ctx context.Context, taskID, id, body string) (models.Comment, error) {
+	comment, err := s.getForWrite(ctx, taskID, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if body == comment.Body {
+		return comment, nil
+	}
+	edited := comment
+	edited.Edit(body, ActorFromContext(ctx), time.Now())
+	if err := edited.Validate(); err != nil {
+		return models.Comment{}, err
+	}
+	return s.repo.SaveComment(WorkspaceFromContext(ctx), edited)
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, taskID, id string) error {
+	if _, err := s.getForWrite(ctx, taskID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteComment(WorkspaceFromContext(ctx), id)
+}
+
+// getForWrite fetches a comment the caller is about to change, checking
+// that they may moderate comments or wrote it and may still comment
+func (s *commentService) getForWrite(ctx context.Context, taskID, id string) (models.Comment, error) {
+	comment, err := s.GetComment(ctx, taskID, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || s.policy.Grants(p.Roles, models.PermModerateComments) ||
+		(comment.Author == p.Subject && s.policy.Grants(p.Roles, models.PermWriteComments)) {
+		return comment, nil
+	}
+	return models.Comment{}, errors.NewForbiddenError(fmt.Sprintf(constants.MessageForbiddenComment, models.PermModerateComments, models.PermWriteComments))
+}
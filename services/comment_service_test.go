@@ -0,0 +1,115 @@
+package services
+
+import (
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestCommentService(t *testing.T) {
+	comments := repository.NewInMemoryCommentRepo()
+	tasks := NewTaskService(NewMockTaskRepository(), WithComments(comments))
+	service := NewCommentService(comments, tasks, nil)
+	task, _ := tasks.CreateTask(ctx, testutils.CreateTestTask())
+	other, _ := tasks.CreateTask(ctx, testutils.CreateTestTask())
+
+	if _, err := service.AddComment(ctx, "missing", "hello"); err != repository.ErrTaskNotFound {
+		t.Errorf("AddComment() on a missing task error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+	if _, err := service.AddComment(ctx, task.ID, "  "); !isValidationError(err, "body") {
+		t.Errorf("AddComment() with a blank body error = %v, want body validation error", err)
+	}
+
+	alice := WithActor(ctx, "alice")
+	first, err := service.AddComment(alice, task.ID, "first")
+	if err != nil || first.ID == "" || first.Author != "alice" || first.TaskID != task.ID {
+		t.Fatalf("AddComment() = %+v, %v", first, err)
+	}
+	second, _ := service.AddComment(WithActor(ctx, "bob"), task.ID, "second")
+	if _, err := service.GetComment(ctx, other.ID, first.ID); err != repository.ErrCommentNotFound {
+		t.Errorf("GetComment() through another task error = %v, want %v", err, repository.ErrCommentNotFound)
+	}
+
+	edited, err := service.EditComment(alice, task.ID, first.ID, "first, edited")
+	if err != nil || edited.Body != "first, edited" || len(edited.Edits) != 1 || edited.Edits[0].Body != "first" || edited.Edits[0].EditedBy != "alice" {
+		t.Errorf("EditComment() = %+v, %v, want the old body in the history", edited, err)
+	}
+	if _, err := service.EditComment(alice, task.ID, first.ID, ""); !isValidationError(err, "body") {
+		t.Errorf("EditComment() with an empty body error = %v, want body validation error", err)
+	}
+
+	list, err := service.ListComments(ctx, task.ID)
+	if err != nil || len(list) != 2 || list[0].ID != first.ID || list[1].ID != second.ID {
+		t.Errorf("ListComments() = %+v, %v, want first then second", list, err)
+	}
+
+	if err := service.DeleteComment(ctx, task.ID, second.ID); err != nil {
+		t.Fatalf("DeleteComment() unexpected error: %v", err)
+	}
+	if _, err := service.GetComment(ctx, task.ID, second.ID); err != repository.ErrCommentNotFound {
+		t.Errorf("GetComment() after delete error = %v, want %v", err, repository.ErrCommentNotFound)
+	}
+
+	t.Run("Trash hides comments and purge removes them", func(t *testing.T) {
+		service.AddComment(ctx, other.ID, "keep me")
+		if err := tasks.DeleteTask(ctx, task.ID, 0); err != nil {
+			t.Fatalf("DeleteTask() unexpected error: %v", err)
+		}
+		if _, err := service.ListComments(ctx, task.ID); err != repository.ErrTaskNotFound {
+			t.Errorf("ListComments() on a trashed task error = %v, want %v", err, repository.ErrTaskNotFound)
+		}
+		if _, err := tasks.RestoreTask(ctx, task.ID); err != nil {
+			t.Fatalf("RestoreTask() unexpected error: %v", err)
+		}
+		if list, _ := service.ListComments(ctx, task.ID); len(list) != 1 {
+			t.Errorf("ListComments() after restore = %+v, want the comment back", list)
+		}
+
+		tasks.DeleteTask(ctx, task.ID, 0)
+		if n, err := tasks.PurgeTrash(ctx, time.Now().Add(time.Second)); err != nil || n != 1 {
+			t.Fatalf("PurgeTrash() = %d, %v, want 1", n, err)
+		}
+		if list, _ := comments.ListComments(WorkspaceFromContext(ctx), task.ID); len(list) != 0 {
+			t.Errorf("comments after purge = %+v, want none", list)
+		}
+		if list, _ := service.ListComments(ctx, other.ID); len(list) != 1 {
+			t.Errorf("ListComments() on another task after purge = %+v, want it untouched", list)
+		}
+	})
+}
+
+func TestCommentService_EnforcesRolePolicy(t *testing.T) {
+	comments := repository.NewInMemoryCommentRepo()
+	tasks := NewTaskService(NewMockTaskRepository())
+	service := NewCommentService(comments, tasks, nil)
+	task, _ := tasks.CreateTask(ctx, testutils.CreateTestTask())
+	viewer := WithPrincipal(ctx, as("vera", models.RoleViewer))
+	member := WithPrincipal(ctx, as("mo", models.RoleMember))
+	other := WithPrincipal(ctx, as("max", models.RoleMember))
+	admin := WithPrincipal(ctx, as("ada", models.RoleAdmin))
+
+	if _, err := service.AddComment(viewer, task.ID, "hi"); !isForbidden(err) {
+		t.Errorf("AddComment() as viewer error = %v, want 403", err)
+	}
+	comment, err := service.AddComment(member, task.ID, "hi")
+	if err != nil || comment.Author != "mo" {
+		t.Fatalf("AddComment() as member = %+v, %v", comment, err)
+	}
+	if _, err := service.ListComments(viewer, task.ID); err != nil {
+		t.Errorf("ListComments() as viewer unexpected error: %v", err)
+	}
+	if _, err := service.EditComment(other, task.ID, comment.ID, "mine now"); !isForbidden(err) {
+		t.Errorf("EditComment() of someone else's comment error = %v, want 403", err)
+	}
+	if err := service.DeleteComment(other, task.ID, comment.ID); !isForbidden(err) {
+		t.Errorf("DeleteComment() of someone else's comment error = %v, want 403", err)
+	}
+	if _, err := service.EditComment(member, task.ID, comment.ID, "hello"); err != nil {
+		t.Errorf("EditComment() of own comment unexpected error: %v", err)
+	}
+	if err := service.DeleteComment(admin, task.ID, comment.ID); err != nil {
+		t.Errorf("DeleteComment() as admin unexpected error: %v", err)
+	}
+}
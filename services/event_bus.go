@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"time"
+)
+
+// DefaultReplaySize is how many recent events an EventBus keeps for
+// subscribers resuming after a disconnect
+const DefaultReplaySize = 1000
+
+// subscriberBuffer is how many events may wait for a subscriber before it is
+// considered too slow and dropped
+const subscriberBuffer = 256
+
+// BusEvent is a task event with its position on the bus. IDs increase with
+// every event and are only meaningful to the bus that issued them.
+type BusEvent struct {
+	ID string
+	models.TaskEvent
+}
+
+// EventBus is an EventPublisher that fans task events out to in-process
+// subscribers. It keeps the most recent events so a subscriber that
+// reconnects can pick up where it left off.
+type EventBus struct {
+	mu     sync.Mutex
+	epoch  string
+	seq    uint64
+	replay []BusEvent // ring buffer of the latest events, oldest at start
+	start  int
+	subs   map[*Subscription]struct{}
+	closed bool
+	policy models.RolePolicy
+}
+
+// Subscription receives the events matching its filter until it is closed.
+// Replay holds the events published since the ID it resumed from; they come
+// before anything on Events. Gap is set when some of those events are no
+// longer held by the bus, so the subscriber should reload its state.
+type Subscription struct {
+	Replay []BusEvent
+	Gap    bool
+
+	bus       *EventBus
+	workspace string
+	filter    models.TaskEventFilter
+	events    chan BusEvent
+	dropped   bool
+}
+
+// NewEventBus keeps the latest replaySize events and lets principals whose
+// roles in policy grant tasks:read subscribe. A nil policy means
+// models.DefaultRolePolicy.
+func NewEventBus(replaySize int, policy models.RolePolicy) *EventBus {
+	return &EventBus{
+		policy: policyOrDefault(policy),
+		// IDs carry the bus start time so IDs from before a restart are
+		// recognised as stale rather than mistaken for recent events
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		replay: make([]BusEvent, 0, replaySize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns event the next ID and delivers it to every matching
+// subscriber. Subscribers whose buffer is full are dropped rather than
+// blocking the caller; they can resubscribe from the last event they saw.
+func (b *EventBus) Publish(event models.TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.seq++
+	e := BusEvent{ID: b.eventID(b.seq), TaskEvent: event}
+	if len(b.replay) < cap(b.replay) {
+		b.replay = append(b.replay, e)
+	} else if len(b.replay) > 0 {
+		b.replay[b.start] = e
+		b.start = (b.start + 1) % len(b.replay)
+	}
+
+	for sub := range b.subs {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			sub.dropped = true
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe starts delivering the events that match filter in the
+// workspace of ctx. When lastEventID is the ID of an event from this bus,
+// the events published after it that match filter are returned in the
+// subscription's Replay.
+func (b *EventBus) Subscribe(ctx context.Context, filter models.TaskEventFilter, lastEventID string) (*Subscription, error) {
+	if err := authorize(ctx, b.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, errors.NewAppError(http.StatusServiceUnavailable, constants.MessageEventBusClosed)
+	}
+
+	sub := &Subscription{
+		bus:       b,
+		workspace: WorkspaceFromContext(ctx),
+		filter:    filter,
+		events:    make(chan BusEvent, subscriberBuffer),
+	}
+	if lastEventID != "" {
+		after, known, err := b.parseEventID(lastEventID)
+		if err != nil {
+			return nil, err
+		}
+		oldest := b.seq - uint64(len(b.replay)) + 1
+		// Unknown IDs come from before a restart; everything the bus
+		// holds is new to the subscriber, but earlier events are lost
+		if !known {
+			after, sub.Gap = 0, true
+		} else if after+1 < oldest {
+			sub.Gap = true
+		}
+		for i := range b.replay {
+			e := b.replay[(b.start+i)%len(b.replay)]
+			if oldest+uint64(i) > after && sub.matches(e.TaskEvent) {
+				sub.Replay = append(sub.Replay, e)
+			}
+		}
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close drops every subscriber and stops accepting events and subscriptions
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.remove(sub)
+	}
+}
+
+// remove drops sub, closing its channel. The caller must hold b.mu.
+func (b *EventBus) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+func (b *EventBus) eventID(seq uint64) string {
+	return b.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseEventID returns the sequence number in id and whether it was issued
+// by this bus. The caller must hold b.mu.
+func (b *EventBus) parseEventID(id string) (uint64, bool, error) {
+	epoch, rawSeq, ok := strings.Cut(id, "-")
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if !ok || epoch == "" || err != nil {
+		return 0, false, errors.NewValidationError(constants.HeaderLastEventID, constants.ValidationInvalidEventID)
+	}
+	if epoch != b.epoch {
+		return 0, false, nil
+	}
+	if seq > b.seq {
+		return 0, false, errors.NewValidationError(constants.HeaderLastEventID, constants.ValidationInvalidEventID)
+	}
+	return seq, true, nil
+}
+
+// Events delivers the subscription's events. It is closed when the
+// subscription is closed, the bus shuts down or the subscriber falls too far
+// behind.
+func (s *Subscription) Events() <-chan BusEvent {
+	return s.events
+}
+
+// matches reports whether event belongs to the subscription's workspace and
+// passes its filter
+func (s *Subscription) matches(event models.TaskEvent) bool {
+	return event.Task.Workspace == s.workspace && s.filter.Matches(event)
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
+
+// Dropped reports whether the subscription was ended because its subscriber
+// fell too far behind
+func (s *Subscription) Dropped() bool {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.dropped
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"taskmanager/constants"
+	"taskmanager/models"
+	"testing"
+)
+
+func busEvent(id, status string) models.TaskEvent {
+	return models.TaskEvent{ID: id, Type: models.EventTaskUpdated, Task: models.Task{ID: id, Workspace: constants.DefaultWorkspace, Status: status}}
+}
+
+func eventIDs(events []BusEvent) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.TaskEvent.ID
+	}
+	return ids
+}
+
+func TestEventBus_Subscribe(t *testing.T) {
+	bus := NewEventBus(10, nil)
+	sub, err := bus.Subscribe(ctx, models.TaskEventFilter{Status: constants.StatusPending}, "")
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer sub.Close()
+
+	bus.Publish(busEvent("a", constants.StatusPending))
+	bus.Publish(busEvent("b", constants.StatusCompleted))
+	bus.Publish(busEvent("c", constants.StatusPending))
+
+	first, second := <-sub.Events(), <-sub.Events()
+	if first.TaskEvent.ID != "a" || second.TaskEvent.ID != "c" {
+		t.Errorf("received %s, %s, want a, c", first.TaskEvent.ID, second.TaskEvent.ID)
+	}
+	if first.ID == second.ID || first.ID == "" {
+		t.Errorf("bus IDs %q and %q should be distinct", first.ID, second.ID)
+	}
+
+	if _, err := bus.Subscribe(ctx, models.TaskEventFilter{Status: "Sleeping"}, ""); !isValidationError(err, "status") {
+		t.Errorf("Subscribe() with unknown status error = %v, want status validation error", err)
+	}
+}
+
+func TestEventBus_Resume(t *testing.T) {
+	bus := NewEventBus(3, nil)
+	var ids []string
+	sub, _ := bus.Subscribe(ctx, models.TaskEventFilter{}, "")
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		bus.Publish(busEvent(id, constants.StatusPending))
+		ids = append(ids, (<-sub.Events()).ID)
+	}
+	sub.Close()
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		want        []string
+		gap         bool
+	}{
+		{"Up to date", ids[4], nil, false},
+		{"Within the buffer", ids[2], []string{"d", "e"}, false},
+		{"Oldest buffered event was the last seen", ids[1], []string{"c", "d", "e"}, false},
+		{"Fell out of the buffer", ids[0], []string{"c", "d", "e"}, true},
+		{"From before a restart", "old-42", []string{"c", "d", "e"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := bus.Subscribe(ctx, models.TaskEventFilter{}, tt.lastEventID)
+			if err != nil {
+				t.Fatalf("Subscribe() unexpected error: %v", err)
+			}
+			defer sub.Close()
+			if got := eventIDs(sub.Replay); len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("Replay = %v, want %v", got, tt.want)
+			}
+			if sub.Gap != tt.gap {
+				t.Errorf("Gap = %v, want %v", sub.Gap, tt.gap)
+			}
+		})
+	}
+
+	for _, bad := range []string{"nonsense", ids[4] + "0"} {
+		if _, err := bus.Subscribe(ctx, models.TaskEventFilter{}, bad); !isValidationError(err, constants.HeaderLastEventID) {
+			t.Errorf("Subscribe(%q) error = %v, want Last-Event-ID validation error", bad, err)
+		}
+	}
+}
+
+func TestEventBus_DropsSlowSubscribers(t *testing.T) {
+	bus := NewEventBus(DefaultReplaySize, nil)
+	slow, _ := bus.Subscribe(ctx, models.TaskEventFilter{}, "")
+	for i := 0; i <= subscriberBuffer; i++ {
+		bus.Publish(busEvent("a", constants.StatusPending))
+	}
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received != subscriberBuffer || !slow.Dropped() {
+		t.Errorf("slow subscriber received %d events, dropped = %v; want %d and dropped", received, slow.Dropped(), subscriberBuffer)
+	}
+	slow.Close() // closing again is harmless
+
+	bus.Close()
+	if _, err := bus.Subscribe(ctx, models.TaskEventFilter{}, ""); err == nil {
+		t.Error("Subscribe() after Close expected an error")
+	}
+}
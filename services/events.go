@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventPublisher receives an event for every task change TaskService stores.
+// Publish is called synchronously after the write, so it must not block.
+type EventPublisher interface {
+	Publish(event models.TaskEvent)
+}
+
+// WithEventPublisher sends task events to p. It may be given more than once.
+func WithEventPublisher(p EventPublisher) TaskServiceOption {
+	return func(s *taskService) {
+		s.publishers = append(s.publishers, p)
+	}
+}
+
+// eventTypes maps audit actions to the event they announce. Purging a task
+// from the trash produces no event since its deletion was already announced.
+var eventTypes = map[string]string{
+	constants.AuditActionCreate:  models.EventTaskCreated,
+	constants.AuditActionUpdate:  models.EventTaskUpdated,
+	constants.AuditActionRestore: models.EventTaskUpdated,
+	constants.AuditActionDelete:  models.EventTaskDeleted,
+}
+
+// publish announces a stored change from before to after to every publisher
+func (s *taskService) publish(ctx context.Context, action string, before, after models.Task) {
+	eventType, ok := eventTypes[action]
+	if !ok || len(s.publishers) == 0 {
+		return
+	}
+	event := models.TaskEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Actor:     ActorFromContext(ctx),
+		Task:      after,
+	}
+	if before.ID != "" {
+		event.Previous = &before
+	}
+	for _, p := range s.publishers {
+		p.Publish(event)
+	}
+}
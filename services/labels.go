@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+)
+
+// ErrLabelNotFound is returned when renaming a label no task carries
+var ErrLabelNotFound = errors.NewNotFoundError("Label")
+
+// ListLabels counts the labels on live tasks
+func (s *taskService) ListLabels(ctx context.Context) ([]models.LabelCount, error) {
+	tasks, err := s.GetTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.CountLabels(tasks), nil
+}
+
+// RenameLabel replaces from with to on every task carrying it, including
+// tasks in the trash, and reports how many tasks changed. Renaming onto a
+// label that is already in use is refused; MergeLabels does that.
+func (s *taskService) RenameLabel(ctx context.Context, from, to string) (int, error) {
+	if err := authorize(ctx, s.policy, models.PermEditTasks); err != nil {
+		return 0, err
+	}
+	if !models.IsValidLabel(from) {
+		return 0, errors.NewValidationError("from", constants.ValidationInvalidLabel)
+	}
+	if !models.IsValidLabel(to) {
+		return 0, errors.NewValidationError("to", constants.ValidationInvalidLabel)
+	}
+
+	ids, err := s.labelled(ctx, []string{from})
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, ErrLabelNotFound
+	}
+	if from == to {
+		return 0, nil
+	}
+	taken, err := s.labelled(ctx, []string{to})
+	if err != nil {
+		return 0, err
+	}
+	if len(taken) > 0 {
+		return 0, errors.NewConflictError(fmt.Sprintf(constants.MessageLabelInUse, to))
+	}
+	return s.relabel(ctx, ids, []string{from}, to)
+}
+
+// MergeLabels replaces every label in from with to on every task carrying
+// one, including tasks in the trash, and reports how many tasks changed
+func (s *taskService) MergeLabels(ctx context.Context, from []string, to string) (int, error) {
+	if err := authorize(ctx, s.policy, models.PermEditTasks); err != nil {
+		return 0, err
+	}
+	if len(from) == 0 {
+		return 0, errors.NewValidationError("from", constants.ValidationLabelsRequired)
+	}
+	for _, label := range from {
+		if !models.IsValidLabel(label) {
+			return 0, errors.NewValidationError("from", constants.ValidationInvalidLabel)
+		}
+	}
+	if !models.IsValidLabel(to) {
+		return 0, errors.NewValidationError("to", constants.ValidationInvalidLabel)
+	}
+
+	ids, err := s.labelled(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	return s.relabel(ctx, ids, from, to)
+}
+
+// labelled lists the IDs of live and trashed tasks carrying any of labels.
+// The IDs are collected up front because relabelling moves tasks out of
+// the result set, which would make cursors skip some.
+func (s *taskService) labelled(ctx context.Context, labels []string) ([]string, error) {
+	var ids []string
+	for _, deleted := range []bool{false, true} {
+		q := models.TaskQuery{LabelsAny: labels, Deleted: deleted, SortBy: models.SortByID, Limit: models.MaxQueryLimit}
+		for {
+			page, err := s.repo.Query(WorkspaceFromContext(ctx), q)
+			if err != nil {
+				return nil, err
+			}
+			for _, task := range page.Tasks {
+				ids = append(ids, task.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			q.Cursor = page.NextCursor
+		}
+	}
+	return ids, nil
+}
+
+// relabel swaps the labels in from for to on each task in ids. A task
+// changed concurrently is re-read and relabelled again.
+func (s *taskService) relabel(ctx context.Context, ids, from []string, to string) (int, error) {
+	changed := 0
+	for _, id := range ids {
+		for {
+			task, err := s.repo.GetByID(WorkspaceFromContext(ctx), id)
+			if err == repository.ErrTaskNotFound {
+				break
+			}
+			if err != nil {
+				return changed, err
+			}
+			labels := models.ReplaceLabels(task.Labels, from, to)
+			if slices.Equal(labels, task.Labels) {
+				break
+			}
+			updated := task
+			updated.Labels = labels
+			updated.UpdatedAt = time.Now()
+			_, err = s.update(ctx, constants.AuditActionUpdate, task, updated)
+			if err == repository.ErrVersionConflict {
+				continue
+			}
+			if err != nil {
+				return changed, err
+			}
+			changed++
+			break
+		}
+	}
+	return changed, nil
+}
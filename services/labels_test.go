@@ -0,0 +1,95 @@
+package services
+
+import (
+	"net/http"
+	"slices"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/testutils"
+	"testing"
+)
+
+func TestTaskService_Labels(t *testing.T) {
+	service := NewTaskService(NewMockTaskRepository())
+	labelled := func(labels ...string) models.Task {
+		task := testutils.CreateTestTask()
+		task.Labels = labels
+		created, err := service.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("CreateTask() unexpected error: %v", err)
+		}
+		return created
+	}
+	a := labelled("frontend", "bug")
+	b := labelled("defect")
+	c := labelled("bug", "defect")
+	trashed := labelled("bug")
+	if err := service.DeleteTask(ctx, trashed.ID, 0); err != nil {
+		t.Fatalf("DeleteTask() unexpected error: %v", err)
+	}
+
+	if !slices.Equal(a.Labels, []string{"bug", "frontend"}) {
+		t.Errorf("CreateTask() labels = %v, want them sorted", a.Labels)
+	}
+	invalid := testutils.CreateTestTask()
+	invalid.Labels = []string{"Has Spaces"}
+	if _, err := service.CreateTask(ctx, invalid); !isValidationError(err, "labels") {
+		t.Errorf("CreateTask() with an invalid label error = %v, want labels validation error", err)
+	}
+
+	counts, err := service.ListLabels(ctx)
+	want := []models.LabelCount{{Label: "bug", Count: 2}, {Label: "defect", Count: 2}, {Label: "frontend", Count: 1}}
+	if err != nil || !slices.Equal(counts, want) {
+		t.Errorf("ListLabels() = %v, %v, want %v", counts, err, want)
+	}
+
+	t.Run("Rename", func(t *testing.T) {
+		if _, err := service.RenameLabel(ctx, "bug", "Bug"); !isValidationError(err, "to") {
+			t.Errorf("RenameLabel() to an invalid label error = %v, want to validation error", err)
+		}
+		if _, err := service.RenameLabel(ctx, "missing", "other"); err != ErrLabelNotFound {
+			t.Errorf("RenameLabel() of an unused label error = %v, want %v", err, ErrLabelNotFound)
+		}
+		_, err := service.RenameLabel(ctx, "bug", "defect")
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusConflict {
+			t.Errorf("RenameLabel() onto a used label error = %v, want conflict", err)
+		}
+
+		n, err := service.RenameLabel(ctx, "bug", "issue")
+		if err != nil || n != 3 {
+			t.Fatalf("RenameLabel() = %d, %v, want 3 tasks including the trashed one", n, err)
+		}
+		if task, _ := service.GetTask(ctx, a.ID); !slices.Equal(task.Labels, []string{"frontend", "issue"}) || task.Version != a.Version+1 {
+			t.Errorf("renamed task = %v at version %d", task.Labels, task.Version)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		if _, err := service.MergeLabels(ctx, nil, "issue"); !isValidationError(err, "from") {
+			t.Errorf("MergeLabels() without labels error = %v, want from validation error", err)
+		}
+		n, err := service.MergeLabels(ctx, []string{"issue", "defect"}, "issue")
+		if err != nil || n != 2 {
+			t.Fatalf("MergeLabels() = %d, %v, want 2 tasks changed", n, err)
+		}
+		for id, want := range map[string][]string{b.ID: {"issue"}, c.ID: {"issue"}, a.ID: {"frontend", "issue"}} {
+			if task, _ := service.GetTask(ctx, id); !slices.Equal(task.Labels, want) {
+				t.Errorf("merged task %s labels = %v, want %v", id, task.Labels, want)
+			}
+		}
+		page, _ := service.QueryTasks(ctx, models.TaskQuery{LabelsNone: []string{"issue"}})
+		if len(page.Tasks) != 0 {
+			t.Errorf("QueryTasks(labelsNone=issue) = %d tasks, want none", len(page.Tasks))
+		}
+	})
+
+	t.Run("Needs edit permission", func(t *testing.T) {
+		viewer := WithPrincipal(ctx, as("vera", models.RoleViewer))
+		if _, err := service.RenameLabel(viewer, "issue", "bug"); !isForbidden(err) {
+			t.Errorf("RenameLabel() as viewer error = %v, want forbidden", err)
+		}
+		if _, err := service.ListLabels(viewer); err != nil {
+			t.Errorf("ListLabels() as viewer unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+)
+
+// WithPolicy replaces the default role-to-permission mapping
+func WithPolicy(p models.RolePolicy) TaskServiceOption {
+	return func(s *taskService) {
+		s.policy = p
+	}
+}
+
+func policyOrDefault(p models.RolePolicy) models.RolePolicy {
+	if p == nil {
+		return models.DefaultRolePolicy()
+	}
+	return p
+}
+
+// authorize fails with a 403 error unless the principal in ctx has perm.
+// Contexts without a principal are let through: they belong to background
+// jobs or to a server running without authentication.
+func authorize(ctx context.Context, policy models.RolePolicy, perm string) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || policy.Grants(p.Roles, perm) {
+		return nil
+	}
+	return errors.NewForbiddenError(fmt.Sprintf(constants.MessageForbidden, perm))
+}
+
+// authorizeTask is authorize for an operation on task, which ownPerm also
+// allows when the task is assigned to the principal
+func authorizeTask(ctx context.Context, policy models.RolePolicy, perm, ownPerm string, task models.Task) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || policy.Grants(p.Roles, perm) {
+		return nil
+	}
+	if task.AssignedTo != "" && task.AssignedTo == p.Subject && policy.Grants(p.Roles, ownPerm) {
+		return nil
+	}
+	return errors.NewForbiddenError(fmt.Sprintf(constants.MessageForbiddenUnlessOwn, perm, ownPerm))
+}
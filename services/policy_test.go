@@ -0,0 +1,137 @@
+package services
+
+import (
+	"net/http"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func isForbidden(err error) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == http.StatusForbidden
+}
+
+func as(subject string, roles ...string) models.Principal {
+	return models.Principal{Subject: subject, Roles: roles, Method: models.AuthMethodToken}
+}
+
+func TestTaskService_EnforcesRolePolicy(t *testing.T) {
+	service := NewTaskService(NewMockTaskRepository())
+	viewer := WithPrincipal(ctx, as("vera", models.RoleViewer))
+	member := WithPrincipal(ctx, as("mo", models.RoleMember))
+	admin := WithPrincipal(ctx, as("ada", models.RoleAdmin))
+
+	mine := testutils.CreateTestTask()
+	mine.AssignedTo = "mo"
+	mine, err := service.CreateTask(member, mine)
+	if err != nil {
+		t.Fatalf("CreateTask() as member unexpected error: %v", err)
+	}
+	theirs, err := service.CreateTask(ctx, testutils.CreateTestTask())
+	if err != nil {
+		t.Fatalf("CreateTask() without a principal unexpected error: %v", err)
+	}
+
+	if _, err := service.GetTasks(viewer); err != nil {
+		t.Errorf("GetTasks() as viewer unexpected error: %v", err)
+	}
+	if _, err := service.GetTasks(WithPrincipal(ctx, as("nobody"))); !isForbidden(err) {
+		t.Errorf("GetTasks() without roles error = %v, want 403", err)
+	}
+	if _, err := service.CreateTask(viewer, testutils.CreateTestTask()); !isForbidden(err) {
+		t.Errorf("CreateTask() as viewer error = %v, want 403", err)
+	}
+	if _, err := service.TransitionTask(viewer, mine.ID, constants.StatusInProgress); !isForbidden(err) {
+		t.Errorf("TransitionTask() as viewer error = %v, want 403", err)
+	}
+
+	// Members edit the tasks assigned to them and nothing else
+	if _, err := service.TransitionTask(member, mine.ID, constants.StatusInProgress); err != nil {
+		t.Errorf("TransitionTask() on own task unexpected error: %v", err)
+	}
+	if _, err := service.PatchTask(member, theirs.ID, constants.ContentTypeMergePatch, []byte(`{"assignedTo":"mo"}`), 0); !isForbidden(err) {
+		t.Errorf("PatchTask() on someone else's task error = %v, want 403", err)
+	}
+	if _, err := service.AddDependency(member, theirs.ID, mine.ID); !isForbidden(err) {
+		t.Errorf("AddDependency() on someone else's task error = %v, want 403", err)
+	}
+	if err := service.DeleteTask(member, mine.ID, 0); !isForbidden(err) {
+		t.Errorf("DeleteTask() as member error = %v, want 403", err)
+	}
+	if _, err := service.PurgeTrash(member, time.Now()); !isForbidden(err) {
+		t.Errorf("PurgeTrash() as member error = %v, want 403", err)
+	}
+
+	// Admins can delete anything
+	if err := service.DeleteTask(admin, theirs.ID, 0); err != nil {
+		t.Errorf("DeleteTask() as admin unexpected error: %v", err)
+	}
+	if _, err := service.RestoreTask(member, theirs.ID); !isForbidden(err) {
+		t.Errorf("RestoreTask() as member error = %v, want 403", err)
+	}
+	if _, err := service.RestoreTask(admin, theirs.ID); err != nil {
+		t.Errorf("RestoreTask() as admin unexpected error: %v", err)
+	}
+}
+
+func TestTaskService_WithPolicy(t *testing.T) {
+	policy := models.RolePolicy{
+		"triager": {models.PermReadTasks, models.PermEditOwnTasks, models.PermDeleteOwnTasks},
+	}
+	service := NewTaskService(NewMockTaskRepository(), WithPolicy(policy))
+	triager := WithPrincipal(ctx, as("tri", "triager"))
+
+	if _, err := service.CreateTask(WithPrincipal(ctx, as("ada", models.RoleAdmin)), testutils.CreateTestTask()); !isForbidden(err) {
+		t.Errorf("CreateTask() with a role the policy does not define error = %v, want 403", err)
+	}
+
+	// Completing a recurring task spawns the next occurrence on the
+	// caller's behalf, even though they cannot create tasks themselves
+	task := testutils.CreateTestTask()
+	task.AssignedTo = "tri"
+	task.RRule = "FREQ=DAILY;COUNT=2"
+	task, _ = service.CreateTask(ctx, task)
+	service.TransitionTask(triager, task.ID, constants.StatusInProgress)
+	if _, err := service.TransitionTask(triager, task.ID, constants.StatusCompleted); err != nil {
+		t.Fatalf("TransitionTask() to completed unexpected error: %v", err)
+	}
+	tasks, _ := service.GetTasks(triager)
+	if len(tasks) != 2 {
+		t.Errorf("GetTasks() returned %d tasks, want the next occurrence as well", len(tasks))
+	}
+	if err := service.DeleteTask(triager, task.ID, 0); err != nil {
+		t.Errorf("DeleteTask() on own task unexpected error: %v", err)
+	}
+}
+
+func TestRolePolicy_OtherServices(t *testing.T) {
+	viewer := WithPrincipal(ctx, as("vera", models.RoleViewer))
+	admin := WithPrincipal(ctx, as("ada", models.RoleAdmin))
+
+	webhooks := NewWebhookService(repository.NewInMemoryWebhookRepo(), nil)
+	hook := models.Webhook{URL: "https://example.com/hook", Active: true}
+	if _, err := webhooks.CreateWebhook(viewer, hook); !isForbidden(err) {
+		t.Errorf("CreateWebhook() as viewer error = %v, want 403", err)
+	}
+	if _, err := webhooks.CreateWebhook(admin, hook); err != nil {
+		t.Errorf("CreateWebhook() as admin unexpected error: %v", err)
+	}
+
+	audit := NewAuditService(repository.NewInMemoryAuditRepo(), NewMockTaskRepository(), nil)
+	if _, err := audit.ListAudit(viewer, models.AuditQuery{}); err != nil {
+		t.Errorf("ListAudit() as viewer unexpected error: %v", err)
+	}
+	if _, err := audit.ListAudit(WithPrincipal(ctx, as("nobody")), models.AuditQuery{}); !isForbidden(err) {
+		t.Errorf("ListAudit() without roles error = %v, want 403", err)
+	}
+
+	bus := NewEventBus(10, nil)
+	if _, err := bus.Subscribe(WithPrincipal(ctx, as("nobody")), models.TaskEventFilter{}, ""); !isForbidden(err) {
+		t.Errorf("Subscribe() without roles error = %v, want 403", err)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProjectService interface {
+	ListProjects(ctx context.Context) ([]models.Project, error)
+	GetProject(ctx context.Context, id string) (models.Project, error)
+	CreateProject(ctx context.Context, project models.Project) (models.Project, error)
+	UpdateProject(ctx context.Context, id string, project models.Project) (models.Project, error)
+	DeleteProject(ctx context.Context, id string) error
+	QueryProjectTasks(ctx context.Context, id string, q models.TaskQuery) (models.TaskPage, error)
+	ProjectStats(ctx context.Context, id string) (models.ProjectStats, error)
+}
+
+type projectService struct {
+	repo   repository.ProjectRepository
+	tasks  TaskService
+	policy models.RolePolicy
+}
+
+// NewProjectService lets principals with tasks:read see projects and their
+// tasks, and those with projects:manage change them. Tasks are read through
+// tasks, so its own permission checks apply as well. A nil policy means
+// models.DefaultRolePolicy.
+func NewProjectService(repo repository.ProjectRepository, tasks TaskService, policy models.RolePolicy) ProjectService {
+	return &projectService{repo: repo, tasks: tasks, policy: policyOrDefault(policy)}
+}
+
+func (s *projectService) ListProjects(ctx context.Context) ([]models.Project, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	return s.repo.ListProjects(WorkspaceFromContext(ctx))
+}
+
+func (s *projectService) GetProject(ctx context.Context, id string) (models.Project, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.Project{}, err
+	}
+	return s.repo.GetProject(WorkspaceFromContext(ctx), id)
+}
+
+func (s *projectService) CreateProject(ctx context.Context, project models.Project) (models.Project, error) {
+	if err := authorize(ctx, s.policy, models.PermManageProjects); err != nil {
+		return models.Project{}, err
+	}
+	if err := project.Validate(); err != nil {
+		return models.Project{}, err
+	}
+	project.ID = uuid.NewString()
+	now := time.Now()
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	return s.repo.SaveProject(WorkspaceFromContext(ctx), project)
+}
+
+// UpdateProject replaces a project's name and description
+func (s *projectService) UpdateProject(ctx context.Context, id string, project models.Project) (models.Project, error) {
+	if err := authorize(ctx, s.policy, models.PermManageProjects); err != nil {
+		return models.Project{}, err
+	}
+	existing, err := s.repo.GetProject(WorkspaceFromContext(ctx), id)
+	if err != nil {
+		return models.Project{}, err
+	}
+	if err := project.Validate(); err != nil {
+		return models.Project{}, err
+	}
+
+	updated := existing
+	updated.Name = project.Name
+	updated.Description = project.Description
+	updated.UpdatedAt = time.Now()
+	return s.repo.SaveProject(WorkspaceFromContext(ctx), updated)
+}
+
+// DeleteProject removes a project that no live task belongs to. Tasks in
+// the trash keep their projectId.
+func (s *projectService) DeleteProject(ctx context.Context, id string) error {
+	if err := authorize(ctx, s.policy, models.PermManageProjects); err != nil {
+		return err
+	}
+	page, err := s.QueryProjectTasks(ctx, id, models.TaskQuery{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(page.Tasks) > 0 {
+		return errors.NewConflictError(constants.MessageProjectNotEmpty)
+	}
+	return s.repo.DeleteProject(WorkspaceFromContext(ctx), id)
+}
+
+// QueryProjectTasks runs q over the live tasks of one project
+func (s *projectService) QueryProjectTasks(ctx context.Context, id string, q models.TaskQuery) (models.TaskPage, error) {
+	if _, err := s.GetProject(ctx, id); err != nil {
+		return models.TaskPage{}, err
+	}
+	q.ProjectID = id
+	return s.tasks.QueryTasks(ctx, q)
+}
+
+// ProjectStats counts the live tasks of a project by status, and those of
+// them that are overdue
+func (s *projectService) ProjectStats(ctx context.Context, id string) (models.ProjectStats, error) {
+	q := models.TaskQuery{Limit: models.MaxQueryLimit}
+	var tasks []models.Task
+	for {
+		page, err := s.QueryProjectTasks(ctx, id, q)
+		if err != nil {
+			return models.ProjectStats{}, err
+		}
+		tasks = append(tasks, page.Tasks...)
+		if page.NextCursor == "" {
+			return models.NewProjectStats(id, tasks, time.Now()), nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"net/http"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestProjectService(t *testing.T) {
+	projects := repository.NewInMemoryProjectRepo()
+	tasks := NewTaskService(NewMockTaskRepository(), WithProjects(projects))
+	service := NewProjectService(projects, tasks, nil)
+
+	if _, err := service.CreateProject(ctx, models.Project{}); !isValidationError(err, "name") {
+		t.Errorf("CreateProject() without a name error = %v, want name validation error", err)
+	}
+	project, err := service.CreateProject(ctx, models.Project{Name: "Launch"})
+	if err != nil || project.ID == "" || project.Workspace != constants.DefaultWorkspace {
+		t.Fatalf("CreateProject() = %+v, %v", project, err)
+	}
+	if _, err := service.GetProject(WithWorkspace(ctx, "team-b"), project.ID); err != repository.ErrProjectNotFound {
+		t.Errorf("GetProject() from another workspace error = %v, want %v", err, repository.ErrProjectNotFound)
+	}
+
+	newTask := func(status string, due time.Duration) models.Task {
+		task := testutils.CreateTestTask()
+		task.ProjectID = project.ID
+		dueDate := time.Now().Add(due)
+		task.DueDate = &dueDate
+		created, err := tasks.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("CreateTask() unexpected error: %v", err)
+		}
+		if status != constants.StatusPending {
+			if created, err = tasks.TransitionTask(ctx, created.ID, status); err != nil {
+				t.Fatalf("TransitionTask() unexpected error: %v", err)
+			}
+		}
+		return created
+	}
+	overdue := newTask(constants.StatusPending, -time.Hour)
+	newTask(constants.StatusInProgress, time.Hour)
+	newTask(constants.StatusCancelled, -time.Hour)
+	tasks.CreateTask(ctx, testutils.CreateTestTask())
+
+	unknown := testutils.CreateTestTask()
+	unknown.ProjectID = "missing"
+	if _, err := tasks.CreateTask(ctx, unknown); !isValidationError(err, "projectId") {
+		t.Errorf("CreateTask() in an unknown project error = %v, want projectId validation error", err)
+	}
+
+	page, err := service.QueryProjectTasks(ctx, project.ID, models.TaskQuery{Status: constants.StatusPending})
+	if err != nil || len(page.Tasks) != 1 || page.Tasks[0].ID != overdue.ID {
+		t.Errorf("QueryProjectTasks() = %+v, %v, want only the pending task", page.Tasks, err)
+	}
+	if _, err := service.QueryProjectTasks(ctx, "missing", models.TaskQuery{}); err != repository.ErrProjectNotFound {
+		t.Errorf("QueryProjectTasks() for an unknown project error = %v, want %v", err, repository.ErrProjectNotFound)
+	}
+
+	stats, err := service.ProjectStats(ctx, project.ID)
+	if err != nil || stats.Total != 3 || stats.Overdue != 1 ||
+		stats.ByStatus[constants.StatusInProgress] != 1 || stats.ByStatus[constants.StatusCompleted] != 0 {
+		t.Errorf("ProjectStats() = %+v, %v, want 3 tasks with 1 overdue", stats, err)
+	}
+
+	if appErr, ok := service.DeleteProject(ctx, project.ID).(*errors.AppError); !ok || appErr.Code != http.StatusConflict {
+		t.Errorf("DeleteProject() with live tasks error = %v, want 409", appErr)
+	}
+	page, _ = service.QueryProjectTasks(ctx, project.ID, models.TaskQuery{})
+	for _, task := range page.Tasks {
+		tasks.DeleteTask(ctx, task.ID, 0)
+	}
+	if err := service.DeleteProject(ctx, project.ID); err != nil {
+		t.Errorf("DeleteProject() once its tasks are trashed unexpected error: %v", err)
+	}
+}
+
+func TestProjectService_EnforcesRolePolicy(t *testing.T) {
+	projects := repository.NewInMemoryProjectRepo()
+	service := NewProjectService(projects, NewTaskService(NewMockTaskRepository()), nil)
+	member := WithPrincipal(ctx, as("mo", models.RoleMember))
+
+	if _, err := service.CreateProject(member, models.Project{Name: "Launch"}); !isForbidden(err) {
+		t.Errorf("CreateProject() as member error = %v, want 403", err)
+	}
+	project, err := service.CreateProject(WithPrincipal(ctx, as("ada", models.RoleAdmin)), models.Project{Name: "Launch"})
+	if err != nil {
+		t.Fatalf("CreateProject() as admin unexpected error: %v", err)
+	}
+	if _, err := service.GetProject(member, project.ID); err != nil {
+		t.Errorf("GetProject() as member unexpected error: %v", err)
+	}
+	if _, err := service.UpdateProject(member, project.ID, models.Project{Name: "Renamed"}); !isForbidden(err) {
+		t.Errorf("UpdateProject() as member error = %v, want 403", err)
+	}
+	if err := service.DeleteProject(member, project.ID); !isForbidden(err) {
+		t.Errorf("DeleteProject() as member error = %v, want 403", err)
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"log"
+	"taskmanager/constants"
+	"time"
+)
+
+// RunTrashPurger permanently removes tasks that have been in the trash for
+// longer than retention, checking every interval until ctx is cancelled
+func RunTrashPurger(ctx context.Context, svc TaskService, retention, interval time.Duration) {
+	ctx = WithActor(ctx, constants.SystemActor)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cutoff := time.Now().Add(-retention)
+			purged := 0
+			err := forEachWorkspace(ctx, svc, func(ctx context.Context) error {
+				n, err := svc.PurgeTrash(ctx, cutoff)
+				purged += n
+				return err
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Printf("trash purge failed after removing %d tasks: %v", purged, err)
+			} else if purged > 0 {
+				log.Printf("purged %d tasks from the trash", purged)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"log"
+	"taskmanager/models"
+	"taskmanager/notifier"
+	"taskmanager/repository"
+	"time"
+)
+
+// ReminderScheduler sends reminders for open tasks that are due within its
+// lead time or already overdue. Sent reminders are recorded so each one
+// fires only once.
+type ReminderScheduler struct {
+	tasks    TaskService
+	sent     repository.ReminderRepository
+	notifier notifier.Notifier
+	lead     time.Duration
+}
+
+// NewReminderScheduler creates a scheduler that warns lead ahead of each due
+// date and again once the task is overdue. A zero lead only sends overdue
+// reminders.
+func NewReminderScheduler(tasks TaskService, sent repository.ReminderRepository, n notifier.Notifier, lead time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{tasks: tasks, sent: sent, notifier: n, lead: lead}
+}
+
+// SendDue sends every reminder due at now that has not been sent yet, in
+// every workspace, and returns how many were sent. Failed deliveries are
+// logged and retried on the next call.
+func (s *ReminderScheduler) SendDue(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+	err := forEachWorkspace(ctx, s.tasks, func(ctx context.Context) error {
+		n, err := s.sendDueIn(ctx, now)
+		sent += n
+		return err
+	})
+	return sent, err
+}
+
+// sendDueIn sends the reminders due at now in the workspace of ctx
+func (s *ReminderScheduler) sendDueIn(ctx context.Context, now time.Time) (int, error) {
+	horizon := now.Add(s.lead + time.Nanosecond)
+	q := models.TaskQuery{
+		DueBefore: &horizon,
+		SortBy:    models.SortByDueDate,
+		Limit:     models.MaxQueryLimit,
+	}
+	sent := 0
+	for {
+		page, err := s.tasks.QueryTasks(ctx, q)
+		if err != nil {
+			return sent, err
+		}
+		for _, task := range page.Tasks {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+			reminder, ok := models.ReminderFor(task, now, s.lead)
+			if !ok {
+				continue
+			}
+			done, err := s.sent.Sent(reminder.Key())
+			if err != nil {
+				return sent, err
+			}
+			if done {
+				continue
+			}
+			if err := s.notifier.Notify(ctx, reminder, task); err != nil {
+				log.Printf("failed to send %s reminder for task %s: %v", reminder.Kind, task.ID, err)
+				continue
+			}
+			reminder.SentAt = now
+			if err := s.sent.Record(reminder); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+		if page.NextCursor == "" {
+			return sent, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// Run calls SendDue every interval until ctx is cancelled
+func (s *ReminderScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sent, err := s.SendDue(ctx, time.Now())
+			if err != nil && ctx.Err() == nil {
+				log.Printf("reminder check failed after sending %d reminders: %v", sent, err)
+			} else if sent > 0 {
+				log.Printf("sent %d due-date reminders", sent)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
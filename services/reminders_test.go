@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+// recordingNotifier remembers every reminder it is asked to send and fails
+// while fail is set
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []models.Reminder
+	fail bool
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, reminder models.Reminder, task models.Task) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail {
+		return stderrors.New("mail server down")
+	}
+	n.sent = append(n.sent, reminder)
+	return nil
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.sent)
+}
+
+func TestReminderScheduler_SendDue(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepo()
+	service := NewTaskService(repo)
+	now := time.Now().Truncate(time.Second)
+	newTask := func(status string, due time.Duration) models.Task {
+		task := testutils.CreateTestTask()
+		task.Status = status
+		dueDate := now.Add(due)
+		task.DueDate = &dueDate
+		created, err := service.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("CreateTask() unexpected error: %v", err)
+		}
+		return created
+	}
+	soon := newTask(constants.StatusPending, 2*time.Hour)
+	overdue := newTask(constants.StatusInProgress, -time.Hour)
+	newTask(constants.StatusPending, 72*time.Hour)
+	newTask(constants.StatusCompleted, -time.Hour)
+
+	n := &recordingNotifier{fail: true}
+	scheduler := NewReminderScheduler(service, repository.NewInMemoryReminderRepo(), n, 24*time.Hour)
+
+	// Failed deliveries are not recorded, so they are retried
+	if sent, err := scheduler.SendDue(ctx, now); err != nil || sent != 0 {
+		t.Fatalf("SendDue() with failing notifier = %d, %v, want 0", sent, err)
+	}
+	n.fail = false
+	if sent, err := scheduler.SendDue(ctx, now); err != nil || sent != 2 {
+		t.Fatalf("SendDue() = %d, %v, want 2", sent, err)
+	}
+	got := map[string]string{}
+	for _, r := range n.sent {
+		got[r.TaskID] = r.Kind
+	}
+	if got[soon.ID] != models.ReminderDueSoon || got[overdue.ID] != models.ReminderOverdue {
+		t.Errorf("reminders sent = %v, want due soon for %s and overdue for %s", got, soon.ID, overdue.ID)
+	}
+
+	// Each reminder fires once
+	if sent, _ := scheduler.SendDue(ctx, now.Add(time.Minute)); sent != 0 {
+		t.Errorf("SendDue() again = %d, want 0", sent)
+	}
+
+	// Once the first task's due date passes it gets its overdue reminder
+	if sent, _ := scheduler.SendDue(ctx, now.Add(3*time.Hour)); sent != 1 || n.sent[2].TaskID != soon.ID || n.sent[2].Kind != models.ReminderOverdue {
+		t.Errorf("SendDue() after due date = %d, last %+v, want an overdue reminder for %s", sent, n.sent[len(n.sent)-1], soon.ID)
+	}
+
+	// Moving the due date makes the task eligible again
+	soon, _ = service.GetTask(ctx, soon.ID)
+	later := now.Add(4 * time.Hour)
+	soon.DueDate = &later
+	if _, err := service.UpdateTask(ctx, soon.ID, soon, 0); err != nil {
+		t.Fatalf("UpdateTask() unexpected error: %v", err)
+	}
+	if sent, _ := scheduler.SendDue(ctx, now.Add(3*time.Hour)); sent != 1 {
+		t.Errorf("SendDue() after moving the due date = %d, want 1", sent)
+	}
+}
+
+func TestReminderScheduler_Run(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepo()
+	service := NewTaskService(repo)
+	task := testutils.CreateTestTask()
+	due := time.Now().Add(-time.Minute)
+	task.DueDate = &due
+	if _, err := service.CreateTask(ctx, task); err != nil {
+		t.Fatalf("CreateTask() unexpected error: %v", err)
+	}
+
+	n := &recordingNotifier{}
+	scheduler := NewReminderScheduler(service, repository.NewInMemoryReminderRepo(), n, time.Hour)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(runCtx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for n.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	if got := n.count(); got != 1 {
+		t.Errorf("Run() sent %d reminders, want exactly 1", got)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/search"
+)
+
+// SearchTasks runs a full-text search over the titles and descriptions of
+// live tasks and returns up to limit results, best first. A limit of 0
+// means models.DefaultSearchLimit.
+func (s *taskService) SearchTasks(ctx context.Context, q string, limit int) ([]models.TaskSearchResult, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(q) == "" {
+		return nil, errors.NewValidationError("q", constants.ValidationSearchQueryRequired)
+	}
+	if limit == 0 {
+		limit = models.DefaultSearchLimit
+	}
+	if limit < 1 || limit > models.MaxSearchLimit {
+		return nil, errors.NewValidationError("limit", constants.ValidationInvalidSearchLimit)
+	}
+	query, err := search.Parse(q)
+	var syntax *search.SyntaxError
+	if stderrors.As(err, &syntax) {
+		return nil, errors.NewSyntaxError("q", syntax.Column, syntax.Msg)
+	}
+	if err != nil {
+		return nil, errors.NewValidationError("q", err.Error())
+	}
+
+	searcher, err := s.searcher(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspace := WorkspaceFromContext(ctx)
+	results := make([]models.TaskSearchResult, 0, limit)
+	seen := make(map[string]bool)
+	// Hits for tasks deleted since they were indexed are dropped, so ask for
+	// more until there are limit results or the index has no more
+	for want := limit; ; want *= 2 {
+		hits := searcher.Search(workspace, query, want)
+		for _, hit := range hits {
+			if seen[hit.ID] {
+				continue
+			}
+			seen[hit.ID] = true
+			task, err := s.repo.GetByID(workspace, hit.ID)
+			if err == repository.ErrTaskNotFound || err == nil && task.IsDeleted() {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, models.TaskSearchResult{
+				Task:  task,
+				Score: hit.Score,
+				Highlights: models.SearchHighlights{
+					Title:       hit.Title,
+					Description: hit.Description,
+				},
+			})
+			if len(results) == limit {
+				return results, nil
+			}
+		}
+		if len(hits) < want {
+			return results, nil
+		}
+	}
+}
+
+// searcher returns the configured index, or a throwaway index of the
+// workspace's live tasks
+func (s *taskService) searcher(ctx context.Context) (repository.TaskSearcher, error) {
+	if s.search != nil {
+		return s.search, nil
+	}
+	tasks, err := s.repo.GetAll(WorkspaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	index := search.NewIndex()
+	for _, task := range tasks {
+		if !task.IsDeleted() {
+			index.Put(WorkspaceFromContext(ctx), search.Document{ID: task.ID, Title: task.Title, Description: task.Description})
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/search"
+	"taskmanager/testutils"
+	"testing"
+)
+
+func TestTaskService_SearchTasks(t *testing.T) {
+	indexed, err := repository.NewIndexedTaskRepo(repository.NewInMemoryTaskRepo())
+	if err != nil {
+		t.Fatalf("NewIndexedTaskRepo() unexpected error: %v", err)
+	}
+	impls := map[string]TaskService{
+		"index":    NewTaskService(indexed, WithSearchIndex(indexed)),
+		"no index": NewTaskService(NewMockTaskRepository()),
+	}
+	for name, service := range impls {
+		t.Run(name, func(t *testing.T) {
+			create := func(ctx context.Context, title, description string) models.Task {
+				task := testutils.CreateTestTask()
+				task.Title = title
+				task.Description = description
+				created, err := service.CreateTask(ctx, task)
+				if err != nil {
+					t.Fatalf("CreateTask() unexpected error: %v", err)
+				}
+				return created
+			}
+			deploy := create(ctx, "Deploy the API", "Roll out the <new> gateway")
+			notes := create(ctx, "Write release notes", "Cover what deploying the gateway changes")
+			trashed := create(ctx, "Deploy the old API", "")
+			if err := service.DeleteTask(ctx, trashed.ID, 0); err != nil {
+				t.Fatalf("DeleteTask() unexpected error: %v", err)
+			}
+			create(WithWorkspace(ctx, "other"), "Deploy elsewhere", "")
+
+			results, err := service.SearchTasks(ctx, "deployed gateway", 0)
+			if err != nil {
+				t.Fatalf("SearchTasks() unexpected error: %v", err)
+			}
+			if len(results) != 2 || results[0].Task.ID != deploy.ID || results[1].Task.ID != notes.ID {
+				t.Fatalf("SearchTasks() = %+v, want the title match first and no trashed or foreign tasks", results)
+			}
+			if results[0].Score <= results[1].Score {
+				t.Errorf("SearchTasks() scores = %v, %v, want descending", results[0].Score, results[1].Score)
+			}
+			want := models.SearchHighlights{
+				Title:       "<mark>Deploy</mark> the API",
+				Description: "Roll out the &lt;new&gt; <mark>gateway</mark>",
+			}
+			if results[0].Highlights != want {
+				t.Errorf("SearchTasks() highlights = %+v, want %+v", results[0].Highlights, want)
+			}
+
+			if results, _ := service.SearchTasks(ctx, `"release notes" writ*`, 1); len(results) != 1 || results[0].Task.ID != notes.ID {
+				t.Errorf("SearchTasks(phrase and prefix) = %+v", results)
+			}
+			if results, _ := service.SearchTasks(ctx, "deploy", 1); len(results) != 1 {
+				t.Errorf("SearchTasks(limit 1) returned %d results", len(results))
+			}
+
+			invalid := []struct {
+				q     string
+				limit int
+				field string
+			}{
+				{"  ", 0, "q"},
+				{`"unterminated`, 0, "q"},
+				{"!!", 0, "q"},
+				{"deploy", -1, "limit"},
+				{"deploy", models.MaxSearchLimit + 1, "limit"},
+			}
+			for _, tt := range invalid {
+				if _, err := service.SearchTasks(ctx, tt.q, tt.limit); !isValidationError(err, tt.field) {
+					t.Errorf("SearchTasks(%q, %d) error = %v, want %s validation error", tt.q, tt.limit, err, tt.field)
+				}
+			}
+
+			if _, err := service.SearchTasks(WithPrincipal(ctx, as("nobody")), "deploy", 0); !isForbidden(err) {
+				t.Errorf("SearchTasks() without a role error = %v, want forbidden", err)
+			}
+			if _, err := service.SearchTasks(WithPrincipal(ctx, as("viewer", models.RoleViewer)), "deploy", 0); err != nil {
+				t.Errorf("SearchTasks() as viewer unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskService_SearchTasksSkipsStaleHits(t *testing.T) {
+	repo := NewMockTaskRepository()
+	index := search.NewIndex()
+	service := NewTaskService(repo, WithSearchIndex(index))
+
+	task := testutils.CreateTestTask()
+	task.Title = "Deploy the API"
+	created, err := service.CreateTask(ctx, task)
+	if err != nil {
+		t.Fatalf("CreateTask() unexpected error: %v", err)
+	}
+	index.Put(constants.DefaultWorkspace, search.Document{ID: created.ID, Title: created.Title})
+	// Better matches for tasks the repository no longer has
+	for _, id := range []string{"gone-1", "gone-2", "gone-3"} {
+		index.Put(constants.DefaultWorkspace, search.Document{ID: id, Title: "Deploy deploy"})
+	}
+
+	results, err := service.SearchTasks(ctx, "deploy", 1)
+	if err != nil {
+		t.Fatalf("SearchTasks() unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Task.ID != created.ID {
+		t.Errorf("SearchTasks(limit 1) = %+v, want the live task past the stale hits", results)
+	}
+}
@@ -1,8 +1,17 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
 	"taskmanager/constants"
 	"taskmanager/errors"
+	"taskmanager/jsonpatch"
 	"taskmanager/models"
 	"taskmanager/repository"
 	"time"
@@ -11,73 +20,889 @@ import (
 )
 
 type TaskService interface {
-    GetTasks() []models.Task
-    GetTask(id string) (models.Task, error)
-    CreateTask(task models.Task) (models.Task, error)
-    UpdateTask(id string, task models.Task) (models.Task, error)
-    DeleteTask(id string) error
+	GetTasks(ctx context.Context) ([]models.Task, error)
+	GetTask(ctx context.Context, id string) (models.Task, error)
+	QueryTasks(ctx context.Context, q models.TaskQuery) (models.TaskPage, error)
+	CreateTask(ctx context.Context, task models.Task) (models.Task, error)
+	UpdateTask(ctx context.Context, id string, task models.Task, expectedVersion int64) (models.Task, error)
+	PatchTask(ctx context.Context, id, contentType string, patch []byte, expectedVersion int64) (models.Task, error)
+	DeleteTask(ctx context.Context, id string, expectedVersion int64) error
+	AllowedTransitions(ctx context.Context, id string) ([]string, error)
+	TransitionTask(ctx context.Context, id, status string) (models.Task, error)
+	ListTrash(ctx context.Context, q models.TaskQuery) (models.TaskPage, error)
+	RestoreTask(ctx context.Context, id string) (models.Task, error)
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error)
+	GetChildren(ctx context.Context, id string) ([]models.Task, error)
+	GetSubtree(ctx context.Context, id string) (models.TaskTree, error)
+	GetDependencies(ctx context.Context, id string) (models.TaskDependencies, error)
+	AddDependency(ctx context.Context, id, blockerID string) (models.Task, error)
+	RemoveDependency(ctx context.Context, id, blockerID string) (models.Task, error)
+	NextTasks(ctx context.Context) ([]models.PlannedTask, error)
+	PreviewOccurrences(ctx context.Context, id string, count int) ([]time.Time, error)
+	ListLabels(ctx context.Context) ([]models.LabelCount, error)
+	RenameLabel(ctx context.Context, from, to string) (int, error)
+	MergeLabels(ctx context.Context, from []string, to string) (int, error)
+	SearchTasks(ctx context.Context, q string, limit int) ([]models.TaskSearchResult, error)
+	// Workspaces lists every workspace holding tasks. Background jobs use
+	// it to run once per workspace.
+	Workspaces(ctx context.Context) ([]string, error)
 }
 
+// ErrDependencyNotFound is returned when removing an edge that does not exist
+var ErrDependencyNotFound = errors.NewNotFoundError("Dependency")
+
 type taskService struct {
-    repo repository.TaskRepository
+	repo        repository.TaskRepository
+	transitions models.TransitionGraph
+	audit       repository.AuditRepository
+	publishers  []EventPublisher
+	policy      models.RolePolicy
+	projects    repository.ProjectRepository
+	comments    repository.CommentRepository
+	attachments *AttachmentStore
+	search      repository.TaskSearcher
+}
+
+// TaskServiceOption configures optional TaskService behaviour
+type TaskServiceOption func(*taskService)
+
+// WithTransitions replaces the default status transition graph
+func WithTransitions(g models.TransitionGraph) TaskServiceOption {
+	return func(s *taskService) {
+		s.transitions = g
+	}
+}
+
+// WithAuditLog records every create, update and delete in audit
+func WithAuditLog(audit repository.AuditRepository) TaskServiceOption {
+	return func(s *taskService) {
+		s.audit = audit
+	}
+}
+
+// WithProjects checks that every task's projectId names a project in
+// projects. Without it projectId is stored unchecked.
+func WithProjects(projects repository.ProjectRepository) TaskServiceOption {
+	return func(s *taskService) {
+		s.projects = projects
+	}
+}
+
+// WithComments removes a task's comments from comments when the task is
+// purged from the trash
+func WithComments(comments repository.CommentRepository) TaskServiceOption {
+	return func(s *taskService) {
+		s.comments = comments
+	}
+}
+
+// WithAttachments removes a task's attachments, and any blobs no other
+// attachment shares, when the task is purged from the trash
+func WithAttachments(attachments *AttachmentStore) TaskServiceOption {
+	return func(s *taskService) {
+		s.attachments = attachments
+	}
+}
+
+// WithSearchIndex answers searches from index, which must be kept up to date
+// with the task repository. Without it every search indexes the workspace
+// from scratch.
+func WithSearchIndex(index repository.TaskSearcher) TaskServiceOption {
+	return func(s *taskService) {
+		s.search = index
+	}
+}
+
+func NewTaskService(r repository.TaskRepository, opts ...TaskServiceOption) TaskService {
+	s := &taskService{
+		repo:        r,
+		transitions: models.DefaultTransitionGraph(),
+		policy:      models.DefaultRolePolicy(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *taskService) GetTasks(ctx context.Context) ([]models.Task, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	tasks, err := s.repo.GetAll(WorkspaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	live := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if !task.IsDeleted() {
+			live = append(live, task)
+		}
+	}
+	return live, nil
+}
+
+func (s *taskService) GetTask(ctx context.Context, id string) (models.Task, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.Task{}, err
+	}
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	tree, err := s.tree(ctx, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	return tree.Task, nil
 }
 
-func NewTaskService(r repository.TaskRepository) TaskService {
-    return &taskService{repo: r}
+func (s *taskService) GetChildren(ctx context.Context, id string) ([]models.Task, error) {
+	tree, err := s.GetSubtree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]models.Task, 0, len(tree.Children))
+	for _, child := range tree.Children {
+		children = append(children, child.Task)
+	}
+	return children, nil
 }
 
-func (s *taskService) GetTasks() []models.Task {
-    return s.repo.GetAll()
+func (s *taskService) GetSubtree(ctx context.Context, id string) (models.TaskTree, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.TaskTree{}, err
+	}
+	root, err := s.getLive(ctx, id)
+	if err != nil {
+		return models.TaskTree{}, err
+	}
+	return s.tree(ctx, root)
 }
 
-func (s *taskService) GetTask(id string) (models.Task, error) {
-    return s.repo.GetByID(id)
+// tree loads everything under root in one repository read and assembles it,
+// which also rolls up root's progress
+func (s *taskService) tree(ctx context.Context, root models.Task) (models.TaskTree, error) {
+	descendants, err := s.repo.Descendants(WorkspaceFromContext(ctx), root.ID)
+	if err != nil {
+		return models.TaskTree{}, err
+	}
+	children := make(map[string][]models.Task)
+	for _, task := range descendants {
+		children[task.ParentID] = append(children[task.ParentID], task)
+	}
+	// Siblings keep the creation order a ParentID query returns
+	for _, kids := range children {
+		slices.SortFunc(kids, func(a, b models.Task) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+	}
+	return models.BuildTaskTree(root, children), nil
 }
 
-func (s *taskService) CreateTask(task models.Task) (models.Task, error) {
+// children returns every live direct subtask of parentID
+func (s *taskService) children(ctx context.Context, parentID string) ([]models.Task, error) {
+	q := models.TaskQuery{ParentID: parentID, Limit: models.MaxQueryLimit}
+	q.Normalize()
+	var result []models.Task
+	for {
+		page, err := s.repo.Query(WorkspaceFromContext(ctx), q)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page.Tasks...)
+		if page.NextCursor == "" {
+			return result, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// getLive loads a task that has not been moved to the trash
+func (s *taskService) getLive(ctx context.Context, id string) (models.Task, error) {
+	task, err := s.repo.GetByID(WorkspaceFromContext(ctx), id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if task.IsDeleted() {
+		return models.Task{}, repository.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (s *taskService) QueryTasks(ctx context.Context, q models.TaskQuery) (models.TaskPage, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.TaskPage{}, err
+	}
+	q.Deleted = false
+	if err := q.Validate(); err != nil {
+		return models.TaskPage{}, err
+	}
+	if q.Filter != nil {
+		q.Filter = q.Filter.ForUser(ActorFromContext(ctx))
+	}
+	q.Normalize()
+	return s.repo.Query(WorkspaceFromContext(ctx), q)
+}
+
+func (s *taskService) CreateTask(ctx context.Context, task models.Task) (models.Task, error) {
+	if err := authorize(ctx, s.policy, models.PermCreateTasks); err != nil {
+		return models.Task{}, err
+	}
+	return s.create(ctx, task)
+}
+
+// create validates and stores a new task without checking the caller's
+// permissions
+func (s *taskService) create(ctx context.Context, task models.Task) (models.Task, error) {
+	task, err := s.prepare(ctx, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	created, err := s.repo.Save(WorkspaceFromContext(ctx), task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	s.record(ctx, constants.AuditActionCreate, models.Task{}, created)
+	s.publish(ctx, constants.AuditActionCreate, models.Task{}, created)
+	return created, nil
+}
+
+// prepare validates a new task and fills in its ID, defaults and timestamps
+func (s *taskService) prepare(ctx context.Context, task models.Task) (models.Task, error) {
+	// Set default status if not provided
+	if task.Status == "" {
+		task.Status = constants.StatusPending
+	}
+
 	// Validate the task
 	if err := task.Validate(); err != nil {
 		return models.Task{}, err
 	}
+	task.Labels = models.SortLabels(task.Labels)
+
+	if err := s.checkParent(ctx, "", task.ParentID); err != nil {
+		return models.Task{}, err
+	}
+	if err := s.checkProject(ctx, task.ProjectID); err != nil {
+		return models.Task{}, err
+	}
+	if err := s.checkBlockers(ctx, "", task.BlockedBy, nil); err != nil {
+		return models.Task{}, err
+	}
+	if task.Status == constants.StatusInProgress {
+		if err := s.checkUnblocked(ctx, task.BlockedBy); err != nil {
+			return models.Task{}, err
+		}
+	}
 
 	// Set default values
 	task.ID = uuid.NewString()
+	task.Progress = nil
 	now := time.Now()
 	task.CreatedAt = now
 	task.UpdatedAt = now
+	task.Version = 1
+	return task, nil
+}
 
-	// Set default status if not provided
-	if task.Status == "" {
-		task.Status = constants.StatusPending
+func (s *taskService) UpdateTask(ctx context.Context, id string, task models.Task, expectedVersion int64) (models.Task, error) {
+	// Get existing task
+	existing, err := s.getForWrite(ctx, id, expectedVersion)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := s.authorizeEdit(ctx, existing); err != nil {
+		return models.Task{}, err
 	}
+	return s.applyUpdate(ctx, existing, task)
+}
 
-	return s.repo.Save(task), nil
+// authorizeEdit checks that the caller may modify task
+func (s *taskService) authorizeEdit(ctx context.Context, task models.Task) error {
+	return authorizeTask(ctx, s.policy, models.PermEditTasks, models.PermEditOwnTasks, task)
 }
 
-func (s *taskService) UpdateTask(id string, task models.Task) (models.Task, error) {
-	// Get existing task
-	existing, err := s.repo.GetByID(id)
+// getForWrite loads a task that is about to be modified. A non-zero
+// expectedVersion must match the stored version.
+func (s *taskService) getForWrite(ctx context.Context, id string, expectedVersion int64) (models.Task, error) {
+	existing, err := s.getLive(ctx, id)
 	if err != nil {
 		return models.Task{}, err
 	}
+	if expectedVersion != 0 && existing.Version != expectedVersion {
+		return models.Task{}, repository.ErrVersionConflict
+	}
+	return existing, nil
+}
 
+// applyUpdate copies the client-editable fields of task onto existing and
+// stores the result. The repository only accepts the write if existing is
+// still the latest version, so concurrent edits cannot silently overwrite
+// each other.
+func (s *taskService) applyUpdate(ctx context.Context, existing, task models.Task) (models.Task, error) {
 	// Validate the updated task
 	if err := task.Validate(); err != nil {
 		return models.Task{}, err
 	}
+	if err := s.checkTransition(existing.Status, task.Status); err != nil {
+		return models.Task{}, err
+	}
+	if err := s.checkSubtasksDone(ctx, existing, task.Status); err != nil {
+		return models.Task{}, err
+	}
+	if task.ParentID != existing.ParentID {
+		if err := s.checkParent(ctx, existing.ID, task.ParentID); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if task.ProjectID != existing.ProjectID {
+		if err := s.checkProject(ctx, task.ProjectID); err != nil {
+			return models.Task{}, err
+		}
+	}
+	if err := s.checkBlockers(ctx, existing.ID, task.BlockedBy, existing.BlockedBy); err != nil {
+		return models.Task{}, err
+	}
+	if task.Status == constants.StatusInProgress && existing.Status != constants.StatusInProgress {
+		if err := s.checkUnblocked(ctx, task.BlockedBy); err != nil {
+			return models.Task{}, err
+		}
+	}
 
 	// Only update allowed fields (SOLID - Single Responsibility)
-	existing.Title = task.Title
-	existing.Description = task.Description
-	existing.Status = task.Status
-	existing.Priority = task.Priority
-	existing.DueDate = task.DueDate
-	existing.AssignedTo = task.AssignedTo
-	existing.UpdatedAt = time.Now()
+	updated := existing
+	updated.Title = task.Title
+	updated.Description = task.Description
+	updated.Status = task.Status
+	updated.Priority = task.Priority
+	updated.DueDate = task.DueDate
+	updated.AssignedTo = task.AssignedTo
+	updated.ProjectID = task.ProjectID
+	updated.ParentID = task.ParentID
+	updated.BlockedBy = task.BlockedBy
+	updated.Labels = models.SortLabels(task.Labels)
+	updated.RRule = task.RRule
+	updated.UpdatedAt = time.Now()
+
+	return s.update(ctx, constants.AuditActionUpdate, existing, updated)
+}
+
+// update stores updated in place of existing and records the change.
+// Completing a recurring task hands its rule over to a new task for the next
+// occurrence, so reopening and completing it again cannot spawn a duplicate.
+func (s *taskService) update(ctx context.Context, action string, existing, updated models.Task) (models.Task, error) {
+	ws := WorkspaceFromContext(ctx)
+	var next *models.Task
+	if updated.Status == constants.StatusCompleted && existing.Status != constants.StatusCompleted {
+		var err error
+		if next, err = s.storeNextOccurrence(ctx, updated); err != nil {
+			return models.Task{}, err
+		}
+		if next != nil {
+			updated.RRule = ""
+		}
+	}
 
-	return s.repo.Update(id, existing)
+	stored, err := s.repo.Update(ws, existing.ID, updated)
+	if err != nil {
+		// The occurrence must not outlive a completion that did not happen
+		if next != nil {
+			if derr := s.repo.Delete(ws, next.ID); derr != nil {
+				log.Printf("failed to remove next occurrence %s of task %s: %v", next.ID, existing.ID, derr)
+			}
+		}
+		return models.Task{}, err
+	}
+	s.record(ctx, action, existing, stored)
+	s.publish(ctx, action, existing, stored)
+	if next != nil {
+		s.record(ctx, constants.AuditActionCreate, models.Task{}, *next)
+		s.publish(ctx, constants.AuditActionCreate, models.Task{}, *next)
+	}
+	return stored, nil
+}
+
+// storeNextOccurrence stores the task following completed in its series, if
+// it has one. It runs before the completion is stored, so when it fails the
+// completion fails with it and can be retried without ending the series.
+// The occurrence is created on behalf of whoever completed this one, so it
+// skips the tasks:create check: being allowed to finish a recurring task is
+// enough.
+func (s *taskService) storeNextOccurrence(ctx context.Context, completed models.Task) (*models.Task, error) {
+	occurrence, ok := completed.NextOccurrence()
+	if !ok {
+		return nil, nil
+	}
+	next, err := s.prepare(ctx, occurrence)
+	if err != nil {
+		return nil, err
+	}
+	if next, err = s.repo.Save(WorkspaceFromContext(ctx), next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// record appends an audit entry describing the change from before to after.
+// The task write has already succeeded at this point, so a failure to record
+// it is logged rather than reported to the caller.
+func (s *taskService) record(ctx context.Context, action string, before, after models.Task) {
+	if s.audit == nil {
+		return
+	}
+	taskID := after.ID
+	if taskID == "" {
+		taskID = before.ID
+	}
+	entry := models.AuditEntry{
+		ID:        uuid.NewString(),
+		TaskID:    taskID,
+		Workspace: WorkspaceFromContext(ctx),
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		Timestamp: time.Now(),
+		Changes:   models.DiffTasks(before, after),
+	}
+	if err := s.audit.Append(entry); err != nil {
+		log.Printf("failed to record audit entry for task %s: %v", taskID, err)
+	}
 }
 
-func (s *taskService) DeleteTask(id string) error {
-    return s.repo.Delete(id)
+func (s *taskService) PatchTask(ctx context.Context, id, contentType string, patch []byte, expectedVersion int64) (models.Task, error) {
+	existing, err := s.getForWrite(ctx, id, expectedVersion)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := s.authorizeEdit(ctx, existing); err != nil {
+		return models.Task{}, err
+	}
+
+	doc, err := json.Marshal(existing)
+	if err != nil {
+		return models.Task{}, err
+	}
+	switch contentType {
+	case constants.ContentTypeMergePatch:
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case constants.ContentTypeJSONPatch:
+		doc, err = jsonpatch.Apply(doc, patch)
+	default:
+		return models.Task{}, errors.NewUnsupportedMediaTypeError(constants.MessageUnsupportedPatchType)
+	}
+	if stderrors.Is(err, jsonpatch.ErrTestFailed) {
+		return models.Task{}, errors.NewConflictError(constants.MessagePatchTestFailed)
+	}
+	if err != nil {
+		return models.Task{}, errors.NewBadRequestError(fmt.Sprintf("%s: %v", constants.MessageInvalidPatch, err))
+	}
+
+	var patched models.Task
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patched); err != nil {
+		return models.Task{}, errors.NewBadRequestError(fmt.Sprintf("%s: %v", constants.MessageInvalidPatch, err))
+	}
+
+	// The patched document goes through the same rules as a full update, which
+	// also keeps server-managed fields such as id, createdAt and version untouched
+	return s.applyUpdate(ctx, existing, patched)
+}
+
+// DeleteTask moves a task to the trash. It stays restorable, along with its
+// comments and attachments, until PurgeTrash removes it for good.
+func (s *taskService) DeleteTask(ctx context.Context, id string, expectedVersion int64) error {
+	existing, err := s.getForWrite(ctx, id, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if err := authorizeTask(ctx, s.policy, models.PermDeleteTasks, models.PermDeleteOwnTasks, existing); err != nil {
+		return err
+	}
+	deleted := existing
+	now := time.Now()
+	deleted.DeletedAt = &now
+	_, err = s.update(ctx, constants.AuditActionDelete, existing, deleted)
+	return err
+}
+
+func (s *taskService) ListTrash(ctx context.Context, q models.TaskQuery) (models.TaskPage, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.TaskPage{}, err
+	}
+	q.Deleted = true
+	if q.SortBy == "" {
+		q.SortBy = models.SortByDeletedAt
+		q.SortDesc = true
+	}
+	if err := q.Validate(); err != nil {
+		return models.TaskPage{}, err
+	}
+	q.Normalize()
+	return s.repo.Query(WorkspaceFromContext(ctx), q)
+}
+
+func (s *taskService) RestoreTask(ctx context.Context, id string) (models.Task, error) {
+	task, err := s.repo.GetByID(WorkspaceFromContext(ctx), id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if !task.IsDeleted() {
+		return models.Task{}, errors.NewConflictError(constants.MessageTaskNotDeleted)
+	}
+	if err := authorizeTask(ctx, s.policy, models.PermDeleteTasks, models.PermDeleteOwnTasks, task); err != nil {
+		return models.Task{}, err
+	}
+	restored := task
+	restored.DeletedAt = nil
+	return s.update(ctx, constants.AuditActionRestore, task, restored)
+}
+
+// PurgeTrash permanently removes tasks that were moved to the trash before
+// deletedBefore and reports how many were removed
+func (s *taskService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := authorize(ctx, s.policy, models.PermDeleteTasks); err != nil {
+		return 0, err
+	}
+	q := models.TaskQuery{
+		Deleted:       true,
+		DeletedBefore: &deletedBefore,
+		SortBy:        models.SortByDeletedAt,
+		Limit:         models.MaxQueryLimit,
+	}
+	purged := 0
+	for {
+		page, err := s.repo.Query(WorkspaceFromContext(ctx), q)
+		if err != nil {
+			return purged, err
+		}
+		for _, task := range page.Tasks {
+			if err := ctx.Err(); err != nil {
+				return purged, err
+			}
+			// Skip tasks restored since the page was read
+			current, err := s.repo.GetByID(WorkspaceFromContext(ctx), task.ID)
+			if err != nil || !current.IsDeleted() || !current.DeletedAt.Before(deletedBefore) {
+				continue
+			}
+			// Comments and attachments go first so a failure leaves the
+			// task in the trash to be purged again rather than orphaning them
+			if s.comments != nil {
+				if _, err := s.comments.DeleteTaskComments(WorkspaceFromContext(ctx), task.ID); err != nil {
+					return purged, err
+				}
+			}
+			if s.attachments != nil {
+				if err := s.attachments.removeTask(WorkspaceFromContext(ctx), task.ID); err != nil {
+					return purged, err
+				}
+			}
+			if err := s.repo.Delete(WorkspaceFromContext(ctx), task.ID); err != nil {
+				if err == repository.ErrTaskNotFound {
+					continue
+				}
+				return purged, err
+			}
+			s.record(ctx, constants.AuditActionPurge, current, models.Task{})
+			purged++
+		}
+		if page.NextCursor == "" {
+			return purged, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func (s *taskService) AllowedTransitions(ctx context.Context, id string) ([]string, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.transitions.AllowedFrom(task.Status), nil
+}
+
+func (s *taskService) TransitionTask(ctx context.Context, id, status string) (models.Task, error) {
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := s.authorizeEdit(ctx, task); err != nil {
+		return models.Task{}, err
+	}
+	if !(&models.Task{Status: status}).IsValidStatus() {
+		return models.Task{}, errors.NewValidationError("status", constants.ValidationInvalidStatus)
+	}
+	if err := s.checkTransition(task.Status, status); err != nil {
+		return models.Task{}, err
+	}
+	if err := s.checkSubtasksDone(ctx, task, status); err != nil {
+		return models.Task{}, err
+	}
+	if status == constants.StatusInProgress && task.Status != constants.StatusInProgress {
+		if err := s.checkUnblocked(ctx, task.BlockedBy); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	updated := task
+	updated.Status = status
+	return s.update(ctx, constants.AuditActionUpdate, task, updated)
+}
+
+// checkTransition rejects status changes not allowed by the transition graph
+func (s *taskService) checkTransition(from, to string) error {
+	if !s.transitions.Allows(from, to) {
+		return errors.NewConflictError(fmt.Sprintf(constants.MessageInvalidTransition, from, to))
+	}
+	return nil
+}
+
+// checkSubtasksDone rejects completing task while any of its subtasks is open
+func (s *taskService) checkSubtasksDone(ctx context.Context, task models.Task, status string) error {
+	if status != constants.StatusCompleted || task.Status == constants.StatusCompleted {
+		return nil
+	}
+	children, err := s.children(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if child.IsOpen() {
+			return errors.NewConflictError(constants.MessageOpenSubtasks)
+		}
+	}
+	return nil
+}
+
+// checkParent verifies that parentID names a live task and that nesting
+// taskID under it would not make taskID its own ancestor. taskID is empty
+// for tasks that do not exist yet.
+func (s *taskService) checkParent(ctx context.Context, taskID, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == taskID {
+		return errors.NewValidationError("parentId", constants.ValidationParentCycle)
+	}
+	parent, err := s.getLive(ctx, parentID)
+	if err == repository.ErrTaskNotFound {
+		return errors.NewValidationError("parentId", constants.ValidationParentNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{parent.ID: true}
+	for ancestorID := parent.ParentID; ancestorID != "" && !seen[ancestorID]; {
+		if ancestorID == taskID {
+			return errors.NewValidationError("parentId", constants.ValidationParentCycle)
+		}
+		seen[ancestorID] = true
+		// Trashed ancestors still count; a purged one ends the chain
+		ancestor, err := s.repo.GetByID(WorkspaceFromContext(ctx), ancestorID)
+		if err == repository.ErrTaskNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		ancestorID = ancestor.ParentID
+	}
+	return nil
+}
+
+// checkProject verifies that projectID names a project in the caller's
+// workspace
+func (s *taskService) checkProject(ctx context.Context, projectID string) error {
+	if projectID == "" || s.projects == nil {
+		return nil
+	}
+	_, err := s.projects.GetProject(WorkspaceFromContext(ctx), projectID)
+	if err == repository.ErrProjectNotFound {
+		return errors.NewValidationError("projectId", constants.ValidationProjectNotFound)
+	}
+	return err
+}
+
+func (s *taskService) GetDependencies(ctx context.Context, id string) (models.TaskDependencies, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.TaskDependencies{}, err
+	}
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return models.TaskDependencies{}, err
+	}
+
+	deps := models.TaskDependencies{BlockedBy: []models.Task{}, Blocks: []models.Task{}}
+	for _, blockerID := range task.BlockedBy {
+		blocker, err := s.getLive(ctx, blockerID)
+		if err == repository.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return models.TaskDependencies{}, err
+		}
+		deps.BlockedBy = append(deps.BlockedBy, blocker)
+		if models.IsBlockedBy(blocker) {
+			deps.Blocked = true
+		}
+	}
+
+	tasks, err := s.GetTasks(ctx)
+	if err != nil {
+		return models.TaskDependencies{}, err
+	}
+	for _, other := range tasks {
+		if slices.Contains(other.BlockedBy, id) {
+			deps.Blocks = append(deps.Blocks, other)
+		}
+	}
+	return deps, nil
+}
+
+func (s *taskService) AddDependency(ctx context.Context, id, blockerID string) (models.Task, error) {
+	existing, err := s.getLive(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := s.authorizeEdit(ctx, existing); err != nil {
+		return models.Task{}, err
+	}
+	if slices.Contains(existing.BlockedBy, blockerID) {
+		return existing, nil
+	}
+	task := existing
+	task.BlockedBy = append(slices.Clone(existing.BlockedBy), blockerID)
+	return s.applyUpdate(ctx, existing, task)
+}
+
+func (s *taskService) RemoveDependency(ctx context.Context, id, blockerID string) (models.Task, error) {
+	existing, err := s.getLive(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := s.authorizeEdit(ctx, existing); err != nil {
+		return models.Task{}, err
+	}
+	i := slices.Index(existing.BlockedBy, blockerID)
+	if i < 0 {
+		return models.Task{}, ErrDependencyNotFound
+	}
+	task := existing
+	task.BlockedBy = slices.Delete(slices.Clone(existing.BlockedBy), i, i+1)
+	if len(task.BlockedBy) == 0 {
+		task.BlockedBy = nil
+	}
+	return s.applyUpdate(ctx, existing, task)
+}
+
+// NextTasks lists pending tasks in an order that respects their dependencies,
+// marking the ones that can be started right away
+func (s *taskService) NextTasks(ctx context.Context) ([]models.PlannedTask, error) {
+	tasks, err := s.GetTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.PlanTasks(tasks), nil
+}
+
+// PreviewOccurrences lists the due dates of the next count occurrences of a
+// recurring task, after its own
+func (s *taskService) PreviewOccurrences(ctx context.Context, id string, count int) ([]time.Time, error) {
+	if count < 1 || count > models.MaxOccurrenceCount {
+		return nil, errors.NewValidationError("count", constants.ValidationInvalidCount)
+	}
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return task.UpcomingOccurrences(count), nil
+}
+
+func (s *taskService) Workspaces(ctx context.Context) ([]string, error) {
+	return s.repo.Workspaces()
+}
+
+// checkBlockers verifies every blocker added to taskID's blockedBy list: it
+// must be a live task, and it must not already depend on taskID directly or
+// transitively. taskID is empty for tasks that do not exist yet.
+func (s *taskService) checkBlockers(ctx context.Context, taskID string, blockedBy, previous []string) error {
+	for _, blockerID := range blockedBy {
+		if slices.Contains(previous, blockerID) {
+			continue
+		}
+		if blockerID == taskID {
+			return errors.NewValidationError("blockedBy", constants.ValidationDependencyCycle)
+		}
+		if _, err := s.getLive(ctx, blockerID); err == repository.ErrTaskNotFound {
+			return errors.NewValidationError("blockedBy", constants.ValidationBlockerNotFound)
+		} else if err != nil {
+			return err
+		}
+		if taskID == "" {
+			continue
+		}
+		cycle, err := s.dependsOn(ctx, blockerID, taskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return errors.NewValidationError("blockedBy", constants.ValidationDependencyCycle)
+		}
+	}
+	return nil
+}
+
+// dependsOn reports whether from is blocked by target, directly or through
+// other tasks
+func (s *taskService) dependsOn(ctx context.Context, from, target string) (bool, error) {
+	seen := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		task, err := s.repo.GetByID(WorkspaceFromContext(ctx), id)
+		if err == repository.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		for _, blockerID := range task.BlockedBy {
+			if blockerID == target {
+				return true, nil
+			}
+			if !seen[blockerID] {
+				seen[blockerID] = true
+				stack = append(stack, blockerID)
+			}
+		}
+	}
+	return false, nil
+}
+
+// checkUnblocked rejects starting a task while any live blocker is unfinished
+func (s *taskService) checkUnblocked(ctx context.Context, blockedBy []string) error {
+	for _, blockerID := range blockedBy {
+		blocker, err := s.getLive(ctx, blockerID)
+		if err == repository.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if models.IsBlockedBy(blocker) {
+			return errors.NewConflictError(constants.MessageTaskBlocked)
+		}
+	}
+	return nil
 }
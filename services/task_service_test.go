@@ -1,14 +1,21 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
 	"testing"
 	"taskmanager/constants"
 	"taskmanager/errors"
 	"taskmanager/models"
 	"taskmanager/repository"
 	"taskmanager/testutils"
+	"time"
 )
 
+var ctx = context.Background()
+
 // MockTaskRepository is a mock implementation of TaskRepository for testing
 type MockTaskRepository struct {
 	tasks map[string]models.Task
@@ -20,50 +27,100 @@ func NewMockTaskRepository() *MockTaskRepository {
 	}
 }
 
-func (m *MockTaskRepository) GetAll() []models.Task {
+func (m *MockTaskRepository) GetAll(workspace string) ([]models.Task, error) {
 	var result []models.Task
 	for _, task := range m.tasks {
-		result = append(result, task)
+		if task.Workspace == workspace {
+			result = append(result, task)
+		}
 	}
-	return result
+	return result, nil
 }
 
-func (m *MockTaskRepository) GetByID(id string) (models.Task, error) {
+func (m *MockTaskRepository) GetByID(workspace, id string) (models.Task, error) {
 	task, exists := m.tasks[id]
-	if !exists {
+	if !exists || task.Workspace != workspace {
 		return models.Task{}, repository.ErrTaskNotFound
 	}
 	return task, nil
 }
 
-func (m *MockTaskRepository) Save(task models.Task) models.Task {
+func (m *MockTaskRepository) Descendants(workspace, id string) ([]models.Task, error) {
+	var result []models.Task
+	seen := map[string]bool{id: true}
+	for queue := []string{id}; len(queue) > 0; queue = queue[1:] {
+		for _, task := range m.tasks {
+			if task.Workspace == workspace && task.ParentID == queue[0] && !task.IsDeleted() && !seen[task.ID] {
+				seen[task.ID] = true
+				result = append(result, task)
+				queue = append(queue, task.ID)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *MockTaskRepository) Query(workspace string, q models.TaskQuery) (models.TaskPage, error) {
+	page := models.TaskPage{Tasks: []models.Task{}}
+	for _, task := range m.tasks {
+		if task.Workspace == workspace && q.Matches(task) && len(page.Tasks) < q.Limit {
+			page.Tasks = append(page.Tasks, task)
+		}
+	}
+	return page, nil
+}
+
+func (m *MockTaskRepository) Save(workspace string, task models.Task) (models.Task, error) {
+	task.Workspace = workspace
 	m.tasks[task.ID] = task
-	return task
+	return task, nil
 }
 
-func (m *MockTaskRepository) Update(id string, task models.Task) (models.Task, error) {
-	if _, exists := m.tasks[id]; !exists {
+func (m *MockTaskRepository) Update(workspace, id string, task models.Task) (models.Task, error) {
+	stored, exists := m.tasks[id]
+	if !exists || stored.Workspace != workspace {
 		return models.Task{}, repository.ErrTaskNotFound
 	}
+	if stored.Version != task.Version {
+		return models.Task{}, repository.ErrVersionConflict
+	}
 	task.ID = id
+	task.Workspace = workspace
+	task.Version++
 	m.tasks[id] = task
 	return task, nil
 }
 
-func (m *MockTaskRepository) Delete(id string) error {
-	if _, exists := m.tasks[id]; !exists {
+func (m *MockTaskRepository) Delete(workspace, id string) error {
+	if task, exists := m.tasks[id]; !exists || task.Workspace != workspace {
 		return repository.ErrTaskNotFound
 	}
 	delete(m.tasks, id)
 	return nil
 }
 
+func (m *MockTaskRepository) Workspaces() ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, task := range m.tasks {
+		if !seen[task.Workspace] {
+			seen[task.Workspace] = true
+			result = append(result, task.Workspace)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 func TestTaskService_GetTasks(t *testing.T) {
 	mockRepo := NewMockTaskRepository()
 	service := NewTaskService(mockRepo)
 
 	// Test empty repository
-	tasks := service.GetTasks()
+	tasks, err := service.GetTasks(ctx)
+	if err != nil {
+		t.Errorf("GetTasks() unexpected error: %v", err)
+	}
 	if len(tasks) != 0 {
 		t.Errorf("GetTasks() on empty repo = %v, want empty slice", tasks)
 	}
@@ -74,10 +131,13 @@ func TestTaskService_GetTasks(t *testing.T) {
 	task2 := testutils.CreateTestTask()
 	task2.ID = "2"
 
-	mockRepo.Save(task1)
-	mockRepo.Save(task2)
+	mockRepo.Save(constants.DefaultWorkspace, task1)
+	mockRepo.Save(constants.DefaultWorkspace, task2)
 
-	tasks = service.GetTasks()
+	tasks, err = service.GetTasks(ctx)
+	if err != nil {
+		t.Errorf("GetTasks() unexpected error: %v", err)
+	}
 	if len(tasks) != 2 {
 		t.Errorf("GetTasks() = %v, want 2 tasks", len(tasks))
 	}
@@ -88,10 +148,10 @@ func TestTaskService_GetTask(t *testing.T) {
 	service := NewTaskService(mockRepo)
 	task := testutils.CreateTestTask()
 	task.ID = "test-id"
-	mockRepo.Save(task)
+	mockRepo.Save(constants.DefaultWorkspace, task)
 
 	// Test getting existing task
-	retrieved, err := service.GetTask("test-id")
+	retrieved, err := service.GetTask(ctx, "test-id")
 	if err != nil {
 		t.Errorf("GetTask() unexpected error: %v", err)
 	}
@@ -100,7 +160,7 @@ func TestTaskService_GetTask(t *testing.T) {
 	}
 
 	// Test getting non-existent task
-	_, err = service.GetTask("non-existent")
+	_, err = service.GetTask(ctx, "non-existent")
 	if err != repository.ErrTaskNotFound {
 		t.Errorf("GetTask() error = %v, want %v", err, repository.ErrTaskNotFound)
 	}
@@ -154,7 +214,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			created, err := service.CreateTask(tt.task)
+			created, err := service.CreateTask(ctx, tt.task)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("CreateTask() expected error but got none")
@@ -192,7 +252,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 	service := NewTaskService(mockRepo)
 	existingTask := testutils.CreateTestTask()
 	existingTask.ID = "test-id"
-	mockRepo.Save(existingTask)
+	mockRepo.Save(constants.DefaultWorkspace, existingTask)
 
 	tests := []struct {
 		name      string
@@ -207,7 +267,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 			task: func() models.Task {
 				task := testutils.CreateTestTask()
 				task.Title = "Updated Title"
-				task.Status = constants.StatusCompleted
+				task.Status = constants.StatusInProgress
 				return task
 			}(),
 			wantError: false,
@@ -234,7 +294,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			updated, err := service.UpdateTask(tt.id, tt.task)
+			updated, err := service.UpdateTask(ctx, tt.id, tt.task, 0)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("UpdateTask() expected error but got none")
@@ -270,17 +330,677 @@ func TestTaskService_DeleteTask(t *testing.T) {
 	service := NewTaskService(mockRepo)
 	task := testutils.CreateTestTask()
 	task.ID = "test-id"
-	mockRepo.Save(task)
+	mockRepo.Save(constants.DefaultWorkspace, task)
 
 	// Test deleting existing task
-	err := service.DeleteTask("test-id")
+	err := service.DeleteTask(ctx, "test-id", 0)
 	if err != nil {
 		t.Errorf("DeleteTask() unexpected error: %v", err)
 	}
 
 	// Test deleting non-existent task
-	err = service.DeleteTask("non-existent")
+	err = service.DeleteTask(ctx, "non-existent", 0)
 	if err != repository.ErrTaskNotFound {
 		t.Errorf("DeleteTask() error = %v, want %v", err, repository.ErrTaskNotFound)
 	}
 }
+
+func TestTaskService_QueryTasks(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	service := NewTaskService(mockRepo)
+	for i, status := range []string{constants.StatusPending, constants.StatusCompleted, constants.StatusPending} {
+		task := testutils.CreateTestTaskWithStatus(status)
+		task.ID = string(rune('1' + i))
+		mockRepo.Save(constants.DefaultWorkspace, task)
+	}
+
+	tests := []struct {
+		name      string
+		query     models.TaskQuery
+		wantCount int
+		wantError bool
+	}{
+		{"No filters uses defaults", models.TaskQuery{}, 3, false},
+		{"Filter by status", models.TaskQuery{Status: constants.StatusPending}, 2, false},
+		{"Limit", models.TaskQuery{Limit: 1}, 1, false},
+		{"Invalid status", models.TaskQuery{Status: "InvalidStatus"}, 0, true},
+		{"Invalid sort field", models.TaskQuery{SortBy: "color"}, 0, true},
+		{"Limit too large", models.TaskQuery{Limit: models.MaxQueryLimit + 1}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.QueryTasks(ctx, tt.query)
+			if tt.wantError {
+				if _, ok := err.(*errors.ValidationError); !ok {
+					t.Errorf("QueryTasks() expected ValidationError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("QueryTasks() unexpected error: %v", err)
+				return
+			}
+			if len(page.Tasks) != tt.wantCount {
+				t.Errorf("QueryTasks() = %v tasks, want %v", len(page.Tasks), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestTaskService_UpdateTask_RejectsInvalidTransition(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	service := NewTaskService(mockRepo)
+	existingTask := testutils.CreateTestTaskWithStatus(constants.StatusCompleted)
+	existingTask.ID = "test-id"
+	mockRepo.Save(constants.DefaultWorkspace, existingTask)
+
+	task := testutils.CreateTestTaskWithStatus(constants.StatusPending)
+	_, err := service.UpdateTask(ctx, "test-id", task, 0)
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != http.StatusConflict {
+		t.Errorf("UpdateTask() error = %v, want 409 AppError", err)
+	}
+
+	// Editing other fields without changing status is always allowed
+	task.Status = constants.StatusCompleted
+	task.Title = "Updated Title"
+	if _, err := service.UpdateTask(ctx, "test-id", task, 0); err != nil {
+		t.Errorf("UpdateTask() unexpected error: %v", err)
+	}
+}
+
+func TestTaskService_TransitionTask(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantCode  int
+		wantError bool
+	}{
+		{"Pending to InProgress", constants.StatusPending, constants.StatusInProgress, 0, false},
+		{"InProgress to Completed", constants.StatusInProgress, constants.StatusCompleted, 0, false},
+		{"Cancelled to Pending", constants.StatusCancelled, constants.StatusPending, 0, false},
+		{"Completed to Pending", constants.StatusCompleted, constants.StatusPending, http.StatusConflict, true},
+		{"Cancelled to InProgress", constants.StatusCancelled, constants.StatusInProgress, http.StatusConflict, true},
+		{"Unknown status", constants.StatusPending, "InvalidStatus", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockTaskRepository()
+			service := NewTaskService(mockRepo)
+			task := testutils.CreateTestTaskWithStatus(tt.from)
+			task.ID = "test-id"
+			mockRepo.Save(constants.DefaultWorkspace, task)
+
+			updated, err := service.TransitionTask(ctx, "test-id", tt.to)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("TransitionTask() expected error but got none")
+				}
+				if tt.wantCode != 0 {
+					if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != tt.wantCode {
+						t.Errorf("TransitionTask() error = %v, want code %v", err, tt.wantCode)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TransitionTask() unexpected error: %v", err)
+			}
+			if updated.Status != tt.to {
+				t.Errorf("TransitionTask() status = %v, want %v", updated.Status, tt.to)
+			}
+		})
+	}
+
+	t.Run("Non-existent task", func(t *testing.T) {
+		service := NewTaskService(NewMockTaskRepository())
+		if _, err := service.TransitionTask(ctx, "non-existent", constants.StatusInProgress); err != repository.ErrTaskNotFound {
+			t.Errorf("TransitionTask() error = %v, want %v", err, repository.ErrTaskNotFound)
+		}
+	})
+}
+
+func TestTaskService_AllowedTransitions(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	graph := models.TransitionGraph{
+		constants.StatusPending: {constants.StatusCompleted},
+	}
+	service := NewTaskService(mockRepo, WithTransitions(graph))
+	task := testutils.CreateTestTaskWithStatus(constants.StatusPending)
+	task.ID = "test-id"
+	mockRepo.Save(constants.DefaultWorkspace, task)
+
+	allowed, err := service.AllowedTransitions(ctx, "test-id")
+	if err != nil {
+		t.Fatalf("AllowedTransitions() unexpected error: %v", err)
+	}
+	if len(allowed) != 1 || allowed[0] != constants.StatusCompleted {
+		t.Errorf("AllowedTransitions() = %v, want [%v]", allowed, constants.StatusCompleted)
+	}
+
+	// The custom graph replaces the default one entirely
+	if _, err := service.TransitionTask(ctx, "test-id", constants.StatusInProgress); err == nil {
+		t.Errorf("TransitionTask() expected error for transition missing from custom graph")
+	}
+}
+
+func TestTaskService_PatchTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		wantCode    int
+		check       func(t *testing.T, task models.Task)
+	}{
+		{
+			name:        "Merge patch keeps untouched fields",
+			contentType: constants.ContentTypeMergePatch,
+			patch:       `{"status":"InProgress"}`,
+			check: func(t *testing.T, task models.Task) {
+				if task.Status != constants.StatusInProgress {
+					t.Errorf("PatchTask() status = %v, want %v", task.Status, constants.StatusInProgress)
+				}
+				if task.Description != "Test Description" || task.DueDate == nil {
+					t.Errorf("PatchTask() wiped fields not in the patch: %+v", task)
+				}
+			},
+		},
+		{
+			name:        "Merge patch null removes field",
+			contentType: constants.ContentTypeMergePatch,
+			patch:       `{"dueDate":null}`,
+			check: func(t *testing.T, task models.Task) {
+				if task.DueDate != nil {
+					t.Errorf("PatchTask() dueDate = %v, want nil", task.DueDate)
+				}
+			},
+		},
+		{
+			name:        "JSON patch",
+			contentType: constants.ContentTypeJSONPatch,
+			patch:       `[{"op":"test","path":"/title","value":"Test Task"},{"op":"replace","path":"/title","value":"Patched"},{"op":"remove","path":"/assignedTo"}]`,
+			check: func(t *testing.T, task models.Task) {
+				if task.Title != "Patched" || task.AssignedTo != "" {
+					t.Errorf("PatchTask() = %+v, want patched title and no assignee", task)
+				}
+			},
+		},
+		{
+			name:        "Server-managed fields are ignored",
+			contentType: constants.ContentTypeMergePatch,
+			patch:       `{"id":"other-id","createdAt":"2000-01-01T00:00:00Z"}`,
+			check: func(t *testing.T, task models.Task) {
+				if task.ID != "test-id" || task.CreatedAt.Year() == 2000 {
+					t.Errorf("PatchTask() changed server-managed fields: %+v", task)
+				}
+			},
+		},
+		{"Patched task fails validation", constants.ContentTypeMergePatch, `{"title":null}`, http.StatusBadRequest, nil},
+		{"Patched status not allowed", constants.ContentTypeMergePatch, `{"status":"Completed"}`, http.StatusConflict, nil},
+		{"Unknown field", constants.ContentTypeMergePatch, `{"colour":"red"}`, http.StatusBadRequest, nil},
+		{"Failed test operation", constants.ContentTypeJSONPatch, `[{"op":"test","path":"/title","value":"Other"}]`, http.StatusConflict, nil},
+		{"Malformed JSON patch", constants.ContentTypeJSONPatch, `[{"op":"replace","path":"/missing","value":1}]`, http.StatusBadRequest, nil},
+		{"Unsupported content type", "application/json", `{}`, http.StatusUnsupportedMediaType, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockTaskRepository()
+			service := NewTaskService(mockRepo)
+			task := testutils.CreateTestTask()
+			task.ID = "test-id"
+			mockRepo.Save(constants.DefaultWorkspace, task)
+
+			patched, err := service.PatchTask(ctx, "test-id", tt.contentType, []byte(tt.patch), 0)
+			if tt.wantCode != 0 {
+				code := 0
+				switch e := err.(type) {
+				case *errors.AppError:
+					code = e.Code
+				case *errors.ValidationError:
+					code = http.StatusBadRequest
+				}
+				if code != tt.wantCode {
+					t.Errorf("PatchTask() error = %v, want code %v", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchTask() unexpected error: %v", err)
+			}
+			tt.check(t, patched)
+		})
+	}
+}
+
+func TestTaskService_OptimisticConcurrency(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	service := NewTaskService(mockRepo)
+
+	created, err := service.CreateTask(ctx, testutils.CreateTestTask())
+	if err != nil {
+		t.Fatalf("CreateTask() unexpected error: %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("CreateTask() version = %v, want 1", created.Version)
+	}
+
+	task := created
+	task.Title = "Updated Title"
+	updated, err := service.UpdateTask(ctx, created.ID, task, 1)
+	if err != nil {
+		t.Fatalf("UpdateTask() with current version unexpected error: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("UpdateTask() version = %v, want 2", updated.Version)
+	}
+
+	// A second writer still holding version 1 is turned away
+	if _, err := service.UpdateTask(ctx, created.ID, task, 1); err != repository.ErrVersionConflict {
+		t.Errorf("UpdateTask() with stale version error = %v, want %v", err, repository.ErrVersionConflict)
+	}
+	if _, err := service.PatchTask(ctx, created.ID, constants.ContentTypeMergePatch, []byte(`{"title":"x"}`), 1); err != repository.ErrVersionConflict {
+		t.Errorf("PatchTask() with stale version error = %v, want %v", err, repository.ErrVersionConflict)
+	}
+	if err := service.DeleteTask(ctx, created.ID, 1); err != repository.ErrVersionConflict {
+		t.Errorf("DeleteTask() with stale version error = %v, want %v", err, repository.ErrVersionConflict)
+	}
+
+	// The version in a PUT body is server-managed and does not act as a precondition
+	task.Version = 99
+	if _, err := service.UpdateTask(ctx, created.ID, task, 0); err != nil {
+		t.Errorf("UpdateTask() unconditional unexpected error: %v", err)
+	}
+
+	if err := service.DeleteTask(ctx, created.ID, 3); err != nil {
+		t.Errorf("DeleteTask() with current version unexpected error: %v", err)
+	}
+}
+
+func TestTaskService_SoftDelete(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	service := NewTaskService(mockRepo)
+	created, _ := service.CreateTask(ctx, testutils.CreateTestTask())
+
+	if err := service.DeleteTask(ctx, created.ID, 0); err != nil {
+		t.Fatalf("DeleteTask() unexpected error: %v", err)
+	}
+	// The task is kept in the repository but hidden from normal reads
+	if stored, err := mockRepo.GetByID(constants.DefaultWorkspace, created.ID); err != nil || !stored.IsDeleted() {
+		t.Errorf("repository task after delete = %+v, %v; want it marked deleted", stored, err)
+	}
+	if _, err := service.GetTask(ctx, created.ID); err != repository.ErrTaskNotFound {
+		t.Errorf("GetTask() of deleted task error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+	if tasks, _ := service.GetTasks(ctx); len(tasks) != 0 {
+		t.Errorf("GetTasks() = %v tasks, want 0", len(tasks))
+	}
+	if page, _ := service.QueryTasks(ctx, models.TaskQuery{Deleted: true}); len(page.Tasks) != 0 {
+		t.Errorf("QueryTasks() = %v tasks, want 0", len(page.Tasks))
+	}
+	if _, err := service.UpdateTask(ctx, created.ID, created, 0); err != repository.ErrTaskNotFound {
+		t.Errorf("UpdateTask() of deleted task error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+	if err := service.DeleteTask(ctx, created.ID, 0); err != repository.ErrTaskNotFound {
+		t.Errorf("DeleteTask() of deleted task error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+
+	trash, err := service.ListTrash(ctx, models.TaskQuery{})
+	if err != nil || len(trash.Tasks) != 1 || trash.Tasks[0].ID != created.ID {
+		t.Errorf("ListTrash() = %+v, %v; want the deleted task", trash.Tasks, err)
+	}
+
+	restored, err := service.RestoreTask(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("RestoreTask() unexpected error: %v", err)
+	}
+	if restored.IsDeleted() || restored.Version != created.Version+2 {
+		t.Errorf("RestoreTask() = %+v, want live task at version %d", restored, created.Version+2)
+	}
+	if _, err := service.GetTask(ctx, created.ID); err != nil {
+		t.Errorf("GetTask() after restore unexpected error: %v", err)
+	}
+
+	_, err = service.RestoreTask(ctx, created.ID)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusConflict {
+		t.Errorf("RestoreTask() of live task error = %v, want 409", err)
+	}
+	if _, err := service.RestoreTask(ctx, "non-existent"); err != repository.ErrTaskNotFound {
+		t.Errorf("RestoreTask() error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+}
+
+func TestTaskService_PurgeTrash(t *testing.T) {
+	mockRepo := NewMockTaskRepository()
+	audit := repository.NewInMemoryAuditRepo()
+	service := NewTaskService(mockRepo, WithAuditLog(audit))
+
+	now := time.Now()
+	for id, deletedAgo := range map[string]time.Duration{"old": 48 * time.Hour, "recent": time.Hour} {
+		task := testutils.CreateTestTask()
+		task.ID = id
+		deletedAt := now.Add(-deletedAgo)
+		task.DeletedAt = &deletedAt
+		mockRepo.Save(constants.DefaultWorkspace, task)
+	}
+	live := testutils.CreateTestTask()
+	live.ID = "live"
+	mockRepo.Save(constants.DefaultWorkspace, live)
+
+	purged, err := service.PurgeTrash(ctx, now.Add(-24*time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeTrash() = %v, %v; want 1 purged", purged, err)
+	}
+	if _, err := mockRepo.GetByID(constants.DefaultWorkspace, "old"); err != repository.ErrTaskNotFound {
+		t.Errorf("old trashed task still stored: %v", err)
+	}
+	for _, id := range []string{"recent", "live"} {
+		if _, err := mockRepo.GetByID(constants.DefaultWorkspace, id); err != nil {
+			t.Errorf("task %s removed by purge: %v", id, err)
+		}
+	}
+	if entries, _ := audit.List(models.AuditQuery{TaskID: "old"}); len(entries) != 1 || entries[0].Action != constants.AuditActionPurge {
+		t.Errorf("audit entries for purged task = %+v, want one purge", entries)
+	}
+}
+
+func TestRunTrashPurger(t *testing.T) {
+	// The purger runs concurrently with the test, so use the thread-safe repository
+	repo := repository.NewInMemoryTaskRepo()
+	service := NewTaskService(repo)
+	task := testutils.CreateTestTask()
+	deletedAt := time.Now().Add(-time.Hour)
+	task.DeletedAt = &deletedAt
+	repo.Save(constants.DefaultWorkspace, task)
+
+	purgeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		RunTrashPurger(purgeCtx, service, time.Minute, time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := repo.GetByID(constants.DefaultWorkspace, task.ID); err == repository.ErrTaskNotFound {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("RunTrashPurger() did not purge the expired task")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestTaskService_Subtasks(t *testing.T) {
+	service := NewTaskService(NewMockTaskRepository())
+	newTask := func(parentID string) models.Task {
+		task := testutils.CreateTestTask()
+		task.ParentID = parentID
+		created, err := service.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("CreateTask() unexpected error: %v", err)
+		}
+		return created
+	}
+	root := newTask("")
+	child := newTask(root.ID)
+	grandchild := newTask(child.ID)
+
+	if _, err := service.CreateTask(ctx, models.Task{Title: "Orphan", ParentID: "missing"}); !isValidationError(err, "parentId") {
+		t.Errorf("CreateTask() with unknown parent error = %v, want parentId validation error", err)
+	}
+
+	// Moving a task under itself or one of its descendants is rejected
+	for _, parentID := range []string{root.ID, grandchild.ID} {
+		moved := root
+		moved.ParentID = parentID
+		if _, err := service.UpdateTask(ctx, root.ID, moved, 0); !isValidationError(err, "parentId") {
+			t.Errorf("UpdateTask() nesting root under %s error = %v, want parentId validation error", parentID, err)
+		}
+	}
+	patch := []byte(`{"parentId":"` + grandchild.ID + `"}`)
+	if _, err := service.PatchTask(ctx, child.ID, constants.ContentTypeMergePatch, patch, 0); !isValidationError(err, "parentId") {
+		t.Errorf("PatchTask() creating a cycle error = %v, want parentId validation error", err)
+	}
+
+	// A parent cannot be completed while a subtask is open
+	service.TransitionTask(ctx, child.ID, constants.StatusInProgress)
+	_, err := service.TransitionTask(ctx, child.ID, constants.StatusCompleted)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusConflict {
+		t.Errorf("TransitionTask() completing parent with open subtask error = %v, want 409", err)
+	}
+	service.TransitionTask(ctx, grandchild.ID, constants.StatusCancelled)
+	if _, err := service.TransitionTask(ctx, child.ID, constants.StatusCompleted); err != nil {
+		t.Errorf("TransitionTask() completing parent with closed subtasks unexpected error: %v", err)
+	}
+
+	got, err := service.GetTask(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("GetTask() unexpected error: %v", err)
+	}
+	if got.Progress == nil || *got.Progress != (models.Progress{Subtasks: 1, Completed: 1, Percent: 100}) {
+		t.Errorf("GetTask() progress = %+v, want 1 of 1 at 100%%", got.Progress)
+	}
+
+	children, err := service.GetChildren(ctx, root.ID)
+	if err != nil || len(children) != 1 || children[0].ID != child.ID {
+		t.Errorf("GetChildren() = %+v, %v; want the child", children, err)
+	}
+	tree, err := service.GetSubtree(ctx, root.ID)
+	if err != nil || len(tree.Children) != 1 || len(tree.Children[0].Children) != 1 {
+		t.Errorf("GetSubtree() = %+v, %v; want root > child > grandchild", tree, err)
+	}
+
+	// Detaching a subtask is always allowed
+	detached := grandchild
+	detached.ParentID = ""
+	detached.Status = constants.StatusCancelled
+	if _, err := service.UpdateTask(ctx, grandchild.ID, detached, 0); err != nil {
+		t.Errorf("UpdateTask() detaching subtask unexpected error: %v", err)
+	}
+	if _, err := service.GetSubtree(ctx, "missing"); err != repository.ErrTaskNotFound {
+		t.Errorf("GetSubtree() error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+}
+
+// isValidationError reports whether err is a ValidationError on field
+func isValidationError(err error, field string) bool {
+	verr, ok := err.(*errors.ValidationError)
+	return ok && verr.Field == field
+}
+
+func TestTaskService_Dependencies(t *testing.T) {
+	service := NewTaskService(NewMockTaskRepository())
+	newTask := func(blockedBy ...string) models.Task {
+		task := testutils.CreateTestTask()
+		task.BlockedBy = blockedBy
+		created, err := service.CreateTask(ctx, task)
+		if err != nil {
+			t.Fatalf("CreateTask() unexpected error: %v", err)
+		}
+		return created
+	}
+	a := newTask()
+	b := newTask(a.ID)
+	c := newTask()
+
+	if _, err := service.CreateTask(ctx, models.Task{Title: "x", BlockedBy: []string{"missing"}}); !isValidationError(err, "blockedBy") {
+		t.Errorf("CreateTask() with unknown blocker error = %v, want blockedBy validation error", err)
+	}
+
+	// a <- b <- c, so a blocked by c would close a cycle
+	if _, err := service.AddDependency(ctx, c.ID, b.ID); err != nil {
+		t.Fatalf("AddDependency() unexpected error: %v", err)
+	}
+	if _, err := service.AddDependency(ctx, a.ID, c.ID); !isValidationError(err, "blockedBy") {
+		t.Errorf("AddDependency() closing a cycle error = %v, want blockedBy validation error", err)
+	}
+	if _, err := service.AddDependency(ctx, a.ID, a.ID); !isValidationError(err, "blockedBy") {
+		t.Errorf("AddDependency() on itself error = %v, want blockedBy validation error", err)
+	}
+
+	deps, err := service.GetDependencies(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("GetDependencies() unexpected error: %v", err)
+	}
+	if len(deps.BlockedBy) != 1 || deps.BlockedBy[0].ID != a.ID || len(deps.Blocks) != 1 || deps.Blocks[0].ID != c.ID || !deps.Blocked {
+		t.Errorf("GetDependencies() = %+v, want blocked by a and blocking c", deps)
+	}
+
+	// b cannot start until a is completed
+	_, err = service.TransitionTask(ctx, b.ID, constants.StatusInProgress)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusConflict {
+		t.Errorf("TransitionTask() starting blocked task error = %v, want 409", err)
+	}
+	if plan, _ := service.NextTasks(ctx); len(plan) != 3 || plan[0].ID != a.ID || !plan[0].Ready || plan[1].Ready {
+		t.Errorf("NextTasks() = %+v, want a ready first, then b and c waiting", plan)
+	}
+	service.TransitionTask(ctx, a.ID, constants.StatusInProgress)
+	service.TransitionTask(ctx, a.ID, constants.StatusCompleted)
+	if _, err := service.TransitionTask(ctx, b.ID, constants.StatusInProgress); err != nil {
+		t.Errorf("TransitionTask() starting unblocked task unexpected error: %v", err)
+	}
+
+	updated, err := service.RemoveDependency(ctx, c.ID, b.ID)
+	if err != nil || len(updated.BlockedBy) != 0 {
+		t.Errorf("RemoveDependency() = %+v, %v; want no blockers", updated.BlockedBy, err)
+	}
+	if _, err := service.RemoveDependency(ctx, c.ID, b.ID); err != ErrDependencyNotFound {
+		t.Errorf("RemoveDependency() of missing edge error = %v, want %v", err, ErrDependencyNotFound)
+	}
+}
+
+func TestTaskService_RecurringTasks(t *testing.T) {
+	service := NewTaskService(NewMockTaskRepository())
+	due := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC) // a Monday
+	task := testutils.CreateTestTask()
+	task.DueDate = &due
+	task.RRule = "FREQ=WEEKLY;COUNT=3;BYDAY=MO"
+
+	if _, err := service.CreateTask(ctx, models.Task{Title: "x", RRule: "FREQ=DAILY"}); !isValidationError(err, "dueDate") {
+		t.Errorf("CreateTask() recurring without due date error = %v, want dueDate validation error", err)
+	}
+	created, err := service.CreateTask(ctx, task)
+	if err != nil {
+		t.Fatalf("CreateTask() unexpected error: %v", err)
+	}
+
+	preview, err := service.PreviewOccurrences(ctx, created.ID, 5)
+	if err != nil {
+		t.Fatalf("PreviewOccurrences() unexpected error: %v", err)
+	}
+	if len(preview) != 2 || !preview[0].Equal(due.AddDate(0, 0, 7)) || !preview[1].Equal(due.AddDate(0, 0, 14)) {
+		t.Errorf("PreviewOccurrences() = %v, want the next two Mondays", preview)
+	}
+	if _, err := service.PreviewOccurrences(ctx, created.ID, 0); !isValidationError(err, "count") {
+		t.Errorf("PreviewOccurrences(0) error = %v, want count validation error", err)
+	}
+
+	// Completing each occurrence spawns the next until COUNT runs out
+	current := created
+	for i := 0; i < 3; i++ {
+		if _, err := service.TransitionTask(ctx, current.ID, constants.StatusInProgress); err != nil {
+			t.Fatalf("TransitionTask() unexpected error: %v", err)
+		}
+		completed, err := service.TransitionTask(ctx, current.ID, constants.StatusCompleted)
+		if err != nil {
+			t.Fatalf("TransitionTask() unexpected error: %v", err)
+		}
+		if handedOver := completed.RRule == ""; handedOver != (i < 2) {
+			t.Errorf("completed occurrence %d rrule = %q, want it handed over only while the series continues", i+1, completed.RRule)
+		}
+
+		tasks, _ := service.GetTasks(ctx)
+		var next *models.Task
+		for j := range tasks {
+			if tasks[j].Status == constants.StatusPending {
+				next = &tasks[j]
+			}
+		}
+		if i == 2 {
+			if next != nil || len(tasks) != 3 {
+				t.Fatalf("series should end after 3 occurrences, got %d tasks", len(tasks))
+			}
+			break
+		}
+		if next == nil {
+			t.Fatalf("no occurrence spawned after completing occurrence %d", i+1)
+		}
+		wantDue := due.AddDate(0, 0, 7*(i+1))
+		if !next.DueDate.Equal(wantDue) || next.Title != task.Title || next.AssignedTo != task.AssignedTo {
+			t.Errorf("next occurrence = %+v, want a copy due %v", next, wantDue)
+		}
+		if want := fmt.Sprintf("FREQ=WEEKLY;COUNT=%d;BYDAY=MO", 2-i); next.RRule != want {
+			t.Errorf("next occurrence rrule = %q, want %q", next.RRule, want)
+		}
+		current = *next
+	}
+}
+
+// flakyTaskRepository fails the next Save or Update when told to
+type flakyTaskRepository struct {
+	*MockTaskRepository
+	failSave, failUpdate bool
+}
+
+func (r *flakyTaskRepository) Save(workspace string, task models.Task) (models.Task, error) {
+	if r.failSave {
+		r.failSave = false
+		return models.Task{}, errors.NewAppError(http.StatusInternalServerError, "disk full")
+	}
+	return r.MockTaskRepository.Save(workspace, task)
+}
+
+func (r *flakyTaskRepository) Update(workspace, id string, task models.Task) (models.Task, error) {
+	if r.failUpdate {
+		r.failUpdate = false
+		return models.Task{}, repository.ErrVersionConflict
+	}
+	return r.MockTaskRepository.Update(workspace, id, task)
+}
+
+func TestTaskService_RecurringTaskFailures(t *testing.T) {
+	repo := &flakyTaskRepository{MockTaskRepository: NewMockTaskRepository()}
+	service := NewTaskService(repo)
+	due := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	task := testutils.CreateTestTask()
+	task.DueDate = &due
+	task.RRule = "FREQ=DAILY"
+	task.Status = constants.StatusInProgress
+	created, err := service.CreateTask(ctx, task)
+	if err != nil {
+		t.Fatalf("CreateTask() unexpected error: %v", err)
+	}
+
+	// A completion that is not stored leaves no occurrence behind
+	repo.failUpdate = true
+	if _, err := service.TransitionTask(ctx, created.ID, constants.StatusCompleted); err != repository.ErrVersionConflict {
+		t.Fatalf("TransitionTask() error = %v, want %v", err, repository.ErrVersionConflict)
+	}
+	if tasks, _ := service.GetTasks(ctx); len(tasks) != 1 || tasks[0].RRule != task.RRule {
+		t.Errorf("GetTasks() after a failed completion = %+v, want only the original task with its rule", tasks)
+	}
+
+	// An occurrence that cannot be stored fails the completion, which can
+	// then be retried
+	repo.failSave = true
+	if _, err := service.TransitionTask(ctx, created.ID, constants.StatusCompleted); err == nil {
+		t.Fatal("TransitionTask() error = nil, want the save error")
+	}
+	if got, _ := service.GetTask(ctx, created.ID); got.Status != constants.StatusInProgress || got.RRule != task.RRule {
+		t.Errorf("GetTask() after a failed occurrence = %+v, want it still in progress with its rule", got)
+	}
+	completed, err := service.TransitionTask(ctx, created.ID, constants.StatusCompleted)
+	if err != nil || completed.RRule != "" {
+		t.Fatalf("TransitionTask() retry = %+v, %v, want the rule handed over", completed, err)
+	}
+	if tasks, _ := service.GetTasks(ctx); len(tasks) != 2 {
+		t.Errorf("GetTasks() after the retry = %d tasks, want the next occurrence", len(tasks))
+	}
+}
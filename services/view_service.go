@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ViewService interface {
+	ListViews(ctx context.Context) ([]models.View, error)
+	GetView(ctx context.Context, id string) (models.View, error)
+	CreateView(ctx context.Context, view models.View) (models.View, error)
+	UpdateView(ctx context.Context, id string, view models.View) (models.View, error)
+	DeleteView(ctx context.Context, id string) error
+	QueryViewTasks(ctx context.Context, id string, q models.TaskQuery) (models.View, models.TaskPage, error)
+}
+
+type viewService struct {
+	repo   repository.ViewRepository
+	tasks  TaskService
+	policy models.RolePolicy
+}
+
+// NewViewService lets principals with tasks:read save views and run them.
+// A view is private to the actor who created it until it is shared with the
+// workspace; only its owner, or a principal with views:manage, may change
+// it. Views run through tasks, so its own permission checks apply as well.
+// A nil policy means models.DefaultRolePolicy.
+func NewViewService(repo repository.ViewRepository, tasks TaskService, policy models.RolePolicy) ViewService {
+	return &viewService{repo: repo, tasks: tasks, policy: policyOrDefault(policy)}
+}
+
+// ListViews returns the caller's own views and those shared with the
+// workspace
+func (s *viewService) ListViews(ctx context.Context) ([]models.View, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return nil, err
+	}
+	return s.repo.ListViews(WorkspaceFromContext(ctx), ActorFromContext(ctx))
+}
+
+// GetView returns a view the caller can see. Other users' private views do
+// not exist as far as the caller is concerned.
+func (s *viewService) GetView(ctx context.Context, id string) (models.View, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.View{}, err
+	}
+	view, err := s.repo.GetView(WorkspaceFromContext(ctx), id)
+	if err != nil {
+		return models.View{}, err
+	}
+	if !view.Shared && view.Owner != ActorFromContext(ctx) {
+		return models.View{}, repository.ErrViewNotFound
+	}
+	return view, nil
+}
+
+func (s *viewService) CreateView(ctx context.Context, view models.View) (models.View, error) {
+	if err := authorize(ctx, s.policy, models.PermReadTasks); err != nil {
+		return models.View{}, err
+	}
+	if err := view.Validate(); err != nil {
+		return models.View{}, err
+	}
+	view.ID = uuid.NewString()
+	view.Owner = ActorFromContext(ctx)
+	view.Columns = viewColumns(view.Columns)
+	now := time.Now()
+	view.CreatedAt = now
+	view.UpdatedAt = now
+	return s.repo.SaveView(WorkspaceFromContext(ctx), view)
+}
+
+// UpdateView replaces a view's name, filter, sort, columns and sharing.
+// The owner stays the same.
+func (s *viewService) UpdateView(ctx context.Context, id string, view models.View) (models.View, error) {
+	existing, err := s.GetView(ctx, id)
+	if err != nil {
+		return models.View{}, err
+	}
+	if err := s.authorizeChange(ctx, existing); err != nil {
+		return models.View{}, err
+	}
+	if err := view.Validate(); err != nil {
+		return models.View{}, err
+	}
+
+	updated := existing
+	updated.Name = view.Name
+	updated.Filter = view.Filter
+	updated.Sort = view.Sort
+	updated.Columns = viewColumns(view.Columns)
+	updated.Shared = view.Shared
+	updated.UpdatedAt = time.Now()
+	return s.repo.SaveView(WorkspaceFromContext(ctx), updated)
+}
+
+func (s *viewService) DeleteView(ctx context.Context, id string) error {
+	view, err := s.GetView(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeChange(ctx, view); err != nil {
+		return err
+	}
+	return s.repo.DeleteView(WorkspaceFromContext(ctx), id)
+}
+
+// QueryViewTasks runs a view for the caller. Only the cursor and limit of q
+// are used; the filter and sort order come from the view, with @me standing
+// for the caller rather than the view's owner.
+func (s *viewService) QueryViewTasks(ctx context.Context, id string, q models.TaskQuery) (models.View, models.TaskPage, error) {
+	view, err := s.GetView(ctx, id)
+	if err != nil {
+		return models.View{}, models.TaskPage{}, err
+	}
+	query, err := view.Query()
+	if err != nil {
+		return models.View{}, models.TaskPage{}, err
+	}
+	query.Cursor = q.Cursor
+	query.Limit = q.Limit
+	page, err := s.tasks.QueryTasks(ctx, query)
+	if err != nil {
+		return models.View{}, models.TaskPage{}, err
+	}
+	return view, page, nil
+}
+
+// authorizeChange fails with a 403 error unless the caller owns view or has
+// views:manage
+func (s *viewService) authorizeChange(ctx context.Context, view models.View) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || view.Owner == p.Subject || s.policy.Grants(p.Roles, models.PermManageViews) {
+		return nil
+	}
+	return errors.NewForbiddenError(fmt.Sprintf(constants.MessageForbiddenView, models.PermManageViews))
+}
+
+// viewColumns copies columns, defaulting to models.DefaultViewColumns
+func viewColumns(columns []string) []string {
+	if len(columns) == 0 {
+		return slices.Clone(models.DefaultViewColumns)
+	}
+	return slices.Clone(columns)
+}
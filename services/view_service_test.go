@@ -0,0 +1,104 @@
+package services
+
+import (
+	"slices"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestViewService(t *testing.T) {
+	tasks := NewTaskService(repository.NewInMemoryTaskRepo())
+	service := NewViewService(repository.NewInMemoryViewRepo(), tasks, nil)
+	alice := WithPrincipal(ctx, as("alice", models.RoleMember))
+	bob := WithPrincipal(ctx, as("bob", models.RoleMember))
+	ada := WithPrincipal(ctx, as("ada", models.RoleAdmin))
+
+	created := make([]models.Task, 3)
+	for i, assignee := range []string{"alice", "alice", "bob"} {
+		task := testutils.CreateTestTask()
+		task.AssignedTo = assignee
+		task.Priority = constants.PriorityHigh
+		due := time.Now().Add(time.Duration(i+1) * time.Hour)
+		task.DueDate = &due
+		var err error
+		if created[i], err = tasks.CreateTask(ctx, task); err != nil {
+			t.Fatalf("CreateTask() unexpected error: %v", err)
+		}
+	}
+
+	invalid := []struct {
+		view  models.View
+		field string
+	}{
+		{models.View{}, "name"},
+		{models.View{Name: "Broken", Filter: "status:"}, "filter"},
+		{models.View{Name: "Broken", Sort: "-owner"}, "sort"},
+		{models.View{Name: "Broken", Columns: []string{"title", "title"}}, "columns"},
+		{models.View{Name: "Broken", Columns: []string{"secret"}}, "columns"},
+	}
+	for _, tt := range invalid {
+		if _, err := service.CreateView(alice, tt.view); !isValidationError(err, tt.field) {
+			t.Errorf("CreateView(%+v) error = %v, want %s validation error", tt.view, err, tt.field)
+		}
+	}
+
+	mine, err := service.CreateView(alice, models.View{Name: "Mine", Filter: "assignedTo:@me AND priority:High", Sort: "-dueDate", Owner: "mallory"})
+	if err != nil || mine.ID == "" || mine.Owner != "alice" || mine.Workspace != constants.DefaultWorkspace ||
+		!slices.Equal(mine.Columns, models.DefaultViewColumns) {
+		t.Fatalf("CreateView() = %+v, %v, want a view owned by alice with the default columns", mine, err)
+	}
+
+	view, page, err := service.QueryViewTasks(alice, mine.ID, models.TaskQuery{Limit: 1})
+	if err != nil || view.ID != mine.ID || len(page.Tasks) != 1 || page.Tasks[0].ID != created[1].ID || page.NextCursor == "" {
+		t.Errorf("QueryViewTasks() = %+v, %+v, %v, want alice's latest task and a cursor", view, page, err)
+	}
+	if _, page, _ := service.QueryViewTasks(alice, mine.ID, models.TaskQuery{Cursor: page.NextCursor}); len(page.Tasks) != 1 || page.Tasks[0].ID != created[0].ID || page.NextCursor != "" {
+		t.Errorf("QueryViewTasks() second page = %+v, want alice's other task", page)
+	}
+
+	// Private views are invisible to others until shared
+	if _, err := service.GetView(bob, mine.ID); err != repository.ErrViewNotFound {
+		t.Errorf("GetView() of another user's private view error = %v, want %v", err, repository.ErrViewNotFound)
+	}
+	if views, _ := service.ListViews(bob); len(views) != 0 {
+		t.Errorf("ListViews() for bob = %+v, want none", views)
+	}
+	mine.Shared = true
+	if mine, err = service.UpdateView(alice, mine.ID, mine); err != nil || !mine.Shared {
+		t.Fatalf("UpdateView() = %+v, %v, want a shared view", mine, err)
+	}
+	if views, _ := service.ListViews(bob); len(views) != 1 || views[0].ID != mine.ID {
+		t.Errorf("ListViews() for bob after sharing = %+v, want alice's view", views)
+	}
+
+	// @me stands for whoever runs the view
+	if _, page, err := service.QueryViewTasks(bob, mine.ID, models.TaskQuery{}); err != nil || len(page.Tasks) != 1 || page.Tasks[0].ID != created[2].ID {
+		t.Errorf("QueryViewTasks() for bob = %+v, %v, want bob's task", page.Tasks, err)
+	}
+
+	// Only the owner or views:manage may change a shared view
+	if _, err := service.UpdateView(bob, mine.ID, models.View{Name: "Hijacked"}); !isForbidden(err) {
+		t.Errorf("UpdateView() by another member error = %v, want forbidden", err)
+	}
+	if err := service.DeleteView(bob, mine.ID); !isForbidden(err) {
+		t.Errorf("DeleteView() by another member error = %v, want forbidden", err)
+	}
+	renamed, err := service.UpdateView(ada, mine.ID, models.View{Name: "Renamed", Shared: true})
+	if err != nil || renamed.Name != "Renamed" || renamed.Owner != "alice" || renamed.Filter != "" {
+		t.Errorf("UpdateView() by admin = %+v, %v, want it renamed and still alice's", renamed, err)
+	}
+	if err := service.DeleteView(ada, mine.ID); err != nil {
+		t.Errorf("DeleteView() by admin unexpected error: %v", err)
+	}
+	if _, err := service.GetView(alice, mine.ID); err != repository.ErrViewNotFound {
+		t.Errorf("GetView() after delete error = %v, want %v", err, repository.ErrViewNotFound)
+	}
+
+	if _, err := service.GetView(WithWorkspace(alice, "team-b"), renamed.ID); err != repository.ErrViewNotFound {
+		t.Errorf("GetView() from another workspace error = %v, want %v", err, repository.ErrViewNotFound)
+	}
+}
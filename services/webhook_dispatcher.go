@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default webhook delivery settings
+const (
+	defaultWebhookAttempts  = 6
+	defaultWebhookBaseDelay = 5 * time.Second
+	defaultWebhookMaxDelay  = 5 * time.Minute
+	defaultWebhookTimeout   = 10 * time.Second
+	webhookQueueSize        = 1024
+)
+
+// SignWebhookPayload returns the X-Webhook-Signature value for a delivery:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed
+// with the webhook's secret
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookJob is one pending attempt to deliver an event to a webhook
+type webhookJob struct {
+	webhookID string
+	event     models.TaskEvent
+	body      []byte
+	attempt   int
+}
+
+// WebhookDispatcher is an EventPublisher that delivers task events to every
+// subscribed webhook in the background. Failed deliveries are retried with
+// exponential backoff and every attempt is recorded in the delivery log.
+type WebhookDispatcher struct {
+	repo          repository.WebhookRepository
+	client        *http.Client
+	allowInternal bool
+	maxAttempts   int
+	baseDelay     time.Duration
+	maxDelay      time.Duration
+	events        chan models.TaskEvent
+	jobs          chan webhookJob
+}
+
+// WebhookDispatcherOption configures optional WebhookDispatcher behaviour
+type WebhookDispatcherOption func(*WebhookDispatcher)
+
+// WithRetryPolicy sets how many times a delivery is attempted and the delay
+// before the first retry, which doubles for each later one up to maxDelay
+func WithRetryPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.maxAttempts = maxAttempts
+		d.baseDelay = baseDelay
+		d.maxDelay = maxDelay
+	}
+}
+
+// AllowInternalTargets lets deliveries connect to loopback, link-local and
+// private addresses, which are refused by default. It has no effect on a
+// client given with WithHTTPClient.
+func AllowInternalTargets() WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.allowInternal = true
+	}
+}
+
+// WithHTTPClient replaces the client used to deliver webhooks
+func WithHTTPClient(client *http.Client) WebhookDispatcherOption {
+	return func(d *WebhookDispatcher) {
+		d.client = client
+	}
+}
+
+func NewWebhookDispatcher(repo repository.WebhookRepository, opts ...WebhookDispatcherOption) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		repo:        repo,
+		maxAttempts: defaultWebhookAttempts,
+		baseDelay:   defaultWebhookBaseDelay,
+		maxDelay:    defaultWebhookMaxDelay,
+		events:      make(chan models.TaskEvent, webhookQueueSize),
+		jobs:        make(chan webhookJob, webhookQueueSize),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.client == nil {
+		d.client = newWebhookClient(d.allowInternal)
+	}
+	return d
+}
+
+// Publish queues event for delivery. It never blocks; events are dropped
+// with a log message if the queue is full.
+func (d *WebhookDispatcher) Publish(event models.TaskEvent) {
+	select {
+	case d.events <- event:
+	default:
+		log.Printf("webhook queue full, dropping %s event %s", event.Type, event.ID)
+	}
+}
+
+// Run delivers queued events using the given number of workers until ctx is
+// cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.fanOut(ctx)
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case job := <-d.jobs:
+					d.deliver(ctx, job)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// fanOut turns each event into a job for every webhook subscribed to it
+func (d *WebhookDispatcher) fanOut(ctx context.Context) {
+	for {
+		select {
+		case event := <-d.events:
+			webhooks, err := d.repo.ListWebhooks()
+			if err != nil {
+				log.Printf("failed to list webhooks for %s event %s: %v", event.Type, event.ID, err)
+				continue
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("failed to encode %s event %s: %v", event.Type, event.ID, err)
+				continue
+			}
+			for _, w := range webhooks {
+				if w.Workspace != event.Task.Workspace || !w.Subscribes(event.Type) {
+					continue
+				}
+				select {
+				case d.jobs <- webhookJob{webhookID: w.ID, event: event, body: body, attempt: 1}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver makes one attempt at a job and schedules a retry if it fails
+func (d *WebhookDispatcher) deliver(ctx context.Context, job webhookJob) {
+	// The webhook may have been changed or removed since the event was queued
+	webhook, err := d.repo.GetWebhook(job.webhookID)
+	if err != nil || !webhook.Subscribes(job.event.Type) {
+		return
+	}
+
+	start := time.Now()
+	status, err := d.post(ctx, webhook, job)
+	delivery := models.WebhookDelivery{
+		ID:         uuid.NewString(),
+		WebhookID:  webhook.ID,
+		EventID:    job.event.ID,
+		EventType:  job.event.Type,
+		Attempt:    job.attempt,
+		Success:    err == nil,
+		StatusCode: status,
+		DurationMs: time.Since(start).Milliseconds(),
+		Timestamp:  start,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if rerr := d.repo.AppendDelivery(delivery); rerr != nil {
+		log.Printf("failed to record delivery to webhook %s: %v", webhook.ID, rerr)
+	}
+
+	if err == nil || job.attempt >= d.maxAttempts || ctx.Err() != nil {
+		return
+	}
+	job.attempt++
+	time.AfterFunc(d.backoff(job.attempt-1), func() {
+		select {
+		case d.jobs <- job:
+		default:
+			log.Printf("webhook queue full, dropping retry of %s event %s", job.event.Type, job.event.ID)
+		}
+	})
+}
+
+// backoff is the delay before retrying after the given failed attempt
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	delay := d.baseDelay
+	for i := 1; i < attempt && delay < d.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxDelay)
+}
+
+// post sends the signed event and returns the response status. Any status
+// outside 2xx is an error.
+func (d *WebhookDispatcher) post(ctx context.Context, webhook models.Webhook, job webhookJob) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(job.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constants.HeaderWebhookEvent, job.event.Type)
+	req.Header.Set(constants.HeaderWebhookID, job.event.ID)
+	req.Header.Set(constants.HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(constants.HeaderWebhookSignature, SignWebhookPayload(webhook.Secret, timestamp, job.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookService interface {
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, webhook models.Webhook) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, id string, limit int) ([]models.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	repo          repository.WebhookRepository
+	policy        models.RolePolicy
+	allowInternal bool
+}
+
+// WebhookServiceOption configures optional WebhookService behaviour
+type WebhookServiceOption func(*webhookService)
+
+// AllowInternalURLs accepts webhook URLs that point at localhost or at
+// loopback, link-local and private addresses, which are rejected by default
+func AllowInternalURLs() WebhookServiceOption {
+	return func(s *webhookService) {
+		s.allowInternal = true
+	}
+}
+
+// NewWebhookService manages subscriptions on behalf of principals whose
+// roles in policy grant webhooks:manage. A nil policy means
+// models.DefaultRolePolicy.
+func NewWebhookService(repo repository.WebhookRepository, policy models.RolePolicy, opts ...WebhookServiceOption) WebhookService {
+	s := &webhookService{repo: repo, policy: policyOrDefault(policy)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// validate checks webhook and, unless internal URLs are allowed, its target
+func (s *webhookService) validate(webhook models.Webhook) error {
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+	if s.allowInternal {
+		return nil
+	}
+	return checkWebhookURL(webhook.URL)
+}
+
+// redact hides a webhook's signing secret. It is only returned when the
+// webhook is created.
+func redact(w models.Webhook) models.Webhook {
+	w.Secret = ""
+	return w
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	if err := authorize(ctx, s.policy, models.PermManageWebhooks); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.repo.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	ws := WorkspaceFromContext(ctx)
+	result := []models.Webhook{}
+	for _, w := range webhooks {
+		if w.Workspace == ws {
+			result = append(result, redact(w))
+		}
+	}
+	return result, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id string) (models.Webhook, error) {
+	if err := authorize(ctx, s.policy, models.PermManageWebhooks); err != nil {
+		return models.Webhook{}, err
+	}
+	w, err := s.get(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return redact(w), nil
+}
+
+// get loads a webhook, hiding those registered in other workspaces
+func (s *webhookService) get(ctx context.Context, id string) (models.Webhook, error) {
+	w, err := s.repo.GetWebhook(id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if w.Workspace != WorkspaceFromContext(ctx) {
+		return models.Webhook{}, repository.ErrWebhookNotFound
+	}
+	return w, nil
+}
+
+// CreateWebhook stores a new subscription, generating a signing secret if
+// none is given. The result is the only response that includes the secret.
+func (s *webhookService) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	if err := authorize(ctx, s.policy, models.PermManageWebhooks); err != nil {
+		return models.Webhook{}, err
+	}
+	if err := s.validate(webhook); err != nil {
+		return models.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return models.Webhook{}, err
+		}
+		webhook.Secret = secret
+	}
+	webhook.ID = uuid.NewString()
+	webhook.Workspace = WorkspaceFromContext(ctx)
+	now := time.Now()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	if err := s.repo.SaveWebhook(webhook); err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook replaces a subscription's URL, events and active flag. The
+// secret is only changed when a new one is given.
+func (s *webhookService) UpdateWebhook(ctx context.Context, id string, webhook models.Webhook) (models.Webhook, error) {
+	if err := authorize(ctx, s.policy, models.PermManageWebhooks); err != nil {
+		return models.Webhook{}, err
+	}
+	existing, err := s.get(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if err := s.validate(webhook); err != nil {
+		return models.Webhook{}, err
+	}
+
+	updated := existing
+	updated.URL = webhook.URL
+	updated.Events = webhook.Events
+	updated.Active = webhook.Active
+	if webhook.Secret != "" {
+		updated.Secret = webhook.Secret
+	}
+	updated.UpdatedAt = time.Now()
+
+	if err := s.repo.SaveWebhook(updated); err != nil {
+		return models.Webhook{}, err
+	}
+	return redact(updated), nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id string) error {
+	if err := authorize(ctx, s.policy, models.PermManageWebhooks); err != nil {
+		return err
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteWebhook(id)
+}
+
+// ListDeliveries lists the most recent delivery attempts for a webhook,
+// newest first
+func (s *webhookService) ListDeliveries(ctx context.Context, id string, limit int) ([]models.WebhookDelivery, error) {
+	if err := authorize(ctx, s.policy, models.PermManageWebhooks); err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > models.MaxDeliveryLimit {
+		return nil, errors.NewValidationError("limit", constants.ValidationInvalidDeliveryLimit)
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(id, limit)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+// recordingPublisher keeps every event it is given
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []models.TaskEvent
+}
+
+func (p *recordingPublisher) Publish(event models.TaskEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func TestTaskService_PublishesEvents(t *testing.T) {
+	events := &recordingPublisher{}
+	service := NewTaskService(NewMockTaskRepository(), WithEventPublisher(events))
+	actorCtx := WithActor(ctx, "alice")
+
+	created, _ := service.CreateTask(actorCtx, testutils.CreateTestTask())
+	service.TransitionTask(actorCtx, created.ID, constants.StatusInProgress)
+	service.DeleteTask(actorCtx, created.ID, 0)
+	service.RestoreTask(actorCtx, created.ID)
+	// Failed changes publish nothing
+	service.TransitionTask(actorCtx, created.ID, "Bogus")
+
+	want := []string{models.EventTaskCreated, models.EventTaskUpdated, models.EventTaskDeleted, models.EventTaskUpdated}
+	if len(events.events) != len(want) {
+		t.Fatalf("published %d events, want %d: %+v", len(events.events), len(want), events.events)
+	}
+	for i, event := range events.events {
+		if event.Type != want[i] || event.Task.ID != created.ID || event.Actor != "alice" || event.ID == "" {
+			t.Errorf("event %d = %+v, want %s for %s by alice", i, event, want[i], created.ID)
+		}
+	}
+	if deleted := events.events[2].Task; !deleted.IsDeleted() {
+		t.Errorf("task.deleted event task = %+v, want its state in the trash", deleted)
+	}
+	if events.events[0].Previous != nil {
+		t.Errorf("task.created event previous = %+v, want none", events.events[0].Previous)
+	}
+	if prev := events.events[1].Previous; prev == nil || prev.Status != constants.StatusPending {
+		t.Errorf("task.updated event previous = %+v, want the pending task", prev)
+	}
+}
+
+func TestWebhookService(t *testing.T) {
+	service := NewWebhookService(repository.NewInMemoryWebhookRepo(), nil)
+
+	if _, err := service.CreateWebhook(ctx, models.Webhook{URL: "ftp://example.com"}); !isValidationError(err, "url") {
+		t.Errorf("CreateWebhook() with bad URL error = %v, want url validation error", err)
+	}
+	if _, err := service.CreateWebhook(ctx, models.Webhook{URL: "https://example.com", Events: []string{"task.exploded"}}); !isValidationError(err, "events") {
+		t.Errorf("CreateWebhook() with unknown event error = %v, want events validation error", err)
+	}
+
+	created, err := service.CreateWebhook(ctx, models.Webhook{URL: "https://example.com/hook", Active: true})
+	if err != nil {
+		t.Fatalf("CreateWebhook() unexpected error: %v", err)
+	}
+	if created.ID == "" || len(created.Secret) != 64 {
+		t.Errorf("CreateWebhook() = %+v, want an ID and a generated secret", created)
+	}
+
+	got, err := service.GetWebhook(ctx, created.ID)
+	if err != nil || got.Secret != "" || got.URL != created.URL {
+		t.Errorf("GetWebhook() = %+v, %v, want the webhook without its secret", got, err)
+	}
+
+	updated, err := service.UpdateWebhook(ctx, created.ID, models.Webhook{URL: "https://example.com/v2", Events: []string{models.EventTaskDeleted}})
+	if err != nil {
+		t.Fatalf("UpdateWebhook() unexpected error: %v", err)
+	}
+	if updated.URL != "https://example.com/v2" || updated.Active || updated.Secret != "" {
+		t.Errorf("UpdateWebhook() = %+v, want new URL, inactive, secret hidden", updated)
+	}
+
+	if _, err := service.ListDeliveries(ctx, created.ID, 0); !isValidationError(err, "limit") {
+		t.Errorf("ListDeliveries(0) error = %v, want limit validation error", err)
+	}
+	if err := service.DeleteWebhook(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteWebhook() unexpected error: %v", err)
+	}
+	if _, err := service.ListDeliveries(ctx, created.ID, 10); err != repository.ErrWebhookNotFound {
+		t.Errorf("ListDeliveries() after delete error = %v, want %v", err, repository.ErrWebhookNotFound)
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	var (
+		mu       sync.Mutex
+		requests []received
+	)
+	arrived := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, received{header: r.Header, body: body})
+		first := len(requests) == 1
+		mu.Unlock()
+		// Fail the first attempt to exercise the retry
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		arrived <- struct{}{}
+	}))
+	defer server.Close()
+
+	repo := repository.NewInMemoryWebhookRepo()
+	// The test server listens on loopback
+	webhooks := NewWebhookService(repo, nil, AllowInternalURLs())
+	hook, _ := webhooks.CreateWebhook(ctx, models.Webhook{URL: server.URL, Secret: "s3cret", Active: true, Events: []string{models.EventTaskCreated}})
+	// Neither of these should receive anything
+	webhooks.CreateWebhook(ctx, models.Webhook{URL: server.URL, Active: true, Events: []string{models.EventTaskDeleted}})
+	webhooks.CreateWebhook(ctx, models.Webhook{URL: server.URL, Active: false})
+
+	dispatcher := NewWebhookDispatcher(repo, WithRetryPolicy(3, 10*time.Millisecond, time.Second), AllowInternalTargets())
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(runCtx, 2)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	service := NewTaskService(NewMockTaskRepository(), WithEventPublisher(dispatcher))
+	task, _ := service.CreateTask(ctx, testutils.CreateTestTask())
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("webhook received %d requests, want 2", i)
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // let the delivery log catch up and any stray request arrive
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("webhook received %d requests, want 2", len(requests))
+	}
+	for _, req := range requests {
+		ts, _ := strconv.ParseInt(req.header.Get(constants.HeaderWebhookTimestamp), 10, 64)
+		if sig := req.header.Get(constants.HeaderWebhookSignature); sig != SignWebhookPayload("s3cret", ts, req.body) {
+			t.Errorf("signature %q does not match the payload", sig)
+		}
+		if req.header.Get(constants.HeaderWebhookEvent) != models.EventTaskCreated {
+			t.Errorf("event header = %q, want %s", req.header.Get(constants.HeaderWebhookEvent), models.EventTaskCreated)
+		}
+		var event models.TaskEvent
+		if err := json.Unmarshal(req.body, &event); err != nil || event.Task.ID != task.ID || event.ID != req.header.Get(constants.HeaderWebhookID) {
+			t.Errorf("payload = %s, want the created task event", req.body)
+		}
+	}
+
+	deliveries, _ := webhooks.ListDeliveries(ctx, hook.ID, 10)
+	if len(deliveries) != 2 {
+		t.Fatalf("ListDeliveries() = %+v, want 2 attempts", deliveries)
+	}
+	if d := deliveries[1]; d.Attempt != 1 || d.Success || d.StatusCode != http.StatusServiceUnavailable || d.Error == "" {
+		t.Errorf("first attempt = %+v, want a failed 503", d)
+	}
+	if d := deliveries[0]; d.Attempt != 2 || !d.Success || d.StatusCode != http.StatusOK {
+		t.Errorf("second attempt = %+v, want a successful retry", d)
+	}
+}
+
+func TestWebhookService_InternalURLs(t *testing.T) {
+	service := NewWebhookService(repository.NewInMemoryWebhookRepo(), nil)
+	for _, url := range []string{
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://127.0.0.1/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/hook",
+		"http://172.16.3.4/hook",
+		"https://192.168.1.10/hook",
+		"http://[fd00::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		if _, err := service.CreateWebhook(ctx, models.Webhook{URL: url}); !isValidationError(err, "url") {
+			t.Errorf("CreateWebhook(%s) error = %v, want url validation error", url, err)
+		}
+	}
+
+	created, err := service.CreateWebhook(ctx, models.Webhook{URL: "https://203.0.113.7/hook"})
+	if err != nil {
+		t.Fatalf("CreateWebhook() with a public address unexpected error: %v", err)
+	}
+	if _, err := service.UpdateWebhook(ctx, created.ID, models.Webhook{URL: "http://10.1.2.3/hook"}); !isValidationError(err, "url") {
+		t.Errorf("UpdateWebhook() to a private address error = %v, want url validation error", err)
+	}
+
+	allowing := NewWebhookService(repository.NewInMemoryWebhookRepo(), nil, AllowInternalURLs())
+	if _, err := allowing.CreateWebhook(ctx, models.Webhook{URL: "http://localhost:8080/hook"}); err != nil {
+		t.Errorf("CreateWebhook() with internal URLs allowed unexpected error: %v", err)
+	}
+}
+
+func TestWebhookDispatcher_RefusesInternalTargets(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	// The URL is stored directly, as if its host had resolved elsewhere when
+	// it was registered
+	hook := models.Webhook{ID: "hook", Workspace: constants.DefaultWorkspace, URL: server.URL, Active: true}
+	job := webhookJob{webhookID: hook.ID, event: models.TaskEvent{ID: "event", Type: models.EventTaskCreated}, attempt: 1}
+
+	d := NewWebhookDispatcher(repository.NewInMemoryWebhookRepo())
+	if _, err := d.post(ctx, hook, job); err == nil || !strings.Contains(err.Error(), "internal address") {
+		t.Errorf("post() to loopback error = %v, want the internal address refused", err)
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests, want none", requests)
+	}
+
+	d = NewWebhookDispatcher(repository.NewInMemoryWebhookRepo(), AllowInternalTargets())
+	if _, err := d.post(ctx, hook, job); err != nil {
+		t.Errorf("post() with internal targets allowed unexpected error: %v", err)
+	}
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	d := NewWebhookDispatcher(repository.NewInMemoryWebhookRepo(), WithRetryPolicy(10, time.Second, 10*time.Second))
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := d.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"taskmanager/constants"
+	"taskmanager/errors"
+	"time"
+)
+
+// internalIP reports whether ip is loopback, link-local (which includes
+// cloud metadata endpoints such as 169.254.169.254), private (RFC 1918 or
+// IPv6 unique local) or unspecified. Webhooks must not reach such addresses
+// unless internal targets are allowed.
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate() || ip.IsUnspecified()
+}
+
+// checkWebhookURL rejects webhook URLs whose host is localhost or an internal
+// IP literal. Names are not resolved here: they may change what they point
+// at, so the dispatcher checks the address it actually dials.
+func checkWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.NewValidationError("url", constants.ValidationInvalidWebhookURL)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.NewValidationError("url", constants.ValidationInternalWebhookURL)
+	}
+	if ip := net.ParseIP(host); ip != nil && internalIP(ip) {
+		return errors.NewValidationError("url", constants.ValidationInternalWebhookURL)
+	}
+	return nil
+}
+
+// refuseInternal is a net.Dialer Control function that fails connections to
+// internal addresses, after any name has been resolved
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || internalIP(ip) {
+		return fmt.Errorf("webhook target %s is an internal address", host)
+	}
+	return nil
+}
+
+// newWebhookClient returns the client deliveries are made with. Unless
+// allowInternal is set it refuses to connect to internal addresses, including
+// those reached through redirects. It never uses a proxy, since the check
+// would then only see the proxy's address.
+func newWebhookClient(allowInternal bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowInternal {
+		dialer.Control = refuseInternal
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: defaultWebhookTimeout, Transport: transport}
+}
@@ -0,0 +1,18 @@
+package services
+
+import "context"
+
+// forEachWorkspace calls fn once for every workspace holding tasks, with
+// ctx confined to that workspace, and stops at the first error
+func forEachWorkspace(ctx context.Context, svc TaskService, fn func(ctx context.Context) error) error {
+	workspaces, err := svc.Workspaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ws := range workspaces {
+		if err := fn(WithWorkspace(ctx, ws)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
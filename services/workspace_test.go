@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"taskmanager/constants"
+	"taskmanager/models"
+	"taskmanager/repository"
+	"taskmanager/testutils"
+	"testing"
+	"time"
+)
+
+func TestTaskService_WorkspaceIsolation(t *testing.T) {
+	audit := repository.NewInMemoryAuditRepo()
+	bus := NewEventBus(10, nil)
+	service := NewTaskService(NewMockTaskRepository(), WithAuditLog(audit), WithEventPublisher(bus))
+	teamA, teamB := WithWorkspace(ctx, "team-a"), WithWorkspace(ctx, "team-b")
+
+	sub, err := bus.Subscribe(teamA, models.TaskEventFilter{}, "")
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer sub.Close()
+
+	ours, err := service.CreateTask(teamA, testutils.CreateTestTask())
+	if err != nil || ours.Workspace != "team-a" {
+		t.Fatalf("CreateTask() = %+v, %v, want a task in team-a", ours, err)
+	}
+	theirs, _ := service.CreateTask(teamB, testutils.CreateTestTask())
+
+	if tasks, _ := service.GetTasks(teamA); len(tasks) != 1 || tasks[0].ID != ours.ID {
+		t.Errorf("GetTasks() = %+v, want only team-a's task", tasks)
+	}
+	if _, err := service.GetTask(teamA, theirs.ID); err != repository.ErrTaskNotFound {
+		t.Errorf("GetTask() across workspaces error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+	if _, err := service.TransitionTask(teamA, theirs.ID, constants.StatusInProgress); err != repository.ErrTaskNotFound {
+		t.Errorf("TransitionTask() across workspaces error = %v, want %v", err, repository.ErrTaskNotFound)
+	}
+	if _, err := service.AddDependency(teamA, ours.ID, theirs.ID); !isValidationError(err, "blockedBy") {
+		t.Errorf("AddDependency() on another workspace's task error = %v, want blockedBy validation error", err)
+	}
+	child := testutils.CreateTestTask()
+	child.ParentID = theirs.ID
+	if _, err := service.CreateTask(teamA, child); !isValidationError(err, "parentId") {
+		t.Errorf("CreateTask() under another workspace's task error = %v, want parentId validation error", err)
+	}
+
+	entries, _ := NewAuditService(audit, NewMockTaskRepository(), nil).ListAudit(teamA, models.AuditQuery{})
+	if len(entries) != 1 || entries[0].TaskID != ours.ID {
+		t.Errorf("ListAudit() = %+v, want only team-a's entry", entries)
+	}
+	select {
+	case e := <-sub.Events():
+		if e.Task.ID != ours.ID {
+			t.Errorf("subscriber received %s, want only team-a's task", e.Task.ID)
+		}
+	default:
+		t.Error("subscriber received no event for team-a's task")
+	}
+	select {
+	case e := <-sub.Events():
+		t.Errorf("subscriber received %s from another workspace", e.Task.ID)
+	default:
+	}
+}
+
+func TestWebhookService_WorkspaceIsolation(t *testing.T) {
+	service := NewWebhookService(repository.NewInMemoryWebhookRepo(), nil)
+	teamA, teamB := WithWorkspace(ctx, "team-a"), WithWorkspace(ctx, "team-b")
+
+	created, err := service.CreateWebhook(teamA, models.Webhook{URL: "https://a.example.com/hook", Active: true})
+	if err != nil || created.Workspace != "team-a" {
+		t.Fatalf("CreateWebhook() = %+v, %v, want a webhook in team-a", created, err)
+	}
+	if webhooks, _ := service.ListWebhooks(teamB); len(webhooks) != 0 {
+		t.Errorf("ListWebhooks() in team-b = %+v, want none", webhooks)
+	}
+	if err := service.DeleteWebhook(teamB, created.ID); err != repository.ErrWebhookNotFound {
+		t.Errorf("DeleteWebhook() across workspaces error = %v, want %v", err, repository.ErrWebhookNotFound)
+	}
+}
+
+func TestBackgroundJobs_VisitEveryWorkspace(t *testing.T) {
+	service := NewTaskService(NewMockTaskRepository())
+	due := time.Now().Add(-time.Hour)
+	for _, ws := range []string{"team-a", "team-b"} {
+		task := testutils.CreateTestTask()
+		task.DueDate = &due
+		created, _ := service.CreateTask(WithWorkspace(ctx, ws), task)
+		service.DeleteTask(WithWorkspace(ctx, ws), created.ID, 0)
+		service.CreateTask(WithWorkspace(ctx, ws), task)
+	}
+
+	scheduler := NewReminderScheduler(service, repository.NewInMemoryReminderRepo(), &recordingNotifier{}, time.Hour)
+	if sent, err := scheduler.SendDue(ctx, time.Now()); err != nil || sent != 2 {
+		t.Errorf("SendDue() = %d, %v, want a reminder in each workspace", sent, err)
+	}
+
+	purged := 0
+	err := forEachWorkspace(ctx, service, func(ctx context.Context) error {
+		n, err := service.PurgeTrash(ctx, time.Now().Add(time.Minute))
+		purged += n
+		return err
+	})
+	if err != nil || purged != 2 {
+		t.Errorf("PurgeTrash() in every workspace = %d, %v, want 2", purged, err)
+	}
+}